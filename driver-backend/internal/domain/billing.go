@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// GpuFractionCostModel charges users the fraction of the hourly price of a host that corresponds to the
+	// fraction of the host's GPUs that their session requests. This is the default serverful cost model.
+	GpuFractionCostModel = "gpu-fraction"
+	// HostCostPerHourCostModel charges users the full hourly price of the host that their session runs on.
+	HostCostPerHourCostModel = "host-cph"
+
+	// AlibabaFaasCostModel charges users for the vCPU-, memory- and GPU-seconds of their serverless functions,
+	// as Alibaba Function Compute does. This is the default serverless cost model.
+	AlibabaFaasCostModel = "alibaba"
+	// AwsLambdaFaasCostModel charges users for the memory-seconds and invocations of their serverless functions,
+	// as AWS Lambda does.
+	AwsLambdaFaasCostModel = "aws-lambda"
+)
+
+// The reference host is the instance type whose prices are used by the serverful cost models.
+// It corresponds to an AWS p3.16xlarge instance.
+const (
+	ReferenceHostGPUs           = 8
+	ReferenceHostCPUs           = 64
+	ReferenceHostMemoryGB       = 488
+	ReferenceHostCostPerHourUSD = 24.48
+
+	// OneYearReservedPriceFactor and ThreeYearReservedPriceFactor are the prices of one-year and three-year
+	// reserved hosts relative to the on-demand price of the reference host.
+	OneYearReservedPriceFactor   = 0.69
+	ThreeYearReservedPriceFactor = 0.43
+)
+
+// Prices of the serverless cost models.
+const (
+	AlibabaCostPerVcpuSecondUSD = 0.0000213
+	AlibabaCostPerGBSecondUSD   = 0.0000021
+	AlibabaCostPerGpuSecondUSD  = 0.000355
+
+	AwsLambdaCostPerGBSecondUSD   = 0.0000166667
+	AwsLambdaCostPerInvocationUSD = 0.0000002
+
+	// DataTransferCostPerGBUSD is the cost of reading one GB of data from cloud storage.
+	DataTransferCostPerGBUSD = 0.09
+)
+
+var (
+	ErrInvalidBillingConfig = errors.New("invalid billing configuration")
+)
+
+// BilledResources are the resources for which a session or a training is billed.
+type BilledResources struct {
+	Gpus     int
+	Cpus     float64
+	MemoryGB float64
+}
+
+// BillingModel computes the costs incurred by users and by the provider under the billing options of
+// the Configuration.
+type BillingModel struct {
+	ServerfulCostModel string
+	FaasCostModel      string
+
+	ServerfulUserCostMultiplier     float64
+	ServerfulProviderCostMultiplier float64
+	FaasUserCostMultiplier          float64
+	FaasProviderCostMultiplier      float64
+
+	// ReservedPriceFactor is the price of the provider's hosts relative to the on-demand price.
+	ReservedPriceFactor float64
+
+	// BillInactiveReplicas indicates whether users are charged for the time that their sessions are not training,
+	// in which case that time is charged at InactiveReplicaCostMultiplier times the regular rate.
+	BillInactiveReplicas          bool
+	InactiveReplicaCostMultiplier float64
+
+	ChargeDataTransfer bool
+
+	UseResourceCredits      bool
+	ResourceCreditCPU       float64
+	ResourceCreditGPU       float64
+	ResourceCreditMemMB     float64
+	ResourceCreditCostInUSD float64
+}
+
+// NewBillingModel creates a new BillingModel from the billing options of the given Configuration.
+// A nil Configuration results in the default BillingModel.
+func NewBillingModel(opts *Configuration) (*BillingModel, error) {
+	model := &BillingModel{
+		ServerfulCostModel:              GpuFractionCostModel,
+		FaasCostModel:                   AlibabaFaasCostModel,
+		ServerfulUserCostMultiplier:     1,
+		ServerfulProviderCostMultiplier: 1,
+		FaasUserCostMultiplier:          1,
+		FaasProviderCostMultiplier:      1,
+		ReservedPriceFactor:             1,
+		InactiveReplicaCostMultiplier:   1,
+	}
+
+	if opts == nil {
+		return model, nil
+	}
+
+	switch opts.ServerfulCostModel {
+	case "":
+	case GpuFractionCostModel, HostCostPerHourCostModel:
+		model.ServerfulCostModel = opts.ServerfulCostModel
+	default:
+		return nil, fmt.Errorf("%w: unsupported serverful cost model \"%s\"", ErrInvalidBillingConfig, opts.ServerfulCostModel)
+	}
+
+	switch opts.FaasCostModel {
+	case "":
+	case AlibabaFaasCostModel, AwsLambdaFaasCostModel:
+		model.FaasCostModel = opts.FaasCostModel
+	default:
+		return nil, fmt.Errorf("%w: unsupported serverless cost model \"%s\"", ErrInvalidBillingConfig, opts.FaasCostModel)
+	}
+
+	multipliers := []struct {
+		name   string
+		value  string
+		target *float64
+	}{
+		{"serverful-user-cost-multiplier", opts.ServerfulUserCostMultiplier, &model.ServerfulUserCostMultiplier},
+		{"serverful-provider-cost-modifier", opts.ServerfulProviderCostMultiplier, &model.ServerfulProviderCostMultiplier},
+		{"faas-user-cost-multiplier", opts.ServerlessUserCostMultiplier, &model.FaasUserCostMultiplier},
+		{"faas-provider-cost-multiplier", opts.ServerlessProviderCostMultiplier, &model.FaasProviderCostMultiplier},
+		{"inactive-replica-cost-multiplier", opts.NonActiveReplicaCostMultiplier, &model.InactiveReplicaCostMultiplier},
+	}
+
+	for _, multiplier := range multipliers {
+		if multiplier.value == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(multiplier.value, 64)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("%w: %s must be a non-negative number, got \"%s\"",
+				ErrInvalidBillingConfig, multiplier.name, multiplier.value)
+		}
+
+		*multiplier.target = value
+	}
+
+	if opts.UseThreeYearReservedPricingForMinimumCapacity {
+		model.ReservedPriceFactor = ThreeYearReservedPriceFactor
+	} else if opts.UseOneYearReservedPricingForMinimumCapacity {
+		model.ReservedPriceFactor = OneYearReservedPriceFactor
+	}
+
+	model.BillInactiveReplicas = opts.BillUsersForNonActiveReplicas
+	model.ChargeDataTransfer = opts.SimulateDataTransferCost
+	model.UseResourceCredits = opts.UseResourceCredits
+	model.ResourceCreditCPU = opts.ResourceCreditCPU
+	model.ResourceCreditGPU = opts.ResourceCreditGPU
+	model.ResourceCreditMemMB = opts.ResourceCreditMemMB
+	model.ResourceCreditCostInUSD = opts.ResourceCreditCostInUSD
+
+	return model, nil
+}
+
+// hostFraction returns the fraction of the reference host that the given resources occupy. For resources without
+// GPUs, this is the larger of the fractions of the host's CPUs and memory.
+func hostFraction(resources BilledResources) float64 {
+	if resources.Gpus > 0 {
+		return float64(resources.Gpus) / ReferenceHostGPUs
+	}
+
+	return max(resources.Cpus/ReferenceHostCPUs, resources.MemoryGB/ReferenceHostMemoryGB)
+}
+
+// ServerfulCostPerHour returns the hourly rate, in USD, at which a user is charged for a session
+// with the given resources under the serverful cost model, before the user cost multiplier is applied.
+func (m *BillingModel) ServerfulCostPerHour(resources BilledResources) float64 {
+	if m.ServerfulCostModel == HostCostPerHourCostModel {
+		return ReferenceHostCostPerHourUSD
+	}
+
+	return ReferenceHostCostPerHourUSD * hostFraction(resources)
+}
+
+// UserSessionCost returns the cost that a user incurs for a session with the given resources that lived for the
+// given duration, of which it spent the given duration training. If resource credits are used, then the cost is
+// returned both in USD and in resource credits; otherwise, the cost in resource credits is 0.
+func (m *BillingModel) UserSessionCost(resources BilledResources, lifetime time.Duration, trainingTime time.Duration) (costUSD float64, credits float64) {
+	var inactiveTime time.Duration
+	if m.BillInactiveReplicas && lifetime > trainingTime {
+		inactiveTime = lifetime - trainingTime
+	}
+
+	if m.UseResourceCredits {
+		credits = m.ResourceCredits(resources, trainingTime) +
+			m.ResourceCredits(resources, inactiveTime)*m.InactiveReplicaCostMultiplier
+		return credits * m.ResourceCreditCostInUSD, credits
+	}
+
+	rate := m.ServerfulCostPerHour(resources)
+	costUSD = rate*trainingTime.Hours() + rate*inactiveTime.Hours()*m.InactiveReplicaCostMultiplier
+
+	return costUSD * m.ServerfulUserCostMultiplier, 0
+}
+
+// UserFaasCost returns the cost, in USD, that a user incurs for running a training with the given resources for
+// the given duration as a serverless function.
+func (m *BillingModel) UserFaasCost(resources BilledResources, duration time.Duration) float64 {
+	seconds := duration.Seconds()
+
+	var cost float64
+	if m.FaasCostModel == AwsLambdaFaasCostModel {
+		// Lambda functions do not have GPUs, so GPUs are charged at the per-GPU rate of the reference host.
+		cost = resources.MemoryGB*seconds*AwsLambdaCostPerGBSecondUSD + AwsLambdaCostPerInvocationUSD +
+			float64(resources.Gpus)*duration.Hours()*ReferenceHostCostPerHourUSD/ReferenceHostGPUs
+	} else {
+		cost = resources.Cpus*seconds*AlibabaCostPerVcpuSecondUSD + resources.MemoryGB*seconds*AlibabaCostPerGBSecondUSD +
+			float64(resources.Gpus)*seconds*AlibabaCostPerGpuSecondUSD
+	}
+
+	return cost * m.FaasUserCostMultiplier
+}
+
+// DataTransferCost returns the cost, in USD, of reading the data of a training with the given resources from cloud
+// storage, which is proportional to the training's memory. DataTransferCost returns 0 if data transfer is not charged.
+func (m *BillingModel) DataTransferCost(resources BilledResources) float64 {
+	if !m.ChargeDataTransfer {
+		return 0
+	}
+
+	return resources.MemoryGB * DataTransferCostPerGBUSD
+}
+
+// ProviderServerfulCost returns the cost, in USD, that the provider incurs for running hosts for the
+// given aggregate lifetime.
+func (m *BillingModel) ProviderServerfulCost(aggregateHostLifetime time.Duration) float64 {
+	return aggregateHostLifetime.Hours() * ReferenceHostCostPerHourUSD * m.ReservedPriceFactor * m.ServerfulProviderCostMultiplier
+}
+
+// ProviderFaasCost returns the cost, in USD, that the provider incurs for running a training with the given
+// resources for the given duration as a serverless function, which occupies a fraction of a reference host.
+func (m *BillingModel) ProviderFaasCost(resources BilledResources, duration time.Duration) float64 {
+	return ReferenceHostCostPerHourUSD * hostFraction(resources) * duration.Hours() * m.FaasProviderCostMultiplier
+}
+
+// ResourceCredits returns the number of resource credits consumed by the given resources over the given duration.
+// Each resource is converted to credits independently; resources without a configured credit size are free.
+func (m *BillingModel) ResourceCredits(resources BilledResources, duration time.Duration) float64 {
+	hours := duration.Hours()
+
+	var credits float64
+	if m.ResourceCreditGPU > 0 {
+		credits += float64(resources.Gpus) * hours / m.ResourceCreditGPU
+	}
+
+	if m.ResourceCreditCPU > 0 {
+		credits += resources.Cpus * hours / m.ResourceCreditCPU
+	}
+
+	if m.ResourceCreditMemMB > 0 {
+		credits += resources.MemoryGB * 1000 * hours / m.ResourceCreditMemMB
+	}
+
+	return credits
+}
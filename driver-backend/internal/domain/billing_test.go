@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Billing Model Tests", func() {
+	It("Will reject invalid configurations", func() {
+		_, err := domain.NewBillingModel(&domain.Configuration{ServerfulCostModel: "per-minute"})
+		Expect(err).To(MatchError(domain.ErrInvalidBillingConfig))
+
+		_, err = domain.NewBillingModel(&domain.Configuration{FaasCostModel: "gcf"})
+		Expect(err).To(MatchError(domain.ErrInvalidBillingConfig))
+
+		_, err = domain.NewBillingModel(&domain.Configuration{ServerfulUserCostMultiplier: "double"})
+		Expect(err).To(MatchError(domain.ErrInvalidBillingConfig))
+	})
+
+	It("Will apply defaults", func() {
+		model, err := domain.NewBillingModel(nil)
+		Expect(err).To(BeNil())
+		Expect(model.ServerfulCostModel).To(Equal(domain.GpuFractionCostModel))
+		Expect(model.FaasCostModel).To(Equal(domain.AlibabaFaasCostModel))
+		Expect(model.ServerfulUserCostMultiplier).To(Equal(1.0))
+		Expect(model.ReservedPriceFactor).To(Equal(1.0))
+	})
+
+	It("Will charge users for the fraction of the host's GPUs that they use", func() {
+		model, err := domain.NewBillingModel(&domain.Configuration{ServerfulUserCostMultiplier: "2"})
+		Expect(err).To(BeNil())
+
+		resources := domain.BilledResources{Gpus: 2}
+		costUSD, credits := model.UserSessionCost(resources, 3*time.Hour, time.Hour)
+		Expect(costUSD).To(BeNumerically("~", domain.ReferenceHostCostPerHourUSD/4*2, 1e-9))
+		Expect(credits).To(Equal(0.0))
+	})
+
+	It("Will charge users for inactive replicas if configured to do so", func() {
+		model, err := domain.NewBillingModel(&domain.Configuration{
+			ServerfulCostModel:             domain.HostCostPerHourCostModel,
+			BillUsersForNonActiveReplicas:  true,
+			NonActiveReplicaCostMultiplier: "0.5",
+		})
+		Expect(err).To(BeNil())
+
+		costUSD, _ := model.UserSessionCost(domain.BilledResources{Gpus: 1}, 3*time.Hour, time.Hour)
+		Expect(costUSD).To(BeNumerically("~", domain.ReferenceHostCostPerHourUSD*2, 1e-9))
+	})
+
+	It("Will charge users in resource credits if configured to do so", func() {
+		model, err := domain.NewBillingModel(&domain.Configuration{
+			UseResourceCredits:      true,
+			ResourceCreditGPU:       1,
+			ResourceCreditCPU:       4,
+			ResourceCreditCostInUSD: 0.5,
+		})
+		Expect(err).To(BeNil())
+
+		costUSD, credits := model.UserSessionCost(domain.BilledResources{Gpus: 2, Cpus: 8}, 2*time.Hour, 2*time.Hour)
+		Expect(credits).To(BeNumerically("~", 8, 1e-9))
+		Expect(costUSD).To(BeNumerically("~", 4, 1e-9))
+	})
+
+	It("Will compute serverless, data transfer and provider costs", func() {
+		model, err := domain.NewBillingModel(&domain.Configuration{
+			FaasCostModel:            domain.AwsLambdaFaasCostModel,
+			SimulateDataTransferCost: true,
+			UseOneYearReservedPricingForMinimumCapacity: true,
+		})
+		Expect(err).To(BeNil())
+
+		resources := domain.BilledResources{MemoryGB: 2}
+		Expect(model.UserFaasCost(resources, 10*time.Second)).To(BeNumerically("~",
+			2*10*domain.AwsLambdaCostPerGBSecondUSD+domain.AwsLambdaCostPerInvocationUSD, 1e-12))
+		Expect(model.DataTransferCost(resources)).To(BeNumerically("~", 2*domain.DataTransferCostPerGBUSD, 1e-12))
+		Expect(model.ProviderServerfulCost(2 * time.Hour)).To(BeNumerically("~",
+			2*domain.ReferenceHostCostPerHourUSD*domain.OneYearReservedPriceFactor, 1e-9))
+	})
+})
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// BreakpointOnTick pauses the workload before the specified tick is issued.
+	BreakpointOnTick BreakpointKind = "tick"
+	// BreakpointOnEvent pauses the workload before a matching event is processed.
+	BreakpointOnEvent BreakpointKind = "event"
+	// BreakpointOnCondition pauses the workload at the end of any tick during which the specified condition held.
+	BreakpointOnCondition BreakpointKind = "condition"
+
+	// ConditionAnySessionDelayed holds if any session was delayed during the tick.
+	ConditionAnySessionDelayed BreakpointCondition = "any_session_delayed"
+	// ConditionAnySessionFailedTick holds if any session failed to process all of its events during the tick.
+	ConditionAnySessionFailedTick BreakpointCondition = "any_session_failed_tick"
+)
+
+var (
+	ErrInvalidBreakpoint  = errors.New("invalid breakpoint")
+	ErrBreakpointNotFound = errors.New("could not find breakpoint with the specified ID")
+)
+
+type BreakpointKind string
+
+func (k BreakpointKind) String() string {
+	return string(k)
+}
+
+type BreakpointCondition string
+
+func (c BreakpointCondition) String() string {
+	return string(c)
+}
+
+// Breakpoint instructs a workload driver to pause the workload when a particular point in the workload is reached.
+//
+// A Breakpoint of kind BreakpointOnEvent matches events whose name is EventName (if EventName is non-empty)
+// and that target the session SessionId (if SessionId is non-empty). At least one of the two must be specified.
+type Breakpoint struct {
+	Id         string              `json:"id"`
+	Kind       BreakpointKind      `json:"kind"`
+	TickNumber int64               `json:"tick_number,omitempty"` // TickNumber is used by BreakpointOnTick breakpoints.
+	SessionId  string              `json:"session_id,omitempty"`  // SessionId is used by BreakpointOnEvent breakpoints. This is the session ID from the trace.
+	EventName  string              `json:"event_name,omitempty"`  // EventName is used by BreakpointOnEvent breakpoints, such as "training-ended".
+	Condition  BreakpointCondition `json:"condition,omitempty"`   // Condition is used by BreakpointOnCondition breakpoints.
+	OneShot    bool                `json:"one_shot"`              // OneShot breakpoints are removed the first time that they are hit.
+	HitCount   int                 `json:"hit_count"`             // HitCount is the number of times the breakpoint has been hit.
+}
+
+// Validate returns nil if the Breakpoint is well-formed and an ErrInvalidBreakpoint error otherwise.
+func (b *Breakpoint) Validate() error {
+	switch b.Kind {
+	case BreakpointOnTick:
+		{
+			if b.TickNumber <= 0 {
+				return fmt.Errorf("%w: tick breakpoint requires a positive tick number", ErrInvalidBreakpoint)
+			}
+		}
+	case BreakpointOnEvent:
+		{
+			if b.EventName == "" && b.SessionId == "" {
+				return fmt.Errorf("%w: event breakpoint requires an event name and/or a session ID", ErrInvalidBreakpoint)
+			}
+		}
+	case BreakpointOnCondition:
+		{
+			if b.Condition != ConditionAnySessionDelayed && b.Condition != ConditionAnySessionFailedTick {
+				return fmt.Errorf("%w: unknown condition \"%s\"", ErrInvalidBreakpoint, b.Condition)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind \"%s\"", ErrInvalidBreakpoint, b.Kind)
+	}
+
+	return nil
+}
+
+// MatchesTick returns true if the Breakpoint is a BreakpointOnTick breakpoint for the specified tick.
+func (b *Breakpoint) MatchesTick(tickNumber int64) bool {
+	return b.Kind == BreakpointOnTick && b.TickNumber == tickNumber
+}
+
+// MatchesEvent returns true if the Breakpoint is a BreakpointOnEvent breakpoint that matches the specified event.
+func (b *Breakpoint) MatchesEvent(sessionId string, eventName string) bool {
+	if b.Kind != BreakpointOnEvent {
+		return false
+	}
+
+	if b.SessionId != "" && b.SessionId != sessionId {
+		return false
+	}
+
+	return b.EventName == "" || b.EventName == eventName
+}
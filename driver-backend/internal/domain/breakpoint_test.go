@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Breakpoint Tests", func() {
+	Context("Validation", func() {
+		It("Will accept well-formed breakpoints", func() {
+			Expect((&domain.Breakpoint{Kind: domain.BreakpointOnTick, TickNumber: 5}).Validate()).To(BeNil())
+			Expect((&domain.Breakpoint{Kind: domain.BreakpointOnEvent, EventName: "training-ended"}).Validate()).To(BeNil())
+			Expect((&domain.Breakpoint{Kind: domain.BreakpointOnEvent, SessionId: "Session1"}).Validate()).To(BeNil())
+			Expect((&domain.Breakpoint{Kind: domain.BreakpointOnCondition, Condition: domain.ConditionAnySessionDelayed}).Validate()).To(BeNil())
+		})
+
+		It("Will reject malformed breakpoints", func() {
+			malformed := []*domain.Breakpoint{
+				{Kind: domain.BreakpointOnTick},
+				{Kind: domain.BreakpointOnEvent},
+				{Kind: domain.BreakpointOnCondition, Condition: "sessions_are_sad"},
+				{Kind: "watchpoint"},
+			}
+
+			for _, bp := range malformed {
+				err := bp.Validate()
+				Expect(err).ToNot(BeNil())
+				Expect(errors.Is(err, domain.ErrInvalidBreakpoint)).To(BeTrue())
+			}
+		})
+	})
+
+	Context("Matching", func() {
+		It("Will match tick breakpoints against the correct tick only", func() {
+			bp := &domain.Breakpoint{Kind: domain.BreakpointOnTick, TickNumber: 5}
+
+			Expect(bp.MatchesTick(5)).To(BeTrue())
+			Expect(bp.MatchesTick(4)).To(BeFalse())
+			Expect(bp.MatchesEvent("Session1", "training-ended")).To(BeFalse())
+		})
+
+		It("Will match event breakpoints by session and event name", func() {
+			bp := &domain.Breakpoint{Kind: domain.BreakpointOnEvent, SessionId: "Session1", EventName: "training-ended"}
+
+			Expect(bp.MatchesEvent("Session1", "training-ended")).To(BeTrue())
+			Expect(bp.MatchesEvent("Session1", "training-started")).To(BeFalse())
+			Expect(bp.MatchesEvent("Session2", "training-ended")).To(BeFalse())
+			Expect(bp.MatchesTick(5)).To(BeFalse())
+		})
+
+		It("Will treat an unspecified session or event name as a wildcard", func() {
+			anySession := &domain.Breakpoint{Kind: domain.BreakpointOnEvent, EventName: "training-ended"}
+			Expect(anySession.MatchesEvent("Session1", "training-ended")).To(BeTrue())
+			Expect(anySession.MatchesEvent("Session2", "training-ended")).To(BeTrue())
+			Expect(anySession.MatchesEvent("Session2", "session-stopped")).To(BeFalse())
+
+			anyEvent := &domain.Breakpoint{Kind: domain.BreakpointOnEvent, SessionId: "Session1"}
+			Expect(anyEvent.MatchesEvent("Session1", "training-started")).To(BeTrue())
+			Expect(anyEvent.MatchesEvent("Session1", "session-stopped")).To(BeTrue())
+			Expect(anyEvent.MatchesEvent("Session2", "training-started")).To(BeFalse())
+		})
+	})
+})
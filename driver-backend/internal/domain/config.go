@@ -193,6 +193,93 @@ type Configuration struct {
 	BaseUrl                      string `name:"base-url" yaml:"base-url" json:"base-url" default:"/"`
 	PrometheusEndpoint           string `name:"prometheus-endpoint" yaml:"prometheus-endpoint" json:"prometheus-endpoint" default:"/metrics"`
 	WorkloadOutputDirectory      string `name:"workload_output_directory" json:"workload_output_directory" yaml:"workload_output_directory" default:"./workload_output_directory"`
+
+	///////////////////////////
+	// Jupyter Server Client //
+	///////////////////////////
+	// The following configure how the backend connects to and authenticates with the Jupyter Server at the
+	// InternalJupyterServerAddress. The JupyterServerBasePath is used as the base_url of the Jupyter Server.
+	// The JupyterServerToken and JupyterServerPassword are secrets, so they are never sent to the frontend.
+	JupyterServerToken              string `name:"jupyter-server-token" yaml:"jupyter-server-token" json:"-" description:"Token used to authenticate with the Jupyter Server. This can also be a JupyterHub API token when connecting to a JupyterHub single-user server."`
+	JupyterServerPassword           string `name:"jupyter-server-password" yaml:"jupyter-server-password" json:"-" description:"Password used to log in to the Jupyter Server if the Jupyter Server uses password authentication."`
+	JupyterServerUseTLS             bool   `name:"jupyter-server-use-tls" yaml:"jupyter-server-use-tls" json:"jupyter-server-use-tls" description:"If true, then connect to the Jupyter Server using HTTPS and WSS rather than HTTP and WS."`
+	JupyterServerCACertFile         string `name:"jupyter-server-ca-cert-file" yaml:"jupyter-server-ca-cert-file" json:"jupyter-server-ca-cert-file" description:"Path to a PEM-encoded file containing additional CA certificates to trust when connecting to the Jupyter Server."`
+	JupyterServerInsecureSkipVerify bool   `name:"jupyter-server-insecure-skip-verify" yaml:"jupyter-server-insecure-skip-verify" json:"jupyter-server-insecure-skip-verify" description:"If true, then the TLS certificate of the Jupyter Server is not verified. This should only be used for testing."`
+	JupyterServerHeaders            string `name:"jupyter-server-headers" yaml:"jupyter-server-headers" json:"jupyter-server-headers" description:"Comma-separated list of Name=Value pairs passed as a single string. Each pair is added as a header to every request sent to the Jupyter Server."`
+
+	/////////////////////////
+	// Kernel Reconnection //
+	/////////////////////////
+	// The following configure how kernel connections reconnect after losing their websocket.
+	// Zero values select the defaults of jupyter.DefaultReconnectionPolicy.
+	KernelReconnectInitialBackoffMillis int  `name:"kernel-reconnect-initial-backoff-ms" yaml:"kernel-reconnect-initial-backoff-ms" json:"kernel-reconnect-initial-backoff-ms" description:"Backoff interval, in milliseconds, after the first failed attempt to reconnect to a kernel."`
+	KernelReconnectMaxBackoffMillis     int  `name:"kernel-reconnect-max-backoff-ms" yaml:"kernel-reconnect-max-backoff-ms" json:"kernel-reconnect-max-backoff-ms" description:"Maximum backoff interval, in milliseconds, between attempts to reconnect to a kernel."`
+	KernelReconnectMaxAttempts          int  `name:"kernel-reconnect-max-attempts" yaml:"kernel-reconnect-max-attempts" json:"kernel-reconnect-max-attempts" description:"Maximum number of attempts per reconnection to a kernel. A negative value means unlimited."`
+	KernelReconnectMaxElapsedSec        int  `name:"kernel-reconnect-max-elapsed-sec" yaml:"kernel-reconnect-max-elapsed-sec" json:"kernel-reconnect-max-elapsed-sec" description:"Maximum duration, in seconds, of each reconnection to a kernel. A negative value means unlimited."`
+	KernelReconnectCircuitThreshold     int  `name:"kernel-reconnect-circuit-threshold" yaml:"kernel-reconnect-circuit-threshold" json:"kernel-reconnect-circuit-threshold" description:"Number of consecutive failed reconnections to a kernel after which no further reconnections are attempted until the cooldown elapses. A negative value disables circuit breaking."`
+	KernelReconnectCircuitCooldownSec   int  `name:"kernel-reconnect-circuit-cooldown-sec" yaml:"kernel-reconnect-circuit-cooldown-sec" json:"kernel-reconnect-circuit-cooldown-sec" description:"Duration, in seconds, for which no reconnections to a kernel are attempted once the circuit has opened."`
+	KernelReconnectDisableReplay        bool `name:"kernel-reconnect-disable-replay" yaml:"kernel-reconnect-disable-replay" json:"kernel-reconnect-disable-replay" description:"If true, then requests that are awaiting a reply when the connection to a kernel is lost are failed rather than re-sent after reconnecting."`
+
+	/////////////////////////
+	// Kernel Session Pool //
+	/////////////////////////
+	// The session pool creates kernels ahead of time so that "session-ready" events do not have to wait for
+	// kernels to be created. The pool is disabled unless session-pool-warm-kernels or session-pool-max-prewarmed is positive.
+	SessionPoolWarmKernels           int     `name:"session-pool-warm-kernels" yaml:"session-pool-warm-kernels" json:"session-pool-warm-kernels" description:"Number of idle generic kernels to keep warm for each of the session pool's kernel specs."`
+	SessionPoolKernelSpecs           string  `name:"session-pool-kernel-specs" yaml:"session-pool-kernel-specs" json:"session-pool-kernel-specs" description:"Comma-separated list of kernel spec names for which generic kernels are kept warm. Defaults to the \"distributed\" kernel spec."`
+	SessionPoolWarmMillicpus         int     `name:"session-pool-warm-millicpus" yaml:"session-pool-warm-millicpus" json:"session-pool-warm-millicpus" description:"Millicpus requested by each generic warm kernel. A generic kernel is only handed out to sessions that require no more resources than it."`
+	SessionPoolWarmMemoryMb          float64 `name:"session-pool-warm-memory-mb" yaml:"session-pool-warm-memory-mb" json:"session-pool-warm-memory-mb" description:"Memory, in MB, requested by each generic warm kernel."`
+	SessionPoolWarmGpus              int     `name:"session-pool-warm-gpus" yaml:"session-pool-warm-gpus" json:"session-pool-warm-gpus" description:"GPUs requested by each generic warm kernel."`
+	SessionPoolWarmVramGb            float64 `name:"session-pool-warm-vram-gb" yaml:"session-pool-warm-vram-gb" json:"session-pool-warm-vram-gb" description:"VRAM, in GB, requested by each generic warm kernel."`
+	SessionPoolMaxPrewarmed          int     `name:"session-pool-max-prewarmed" yaml:"session-pool-max-prewarmed" json:"session-pool-max-prewarmed" description:"Maximum number of kernels pre-created for specific upcoming sessions that have not yet been claimed. Zero disables pre-warming for upcoming sessions."`
+	SessionPoolPrewarmLookaheadTicks int     `name:"session-pool-prewarm-lookahead-ticks" yaml:"session-pool-prewarm-lookahead-ticks" json:"session-pool-prewarm-lookahead-ticks" description:"Number of ticks ahead of the current tick within which upcoming \"session-ready\" events have their kernels pre-created."`
+
+	////////////////////
+	// Output Capture //
+	////////////////////
+	// The stream, display_data, execute_result, and error outputs of each training are captured per session.
+	OutputCaptureMaxRecordsPerSession int    `name:"output-capture-max-records-per-session" yaml:"output-capture-max-records-per-session" json:"output-capture-max-records-per-session" description:"Number of most-recent output records retained in memory for each session. Older records are spilled to disk if a spill directory is configured, or else discarded."`
+	OutputCaptureSpillDirectory       string `name:"output-capture-spill-dir" yaml:"output-capture-spill-dir" json:"output-capture-spill-dir" description:"Directory to which output records evicted from memory are appended, in a workload-specific subdirectory. If left empty, then evicted records are discarded."`
+
+	////////////////////
+	// Kernel Console //
+	////////////////////
+	// The kernel console streams the messages exchanged with a workload's kernels to the dashboard via websocket.
+	KernelConsoleAllowExecute bool `name:"kernel-console-allow-execute" yaml:"kernel-console-allow-execute" json:"kernel-console-allow-execute" description:"If true, then the kernel console may send one-off \"execute_request\" messages to the kernels that it is attached to."`
+
+	///////////////////////
+	// Orphan Collection //
+	///////////////////////
+	// Jupyter sessions and kernels that outlive their workload (e.g., because the backend crashed or the workload was aborted) are reconciled against the live workloads.
+	OrphanGcIntervalSec    int  `name:"orphan-gc-interval-sec" yaml:"orphan-gc-interval-sec" json:"orphan-gc-interval-sec" description:"Interval, in seconds, at which orphaned Jupyter sessions and kernels are reconciled. If zero, then orphans are only reconciled on demand."`
+	OrphanGcGracePeriodSec int  `name:"orphan-gc-grace-period-sec" yaml:"orphan-gc-grace-period-sec" json:"orphan-gc-grace-period-sec" description:"Number of seconds that a Jupyter session or kernel must remain orphaned before it is stopped. Defaults to 300 seconds. Negative values disable the grace period."`
+	OrphanGcDryRun         bool `name:"orphan-gc-dry-run" yaml:"orphan-gc-dry-run" json:"orphan-gc-dry-run" description:"If true, then orphaned Jupyter sessions and kernels are only reported rather than stopped."`
+
+	////////////////////////
+	// Prometheus Metrics //
+	////////////////////////
+	// Each of the following is a comma-separated list of strictly-increasing histogram bucket upper bounds passed as a single string, such as "10,100,1000".
+	// If left empty, then the default buckets of the associated histogram are used.
+	TrainingDurationBucketsMillis      string `name:"training-duration-buckets-ms" yaml:"training-duration-buckets-ms" json:"training-duration-buckets-ms" description:"Comma-separated list of bucket upper bounds, in milliseconds, for the training duration histogram."`
+	SessionLifetimeBucketsSeconds      string `name:"session-lifetime-buckets-sec" yaml:"session-lifetime-buckets-sec" json:"session-lifetime-buckets-sec" description:"Comma-separated list of bucket upper bounds, in seconds, for the session lifetime histogram."`
+	JupyterSessionLatencyBucketsMillis string `name:"jupyter-session-latency-buckets-ms" yaml:"jupyter-session-latency-buckets-ms" json:"jupyter-session-latency-buckets-ms" description:"Comma-separated list of bucket upper bounds, in milliseconds, for the Jupyter session creation and termination latency histograms."`
+	ExecuteRequestLatencyBucketsMillis string `name:"execute-request-latency-buckets-ms" yaml:"execute-request-latency-buckets-ms" json:"execute-request-latency-buckets-ms" description:"Comma-separated list of bucket upper bounds, in milliseconds, for the end-to-end \"execute_request\" latency histogram."`
+	TickDurationBucketsMillis          string `name:"tick-duration-buckets-ms" yaml:"tick-duration-buckets-ms" json:"tick-duration-buckets-ms" description:"Comma-separated list of bucket upper bounds, in milliseconds, for the tick processing duration histogram."`
+
+	//////////////
+	// Alerting //
+	//////////////
+	AlertRulesFilepath string `name:"alert-rules-file" yaml:"alert-rules-file" json:"alert-rules-file" description:"Path to a .YAML file containing the definitions of one or more alert rules that are evaluated against running workloads."`
+	AlertWebhookUrl    string `name:"alert-webhook-url" yaml:"alert-webhook-url" json:"alert-webhook-url" description:"URL to which fired alerts are posted as JSON. If left empty, then alerts are only sent to the dashboard."`
+
+	///////////////////////////////
+	// Workload Lifecycle Events //
+	///////////////////////////////
+	// The EventWebhookSecret is a secret, so it is never sent to the frontend.
+	EventWebhookUrls       string `name:"event-webhook-urls" yaml:"event-webhook-urls" json:"event-webhook-urls" description:"Comma-separated list of URLs passed as a single string. Workload lifecycle events are posted to each URL as JSON."`
+	EventWebhookSecret     string `name:"event-webhook-secret" yaml:"event-webhook-secret" json:"-" description:"Secret used to sign workload lifecycle events posted to webhooks using HMAC-SHA256. If left empty, then events are not signed."`
+	EventWebhookMaxRetries int    `name:"event-webhook-max-retries" yaml:"event-webhook-max-retries" json:"event-webhook-max-retries" description:"Maximum number of times that the delivery of a workload lifecycle event to a webhook is retried."`
+	EventLogFile           string `name:"event-log-file" yaml:"event-log-file" json:"event-log-file" description:"Path to a file to which workload lifecycle events are appended as line-delimited JSON. If left empty, then events are not written to a file."`
 }
 
 func GetDefaultConfig() *Configuration {
@@ -223,6 +310,7 @@ func GetDefaultConfig() *Configuration {
 		ExpectedOriginAddresses:      "localhost,127.0.0.1",
 		TraceStep:                    60,
 		WorkloadOutputDirectory:      "./workload_output_directory",
+		EventWebhookMaxRetries:       3,
 	}
 }
 
@@ -0,0 +1,27 @@
+package domain_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Configuration Tests", func() {
+	It("Will not encode secrets as JSON", func() {
+		config := &domain.Configuration{
+			JupyterServerToken:    "jupyter-token",
+			JupyterServerPassword: "jupyter-password",
+			EventWebhookSecret:    "webhook-secret",
+		}
+
+		encoded, err := json.Marshal(config)
+		Expect(err).To(BeNil())
+
+		Expect(string(encoded)).ToNot(ContainSubstring("jupyter-token"))
+		Expect(string(encoded)).ToNot(ContainSubstring("jupyter-password"))
+		Expect(string(encoded)).ToNot(ContainSubstring("webhook-secret"))
+	})
+})
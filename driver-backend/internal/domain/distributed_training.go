@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// ColocationAny places the workers of a distributed training on any hosts. This is the default colocation.
+	ColocationAny = "any"
+	// ColocationSameHost places all the workers of a distributed training on a single host.
+	ColocationSameHost = "same-host"
+	// ColocationSpread places each worker of a distributed training on a different host.
+	ColocationSpread = "spread"
+)
+
+var (
+	ErrInvalidDistributedTraining = errors.New("invalid distributed training")
+)
+
+// DistributedTraining describes a training that runs as a gang of WorldSize workers with GpusPerWorker GPUs each.
+// The workers of a gang are scheduled together, and the training only starts once every worker is running.
+type DistributedTraining struct {
+	WorldSize     int    `name:"world_size" json:"world_size" yaml:"world_size" description:"The number of workers of the training."`
+	GpusPerWorker int    `name:"gpus_per_worker" json:"gpus_per_worker" yaml:"gpus_per_worker" description:"The number of GPUs of each worker."`
+	Colocation    string `name:"colocation" json:"colocation,omitempty" yaml:"colocation" description:"Constrains the hosts of the workers: \"any\", \"same-host\" or \"spread\"."`
+}
+
+// NumGPUs returns the total number of GPUs of the workers of the DistributedTraining.
+func (t *DistributedTraining) NumGPUs() int {
+	return t.WorldSize * t.GpusPerWorker
+}
+
+// IsGang returns true if the DistributedTraining has more than one worker.
+func (t *DistributedTraining) IsGang() bool {
+	return t != nil && t.WorldSize > 1
+}
+
+// GetColocation returns the colocation of the DistributedTraining, which defaults to ColocationAny.
+func (t *DistributedTraining) GetColocation() string {
+	if t.Colocation == "" {
+		return ColocationAny
+	}
+
+	return t.Colocation
+}
+
+// Validate returns an error if the DistributedTraining is invalid.
+func (t *DistributedTraining) Validate() error {
+	if t.WorldSize < 1 {
+		return fmt.Errorf("%w: world size must be at least 1, got %d", ErrInvalidDistributedTraining, t.WorldSize)
+	}
+
+	if t.GpusPerWorker < 0 {
+		return fmt.Errorf("%w: gpus per worker must be non-negative, got %d", ErrInvalidDistributedTraining, t.GpusPerWorker)
+	}
+
+	switch t.GetColocation() {
+	case ColocationAny, ColocationSameHost, ColocationSpread:
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported colocation \"%s\"", ErrInvalidDistributedTraining, t.Colocation)
+	}
+}
+
+// DistributedTrainingConfig specifies which trainings of a workload are distributed. This is mainly used by workloads
+// that are generated from traces, whose trainings only specify their total number of GPUs.
+//
+// The trainings of the sessions listed in Sessions are distributed as specified for their session. Other trainings
+// that request at least MinGpus GPUs are split into workers of GpusPerWorker GPUs each.
+type DistributedTrainingConfig struct {
+	GpusPerWorker int                             `name:"gpus_per_worker" json:"gpus_per_worker,omitempty" yaml:"gpus_per_worker" description:"The number of GPUs of each worker of trainings that are split into workers."`
+	MinGpus       int                             `name:"min_gpus" json:"min_gpus,omitempty" yaml:"min_gpus" description:"Trainings requesting at least this many GPUs are split into workers. Defaults to more than gpus_per_worker."`
+	Colocation    string                          `name:"colocation" json:"colocation,omitempty" yaml:"colocation" description:"The colocation of the workers of trainings that are split into workers."`
+	Sessions      map[string]*DistributedTraining `name:"sessions" json:"sessions,omitempty" yaml:"sessions" description:"The distributed trainings of individual sessions."`
+}
+
+// Validate returns an error if the DistributedTrainingConfig is invalid.
+func (c *DistributedTrainingConfig) Validate() error {
+	if c.GpusPerWorker < 0 || c.MinGpus < 0 {
+		return fmt.Errorf("%w: gpus per worker and min gpus must be non-negative", ErrInvalidDistributedTraining)
+	}
+
+	split := &DistributedTraining{WorldSize: 1, GpusPerWorker: c.GpusPerWorker, Colocation: c.Colocation}
+	if err := split.Validate(); err != nil {
+		return err
+	}
+
+	for sessionId, training := range c.Sessions {
+		if training == nil {
+			return fmt.Errorf("%w: session \"%s\" has no distributed training", ErrInvalidDistributedTraining, sessionId)
+		}
+
+		if err := training.Validate(); err != nil {
+			return fmt.Errorf("session \"%s\": %w", sessionId, err)
+		}
+	}
+
+	return nil
+}
+
+// DistributedTrainingOf returns the DistributedTraining of a training of the specified session that requests the
+// given number of GPUs, or nil if the training is not distributed. The config may be nil.
+func (c *DistributedTrainingConfig) DistributedTrainingOf(sessionId string, gpus int) *DistributedTraining {
+	if c == nil {
+		return nil
+	}
+
+	if training, loaded := c.Sessions[sessionId]; loaded {
+		return training
+	}
+
+	if c.GpusPerWorker <= 0 {
+		return nil
+	}
+
+	minGpus := c.MinGpus
+	if minGpus <= 0 {
+		minGpus = c.GpusPerWorker + 1
+	}
+
+	if gpus < minGpus {
+		return nil
+	}
+
+	return &DistributedTraining{
+		WorldSize:     (gpus + c.GpusPerWorker - 1) / c.GpusPerWorker,
+		GpusPerWorker: c.GpusPerWorker,
+		Colocation:    c.Colocation,
+	}
+}
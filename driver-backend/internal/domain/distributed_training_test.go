@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Distributed Training Tests", func() {
+	It("Will reject invalid distributed trainings", func() {
+		Expect((&domain.DistributedTraining{WorldSize: 0, GpusPerWorker: 1}).Validate()).To(MatchError(domain.ErrInvalidDistributedTraining))
+		Expect((&domain.DistributedTraining{WorldSize: 2, GpusPerWorker: -1}).Validate()).To(MatchError(domain.ErrInvalidDistributedTraining))
+		Expect((&domain.DistributedTraining{WorldSize: 2, GpusPerWorker: 1, Colocation: "same-rack"}).Validate()).To(MatchError(domain.ErrInvalidDistributedTraining))
+		Expect((&domain.DistributedTrainingConfig{GpusPerWorker: 4, Colocation: "same-rack"}).Validate()).To(MatchError(domain.ErrInvalidDistributedTraining))
+		Expect((&domain.DistributedTrainingConfig{Sessions: map[string]*domain.DistributedTraining{"a": nil}}).Validate()).To(MatchError(domain.ErrInvalidDistributedTraining))
+
+		training := &domain.DistributedTraining{WorldSize: 4, GpusPerWorker: 2}
+		Expect(training.Validate()).To(BeNil())
+		Expect(training.GetColocation()).To(Equal(domain.ColocationAny))
+		Expect(training.NumGPUs()).To(Equal(8))
+	})
+
+	It("Will split large trainings into workers", func() {
+		config := &domain.DistributedTrainingConfig{
+			GpusPerWorker: 4,
+			Colocation:    domain.ColocationSpread,
+			Sessions: map[string]*domain.DistributedTraining{
+				"a": {WorldSize: 2, GpusPerWorker: 1, Colocation: domain.ColocationSameHost},
+			},
+		}
+		Expect(config.Validate()).To(BeNil())
+
+		Expect(config.DistributedTrainingOf("a", 2)).To(Equal(&domain.DistributedTraining{WorldSize: 2, GpusPerWorker: 1, Colocation: domain.ColocationSameHost}))
+		Expect(config.DistributedTrainingOf("b", 4)).To(BeNil())
+		Expect(config.DistributedTrainingOf("b", 10)).To(Equal(&domain.DistributedTraining{WorldSize: 3, GpusPerWorker: 4, Colocation: domain.ColocationSpread}))
+
+		config.MinGpus = 16
+		Expect(config.DistributedTrainingOf("b", 10)).To(BeNil())
+
+		var noConfig *domain.DistributedTrainingConfig
+		Expect(noConfig.DistributedTrainingOf("b", 10)).To(BeNil())
+	})
+
+	It("Will make coordinated resource requests for gangs", func() {
+		request := domain.NewResourceRequest(4, 1024, 1, 8, domain.AnyGpuType).
+			WithDistributedTraining(&domain.DistributedTraining{WorldSize: 1, GpusPerWorker: 1})
+		Expect(request.Distributed).To(BeNil())
+
+		gang := &domain.DistributedTraining{WorldSize: 2, GpusPerWorker: 4, Colocation: domain.ColocationSpread}
+		request = request.WithDistributedTraining(gang)
+		Expect(request.Distributed).To(Equal(gang))
+		Expect(request.Gpus).To(Equal(8))
+	})
+})
@@ -16,12 +16,26 @@ const (
 	EventSessionTrainingEnded   SessionEventName = "training-ended"
 	EventSessionStopped         SessionEventName = "session-stopped"
 	EventSessionUpdateGpuUtil   SessionEventName = "update-gpu-util"
+	// EventSessionInteractiveCell is a short, CPU-only execution that a user runs between the trainings of a session.
+	EventSessionInteractiveCell SessionEventName = "interactive-cell"
 
 	// EventInvalidName is a placeholder/default value that should not appear during normal operation.
 	EventInvalidName SessionEventName = "invalid-name"
 
 	EventWorkloadStarted  WorkloadEventName = "workload-started"
 	EventWorkloadComplete WorkloadEventName = "workload-complete"
+
+	// EventQueueEventInjected records that an operator injected an ad-hoc event into a workload's event queue.
+	EventQueueEventInjected WorkloadEventName = "event-queue-event-injected"
+	// EventQueueEventDropped records that an operator dropped an event from a workload's event queue.
+	EventQueueEventDropped WorkloadEventName = "event-queue-event-dropped"
+	// EventQueueEventPostponed records that an operator postponed an event in a workload's event queue.
+	EventQueueEventPostponed WorkloadEventName = "event-queue-event-postponed"
+	// EventQueueSessionShifted records that an operator moved a session's entire schedule forward or back.
+	EventQueueSessionShifted WorkloadEventName = "event-queue-session-shifted"
+
+	// EventNotebookCellExecuted records the execution of a notebook cell by a notebook-based workload.
+	EventNotebookCellExecuted WorkloadEventName = "notebook-cell-executed"
 )
 
 type WorkloadEventName string
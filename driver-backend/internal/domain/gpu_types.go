@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+const (
+	// AnyGpuType is used by ResourceRequest structs when they do not require/request a specific GPU.
+	AnyGpuType = "ANY_GPU"
+)
+
+var (
+	ErrInvalidGpuTypes = errors.New("invalid gpu types")
+)
+
+// GpuTypes are the GPU models that are acceptable for a session or a training, in order of preference
+// (e.g., ["A100-80GB", "A100-40GB"]). The first GPU type is the preferred one, and the others are acceptable
+// alternatives to fall back to. Empty GpuTypes accept any GPU.
+type GpuTypes []string
+
+// Preferred returns the preferred GPU type, which is AnyGpuType if any GPU is acceptable.
+func (t GpuTypes) Preferred() string {
+	if len(t) == 0 {
+		return AnyGpuType
+	}
+
+	return t[0]
+}
+
+// Alternatives returns the acceptable GPU types other than the preferred one.
+func (t GpuTypes) Alternatives() []string {
+	if len(t) < 2 {
+		return nil
+	}
+
+	return t[1:]
+}
+
+// Validate returns an error if the GpuTypes are invalid.
+func (t GpuTypes) Validate() error {
+	seen := make(map[string]struct{}, len(t))
+	for _, gpuType := range t {
+		if gpuType == "" {
+			return fmt.Errorf("%w: gpu type must not be empty", ErrInvalidGpuTypes)
+		}
+
+		if gpuType == AnyGpuType && len(t) > 1 {
+			return fmt.Errorf("%w: \"%s\" cannot be combined with other gpu types", ErrInvalidGpuTypes, AnyGpuType)
+		}
+
+		if _, duplicate := seen[gpuType]; duplicate {
+			return fmt.Errorf("%w: duplicate gpu type \"%s\"", ErrInvalidGpuTypes, gpuType)
+		}
+		seen[gpuType] = struct{}{}
+	}
+
+	return nil
+}
+
+// WeightedGpuTypes are GpuTypes that are assigned to a fraction of the sessions of a workload.
+type WeightedGpuTypes struct {
+	GpuTypes GpuTypes `name:"gpu_types" json:"gpu_types" yaml:"gpu_types" description:"The acceptable GPU types, in order of preference."`
+	Weight   float64  `name:"weight" json:"weight" yaml:"weight" description:"The relative weight with which sessions are assigned these GPU types."`
+}
+
+// GpuTypeConfig specifies which GPU types the sessions of a workload require.
+//
+// The GPU types of a session are, in order of precedence: the GPU types listed for the session in Sessions (or in the
+// workload template), GPU types drawn from the Mix, and the Default GPU types. The trainings of a template session may
+// override the GPU types of their session.
+type GpuTypeConfig struct {
+	Default  GpuTypes            `name:"default" json:"default,omitempty" yaml:"default" description:"The GPU types of sessions that are not assigned any others."`
+	Sessions map[string]GpuTypes `name:"sessions" json:"sessions,omitempty" yaml:"sessions" description:"The GPU types of individual sessions."`
+	Mix      []*WeightedGpuTypes `name:"mix" json:"mix,omitempty" yaml:"mix" description:"GPU types from which the GPU types of the other sessions are drawn at random."`
+}
+
+// Validate returns an error if the GpuTypeConfig is invalid.
+func (c *GpuTypeConfig) Validate() error {
+	if err := c.Default.Validate(); err != nil {
+		return err
+	}
+
+	for sessionId, gpuTypes := range c.Sessions {
+		if err := gpuTypes.Validate(); err != nil {
+			return fmt.Errorf("session \"%s\": %w", sessionId, err)
+		}
+	}
+
+	for _, entry := range c.Mix {
+		if entry == nil || entry.Weight <= 0 {
+			return fmt.Errorf("%w: weights of the gpu type mix must be positive", ErrInvalidGpuTypes)
+		}
+
+		if err := entry.GpuTypes.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GpuTypeAssigner assigns GpuTypes to the sessions of a workload according to a GpuTypeConfig.
+type GpuTypeAssigner struct {
+	config       *GpuTypeConfig
+	sessionTypes map[string]GpuTypes // sessionTypes is a map from session ID to the GpuTypes of the session.
+	totalWeight  float64
+	rng          *rand.Rand
+	mu           sync.Mutex
+}
+
+// NewGpuTypeAssigner creates a new GpuTypeAssigner whose random assignments are seeded with the given seed.
+// The config may be nil, in which case only the GPU types set via SetSessionGpuTypes are assigned.
+func NewGpuTypeAssigner(config *GpuTypeConfig, seed int64) *GpuTypeAssigner {
+	if config == nil {
+		config = &GpuTypeConfig{}
+	}
+
+	assigner := &GpuTypeAssigner{
+		config:       config,
+		sessionTypes: make(map[string]GpuTypes, len(config.Sessions)),
+		rng:          rand.New(rand.NewSource(seed)),
+	}
+
+	for sessionId, gpuTypes := range config.Sessions {
+		assigner.sessionTypes[sessionId] = gpuTypes
+	}
+
+	for _, entry := range config.Mix {
+		assigner.totalWeight += entry.Weight
+	}
+
+	return assigner
+}
+
+// SetSessionGpuTypes records the GPU types of the specified session, unless they were already known.
+// SetSessionGpuTypes is used to pass on the GPU types of the sessions of workload templates.
+func (a *GpuTypeAssigner) SetSessionGpuTypes(sessionId string, gpuTypes GpuTypes) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, loaded := a.sessionTypes[sessionId]; !loaded && len(gpuTypes) > 0 {
+		a.sessionTypes[sessionId] = gpuTypes
+	}
+}
+
+// AssignGpuTypes returns the GPU types of the specified session. The first call for a given session decides
+// the GPU types of a session whose GPU types are not known in advance; subsequent calls return the same GPU types.
+func (a *GpuTypeAssigner) AssignGpuTypes(sessionId string) GpuTypes {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if gpuTypes, loaded := a.sessionTypes[sessionId]; loaded {
+		return gpuTypes
+	}
+
+	gpuTypes := a.config.Default
+	if a.totalWeight > 0 {
+		target := a.rng.Float64() * a.totalWeight
+		for _, entry := range a.config.Mix {
+			target -= entry.Weight
+			if target < 0 {
+				gpuTypes = entry.GpuTypes
+				break
+			}
+		}
+	}
+
+	a.sessionTypes[sessionId] = gpuTypes
+	return gpuTypes
+}
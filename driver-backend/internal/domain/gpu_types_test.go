@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("GPU Type Tests", func() {
+	It("Will reject invalid GPU types", func() {
+		Expect(domain.GpuTypes{"A100-80GB", ""}.Validate()).To(MatchError(domain.ErrInvalidGpuTypes))
+		Expect(domain.GpuTypes{"T4", "T4"}.Validate()).To(MatchError(domain.ErrInvalidGpuTypes))
+		Expect(domain.GpuTypes{domain.AnyGpuType, "T4"}.Validate()).To(MatchError(domain.ErrInvalidGpuTypes))
+		Expect((&domain.GpuTypeConfig{Mix: []*domain.WeightedGpuTypes{{GpuTypes: domain.GpuTypes{"T4"}}}}).Validate()).To(MatchError(domain.ErrInvalidGpuTypes))
+		Expect((&domain.GpuTypeConfig{Sessions: map[string]domain.GpuTypes{"a": {"T4", "T4"}}}).Validate()).To(MatchError(domain.ErrInvalidGpuTypes))
+		Expect((&domain.GpuTypeConfig{Default: domain.GpuTypes{"A100-80GB", "A100-40GB"}}).Validate()).To(BeNil())
+	})
+
+	It("Will split GPU types into the preferred GPU type and its alternatives", func() {
+		var anyGpu domain.GpuTypes
+		Expect(anyGpu.Preferred()).To(Equal(domain.AnyGpuType))
+		Expect(anyGpu.Alternatives()).To(BeNil())
+
+		request := domain.NewZeroedResourceRequest(domain.AnyGpuType).WithGpuTypes(domain.GpuTypes{"A100-80GB", "A100-40GB", "V100"})
+		Expect(request.RequestedGpuName).To(Equal("A100-80GB"))
+		Expect(request.AcceptableGpuNames).To(Equal([]string{"A100-40GB", "V100"}))
+	})
+
+	It("Will assign GPU types in order of precedence", func() {
+		assigner := domain.NewGpuTypeAssigner(&domain.GpuTypeConfig{
+			Default:  domain.GpuTypes{"T4"},
+			Sessions: map[string]domain.GpuTypes{"a": {"A100-80GB"}},
+		}, 0)
+
+		assigner.SetSessionGpuTypes("a", domain.GpuTypes{"V100"})
+		assigner.SetSessionGpuTypes("b", domain.GpuTypes{"V100"})
+
+		Expect(assigner.AssignGpuTypes("a")).To(Equal(domain.GpuTypes{"A100-80GB"}))
+		Expect(assigner.AssignGpuTypes("b")).To(Equal(domain.GpuTypes{"V100"}))
+		Expect(assigner.AssignGpuTypes("c")).To(Equal(domain.GpuTypes{"T4"}))
+
+		Expect(domain.NewGpuTypeAssigner(nil, 0).AssignGpuTypes("d")).To(BeEmpty())
+	})
+
+	It("Will draw GPU types from the mix deterministically", func() {
+		config := &domain.GpuTypeConfig{Mix: []*domain.WeightedGpuTypes{
+			{GpuTypes: domain.GpuTypes{"A100-80GB"}, Weight: 1},
+			{GpuTypes: domain.GpuTypes{"T4"}, Weight: 3},
+		}}
+
+		first := domain.NewGpuTypeAssigner(config, 7)
+		second := domain.NewGpuTypeAssigner(config, 7)
+
+		seen := make(map[string]int)
+		for i := 0; i < 200; i++ {
+			sessionId := fmt.Sprintf("session-%d", i)
+			gpuTypes := first.AssignGpuTypes(sessionId)
+			Expect(second.AssignGpuTypes(sessionId)).To(Equal(gpuTypes))
+			seen[gpuTypes.Preferred()] += 1
+		}
+
+		Expect(seen).To(HaveLen(2))
+		Expect(seen["T4"]).To(BeNumerically(">", seen["A100-80GB"]))
+	})
+})
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+const (
+	// MaxGpuUtilizationSamplesPerSession is the maximum number of GpuUtilizationSample instances that are retained
+	// by each session. The oldest samples are discarded once a session exceeds it.
+	MaxGpuUtilizationSamplesPerSession = 4096
+)
+
+// GpuUtilizationSample is a single sample of the time series of a session's GPU utilization, which is recorded
+// from the EventSessionUpdateGpuUtil events that the trace generates while the session is training.
+//
+// GpuUtilizationSample is also the resource-usage hint that is forwarded to the Cluster Gateway.
+type GpuUtilizationSample struct {
+	// Timestamp is the simulated time at which the GPU utilization was sampled.
+	Timestamp time.Time `json:"timestamp"`
+	// Utilization is the GPU utilization, in percent, summed across all the GPUs of the session.
+	Utilization float64 `json:"utilization"`
+	// NumGpus is the number of GPUs that the session is using.
+	NumGpus int `json:"num_gpus"`
+	// VramGb is the amount of VRAM, in GB, that the session is using.
+	VramGb float64 `json:"vram_gb"`
+}
+
+// NewGpuUtilizationSample creates a new GpuUtilizationSample from the given SessionMetadata.
+func NewGpuUtilizationSample(timestamp time.Time, meta SessionMetadata) *GpuUtilizationSample {
+	return &GpuUtilizationSample{
+		Timestamp:   timestamp,
+		Utilization: meta.GetGpuUtilization(),
+		NumGpus:     meta.GetNumGPUs(),
+		VramGb:      meta.GetVRAM(),
+	}
+}
+
+// PerGpuUtilization returns the mean utilization, in percent, of each of the session's GPUs.
+func (s *GpuUtilizationSample) PerGpuUtilization() float64 {
+	if s.NumGpus <= 0 {
+		return 0
+	}
+
+	return s.Utilization / float64(s.NumGpus)
+}
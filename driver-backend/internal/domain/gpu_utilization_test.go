@@ -0,0 +1,39 @@
+package domain_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("GPU Utilization Tests", func() {
+	It("Will compute the utilization of each GPU", func() {
+		sample := &domain.GpuUtilizationSample{Utilization: 150, NumGpus: 2}
+		Expect(sample.PerGpuUtilization()).To(Equal(75.0))
+
+		sample = &domain.GpuUtilizationSample{Utilization: 50}
+		Expect(sample.PerGpuUtilization()).To(BeZero())
+	})
+
+	It("Will retain only the most recent samples of a session", func() {
+		session := &domain.BasicWorkloadSession{}
+		startTime := time.Now()
+
+		numSamples := domain.MaxGpuUtilizationSamplesPerSession + 10
+		for i := 0; i < numSamples; i++ {
+			session.AddGpuUtilizationSample(&domain.GpuUtilizationSample{
+				Timestamp:   startTime.Add(time.Duration(i) * time.Second),
+				Utilization: float64(i),
+				NumGpus:     1,
+			})
+		}
+
+		series := session.GetGpuUtilizationSeries()
+		Expect(series).To(HaveLen(domain.MaxGpuUtilizationSamplesPerSession))
+		Expect(series[0].Utilization).To(Equal(10.0))
+		Expect(series[len(series)-1].Utilization).To(Equal(float64(numSamples - 1)))
+	})
+})
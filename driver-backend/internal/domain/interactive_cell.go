@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInteractiveCellDurationMillis is the default mean duration, in milliseconds, of the interactive cells
+	// that are generated from a per-session rate.
+	DefaultInteractiveCellDurationMillis = 500
+)
+
+var (
+	ErrInvalidInteractiveCellConfig = errors.New("invalid interactive cell configuration")
+)
+
+// InteractiveCellMetadata is implemented by the data of EventSessionInteractiveCell events.
+type InteractiveCellMetadata interface {
+	// GetInteractiveCellDuration returns how long the interactive cell executes for.
+	GetInteractiveCellDuration() time.Duration
+}
+
+// InteractiveCell is the data of an EventSessionInteractiveCell event that was generated from a per-session rate,
+// rather than taken from the trace. InteractiveCell embeds the SessionMetadata of the cell's session, so that the
+// event can be handled like every other session event.
+type InteractiveCell struct {
+	SessionMetadata
+
+	// Duration is how long the interactive cell executes for.
+	Duration time.Duration `json:"duration"`
+}
+
+// GetInteractiveCellDuration returns how long the interactive cell executes for.
+func (c *InteractiveCell) GetInteractiveCellDuration() time.Duration {
+	return c.Duration
+}
+
+// InteractiveCellConfig specifies the short, CPU-only cells that the users of a workload's sessions execute between
+// their trainings. Interactive cells are generated at a per-session rate, taken from the CPU bursts that the trace
+// of a CSV preset records outside of training windows, or both.
+type InteractiveCellConfig struct {
+	// CellsPerHour is the mean number of interactive cells that each session executes per hour of simulated time.
+	// The gaps between the cells of a session are exponentially distributed. If zero, then no cells are generated,
+	// unless the session's rate is specified by Sessions or by the workload template.
+	CellsPerHour float64 `name:"cells_per_hour" json:"cells_per_hour,omitempty" yaml:"cells_per_hour" description:"Mean number of interactive cells that each session executes per hour."`
+	// Sessions is a map from session ID to the rate, in cells per hour, of that session. Sessions overrides both
+	// CellsPerHour and the rates specified by the sessions of a workload template.
+	Sessions map[string]float64 `name:"sessions" json:"sessions,omitempty" yaml:"sessions" description:"Map from session ID to the number of interactive cells that the session executes per hour."`
+	// MeanDurationMillis is the mean duration, in milliseconds, of the generated interactive cells. The durations
+	// are exponentially distributed.
+	MeanDurationMillis int64 `name:"mean_duration_millis" json:"mean_duration_millis,omitempty" yaml:"mean_duration_millis" description:"Mean duration (in milliseconds) of the generated interactive cells. Defaults to 500."`
+	// FromTrace specifies that the CPU bursts recorded by the trace of a CSV preset outside of training windows
+	// are replayed as interactive cells.
+	FromTrace bool `name:"from_trace" json:"from_trace,omitempty" yaml:"from_trace" description:"Replay the CPU bursts of the trace that occur outside of training windows as interactive cells."`
+}
+
+// Validate returns an error if the InteractiveCellConfig is invalid.
+func (c *InteractiveCellConfig) Validate() error {
+	if c.CellsPerHour < 0 {
+		return fmt.Errorf("%w: rate must be non-negative, got %v", ErrInvalidInteractiveCellConfig, c.CellsPerHour)
+	}
+
+	for sessionId, rate := range c.Sessions {
+		if rate < 0 {
+			return fmt.Errorf("%w: rate of session \"%s\" must be non-negative, got %v", ErrInvalidInteractiveCellConfig, sessionId, rate)
+		}
+	}
+
+	if c.MeanDurationMillis < 0 {
+		return fmt.Errorf("%w: mean duration must be non-negative, got %d", ErrInvalidInteractiveCellConfig, c.MeanDurationMillis)
+	}
+
+	return nil
+}
+
+// RateOf returns the rate, in cells per hour, at which the specified session executes interactive cells.
+// The given template rate is the rate specified by the session's workload template, if any.
+func (c *InteractiveCellConfig) RateOf(sessionId string, templateRate float64) float64 {
+	if c == nil {
+		return templateRate
+	}
+
+	if rate, loaded := c.Sessions[sessionId]; loaded {
+		return rate
+	}
+
+	if templateRate > 0 {
+		return templateRate
+	}
+
+	return c.CellsPerHour
+}
+
+// MeanDuration returns the mean duration of the generated interactive cells.
+func (c *InteractiveCellConfig) MeanDuration() time.Duration {
+	if c == nil || c.MeanDurationMillis == 0 {
+		return time.Millisecond * DefaultInteractiveCellDurationMillis
+	}
+
+	return time.Duration(c.MeanDurationMillis) * time.Millisecond
+}
+
+// InteractiveCellSampler samples the arrivals and durations of the interactive cells of a workload.
+type InteractiveCellSampler struct {
+	config *InteractiveCellConfig
+	rng    *rand.Rand
+	mu     sync.Mutex
+}
+
+// NewInteractiveCellSampler creates a new InteractiveCellSampler for the given InteractiveCellConfig, which may
+// be nil. The samples are drawn from an RNG seeded with the given seed.
+func NewInteractiveCellSampler(config *InteractiveCellConfig, seed int64) *InteractiveCellSampler {
+	return &InteractiveCellSampler{
+		config: config,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NextArrival returns the time until the next interactive cell of a session that executes cells at the given rate,
+// in cells per hour. NextArrival returns false if the rate is not positive.
+func (s *InteractiveCellSampler) NextArrival(cellsPerHour float64) (time.Duration, bool) {
+	if cellsPerHour <= 0 {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	gap := s.rng.ExpFloat64()
+	s.mu.Unlock()
+
+	return time.Duration(gap / cellsPerHour * float64(time.Hour)), true
+}
+
+// Duration returns the duration of a generated interactive cell, which is at least one millisecond.
+func (s *InteractiveCellSampler) Duration() time.Duration {
+	s.mu.Lock()
+	duration := time.Duration(s.rng.ExpFloat64() * float64(s.config.MeanDuration()))
+	s.mu.Unlock()
+
+	return max(duration, time.Millisecond)
+}
@@ -0,0 +1,88 @@
+package domain_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Interactive Cell Tests", func() {
+	Context("Validation", func() {
+		It("Will accept the zero configuration", func() {
+			Expect((&domain.InteractiveCellConfig{}).Validate()).To(BeNil())
+		})
+
+		It("Will reject negative rates and durations", func() {
+			err := (&domain.InteractiveCellConfig{CellsPerHour: -1}).Validate()
+			Expect(err).To(MatchError(domain.ErrInvalidInteractiveCellConfig))
+
+			err = (&domain.InteractiveCellConfig{Sessions: map[string]float64{"Session1": -2}}).Validate()
+			Expect(err).To(MatchError(domain.ErrInvalidInteractiveCellConfig))
+
+			err = (&domain.InteractiveCellConfig{MeanDurationMillis: -100}).Validate()
+			Expect(err).To(MatchError(domain.ErrInvalidInteractiveCellConfig))
+		})
+	})
+
+	Context("Rates", func() {
+		It("Will prefer per-session rates over template rates over the default rate", func() {
+			config := &domain.InteractiveCellConfig{
+				CellsPerHour: 6,
+				Sessions:     map[string]float64{"Session1": 0},
+			}
+
+			Expect(config.RateOf("Session1", 12)).To(BeZero())
+			Expect(config.RateOf("Session2", 12)).To(Equal(12.0))
+			Expect(config.RateOf("Session2", 0)).To(Equal(6.0))
+		})
+
+		It("Will only use template rates without a configuration", func() {
+			var config *domain.InteractiveCellConfig
+			Expect(config.RateOf("Session1", 12)).To(Equal(12.0))
+			Expect(config.MeanDuration()).To(Equal(time.Millisecond * domain.DefaultInteractiveCellDurationMillis))
+		})
+	})
+
+	Context("Sampling", func() {
+		It("Will not generate cells for sessions without a positive rate", func() {
+			sampler := domain.NewInteractiveCellSampler(nil, 0)
+
+			_, ok := sampler.NextArrival(0)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Will generate cells at the configured rate", func() {
+			sampler := domain.NewInteractiveCellSampler(&domain.InteractiveCellConfig{MeanDurationMillis: 200}, 0)
+
+			var totalGap, totalDuration time.Duration
+			numSamples := 10000
+			for i := 0; i < numSamples; i++ {
+				gap, ok := sampler.NextArrival(60)
+				Expect(ok).To(BeTrue())
+				totalGap += gap
+
+				duration := sampler.Duration()
+				Expect(duration).To(BeNumerically(">=", time.Millisecond))
+				totalDuration += duration
+			}
+
+			Expect(totalGap / time.Duration(numSamples)).To(BeNumerically("~", time.Minute, time.Second*3))
+			Expect(totalDuration / time.Duration(numSamples)).To(BeNumerically("~", time.Millisecond*200, time.Millisecond*10))
+		})
+
+		It("Will generate the same cells for the same seed", func() {
+			first := domain.NewInteractiveCellSampler(nil, 42)
+			second := domain.NewInteractiveCellSampler(nil, 42)
+
+			for i := 0; i < 10; i++ {
+				firstGap, _ := first.NextArrival(30)
+				secondGap, _ := second.NextArrival(30)
+				Expect(firstGap).To(Equal(secondGap))
+				Expect(first.Duration()).To(Equal(second.Duration()))
+			}
+		})
+	})
+})
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	// CodeCellType is the cell_type of notebook cells that contain code.
+	CodeCellType = "code"
+
+	// ThinkTimeMetadataKey is the key of the (cell or notebook) metadata entry that specifies how long,
+	// in seconds, the user "thinks" before executing a cell. Cell-level metadata takes precedence.
+	ThinkTimeMetadataKey = "think_time_sec"
+
+	// MinimumSupportedNbformat is the oldest version of the notebook format that can be replayed.
+	MinimumSupportedNbformat = 4
+
+	ConstantThinkTime    = "constant"
+	UniformThinkTime     = "uniform"
+	ExponentialThinkTime = "exponential"
+	NormalThinkTime      = "normal"
+)
+
+var (
+	ErrInvalidNotebook              = errors.New("invalid notebook")
+	ErrUnsupportedThinkTimeDistType = errors.New("unsupported think-time distribution")
+)
+
+// Notebook is the subset of the Jupyter notebook format (nbformat v4) that is needed to replay a notebook.
+type Notebook struct {
+	Cells         []*NotebookCell        `json:"cells"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	Nbformat      int                    `json:"nbformat"`
+	NbformatMinor int                    `json:"nbformat_minor"`
+}
+
+// ParseNotebook parses the contents of an .ipynb file.
+func ParseNotebook(data []byte) (*Notebook, error) {
+	var notebook *Notebook
+	if err := json.Unmarshal(data, &notebook); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotebook, err)
+	}
+
+	if notebook == nil {
+		return nil, fmt.Errorf("%w: notebook is empty", ErrInvalidNotebook)
+	}
+
+	if notebook.Nbformat < MinimumSupportedNbformat {
+		return nil, fmt.Errorf("%w: unsupported nbformat %d (must be at least %d)",
+			ErrInvalidNotebook, notebook.Nbformat, MinimumSupportedNbformat)
+	}
+
+	return notebook, nil
+}
+
+// CodeCells returns the non-empty code cells of the Notebook in order.
+func (n *Notebook) CodeCells() []*NotebookCell {
+	cells := make([]*NotebookCell, 0, len(n.Cells))
+	for _, cell := range n.Cells {
+		if cell.CellType == CodeCellType && strings.TrimSpace(string(cell.Source)) != "" {
+			cells = append(cells, cell)
+		}
+	}
+
+	return cells
+}
+
+// ThinkTime returns the think time specified by the Notebook's metadata, if there is one.
+func (n *Notebook) ThinkTime() (time.Duration, bool) {
+	return thinkTimeFromMetadata(n.Metadata)
+}
+
+// NotebookCell is a single cell of a Notebook.
+type NotebookCell struct {
+	CellType string                 `json:"cell_type"`
+	Source   NotebookSource         `json:"source"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// ThinkTime returns the think time specified by the NotebookCell's metadata, if there is one.
+func (c *NotebookCell) ThinkTime() (time.Duration, bool) {
+	return thinkTimeFromMetadata(c.Metadata)
+}
+
+// NotebookSource is the source of a NotebookCell. In .ipynb files, the source is either a string or a list of lines.
+type NotebookSource string
+
+func (s *NotebookSource) UnmarshalJSON(data []byte) error {
+	var source string
+	if err := json.Unmarshal(data, &source); err == nil {
+		*s = NotebookSource(source)
+		return nil
+	}
+
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+
+	*s = NotebookSource(strings.Join(lines, ""))
+	return nil
+}
+
+func thinkTimeFromMetadata(metadata map[string]interface{}) (time.Duration, bool) {
+	val, ok := metadata[ThinkTimeMetadataKey]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, ok := val.(float64)
+	if !ok || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// WorkloadNotebook is a notebook that is replayed by a notebook-based workload. Each WorkloadNotebook is
+// replayed by its own session.
+type WorkloadNotebook struct {
+	// Name identifies the notebook. It is also used as the ID of the session that replays the notebook.
+	Name string `json:"name" yaml:"name"`
+
+	// Content is the contents of the .ipynb file. If Content is empty, then the notebook is read from FilePath.
+	Content json.RawMessage `json:"content,omitempty" yaml:"-"`
+
+	// FilePath is the path of an .ipynb file on the server.
+	FilePath string `json:"file_path,omitempty" yaml:"file_path"`
+
+	// StartTick is the tick at which the session that replays the notebook is started.
+	StartTick int `json:"start_tick" yaml:"start_tick"`
+
+	// ResourceRequest is the resource request of the session that replays the notebook.
+	ResourceRequest *ResourceRequest `json:"resource_request,omitempty" yaml:"resource_request"`
+}
+
+// ThinkTimeDistribution is the distribution from which the think time between cells is sampled
+// when a notebook's metadata does not specify it. All parameters are in seconds.
+type ThinkTimeDistribution struct {
+	Distribution string  `json:"distribution" yaml:"distribution"` // Distribution is one of "constant", "uniform", "exponential", or "normal".
+	MeanSec      float64 `json:"mean_sec" yaml:"mean_sec"`         // MeanSec is used by the constant, exponential, and normal distributions.
+	StdDevSec    float64 `json:"std_dev_sec" yaml:"std_dev_sec"`   // StdDevSec is used by the normal distribution.
+	MinSec       float64 `json:"min_sec" yaml:"min_sec"`           // MinSec bounds the sampled think time from below.
+	MaxSec       float64 `json:"max_sec" yaml:"max_sec"`           // MaxSec bounds the sampled think time from above, if it is positive.
+}
+
+// Validate returns an ErrUnsupportedThinkTimeDistType error if the Distribution is not supported.
+func (d *ThinkTimeDistribution) Validate() error {
+	switch d.Distribution {
+	case ConstantThinkTime, UniformThinkTime, ExponentialThinkTime, NormalThinkTime:
+		return nil
+	default:
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedThinkTimeDistType, d.Distribution)
+	}
+}
+
+// Sample samples a think time from the distribution.
+func (d *ThinkTimeDistribution) Sample(rng *rand.Rand) time.Duration {
+	var seconds float64
+	switch d.Distribution {
+	case UniformThinkTime:
+		seconds = d.MinSec + rng.Float64()*(d.MaxSec-d.MinSec)
+	case ExponentialThinkTime:
+		seconds = rng.ExpFloat64() * d.MeanSec
+	case NormalThinkTime:
+		seconds = rng.NormFloat64()*d.StdDevSec + d.MeanSec
+	default:
+		seconds = d.MeanSec
+	}
+
+	seconds = math.Max(seconds, d.MinSec)
+	if d.MaxSec > 0 {
+		seconds = math.Min(seconds, d.MaxSec)
+	}
+
+	return time.Duration(math.Max(seconds, 0) * float64(time.Second))
+}
+
+// NotebookCellExecution records the replay of a single notebook cell. It is attached to the WorkloadEvent
+// that is recorded when the cell finishes executing.
+type NotebookCellExecution struct {
+	Notebook     string        `json:"notebook"`
+	CellIndex    int           `json:"cell_index"` // CellIndex is the index of the cell among the notebook's code cells.
+	Status       string        `json:"status"`     // Status is the status of the "execute_reply".
+	LatencyMilli int64         `json:"latency_ms"` // LatencyMilli is the time from submitting the cell until receiving the "execute_reply".
+	ErrorName    string        `json:"ename,omitempty"`
+	ErrorValue   string        `json:"evalue,omitempty"`
+	Outputs      []interface{} `json:"outputs,omitempty"` // Outputs are the contents of the IOPub output messages of the cell.
+}
@@ -0,0 +1,94 @@
+package domain_test
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var notebookJson = `{
+  "nbformat": 4,
+  "nbformat_minor": 5,
+  "metadata": {"think_time_sec": 12},
+  "cells": [
+    {"cell_type": "markdown", "source": "# Title", "metadata": {}},
+    {"cell_type": "code", "source": ["import os\n", "print(os.getcwd())"], "metadata": {"think_time_sec": 3.5}},
+    {"cell_type": "code", "source": "   \n", "metadata": {}},
+    {"cell_type": "code", "source": "x = 1", "metadata": {}}
+  ]
+}`
+
+var _ = Describe("Notebook Tests", func() {
+	Context("Parsing", func() {
+		It("Will return only the non-empty code cells", func() {
+			notebook, err := domain.ParseNotebook([]byte(notebookJson))
+			Expect(err).To(BeNil())
+			Expect(notebook).ToNot(BeNil())
+
+			cells := notebook.CodeCells()
+			Expect(cells).To(HaveLen(2))
+			Expect(string(cells[0].Source)).To(Equal("import os\nprint(os.getcwd())"))
+			Expect(string(cells[1].Source)).To(Equal("x = 1"))
+		})
+
+		It("Will read think times from cell and notebook metadata", func() {
+			notebook, err := domain.ParseNotebook([]byte(notebookJson))
+			Expect(err).To(BeNil())
+
+			thinkTime, ok := notebook.ThinkTime()
+			Expect(ok).To(BeTrue())
+			Expect(thinkTime).To(Equal(time.Second * 12))
+
+			cells := notebook.CodeCells()
+			thinkTime, ok = cells[0].ThinkTime()
+			Expect(ok).To(BeTrue())
+			Expect(thinkTime).To(Equal(time.Millisecond * 3500))
+
+			_, ok = cells[1].ThinkTime()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Will reject invalid and unsupported notebooks", func() {
+			inputs := []string{
+				`not json`,
+				`null`,
+				`{"nbformat": 3, "cells": []}`,
+			}
+
+			for _, input := range inputs {
+				_, err := domain.ParseNotebook([]byte(input))
+				Expect(err).ToNot(BeNil())
+				Expect(errors.Is(err, domain.ErrInvalidNotebook)).To(BeTrue())
+			}
+		})
+	})
+
+	Context("Think-time distributions", func() {
+		It("Will reject unsupported distributions", func() {
+			err := (&domain.ThinkTimeDistribution{Distribution: "zipf"}).Validate()
+			Expect(errors.Is(err, domain.ErrUnsupportedThinkTimeDistType)).To(BeTrue())
+		})
+
+		It("Will sample think times within the configured bounds", func() {
+			rng := rand.New(rand.NewSource(0))
+			dist := &domain.ThinkTimeDistribution{Distribution: domain.NormalThinkTime, MeanSec: 10, StdDevSec: 20, MinSec: 2, MaxSec: 15}
+			Expect(dist.Validate()).To(BeNil())
+
+			for i := 0; i < 100; i++ {
+				thinkTime := dist.Sample(rng)
+				Expect(thinkTime).To(BeNumerically(">=", time.Second*2))
+				Expect(thinkTime).To(BeNumerically("<=", time.Second*15))
+			}
+		})
+
+		It("Will return the mean for constant distributions", func() {
+			dist := &domain.ThinkTimeDistribution{Distribution: domain.ConstantThinkTime, MeanSec: 4}
+			Expect(dist.Sample(rand.New(rand.NewSource(0)))).To(Equal(time.Second * 4))
+		})
+	})
+})
@@ -10,6 +10,33 @@ type ResourceRequest struct {
 	Gpus             int     `json:"gpus"`               // The number of GPUs required by the session.
 	VRAM             float64 `json:"vram"`               // The amount of VRAM (i.e., GPU memory) required in GB.
 	RequestedGpuName string  `json:"gpu_type,omitempty"` // The name of the specific GPU requested by the session.
+
+	// AcceptableGpuNames are the GPUs that are acceptable alternatives to the RequestedGpuName, in order of preference.
+	AcceptableGpuNames []string `json:"acceptable_gpu_types,omitempty"`
+
+	// Distributed is non-nil if the resources are requested for a distributed training, in which case the Gpus
+	// are split across the training's workers, all of which must be scheduled together.
+	Distributed *DistributedTraining `json:"distributed,omitempty"`
+}
+
+// WithGpuTypes sets the requested and acceptable GPUs of the ResourceRequest to the given GpuTypes,
+// and it returns the ResourceRequest.
+func (s *ResourceRequest) WithGpuTypes(gpuTypes GpuTypes) *ResourceRequest {
+	s.RequestedGpuName = gpuTypes.Preferred()
+	s.AcceptableGpuNames = gpuTypes.Alternatives()
+	return s
+}
+
+// WithDistributedTraining makes the ResourceRequest a coordinated request for all the workers of the given
+// DistributedTraining, and it returns the ResourceRequest. Trainings that are not gangs are left as-is.
+func (s *ResourceRequest) WithDistributedTraining(training *DistributedTraining) *ResourceRequest {
+	if !training.IsGang() {
+		return s
+	}
+
+	s.Distributed = training
+	s.Gpus = training.NumGPUs()
+	return s
 }
 
 // NewZeroedResourceRequest returns a ResourceRequest encoding zero current resource usage.
@@ -114,6 +114,18 @@ const (
 	// used to dynamically create a Grafana Dashboard.
 	VariablesEndpoint = "variables"
 
+	// EventQueueEndpoint is used to inspect and manipulate the event queue of a running workload.
+	EventQueueEndpoint = "event-queue"
+
+	// OutputCaptureEndpoint is used to query the captured outputs of the trainings of a workload.
+	OutputCaptureEndpoint = "workload-output"
+
+	// KernelConsoleEndpoint is the websocket endpoint that streams the messages exchanged with a workload's kernels.
+	KernelConsoleEndpoint = "kernel-console"
+
+	// OrphansEndpoint is used to inspect and reconcile the Jupyter sessions and kernels that outlived their workload.
+	OrphansEndpoint = "orphans"
+
 	// NoOpEndpoint is essentially just used to test the validity of the current authentication token.
 	NoOpEndpoint = "no-op"
 )
@@ -19,6 +19,12 @@ const (
 	SessionDiscarded     SessionState = "discarded"      // Session was not sampled for the workload.
 )
 
+const (
+	// MaxIoPubMessagesPerStream is the number of most-recent stdout and stderr IOPub messages retained by a
+	// BasicWorkloadSession. The full, per-training output of a session is captured separately.
+	MaxIoPubMessagesPerStream = 1000
+)
+
 var (
 	ErrIllegalStateTransition = errors.New("illegal state transition")
 )
@@ -100,6 +106,9 @@ type BasicWorkloadSession struct {
 	TotalDelayMilliseconds int64            `json:"total_delay_milliseconds"`
 	Discarded              bool             `json:"discarded"`
 	FailedTicks            int              `json:"failed_ticks"`
+
+	// GpuUtilizationSeries is the time series of the Session's GPU utilization during its trainings.
+	GpuUtilizationSeries []*GpuUtilizationSample `json:"gpu_utilization_series"`
 }
 
 func NewWorkloadSession(id string, meta SessionMetadata, resourceRequest *ResourceRequest, createdAtTime time.Time, atom *zap.AtomicLevel) *BasicWorkloadSession {
@@ -115,6 +124,7 @@ func NewWorkloadSession(id string, meta SessionMetadata, resourceRequest *Resour
 		StderrIoPubMessages:    make([]string, 0),
 		StdoutIoPubMessages:    make([]string, 0),
 		TotalDelayMilliseconds: 0,
+		GpuUtilizationSeries:   make([]*GpuUtilizationSample, 0),
 	}
 
 	zapConfig := zap.NewDevelopmentEncoderConfig()
@@ -198,11 +208,32 @@ func (s *BasicWorkloadSession) GetStdoutIoPubMessages() []string {
 }
 
 func (s *BasicWorkloadSession) AddStderrIoPubMessage(message string) {
-	s.StderrIoPubMessages = append(s.StderrIoPubMessages, message)
+	s.StderrIoPubMessages = appendBounded(s.StderrIoPubMessages, message, MaxIoPubMessagesPerStream)
 }
 
 func (s *BasicWorkloadSession) AddStdoutIoPubMessage(message string) {
-	s.StdoutIoPubMessages = append(s.StdoutIoPubMessages, message)
+	s.StdoutIoPubMessages = appendBounded(s.StdoutIoPubMessages, message, MaxIoPubMessagesPerStream)
+}
+
+// GetGpuUtilizationSeries returns the time series of the Session's GPU utilization during its trainings.
+func (s *BasicWorkloadSession) GetGpuUtilizationSeries() []*GpuUtilizationSample {
+	return s.GpuUtilizationSeries
+}
+
+// AddGpuUtilizationSample appends the given GpuUtilizationSample to the Session's GPU utilization time series.
+func (s *BasicWorkloadSession) AddGpuUtilizationSample(sample *GpuUtilizationSample) {
+	s.GpuUtilizationSeries = appendBounded(s.GpuUtilizationSeries, sample, MaxGpuUtilizationSamplesPerSession)
+}
+
+// appendBounded appends the message to the messages, discarding the oldest messages such that no more than
+// limit messages are retained.
+func appendBounded[T any](messages []T, message T, limit int) []T {
+	messages = append(messages, message)
+	if len(messages) > limit {
+		messages = append(messages[:0], messages[len(messages)-limit:]...)
+	}
+
+	return messages
 }
 
 // NumFailedTicks returns the number of times that this Session failed to process all of its events during a tick
@@ -291,6 +322,10 @@ func (s *BasicWorkloadSession) GetCreatedAt() time.Time {
 	return s.CreatedAt
 }
 
+func (s *BasicWorkloadSession) GetMeta() SessionMetadata {
+	return s.Meta
+}
+
 func (s *BasicWorkloadSession) GetTrainings() []*TrainingEvent {
 	return s.TrainingEvents
 }
@@ -312,6 +347,22 @@ type WorkloadTemplateSession struct {
 	NumTrainingEvents int              `json:"num_training_events"`
 	TotalExecTime     int64            `json:"total_exec_time"`
 	ExecutionTimes    []int64          `json:"-"`
+
+	// User is the name of the simulated user that owns the session. User is empty if the owner is not known,
+	// in which case the owner is chosen as specified by the TenancyConfig of the workload, if any.
+	User string `json:"user,omitempty"`
+
+	// GpuTypes are the GPU types that the session requires. GpuTypes is empty if the session accepts any GPU,
+	// in which case the GPU types are chosen as specified by the GpuTypeConfig of the workload, if any.
+	GpuTypes GpuTypes `json:"gpu_types,omitempty"`
+
+	// DependsOn are the sessions that the session depends on. The trainings of the session are held until the
+	// sessions that they depend on have made enough progress.
+	DependsOn []*SessionDependency `json:"depends_on,omitempty"`
+
+	// InteractiveCellsPerHour is the mean number of interactive cells that the session executes per hour between
+	// its trainings. If zero, then the rate is chosen as specified by the InteractiveCellConfig of the workload, if any.
+	InteractiveCellsPerHour float64 `json:"interactive_cells_per_hour,omitempty"`
 }
 
 func (t *WorkloadTemplateSession) String() string {
@@ -345,6 +396,11 @@ type TrainingEvent struct {
 	GpuUtil         []GpuUtilization `json:"gpu_utilizations"`
 	StartTick       int              `json:"start_tick"`
 	DurationInTicks int              `json:"duration_in_ticks"`
+	GpuTypes        GpuTypes         `json:"gpu_types,omitempty"` // GpuTypes override the GPU types of the training's session, if non-empty.
+
+	// Distributed is non-nil if the training is a distributed training, in which case the number of
+	// entries of GpuUtil must equal the total number of GPUs of the training's workers.
+	Distributed *DistributedTraining `json:"distributed,omitempty"`
 }
 
 // GpuUtilization is a struct here with a Utilization field so it matches the JSON generated by the form in the frontend.
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// ClosedLoopBehavior preserves each user's think time relative to the completion of their previous training.
+	// When a training starts late, every later event of the session is pushed back by the same amount.
+	// This is the default behavior model.
+	ClosedLoopBehavior = "closed_loop"
+	// OpenLoopBehavior submits trainings at their trace timestamps, regardless of whether the session's previous
+	// training was delayed. A late start still preserves the duration of the training itself, but the delay is
+	// not carried over to the session's later trainings. The resulting queuing delay is measured instead.
+	OpenLoopBehavior = "open_loop"
+	// HybridBehavior behaves like ClosedLoopBehavior as long as the user's patience is not exhausted. If a training
+	// waits longer than the user's patience to start, then the user abandons it: they give up after waiting for
+	// their patience and resume their schedule from that point on.
+	HybridBehavior = "hybrid"
+
+	// FixedPatience gives every training the same patience.
+	FixedPatience = "fixed"
+	// ExponentialPatience samples the patience of each training from an exponential distribution whose mean
+	// is the configured patience.
+	ExponentialPatience = "exponential"
+)
+
+var (
+	ErrInvalidBehaviorModel     = errors.New("invalid session behavior model")
+	ErrUnsupportedBehaviorModel = errors.New("unsupported session behavior model")
+)
+
+// SessionBehaviorModel specifies how the sessions of a workload react to their trainings being delayed.
+type SessionBehaviorModel struct {
+	Model string `name:"model" json:"model,omitempty" yaml:"model" description:"How sessions react to delayed trainings: 'closed_loop' (default), 'open_loop', or 'hybrid'."`
+	// PatienceSec is how long, in seconds, a user of a HybridBehavior session waits for a training to start
+	// before abandoning it. If PatienceDistribution is ExponentialPatience, then PatienceSec is the mean patience.
+	PatienceSec float64 `name:"patience_sec" json:"patience_sec,omitempty" yaml:"patience_sec" description:"How long (in seconds) a user waits for a training to start before abandoning it. Only used by the 'hybrid' model."`
+	// PatienceDistribution is how the patience of each training is chosen.
+	PatienceDistribution string `name:"patience_distribution" json:"patience_distribution,omitempty" yaml:"patience_distribution" description:"How the patience of each training is chosen: 'fixed' (default) or 'exponential'."`
+}
+
+// Type returns the behavior model of the SessionBehaviorModel, which is ClosedLoopBehavior if none is specified.
+func (m *SessionBehaviorModel) Type() string {
+	if m == nil || m.Model == "" {
+		return ClosedLoopBehavior
+	}
+
+	return m.Model
+}
+
+// Validate returns an error if the SessionBehaviorModel is invalid.
+func (m *SessionBehaviorModel) Validate() error {
+	switch m.Type() {
+	case ClosedLoopBehavior, OpenLoopBehavior:
+		return nil
+	case HybridBehavior:
+	default:
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedBehaviorModel, m.Model)
+	}
+
+	if m.PatienceSec <= 0 {
+		return fmt.Errorf("%w: the hybrid model requires a positive patience, got %v", ErrInvalidBehaviorModel, m.PatienceSec)
+	}
+
+	switch m.PatienceDistribution {
+	case "", FixedPatience, ExponentialPatience:
+	default:
+		return fmt.Errorf("%w: unsupported patience distribution \"%s\"", ErrInvalidBehaviorModel, m.PatienceDistribution)
+	}
+
+	return nil
+}
+
+// TrainingDelay describes how the delay of a training's start is applied to the training's session.
+type TrainingDelay struct {
+	// Retained is the part of the delay that is carried over to every later event of the session.
+	Retained time.Duration
+	// Transient is the part of the delay that only applies to the delayed training itself, so that the training
+	// still runs for its full duration. It is lifted again once the training ends.
+	Transient time.Duration
+	// Abandoned is true if the user gave up on the training because it exceeded their patience.
+	Abandoned bool
+	// Patience is the patience of the user for the training. Patience is zero unless the model is HybridBehavior.
+	Patience time.Duration
+}
+
+// SessionBehavior applies a SessionBehaviorModel to the delays of the trainings of a workload.
+type SessionBehavior struct {
+	model *SessionBehaviorModel
+	rng   *rand.Rand
+	mu    sync.Mutex
+}
+
+// NewSessionBehavior creates a new SessionBehavior. The patience of the trainings is sampled from an RNG
+// seeded with the given seed. If model is nil, then the sessions follow the ClosedLoopBehavior.
+func NewSessionBehavior(model *SessionBehaviorModel, seed int64) *SessionBehavior {
+	if model == nil {
+		model = &SessionBehaviorModel{Model: ClosedLoopBehavior}
+	}
+
+	return &SessionBehavior{
+		model: model,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Model returns the SessionBehaviorModel of the SessionBehavior.
+func (b *SessionBehavior) Model() *SessionBehaviorModel {
+	return b.model
+}
+
+// TrainingDelayed returns how a delay of the given amount in the start of a training is to be applied
+// to the training's session.
+func (b *SessionBehavior) TrainingDelayed(delay time.Duration) TrainingDelay {
+	if delay <= 0 {
+		return TrainingDelay{}
+	}
+
+	switch b.model.Type() {
+	case OpenLoopBehavior:
+		return TrainingDelay{Transient: delay}
+	case HybridBehavior:
+		patience := b.samplePatience()
+		if delay <= patience {
+			return TrainingDelay{Retained: delay, Patience: patience}
+		}
+
+		return TrainingDelay{Retained: patience, Transient: delay - patience, Abandoned: true, Patience: patience}
+	default:
+		return TrainingDelay{Retained: delay}
+	}
+}
+
+// samplePatience returns the patience of the user for one training.
+func (b *SessionBehavior) samplePatience() time.Duration {
+	patience := b.model.PatienceSec
+
+	if b.model.PatienceDistribution == ExponentialPatience {
+		b.mu.Lock()
+		patience *= b.rng.ExpFloat64()
+		b.mu.Unlock()
+	}
+
+	return time.Duration(patience * float64(time.Second))
+}
@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Session Behavior Tests", func() {
+	Context("Validation", func() {
+		It("Will default to the closed-loop model", func() {
+			var model *domain.SessionBehaviorModel
+			Expect(model.Type()).To(Equal(domain.ClosedLoopBehavior))
+			Expect((&domain.SessionBehaviorModel{}).Validate()).To(BeNil())
+		})
+
+		It("Will reject unsupported models", func() {
+			err := (&domain.SessionBehaviorModel{Model: "random"}).Validate()
+			Expect(err).To(MatchError(domain.ErrUnsupportedBehaviorModel))
+		})
+
+		It("Will require a positive patience for the hybrid model", func() {
+			err := (&domain.SessionBehaviorModel{Model: domain.HybridBehavior}).Validate()
+			Expect(err).To(MatchError(domain.ErrInvalidBehaviorModel))
+
+			err = (&domain.SessionBehaviorModel{Model: domain.HybridBehavior, PatienceSec: 30, PatienceDistribution: "normal"}).Validate()
+			Expect(err).To(MatchError(domain.ErrInvalidBehaviorModel))
+
+			Expect((&domain.SessionBehaviorModel{Model: domain.HybridBehavior, PatienceSec: 30}).Validate()).To(BeNil())
+		})
+	})
+
+	Context("Applying delays", func() {
+		It("Will retain the whole delay under the closed-loop model", func() {
+			behavior := domain.NewSessionBehavior(nil, 0)
+
+			delay := behavior.TrainingDelayed(time.Second * 10)
+			Expect(delay.Retained).To(Equal(time.Second * 10))
+			Expect(delay.Transient).To(BeZero())
+			Expect(delay.Abandoned).To(BeFalse())
+		})
+
+		It("Will only apply the delay to the delayed training under the open-loop model", func() {
+			behavior := domain.NewSessionBehavior(&domain.SessionBehaviorModel{Model: domain.OpenLoopBehavior}, 0)
+
+			delay := behavior.TrainingDelayed(time.Second * 10)
+			Expect(delay.Retained).To(BeZero())
+			Expect(delay.Transient).To(Equal(time.Second * 10))
+			Expect(delay.Abandoned).To(BeFalse())
+		})
+
+		It("Will abandon trainings that exceed the user's patience under the hybrid model", func() {
+			behavior := domain.NewSessionBehavior(&domain.SessionBehaviorModel{Model: domain.HybridBehavior, PatienceSec: 30}, 0)
+
+			delay := behavior.TrainingDelayed(time.Second * 10)
+			Expect(delay.Retained).To(Equal(time.Second * 10))
+			Expect(delay.Transient).To(BeZero())
+			Expect(delay.Abandoned).To(BeFalse())
+
+			delay = behavior.TrainingDelayed(time.Second * 45)
+			Expect(delay.Retained).To(Equal(time.Second * 30))
+			Expect(delay.Transient).To(Equal(time.Second * 15))
+			Expect(delay.Abandoned).To(BeTrue())
+		})
+
+		It("Will sample the same patience for the same seed", func() {
+			model := &domain.SessionBehaviorModel{
+				Model:                domain.HybridBehavior,
+				PatienceSec:          30,
+				PatienceDistribution: domain.ExponentialPatience,
+			}
+
+			first := domain.NewSessionBehavior(model, 42)
+			second := domain.NewSessionBehavior(model, 42)
+
+			for i := 0; i < 10; i++ {
+				Expect(first.TrainingDelayed(time.Minute)).To(Equal(second.TrainingDelayed(time.Minute)))
+			}
+		})
+
+		It("Will ignore non-positive delays", func() {
+			behavior := domain.NewSessionBehavior(&domain.SessionBehaviorModel{Model: domain.HybridBehavior, PatienceSec: 30}, 0)
+			Expect(behavior.TrainingDelayed(0)).To(Equal(domain.TrainingDelay{}))
+		})
+	})
+})
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidSessionDependency = errors.New("invalid session dependency")
+)
+
+// SessionDependency is an edge of a SessionDependencyGraph. It specifies that a training of the dependent session
+// may only start once the session that it depends on has made enough progress.
+type SessionDependency struct {
+	// SessionId is the ID of the session that the dependent session depends on.
+	SessionId string `json:"session_id"`
+	// AfterTrainings is the number of trainings of the session that must complete. If AfterTrainings is 0, then
+	// the session must stop.
+	AfterTrainings int `json:"after_trainings,omitempty"`
+	// BeforeTraining is the index of the first training of the dependent session that must wait for the dependency.
+	BeforeTraining int `json:"before_training,omitempty"`
+}
+
+// IsSatisfied returns true if the SessionDependency is satisfied by a session that has completed the given number
+// of trainings. A SessionDependency is always satisfied by a session that stopped, as it will not progress further.
+func (d *SessionDependency) IsSatisfied(trainingsCompleted int, stopped bool) bool {
+	if stopped {
+		return true
+	}
+
+	return d.AfterTrainings > 0 && trainingsCompleted >= d.AfterTrainings
+}
+
+// SessionDependencyGraph is the directed acyclic graph formed by the SessionDependency edges of the sessions of a
+// workload template, which expresses pipelines, fan-outs and fan-ins of sessions.
+type SessionDependencyGraph struct {
+	dependencies map[string][]*SessionDependency // dependencies is a map from session ID to the dependencies of the session.
+	dependents   map[string][]string             // dependents is a map from session ID to the sessions that depend on it.
+	order        []string                        // order are the sessions with dependencies or dependents, in topological order.
+}
+
+// NewSessionDependencyGraph creates the SessionDependencyGraph of the given sessions, and it returns an error if
+// a dependency refers to an unknown session or training, or if the dependencies form a cycle.
+func NewSessionDependencyGraph(sessions []*WorkloadTemplateSession) (*SessionDependencyGraph, error) {
+	graph := &SessionDependencyGraph{
+		dependencies: make(map[string][]*SessionDependency),
+		dependents:   make(map[string][]string),
+	}
+
+	sessionsById := make(map[string]*WorkloadTemplateSession, len(sessions))
+	for _, session := range sessions {
+		if session != nil {
+			sessionsById[session.GetId()] = session
+		}
+	}
+
+	inDegree := make(map[string]int)
+	for _, session := range sessions {
+		if session == nil {
+			continue
+		}
+
+		sessionId := session.GetId()
+		for _, dependency := range session.DependsOn {
+			if err := validateSessionDependency(session, dependency, sessionsById); err != nil {
+				return nil, fmt.Errorf("session \"%s\": %w", sessionId, err)
+			}
+
+			if _, loaded := inDegree[dependency.SessionId]; !loaded {
+				inDegree[dependency.SessionId] = 0
+			}
+
+			graph.dependencies[sessionId] = append(graph.dependencies[sessionId], dependency)
+			graph.dependents[dependency.SessionId] = append(graph.dependents[dependency.SessionId], sessionId)
+			inDegree[sessionId] += 1
+		}
+	}
+
+	// Kahn's algorithm. Sessions are visited in the order of the template, so that the order is deterministic.
+	ready := make([]string, 0, len(inDegree))
+	for _, session := range sessions {
+		if session == nil {
+			continue
+		}
+
+		if degree, loaded := inDegree[session.GetId()]; loaded && degree == 0 {
+			ready = append(ready, session.GetId())
+		}
+	}
+
+	for len(ready) > 0 {
+		sessionId := ready[0]
+		ready = ready[1:]
+		graph.order = append(graph.order, sessionId)
+
+		for _, dependent := range graph.dependents[sessionId] {
+			inDegree[dependent] -= 1
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(graph.order) < len(inDegree) {
+		return nil, fmt.Errorf("%w: the dependencies of the sessions form a cycle", ErrInvalidSessionDependency)
+	}
+
+	return graph, nil
+}
+
+// validateSessionDependency returns an error if the given dependency of the given session is invalid.
+func validateSessionDependency(session *WorkloadTemplateSession, dependency *SessionDependency, sessionsById map[string]*WorkloadTemplateSession) error {
+	if dependency == nil {
+		return fmt.Errorf("%w: dependency must not be null", ErrInvalidSessionDependency)
+	}
+
+	if dependency.SessionId == session.GetId() {
+		return fmt.Errorf("%w: session cannot depend on itself", ErrInvalidSessionDependency)
+	}
+
+	upstream, loaded := sessionsById[dependency.SessionId]
+	if !loaded {
+		return fmt.Errorf("%w: unknown session \"%s\"", ErrInvalidSessionDependency, dependency.SessionId)
+	}
+
+	if dependency.AfterTrainings < 0 || dependency.AfterTrainings > len(upstream.Trainings) {
+		return fmt.Errorf("%w: session \"%s\" has %d training(s), cannot wait for %d",
+			ErrInvalidSessionDependency, dependency.SessionId, len(upstream.Trainings), dependency.AfterTrainings)
+	}
+
+	if dependency.BeforeTraining < 0 || (len(session.Trainings) > 0 && dependency.BeforeTraining >= len(session.Trainings)) {
+		return fmt.Errorf("%w: session has %d training(s), training %d cannot wait for session \"%s\"",
+			ErrInvalidSessionDependency, len(session.Trainings), dependency.BeforeTraining, dependency.SessionId)
+	}
+
+	return nil
+}
+
+// IsEmpty returns true if none of the sessions depend on each other.
+func (g *SessionDependencyGraph) IsEmpty() bool {
+	return len(g.order) == 0
+}
+
+// Dependencies returns the dependencies of the specified session.
+func (g *SessionDependencyGraph) Dependencies(sessionId string) []*SessionDependency {
+	return g.dependencies[sessionId]
+}
+
+// Dependents returns the IDs of the sessions that depend on the specified session.
+func (g *SessionDependencyGraph) Dependents(sessionId string) []string {
+	return g.dependents[sessionId]
+}
+
+// TopologicalOrder returns the sessions that have dependencies or dependents, such that every session comes
+// after the sessions that it depends on.
+func (g *SessionDependencyGraph) TopologicalOrder() []string {
+	return g.order
+}
+
+// CriticalPath returns the chain of sessions that ends at the specified session, in which each session was held
+// until the session before it made progress, as recorded by the given map from session ID to the ID of the session
+// whose progress released it.
+func (g *SessionDependencyGraph) CriticalPath(sessionId string, releasedBy map[string]string) []string {
+	path := []string{sessionId}
+	for {
+		upstream, loaded := releasedBy[path[0]]
+		if !loaded || len(path) > len(g.order) {
+			return path
+		}
+
+		path = append([]string{upstream}, path...)
+	}
+}
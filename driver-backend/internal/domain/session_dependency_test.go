@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+// templateSession creates a WorkloadTemplateSession with the given number of trainings and dependencies.
+func templateSession(id string, numTrainings int, dependsOn ...*domain.SessionDependency) *domain.WorkloadTemplateSession {
+	trainings := make([]*domain.TrainingEvent, 0, numTrainings)
+	for i := 0; i < numTrainings; i++ {
+		trainings = append(trainings, &domain.TrainingEvent{TrainingIndex: i})
+	}
+
+	return &domain.WorkloadTemplateSession{
+		BasicWorkloadSession: &domain.BasicWorkloadSession{Id: id},
+		Trainings:            trainings,
+		DependsOn:            dependsOn,
+	}
+}
+
+var _ = Describe("Session Dependency Tests", func() {
+	It("Will order a fan-out and fan-in of sessions topologically", func() {
+		graph, err := domain.NewSessionDependencyGraph([]*domain.WorkloadTemplateSession{
+			templateSession("eval", 1,
+				&domain.SessionDependency{SessionId: "trial-1"},
+				&domain.SessionDependency{SessionId: "trial-2"}),
+			templateSession("trial-1", 2, &domain.SessionDependency{SessionId: "prep", AfterTrainings: 3}),
+			templateSession("trial-2", 2, &domain.SessionDependency{SessionId: "prep", AfterTrainings: 3}),
+			templateSession("prep", 3),
+			templateSession("independent", 1),
+		})
+		Expect(err).To(BeNil())
+		Expect(graph.IsEmpty()).To(BeFalse())
+
+		Expect(graph.TopologicalOrder()).To(Equal([]string{"prep", "trial-1", "trial-2", "eval"}))
+		Expect(graph.Dependents("prep")).To(ConsistOf("trial-1", "trial-2"))
+		Expect(graph.Dependencies("eval")).To(HaveLen(2))
+		Expect(graph.Dependencies("independent")).To(BeEmpty())
+
+		releasedBy := map[string]string{"eval": "trial-2", "trial-2": "prep"}
+		Expect(graph.CriticalPath("eval", releasedBy)).To(Equal([]string{"prep", "trial-2", "eval"}))
+		Expect(graph.CriticalPath("independent", releasedBy)).To(Equal([]string{"independent"}))
+	})
+
+	It("Will reject invalid dependencies", func() {
+		invalid := [][]*domain.WorkloadTemplateSession{
+			{templateSession("a", 1, &domain.SessionDependency{SessionId: "a"})},
+			{templateSession("a", 1, &domain.SessionDependency{SessionId: "missing"})},
+			{templateSession("a", 1, &domain.SessionDependency{SessionId: "b", AfterTrainings: 2}), templateSession("b", 1)},
+			{templateSession("a", 1, &domain.SessionDependency{SessionId: "b", BeforeTraining: 1}), templateSession("b", 1)},
+			{
+				templateSession("a", 1, &domain.SessionDependency{SessionId: "c"}),
+				templateSession("b", 1, &domain.SessionDependency{SessionId: "a"}),
+				templateSession("c", 1, &domain.SessionDependency{SessionId: "b"}),
+			},
+		}
+
+		for _, sessions := range invalid {
+			_, err := domain.NewSessionDependencyGraph(sessions)
+			Expect(err).To(MatchError(domain.ErrInvalidSessionDependency))
+		}
+
+		graph, err := domain.NewSessionDependencyGraph([]*domain.WorkloadTemplateSession{templateSession("a", 1)})
+		Expect(err).To(BeNil())
+		Expect(graph.IsEmpty()).To(BeTrue())
+	})
+
+	It("Will satisfy dependencies once the session made enough progress", func() {
+		afterThird := &domain.SessionDependency{SessionId: "a", AfterTrainings: 3}
+		Expect(afterThird.IsSatisfied(2, false)).To(BeFalse())
+		Expect(afterThird.IsSatisfied(3, false)).To(BeTrue())
+		Expect(afterThird.IsSatisfied(0, true)).To(BeTrue())
+
+		afterStop := &domain.SessionDependency{SessionId: "a"}
+		Expect(afterStop.IsSatisfied(10, false)).To(BeFalse())
+		Expect(afterStop.IsSatisfied(10, true)).To(BeTrue())
+	})
+})
@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// FirstRouteStrategy assigns every session that is not matched by a rule to the first route.
+	FirstRouteStrategy = "first"
+	// RoundRobinRouteStrategy assigns the sessions that are not matched by a rule to the routes in turn.
+	RoundRobinRouteStrategy = "round_robin"
+	// WeightedRouteStrategy assigns the sessions that are not matched by a rule to a randomly-selected route,
+	// such that each route receives a share of the sessions proportional to its weight.
+	WeightedRouteStrategy = "weighted"
+
+	// DefaultRouteName is the name of the route used when a workload does not specify a SessionRoutingTable.
+	DefaultRouteName = "default"
+)
+
+var (
+	ErrInvalidRoutingTable        = errors.New("invalid session routing table")
+	ErrUnsupportedRoutingStrategy = errors.New("unsupported session routing strategy")
+)
+
+// SessionRoute is a destination for the sessions of a workload: a Jupyter Server and the kernel spec with which
+// the kernels of the sessions are created there.
+type SessionRoute struct {
+	// Name uniquely identifies the route within its SessionRoutingTable. Statistics are broken down by Name.
+	Name string `name:"name" json:"name" yaml:"name" description:"Uniquely identifies the route. Statistics are broken down by route name."`
+	// JupyterServerAddress is the address of the Jupyter Server. If empty, the configured Jupyter Server is used.
+	JupyterServerAddress string `name:"jupyter_server_address" json:"jupyter_server_address,omitempty" yaml:"jupyter_server_address" description:"Address of the Jupyter Server to which the sessions are routed. If empty, the configured Jupyter Server is used."`
+	// KernelSpec is the name of the kernel spec. If empty, the configured kernel spec is used.
+	KernelSpec string `name:"kernel_spec" json:"kernel_spec,omitempty" yaml:"kernel_spec" description:"Name of the kernel spec with which kernels are created. If empty, the configured kernel spec is used."`
+	// Weight is the relative share of the sessions that the route receives under the WeightedRouteStrategy.
+	Weight float64 `name:"weight" json:"weight,omitempty" yaml:"weight" description:"Relative share of the unmatched sessions that the route receives when using the weighted strategy."`
+}
+
+// SessionRoutingRule assigns the sessions that it matches to a particular route.
+//
+// A session is matched if it matches every criterion that the SessionRoutingRule specifies.
+type SessionRoutingRule struct {
+	// Route is the name of the route to which matching sessions are assigned.
+	Route string `name:"route" json:"route" yaml:"route" description:"Name of the route to which matching sessions are assigned."`
+	// SessionIdPattern is a regular expression that the (trace) session ID must match.
+	SessionIdPattern string `name:"session_id_pattern" json:"session_id_pattern,omitempty" yaml:"session_id_pattern" description:"Regular expression that the session ID must match."`
+	// MinGpus is the minimum number of GPUs that the session must require.
+	MinGpus int `name:"min_gpus" json:"min_gpus,omitempty" yaml:"min_gpus" description:"Minimum number of GPUs that the session must require."`
+	// MaxGpus is the maximum number of GPUs that the session may require. If nil, there is no maximum.
+	MaxGpus *int `name:"max_gpus" json:"max_gpus,omitempty" yaml:"max_gpus" description:"Maximum number of GPUs that the session may require. Unbounded if unspecified."`
+
+	sessionIdRegexp *regexp.Regexp
+}
+
+// matches returns true if the SessionRoutingRule matches the specified session.
+func (r *SessionRoutingRule) matches(sessionId string, gpus int) bool {
+	if r.sessionIdRegexp != nil && !r.sessionIdRegexp.MatchString(sessionId) {
+		return false
+	}
+
+	if gpus < r.MinGpus {
+		return false
+	}
+
+	return r.MaxGpus == nil || gpus <= *r.MaxGpus
+}
+
+// SessionRoutingTable assigns each session of a workload to one of several routes, so that a single workload can
+// exercise several Jupyter Servers (and thus scheduling stacks) or kernel specs side-by-side.
+//
+// The Rules are evaluated in order and the first matching rule wins. Sessions that are not matched by any rule
+// are assigned according to the Strategy.
+type SessionRoutingTable struct {
+	Routes   []*SessionRoute       `name:"routes" json:"routes" yaml:"routes" description:"The routes to which sessions can be assigned."`
+	Rules    []*SessionRoutingRule `name:"rules" json:"rules,omitempty" yaml:"rules" description:"Rules assigning sessions to routes. The first matching rule wins."`
+	Strategy string                `name:"strategy" json:"strategy,omitempty" yaml:"strategy" description:"How sessions not matched by any rule are assigned: 'first' (default), 'round_robin', or 'weighted'."`
+}
+
+// Validate returns an error if the SessionRoutingTable is malformed.
+func (t *SessionRoutingTable) Validate() error {
+	if len(t.Routes) == 0 {
+		return fmt.Errorf("%w: no routes specified", ErrInvalidRoutingTable)
+	}
+
+	routes := make(map[string]*SessionRoute, len(t.Routes))
+	totalWeight := 0.0
+	for _, route := range t.Routes {
+		if route == nil || route.Name == "" {
+			return fmt.Errorf("%w: every route must have a name", ErrInvalidRoutingTable)
+		}
+
+		if _, loaded := routes[route.Name]; loaded {
+			return fmt.Errorf("%w: duplicate route \"%s\"", ErrInvalidRoutingTable, route.Name)
+		}
+
+		if route.Weight < 0 {
+			return fmt.Errorf("%w: route \"%s\" has negative weight %f", ErrInvalidRoutingTable, route.Name, route.Weight)
+		}
+
+		routes[route.Name] = route
+		totalWeight += route.Weight
+	}
+
+	for i, rule := range t.Rules {
+		if rule == nil {
+			return fmt.Errorf("%w: rule #%d is empty", ErrInvalidRoutingTable, i)
+		}
+
+		if _, loaded := routes[rule.Route]; !loaded {
+			return fmt.Errorf("%w: rule #%d refers to unknown route \"%s\"", ErrInvalidRoutingTable, i, rule.Route)
+		}
+
+		if rule.MaxGpus != nil && *rule.MaxGpus < rule.MinGpus {
+			return fmt.Errorf("%w: rule #%d has max_gpus %d < min_gpus %d", ErrInvalidRoutingTable, i, *rule.MaxGpus, rule.MinGpus)
+		}
+
+		if rule.SessionIdPattern != "" {
+			if _, err := regexp.Compile(rule.SessionIdPattern); err != nil {
+				return fmt.Errorf("%w: rule #%d has invalid session ID pattern: %v", ErrInvalidRoutingTable, i, err)
+			}
+		}
+	}
+
+	switch strings.ToLower(t.Strategy) {
+	case "", FirstRouteStrategy, RoundRobinRouteStrategy:
+	case WeightedRouteStrategy:
+		if totalWeight <= 0 {
+			return fmt.Errorf("%w: the weighted strategy requires at least one route with positive weight", ErrInvalidRoutingTable)
+		}
+	default:
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedRoutingStrategy, t.Strategy)
+	}
+
+	return nil
+}
+
+// SessionRouter assigns sessions to the routes of a SessionRoutingTable.
+//
+// Assignments are sticky: a session is always assigned to the same route, no matter how many times it is routed.
+type SessionRouter struct {
+	table       *SessionRoutingTable
+	strategy    string
+	rng         *rand.Rand
+	next        int                      // next is the index of the route to be used next by the RoundRobinRouteStrategy.
+	assignments map[string]*SessionRoute // assignments is a map from session ID to the route of that session.
+
+	mu sync.Mutex
+}
+
+// NewSessionRouter validates the given SessionRoutingTable and returns a SessionRouter for it.
+//
+// The seed is used by the WeightedRouteStrategy so that the assignment of sessions to routes is reproducible.
+func NewSessionRouter(table *SessionRoutingTable, seed int64) (*SessionRouter, error) {
+	if err := table.Validate(); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range table.Rules {
+		if rule.SessionIdPattern != "" {
+			rule.sessionIdRegexp = regexp.MustCompile(rule.SessionIdPattern)
+		}
+	}
+
+	strategy := strings.ToLower(table.Strategy)
+	if strategy == "" {
+		strategy = FirstRouteStrategy
+	}
+
+	return &SessionRouter{
+		table:       table,
+		strategy:    strategy,
+		rng:         rand.New(rand.NewSource(seed)),
+		assignments: make(map[string]*SessionRoute),
+	}, nil
+}
+
+// Routes returns the routes of the SessionRouter's SessionRoutingTable.
+func (r *SessionRouter) Routes() []*SessionRoute {
+	return r.table.Routes
+}
+
+// Route returns the route of the specified session, which requires the specified number of GPUs.
+func (r *SessionRouter) Route(sessionId string, gpus int) *SessionRoute {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if route, loaded := r.assignments[sessionId]; loaded {
+		return route
+	}
+
+	route := r.match(sessionId, gpus)
+	r.assignments[sessionId] = route
+	return route
+}
+
+// match returns the route of the first rule that matches the specified session, or else the route selected by
+// the SessionRouter's strategy.
+func (r *SessionRouter) match(sessionId string, gpus int) *SessionRoute {
+	for _, rule := range r.table.Rules {
+		if rule.matches(sessionId, gpus) {
+			return r.routeNamed(rule.Route)
+		}
+	}
+
+	routes := r.table.Routes
+	switch r.strategy {
+	case RoundRobinRouteStrategy:
+		route := routes[r.next%len(routes)]
+		r.next += 1
+		return route
+	case WeightedRouteStrategy:
+		totalWeight := 0.0
+		for _, route := range routes {
+			totalWeight += route.Weight
+		}
+
+		target := r.rng.Float64() * totalWeight
+		for _, route := range routes {
+			if route.Weight <= 0 {
+				continue
+			}
+
+			if target < route.Weight {
+				return route
+			}
+			target -= route.Weight
+		}
+
+		// Floating-point error; fall back to the last route with positive weight.
+		for i := len(routes) - 1; i >= 0; i-- {
+			if routes[i].Weight > 0 {
+				return routes[i]
+			}
+		}
+	}
+
+	return routes[0]
+}
+
+func (r *SessionRouter) routeNamed(name string) *SessionRoute {
+	for _, route := range r.table.Routes {
+		if route.Name == name {
+			return route
+		}
+	}
+
+	// Unreachable, as the SessionRoutingTable was validated.
+	return r.table.Routes[0]
+}
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Session Routing Tests", func() {
+	Context("Validation", func() {
+		It("Will reject malformed routing tables", func() {
+			maxGpus := 1
+
+			invalid := []*domain.SessionRoutingTable{
+				{},
+				{Routes: []*domain.SessionRoute{{Name: "a"}, {Name: "a"}}},
+				{Routes: []*domain.SessionRoute{{Name: "a"}}, Rules: []*domain.SessionRoutingRule{{Route: "b"}}},
+				{Routes: []*domain.SessionRoute{{Name: "a"}}, Rules: []*domain.SessionRoutingRule{{Route: "a", SessionIdPattern: "("}}},
+				{Routes: []*domain.SessionRoute{{Name: "a"}}, Rules: []*domain.SessionRoutingRule{{Route: "a", MinGpus: 2, MaxGpus: &maxGpus}}},
+				{Routes: []*domain.SessionRoute{{Name: "a"}}, Strategy: domain.WeightedRouteStrategy},
+			}
+
+			for _, table := range invalid {
+				Expect(table.Validate()).To(MatchError(domain.ErrInvalidRoutingTable))
+			}
+
+			table := &domain.SessionRoutingTable{Routes: []*domain.SessionRoute{{Name: "a"}}, Strategy: "random"}
+			Expect(table.Validate()).To(MatchError(domain.ErrUnsupportedRoutingStrategy))
+		})
+	})
+
+	Context("Routing", func() {
+		It("Will apply the first matching rule", func() {
+			maxGpus := 0
+			router, err := domain.NewSessionRouter(&domain.SessionRoutingTable{
+				Routes: []*domain.SessionRoute{{Name: "baseline"}, {Name: "candidate", KernelSpec: "candidate"}, {Name: "cpu"}},
+				Rules: []*domain.SessionRoutingRule{
+					{Route: "cpu", MaxGpus: &maxGpus},
+					{Route: "candidate", SessionIdPattern: "^exp-"},
+					{Route: "candidate", MinGpus: 4},
+				},
+			}, 0)
+			Expect(err).To(BeNil())
+
+			Expect(router.Route("exp-1", 0).Name).To(Equal("cpu"))
+			Expect(router.Route("exp-2", 1).Name).To(Equal("candidate"))
+			Expect(router.Route("session-3", 8).Name).To(Equal("candidate"))
+			Expect(router.Route("session-4", 2).Name).To(Equal("baseline"))
+		})
+
+		It("Will assign unmatched sessions in turn and keep their assignments", func() {
+			router, err := domain.NewSessionRouter(&domain.SessionRoutingTable{
+				Routes:   []*domain.SessionRoute{{Name: "a"}, {Name: "b"}},
+				Strategy: domain.RoundRobinRouteStrategy,
+			}, 0)
+			Expect(err).To(BeNil())
+
+			Expect(router.Route("session-1", 1).Name).To(Equal("a"))
+			Expect(router.Route("session-2", 1).Name).To(Equal("b"))
+			Expect(router.Route("session-3", 1).Name).To(Equal("a"))
+			Expect(router.Route("session-2", 1).Name).To(Equal("b"))
+		})
+
+		It("Will split unmatched sessions according to the route weights", func() {
+			router, err := domain.NewSessionRouter(&domain.SessionRoutingTable{
+				Routes:   []*domain.SessionRoute{{Name: "a", Weight: 3}, {Name: "b", Weight: 1}, {Name: "unused"}},
+				Strategy: domain.WeightedRouteStrategy,
+			}, 42)
+			Expect(err).To(BeNil())
+
+			counts := make(map[string]int)
+			for i := 0; i < 4000; i++ {
+				counts[router.Route(fmt.Sprintf("session-%d", i), 1).Name] += 1
+			}
+
+			Expect(counts["unused"]).To(Equal(0))
+			Expect(counts["a"]).To(BeNumerically("~", 3000, 150))
+			Expect(counts["b"]).To(BeNumerically("~", 1000, 150))
+		})
+	})
+})
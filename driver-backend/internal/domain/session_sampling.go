@@ -0,0 +1,353 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+const (
+	// UniformSampling samples each session with probability equal to the sample percentage, using a random
+	// number generator seeded from the workload. This is the default strategy.
+	UniformSampling = "uniform"
+	// HashSampling samples a session if the hash of its ID (salted with the workload's seed) falls within the
+	// sample percentage. The decision for a session does not depend on the order in which sessions are encountered.
+	HashSampling = "hash"
+	// StratifiedGpuSampling buckets the sessions by GPU demand and samples the sample percentage of each bucket.
+	StratifiedGpuSampling = "stratified_gpus"
+	// StratifiedDurationSampling buckets the sessions by duration and samples the sample percentage of each bucket.
+	StratifiedDurationSampling = "stratified_duration"
+	// TopNSampling samples the N busiest sessions, i.e., those with the most training events.
+	TopNSampling = "top_n"
+	// ExplicitSampling samples exactly the sessions of an explicit include list, or every session not on an
+	// explicit exclude list.
+	ExplicitSampling = "explicit"
+
+	// DefaultNumStrata is the number of buckets used by the stratified strategies if none is specified.
+	DefaultNumStrata = 4
+)
+
+var (
+	ErrInvalidSamplingConfig       = errors.New("invalid session sampling configuration")
+	ErrUnsupportedSamplingStrategy = errors.New("unsupported session sampling strategy")
+)
+
+// SessionSamplingConfig specifies how the sessions of a workload are sampled.
+type SessionSamplingConfig struct {
+	Strategy string `name:"strategy" json:"strategy,omitempty" yaml:"strategy" description:"How sessions are sampled: 'uniform' (default), 'hash', 'stratified_gpus', 'stratified_duration', 'top_n', or 'explicit'."`
+	// NumStrata is the number of buckets used by the stratified strategies.
+	NumStrata int `name:"num_strata" json:"num_strata,omitempty" yaml:"num_strata" description:"Number of buckets used by the stratified strategies. Defaults to 4."`
+	// TopN is the number of sessions sampled by the TopNSampling strategy. If zero, the sample percentage of
+	// the sessions is sampled.
+	TopN int `name:"top_n" json:"top_n,omitempty" yaml:"top_n" description:"Number of busiest sessions sampled by the 'top_n' strategy. Defaults to the sample percentage of the sessions."`
+	// Include lists the sessions sampled by the ExplicitSampling strategy.
+	Include []string `name:"include" json:"include,omitempty" yaml:"include" description:"Sessions sampled by the 'explicit' strategy. If empty, every session that is not excluded is sampled."`
+	// Exclude lists the sessions never sampled by the ExplicitSampling strategy.
+	Exclude []string `name:"exclude" json:"exclude,omitempty" yaml:"exclude" description:"Sessions never sampled by the 'explicit' strategy."`
+}
+
+// Validate returns an error if the SessionSamplingConfig is invalid.
+func (c *SessionSamplingConfig) Validate() error {
+	switch c.Strategy {
+	case "", UniformSampling, HashSampling, StratifiedGpuSampling, StratifiedDurationSampling, TopNSampling, ExplicitSampling:
+	default:
+		return fmt.Errorf("%w: \"%s\"", ErrUnsupportedSamplingStrategy, c.Strategy)
+	}
+
+	if c.NumStrata < 0 {
+		return fmt.Errorf("%w: the number of strata cannot be negative", ErrInvalidSamplingConfig)
+	}
+
+	if c.TopN < 0 {
+		return fmt.Errorf("%w: N cannot be negative", ErrInvalidSamplingConfig)
+	}
+
+	return nil
+}
+
+// SessionProfile describes a session of a workload for the purpose of sampling.
+type SessionProfile struct {
+	SessionId string
+	// Gpus is the maximum number of GPUs used by the session.
+	Gpus int
+	// NumTrainings is the number of training events of the session.
+	NumTrainings int
+	// Duration is the duration of the session, in ticks if known, or else its number of training events.
+	Duration float64
+}
+
+// SessionProfilesFromMaxUtilization returns the SessionProfile of each of the sessions of a MaxUtilizationWrapper.
+//
+// The durations of the sessions are not known in advance, so the number of training events is used instead.
+func SessionProfilesFromMaxUtilization(wrapper *MaxUtilizationWrapper) []*SessionProfile {
+	if wrapper == nil {
+		return nil
+	}
+
+	profiles := make(map[string]*SessionProfile)
+	profileOf := func(sessionId string) *SessionProfile {
+		profile, loaded := profiles[sessionId]
+		if !loaded {
+			profile = &SessionProfile{SessionId: sessionId}
+			profiles[sessionId] = profile
+		}
+		return profile
+	}
+
+	for sessionId, gpus := range wrapper.GpuSessionMap {
+		profileOf(sessionId).Gpus = gpus
+	}
+
+	for sessionId := range wrapper.CpuSessionMap {
+		profileOf(sessionId)
+	}
+
+	for sessionId, tasks := range wrapper.GpuTaskMap {
+		profileOf(sessionId).NumTrainings = len(tasks)
+	}
+
+	for sessionId, tasks := range wrapper.CpuTaskMap {
+		if profile := profileOf(sessionId); len(tasks) > profile.NumTrainings {
+			profile.NumTrainings = len(tasks)
+		}
+	}
+
+	result := make([]*SessionProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		profile.Duration = float64(profile.NumTrainings)
+		result = append(result, profile)
+	}
+
+	return result
+}
+
+// SessionSampler decides which sessions of a workload are sampled. Every strategy is seeded from the workload,
+// so that two runs of the same workload with the same seed sample the same sessions.
+//
+// The stratified and top-N strategies require the population of sessions, which is specified via SetPopulation.
+// Sessions that are not part of the population are sampled as per the UniformSampling strategy by the stratified
+// strategies, and are never sampled by the TopNSampling strategy.
+type SessionSampler struct {
+	config     SessionSamplingConfig
+	percentage float64
+	seed       int64
+	rng        *rand.Rand
+
+	population []*SessionProfile
+	selected   map[string]struct{} // selected are the sessions selected from the population, if applicable.
+	include    map[string]struct{}
+	exclude    map[string]struct{}
+
+	mu sync.Mutex
+}
+
+// NewSessionSampler creates a new SessionSampler that samples the given percentage of sessions, as a fraction
+// between 0 and 1, using the strategy of the given SessionSamplingConfig. If config is nil, then the
+// UniformSampling strategy is used.
+//
+// The SessionSamplingConfig is assumed to be valid.
+func NewSessionSampler(config *SessionSamplingConfig, percentage float64, seed int64) *SessionSampler {
+	sampler := &SessionSampler{
+		percentage: percentage,
+		seed:       seed,
+		rng:        rand.New(rand.NewSource(seed)),
+		include:    make(map[string]struct{}),
+		exclude:    make(map[string]struct{}),
+	}
+
+	if config != nil {
+		sampler.config = *config
+	}
+
+	if sampler.config.Strategy == "" {
+		sampler.config.Strategy = UniformSampling
+	}
+
+	if sampler.config.NumStrata == 0 {
+		sampler.config.NumStrata = DefaultNumStrata
+	}
+
+	for _, sessionId := range sampler.config.Include {
+		sampler.include[sessionId] = struct{}{}
+	}
+
+	for _, sessionId := range sampler.config.Exclude {
+		sampler.exclude[sessionId] = struct{}{}
+	}
+
+	return sampler
+}
+
+// Strategy returns the name of the strategy used by the SessionSampler.
+func (s *SessionSampler) Strategy() string {
+	return s.config.Strategy
+}
+
+// Config returns the SessionSamplingConfig of the SessionSampler, with defaults applied.
+func (s *SessionSampler) Config() *SessionSamplingConfig {
+	config := s.config
+	return &config
+}
+
+// SetPopulation specifies the sessions of the workload, from which the stratified and top-N strategies select.
+func (s *SessionSampler) SetPopulation(population []*SessionProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sort the population so that the selection does not depend on the order in which it was specified.
+	s.population = make([]*SessionProfile, len(population))
+	copy(s.population, population)
+	sort.Slice(s.population, func(i, j int) bool {
+		return s.population[i].SessionId < s.population[j].SessionId
+	})
+
+	s.selected = nil
+}
+
+// Sample returns true if the specified session should be sampled.
+//
+// Sample should be called at most once per session, as the UniformSampling strategy consumes a random number
+// for each call.
+func (s *SessionSampler) Sample(sessionId string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.config.Strategy {
+	case HashSampling:
+		return s.hashFraction(sessionId) < s.percentage
+	case StratifiedGpuSampling, StratifiedDurationSampling, TopNSampling:
+		selected := s.selectFromPopulation()
+		if selected == nil {
+			return s.rng.Float64() < s.percentage
+		}
+
+		if _, ok := selected[sessionId]; ok {
+			return true
+		}
+
+		// Sessions that were not part of the population are only sampled by the stratified strategies.
+		if s.config.Strategy != TopNSampling && !s.inPopulation(sessionId) {
+			return s.rng.Float64() < s.percentage
+		}
+
+		return false
+	case ExplicitSampling:
+		if _, excluded := s.exclude[sessionId]; excluded {
+			return false
+		}
+
+		if len(s.include) == 0 {
+			return true
+		}
+
+		_, included := s.include[sessionId]
+		return included
+	default:
+		return s.rng.Float64() < s.percentage
+	}
+}
+
+// hashFraction maps the specified session to a number in [0, 1) using the hash of its ID salted with the seed.
+func (s *SessionSampler) hashFraction(sessionId string) float64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(fmt.Sprintf("%d/%s", s.seed, sessionId)))
+
+	// The high bits of FNV hashes of similar IDs are poorly distributed, so mix them as per SplitMix64.
+	x := hash.Sum64()
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return float64(x>>11) / float64(uint64(1)<<53)
+}
+
+func (s *SessionSampler) inPopulation(sessionId string) bool {
+	index := sort.Search(len(s.population), func(i int) bool {
+		return s.population[i].SessionId >= sessionId
+	})
+
+	return index < len(s.population) && s.population[index].SessionId == sessionId
+}
+
+// selectFromPopulation lazily selects the sessions of the population as per the stratified or top-N strategy.
+// selectFromPopulation returns nil if the population is unknown.
+func (s *SessionSampler) selectFromPopulation() map[string]struct{} {
+	if s.selected != nil || len(s.population) == 0 {
+		return s.selected
+	}
+
+	s.selected = make(map[string]struct{})
+	if s.config.Strategy == TopNSampling {
+		s.selectTopN()
+	} else {
+		s.selectStratified()
+	}
+
+	return s.selected
+}
+
+// numToSample returns the number of sessions to sample out of the specified number of sessions.
+func (s *SessionSampler) numToSample(numSessions int) int {
+	return int(math.Round(s.percentage * float64(numSessions)))
+}
+
+// selectTopN selects the N sessions with the most training events, breaking ties by GPU demand and then by ID.
+func (s *SessionSampler) selectTopN() {
+	n := s.config.TopN
+	if n == 0 {
+		n = s.numToSample(len(s.population))
+	}
+
+	busiest := make([]*SessionProfile, len(s.population))
+	copy(busiest, s.population)
+	sort.SliceStable(busiest, func(i, j int) bool {
+		if busiest[i].NumTrainings != busiest[j].NumTrainings {
+			return busiest[i].NumTrainings > busiest[j].NumTrainings
+		}
+		return busiest[i].Gpus > busiest[j].Gpus
+	})
+
+	for i := 0; i < n && i < len(busiest); i++ {
+		s.selected[busiest[i].SessionId] = struct{}{}
+	}
+}
+
+// selectStratified splits the population into NumStrata equally-sized buckets by GPU demand or duration and
+// selects a seeded random subset of each bucket.
+func (s *SessionSampler) selectStratified() {
+	key := func(profile *SessionProfile) float64 {
+		if s.config.Strategy == StratifiedGpuSampling {
+			return float64(profile.Gpus)
+		}
+		return profile.Duration
+	}
+
+	ordered := make([]*SessionProfile, len(s.population))
+	copy(ordered, s.population)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return key(ordered[i]) < key(ordered[j])
+	})
+
+	numStrata := s.config.NumStrata
+	if numStrata > len(ordered) {
+		numStrata = len(ordered)
+	}
+
+	for stratum := 0; stratum < numStrata; stratum++ {
+		members := ordered[stratum*len(ordered)/numStrata : (stratum+1)*len(ordered)/numStrata]
+
+		shuffled := make([]*SessionProfile, len(members))
+		copy(shuffled, members)
+		s.rng.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		for _, profile := range shuffled[:s.numToSample(len(shuffled))] {
+			s.selected[profile.SessionId] = struct{}{}
+		}
+	}
+}
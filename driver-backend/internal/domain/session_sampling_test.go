@@ -0,0 +1,139 @@
+package domain_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+// sample returns the sessions sampled by the given domain.SessionSampler, in order.
+func sample(sampler *domain.SessionSampler, sessionIds []string) []string {
+	sampled := make([]string, 0)
+	for _, sessionId := range sessionIds {
+		if sampler.Sample(sessionId) {
+			sampled = append(sampled, sessionId)
+		}
+	}
+	return sampled
+}
+
+var _ = Describe("Session Sampling Tests", func() {
+	var (
+		sessionIds []string
+		population []*domain.SessionProfile
+	)
+
+	BeforeEach(func() {
+		sessionIds = make([]string, 0, 100)
+		population = make([]*domain.SessionProfile, 0, 100)
+		for i := 0; i < 100; i++ {
+			sessionId := fmt.Sprintf("session-%d", i)
+			sessionIds = append(sessionIds, sessionId)
+			population = append(population, &domain.SessionProfile{
+				SessionId:    sessionId,
+				Gpus:         i % 4,
+				NumTrainings: i,
+				Duration:     float64(100 - i),
+			})
+		}
+	})
+
+	It("Will reject unsupported strategies", func() {
+		Expect((&domain.SessionSamplingConfig{Strategy: "random"}).Validate()).To(MatchError(domain.ErrUnsupportedSamplingStrategy))
+		Expect((&domain.SessionSamplingConfig{TopN: -1}).Validate()).To(MatchError(domain.ErrInvalidSamplingConfig))
+		Expect((&domain.SessionSamplingConfig{}).Validate()).To(Succeed())
+	})
+
+	It("Will sample the same sessions given the same seed", func() {
+		for _, strategy := range []string{domain.UniformSampling, domain.HashSampling, domain.StratifiedGpuSampling, domain.StratifiedDurationSampling} {
+			config := &domain.SessionSamplingConfig{Strategy: strategy}
+
+			first := domain.NewSessionSampler(config, 0.3, 42)
+			first.SetPopulation(population)
+			second := domain.NewSessionSampler(config, 0.3, 42)
+			second.SetPopulation(population)
+			other := domain.NewSessionSampler(config, 0.3, 7)
+			other.SetPopulation(population)
+
+			sampled := sample(first, sessionIds)
+			Expect(sampled).ToNot(BeEmpty(), strategy)
+			Expect(sample(second, sessionIds)).To(Equal(sampled), strategy)
+			Expect(sample(other, sessionIds)).ToNot(Equal(sampled), strategy)
+		}
+	})
+
+	It("Will default to seeded uniform sampling", func() {
+		sampler := domain.NewSessionSampler(nil, 1.0, 1)
+		Expect(sampler.Strategy()).To(Equal(domain.UniformSampling))
+		Expect(sample(sampler, sessionIds)).To(Equal(sessionIds))
+	})
+
+	It("Will make hash-based decisions independently of the order of the sessions", func() {
+		config := &domain.SessionSamplingConfig{Strategy: domain.HashSampling}
+		sampler := domain.NewSessionSampler(config, 0.5, 3)
+
+		reversed := make([]string, 0, len(sessionIds))
+		for i := len(sessionIds) - 1; i >= 0; i-- {
+			reversed = append(reversed, sessionIds[i])
+		}
+
+		sampled := sample(sampler, sessionIds)
+		Expect(sample(domain.NewSessionSampler(config, 0.5, 3), reversed)).To(ConsistOf(sampled))
+	})
+
+	It("Will sample each stratum equally", func() {
+		sampler := domain.NewSessionSampler(&domain.SessionSamplingConfig{Strategy: domain.StratifiedGpuSampling}, 0.2, 5)
+		sampler.SetPopulation(population)
+
+		numPerGpus := make(map[int]int)
+		for _, sessionId := range sample(sampler, sessionIds) {
+			var index int
+			_, err := fmt.Sscanf(sessionId, "session-%d", &index)
+			Expect(err).To(BeNil())
+			numPerGpus[index%4] += 1
+		}
+
+		Expect(numPerGpus).To(Equal(map[int]int{0: 5, 1: 5, 2: 5, 3: 5}))
+	})
+
+	It("Will sample the busiest sessions", func() {
+		sampler := domain.NewSessionSampler(&domain.SessionSamplingConfig{Strategy: domain.TopNSampling, TopN: 3}, 0.5, 5)
+		sampler.SetPopulation(population)
+
+		Expect(sample(sampler, append(sessionIds, "unknown"))).To(Equal([]string{"session-97", "session-98", "session-99"}))
+	})
+
+	It("Will honor the explicit include and exclude lists", func() {
+		sampler := domain.NewSessionSampler(&domain.SessionSamplingConfig{
+			Strategy: domain.ExplicitSampling,
+			Include:  []string{"session-1", "session-2"},
+			Exclude:  []string{"session-2"},
+		}, 0.5, 5)
+		Expect(sample(sampler, sessionIds)).To(Equal([]string{"session-1"}))
+
+		sampler = domain.NewSessionSampler(&domain.SessionSamplingConfig{
+			Strategy: domain.ExplicitSampling,
+			Exclude:  []string{"session-2"},
+		}, 0.5, 5)
+		Expect(sample(sampler, sessionIds)).To(HaveLen(99))
+	})
+
+	It("Will derive the population from the maximum utilization of a preset", func() {
+		wrapper := domain.NewMaxUtilizationWrapper(
+			map[string]float64{"a": 1, "b": 2},
+			map[string]float64{"a": 1, "b": 2},
+			map[string]int{"a": 1, "b": 4},
+			map[string][]float64{"a": {1, 1}, "b": {2}},
+			map[string][]float64{"a": {1, 1}, "b": {2}},
+			map[string][]int{"a": {1, 1}, "b": {4}})
+
+		profiles := domain.SessionProfilesFromMaxUtilization(wrapper)
+		Expect(profiles).To(ConsistOf(
+			&domain.SessionProfile{SessionId: "a", Gpus: 1, NumTrainings: 2, Duration: 2},
+			&domain.SessionProfile{SessionId: "b", Gpus: 4, NumTrainings: 1, Duration: 1},
+		))
+	})
+})
@@ -0,0 +1,225 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+const (
+	// UniformUserAssignment assigns each session to a user chosen uniformly at random. This is the default.
+	UniformUserAssignment = "uniform"
+	// ZipfUserAssignment assigns sessions to users following a Zipf distribution, so that a few users own most
+	// of the sessions.
+	ZipfUserAssignment = "zipf"
+	// RoundRobinUserAssignment assigns sessions to users in turn, in the order in which the sessions arrive.
+	RoundRobinUserAssignment = "round_robin"
+
+	// DefaultZipfExponent is the exponent of the ZipfUserAssignment if none is specified.
+	DefaultZipfExponent = 1.5
+)
+
+var (
+	ErrInvalidTenancyConfig = errors.New("invalid tenancy configuration")
+)
+
+// UserQuota bounds the resources that a simulated user may use at the same time.
+// A zero limit means that the resource is not limited.
+type UserQuota struct {
+	MaxActiveSessions int `name:"max_active_sessions" json:"max_active_sessions,omitempty" yaml:"max_active_sessions" description:"Maximum number of sessions that the user may have at the same time."`
+	MaxGpus           int `name:"max_gpus" json:"max_gpus,omitempty" yaml:"max_gpus" description:"Maximum number of GPUs that the user's trainings may use at the same time."`
+}
+
+// SimulatedUser is a user that owns some of the sessions of a workload.
+type SimulatedUser struct {
+	Username string     `name:"username" json:"username" yaml:"username" description:"The name of the user."`
+	Quota    *UserQuota `name:"quota" json:"quota,omitempty" yaml:"quota" description:"The quota of the user. Defaults to the default quota."`
+}
+
+// TenancyConfig specifies how the sessions of a workload are assigned to simulated users and which quotas
+// apply to those users.
+//
+// Sessions whose owner is known from the trace (SessionUsers, or the user of a template session) are assigned to
+// that user. All other sessions are assigned to one of the Users according to the Assignment distribution.
+type TenancyConfig struct {
+	Assignment string `name:"assignment" json:"assignment,omitempty" yaml:"assignment" description:"How sessions without a known owner are assigned to users: 'uniform' (default), 'zipf', or 'round_robin'."`
+	// Users are the simulated users. If Users is empty, then NumUsers users named "user-0", "user-1", etc. are used.
+	Users    []*SimulatedUser `name:"users" json:"users,omitempty" yaml:"users" description:"The simulated users."`
+	NumUsers int              `name:"num_users" json:"num_users,omitempty" yaml:"num_users" description:"Number of generated users if no users are listed. Defaults to 1."`
+	// ZipfExponent is the exponent of the ZipfUserAssignment, which must be greater than 1.
+	ZipfExponent float64 `name:"zipf_exponent" json:"zipf_exponent,omitempty" yaml:"zipf_exponent" description:"Exponent (> 1) of the 'zipf' assignment. Defaults to 1.5."`
+	// SessionUsers is a map from session ID to the username of the session's owner, as known from the trace.
+	SessionUsers map[string]string `name:"session_users" json:"session_users,omitempty" yaml:"session_users" description:"Owner of each session, as known from the trace."`
+	// DefaultQuota is the quota of each user that does not specify its own.
+	DefaultQuota *UserQuota `name:"default_quota" json:"default_quota,omitempty" yaml:"default_quota" description:"Quota of each user that does not specify its own."`
+}
+
+// Validate returns an error if the TenancyConfig is invalid.
+func (c *TenancyConfig) Validate() error {
+	switch c.Assignment {
+	case "", UniformUserAssignment, RoundRobinUserAssignment:
+	case ZipfUserAssignment:
+		if c.ZipfExponent != 0 && c.ZipfExponent <= 1 {
+			return fmt.Errorf("%w: zipf exponent must be greater than 1, got %v", ErrInvalidTenancyConfig, c.ZipfExponent)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported user assignment \"%s\"", ErrInvalidTenancyConfig, c.Assignment)
+	}
+
+	if c.NumUsers < 0 {
+		return fmt.Errorf("%w: number of users must be non-negative, got %d", ErrInvalidTenancyConfig, c.NumUsers)
+	}
+
+	usernames := make(map[string]struct{}, len(c.Users))
+	for _, user := range c.Users {
+		if user == nil || user.Username == "" {
+			return fmt.Errorf("%w: every user must have a username", ErrInvalidTenancyConfig)
+		}
+
+		if _, duplicate := usernames[user.Username]; duplicate {
+			return fmt.Errorf("%w: duplicate user \"%s\"", ErrInvalidTenancyConfig, user.Username)
+		}
+		usernames[user.Username] = struct{}{}
+
+		if err := user.Quota.validate(); err != nil {
+			return err
+		}
+	}
+
+	return c.DefaultQuota.validate()
+}
+
+// validate returns an error if the UserQuota is invalid. A nil UserQuota is valid.
+func (q *UserQuota) validate() error {
+	if q == nil {
+		return nil
+	}
+
+	if q.MaxActiveSessions < 0 || q.MaxGpus < 0 {
+		return fmt.Errorf("%w: quota limits must be non-negative", ErrInvalidTenancyConfig)
+	}
+
+	return nil
+}
+
+// Usernames returns the names of the users to which sessions without a known owner are assigned.
+func (c *TenancyConfig) Usernames() []string {
+	if len(c.Users) > 0 {
+		usernames := make([]string, 0, len(c.Users))
+		for _, user := range c.Users {
+			usernames = append(usernames, user.Username)
+		}
+		return usernames
+	}
+
+	numUsers := max(c.NumUsers, 1)
+	usernames := make([]string, 0, numUsers)
+	for i := 0; i < numUsers; i++ {
+		usernames = append(usernames, fmt.Sprintf("user-%d", i))
+	}
+
+	return usernames
+}
+
+// QuotaOf returns the quota of the specified user, which is nil if the user is not limited.
+func (c *TenancyConfig) QuotaOf(username string) *UserQuota {
+	for _, user := range c.Users {
+		if user.Username == username && user.Quota != nil {
+			return user.Quota
+		}
+	}
+
+	return c.DefaultQuota
+}
+
+// UserAssigner assigns the sessions of a workload to simulated users according to a TenancyConfig.
+type UserAssigner struct {
+	config       *TenancyConfig
+	usernames    []string
+	sessionUsers map[string]string // sessionUsers is a map from session ID to the user that owns the session.
+	rng          *rand.Rand
+	zipf         *rand.Zipf
+	next         int // next is the index of the next user of the RoundRobinUserAssignment.
+	mu           sync.Mutex
+}
+
+// NewUserAssigner creates a new UserAssigner whose random assignments are seeded with the given seed.
+func NewUserAssigner(config *TenancyConfig, seed int64) *UserAssigner {
+	assigner := &UserAssigner{
+		config:       config,
+		usernames:    config.Usernames(),
+		sessionUsers: make(map[string]string, len(config.SessionUsers)),
+		rng:          rand.New(rand.NewSource(seed)),
+	}
+
+	for sessionId, username := range config.SessionUsers {
+		assigner.sessionUsers[sessionId] = username
+	}
+
+	if config.Assignment == ZipfUserAssignment {
+		exponent := config.ZipfExponent
+		if exponent == 0 {
+			exponent = DefaultZipfExponent
+		}
+
+		assigner.zipf = rand.NewZipf(assigner.rng, exponent, 1, uint64(len(assigner.usernames)-1))
+	}
+
+	return assigner
+}
+
+// SetSessionUser records that the specified session is owned by the specified user, unless the owner of the
+// session was already known. SetSessionUser is used to pass on ownership information from the trace.
+func (a *UserAssigner) SetSessionUser(sessionId string, username string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, loaded := a.sessionUsers[sessionId]; !loaded && username != "" {
+		a.sessionUsers[sessionId] = username
+	}
+}
+
+// AssignUser returns the user that owns the specified session. The first call for a given session decides
+// the owner of a session whose owner is not known from the trace; subsequent calls return the same user.
+func (a *UserAssigner) AssignUser(sessionId string) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if username, loaded := a.sessionUsers[sessionId]; loaded {
+		return username
+	}
+
+	var idx int
+	switch a.config.Assignment {
+	case ZipfUserAssignment:
+		idx = int(a.zipf.Uint64())
+	case RoundRobinUserAssignment:
+		idx = a.next
+		a.next = (a.next + 1) % len(a.usernames)
+	default:
+		idx = a.rng.Intn(len(a.usernames))
+	}
+
+	username := a.usernames[idx]
+	a.sessionUsers[sessionId] = username
+
+	return username
+}
+
+// JainFairnessIndex returns Jain's fairness index of the given allocations, which ranges from 1/n, if a single
+// party receives everything, to 1, if every party receives the same amount. JainFairnessIndex returns 1 if there
+// are no allocations or if every allocation is zero.
+func JainFairnessIndex(allocations []float64) float64 {
+	var sum, sumOfSquares float64
+	for _, allocation := range allocations {
+		sum += allocation
+		sumOfSquares += allocation * allocation
+	}
+
+	if sumOfSquares == 0 {
+		return 1
+	}
+
+	return (sum * sum) / (float64(len(allocations)) * sumOfSquares)
+}
@@ -0,0 +1,79 @@
+package domain_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Tenancy Tests", func() {
+	It("Will reject invalid configurations", func() {
+		Expect((&domain.TenancyConfig{Assignment: "lottery"}).Validate()).To(MatchError(domain.ErrInvalidTenancyConfig))
+		Expect((&domain.TenancyConfig{Assignment: domain.ZipfUserAssignment, ZipfExponent: 0.5}).Validate()).To(MatchError(domain.ErrInvalidTenancyConfig))
+		Expect((&domain.TenancyConfig{NumUsers: -1}).Validate()).To(MatchError(domain.ErrInvalidTenancyConfig))
+		Expect((&domain.TenancyConfig{Users: []*domain.SimulatedUser{{Username: "a"}, {Username: "a"}}}).Validate()).To(MatchError(domain.ErrInvalidTenancyConfig))
+		Expect((&domain.TenancyConfig{DefaultQuota: &domain.UserQuota{MaxGpus: -1}}).Validate()).To(MatchError(domain.ErrInvalidTenancyConfig))
+		Expect((&domain.TenancyConfig{Assignment: domain.ZipfUserAssignment, NumUsers: 4}).Validate()).To(BeNil())
+	})
+
+	It("Will generate usernames and resolve quotas", func() {
+		Expect((&domain.TenancyConfig{}).Usernames()).To(Equal([]string{"user-0"}))
+		Expect((&domain.TenancyConfig{NumUsers: 2}).Usernames()).To(Equal([]string{"user-0", "user-1"}))
+
+		defaultQuota := &domain.UserQuota{MaxActiveSessions: 1}
+		aliceQuota := &domain.UserQuota{MaxGpus: 8}
+		config := &domain.TenancyConfig{
+			Users:        []*domain.SimulatedUser{{Username: "alice", Quota: aliceQuota}, {Username: "bob"}},
+			DefaultQuota: defaultQuota,
+		}
+		Expect(config.Usernames()).To(Equal([]string{"alice", "bob"}))
+		Expect(config.QuotaOf("alice")).To(Equal(aliceQuota))
+		Expect(config.QuotaOf("bob")).To(Equal(defaultQuota))
+	})
+
+	It("Will assign sessions deterministically", func() {
+		config := &domain.TenancyConfig{Assignment: domain.ZipfUserAssignment, NumUsers: 8}
+		first := domain.NewUserAssigner(config, 42)
+		second := domain.NewUserAssigner(config, 42)
+
+		for i := 0; i < 64; i++ {
+			sessionId := fmt.Sprintf("session-%d", i)
+			Expect(first.AssignUser(sessionId)).To(Equal(second.AssignUser(sessionId)))
+			Expect(first.AssignUser(sessionId)).To(Equal(second.AssignUser(sessionId)))
+		}
+	})
+
+	It("Will assign sessions in turn with the round-robin assignment", func() {
+		assigner := domain.NewUserAssigner(&domain.TenancyConfig{Assignment: domain.RoundRobinUserAssignment, NumUsers: 2}, 0)
+
+		Expect(assigner.AssignUser("a")).To(Equal("user-0"))
+		Expect(assigner.AssignUser("b")).To(Equal("user-1"))
+		Expect(assigner.AssignUser("c")).To(Equal("user-0"))
+		Expect(assigner.AssignUser("a")).To(Equal("user-0"))
+	})
+
+	It("Will prefer the owners known from the trace", func() {
+		assigner := domain.NewUserAssigner(&domain.TenancyConfig{
+			Assignment:   domain.RoundRobinUserAssignment,
+			NumUsers:     2,
+			SessionUsers: map[string]string{"a": "carol"},
+		}, 0)
+
+		assigner.SetSessionUser("a", "dave")
+		assigner.SetSessionUser("b", "erin")
+
+		Expect(assigner.AssignUser("a")).To(Equal("carol"))
+		Expect(assigner.AssignUser("b")).To(Equal("erin"))
+		Expect(assigner.AssignUser("c")).To(Equal("user-0"))
+	})
+
+	It("Will compute Jain's fairness index", func() {
+		Expect(domain.JainFairnessIndex(nil)).To(Equal(1.0))
+		Expect(domain.JainFairnessIndex([]float64{0, 0})).To(Equal(1.0))
+		Expect(domain.JainFairnessIndex([]float64{5, 5, 5})).To(BeNumerically("~", 1.0, 1e-9))
+		Expect(domain.JainFairnessIndex([]float64{10, 0, 0, 0})).To(BeNumerically("~", 0.25, 1e-9))
+	})
+})
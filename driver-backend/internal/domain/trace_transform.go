@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultCopyOffsetSec is the default offset, in seconds, between consecutive copies of a session
+	// when the arrival rate of a trace is multiplied.
+	DefaultCopyOffsetSec = 60
+)
+
+var (
+	ErrInvalidTraceTransform = errors.New("invalid trace transformation")
+)
+
+// TraceTransformConfig specifies how the stream of session events synthesized from the trace of a CSV preset
+// is transformed before it is replayed. TraceTransformConfig is distinct from the timescale adjustment factor of
+// the workload: it changes which events are replayed and at what trace time, rather than how fast the trace time
+// passes.
+type TraceTransformConfig struct {
+	// ArrivalRateMultiplier is the factor by which the session arrival rate is multiplied. Each session of the
+	// trace is replayed ArrivalRateMultiplier times: once as-is, and the remaining times as copies with new IDs,
+	// each shifted by a further CopyOffsetSec. The internal behavior of each copy is identical to the original.
+	ArrivalRateMultiplier int `name:"arrival_rate_multiplier" json:"arrival_rate_multiplier,omitempty" yaml:"arrival_rate_multiplier" description:"Factor by which the session arrival rate is multiplied by overlaying shifted copies of each session. Defaults to 1."`
+	// CopyOffsetSec is the offset, in seconds, between consecutive copies of a session.
+	CopyOffsetSec float64 `name:"copy_offset_sec" json:"copy_offset_sec,omitempty" yaml:"copy_offset_sec" description:"Offset (in seconds) between consecutive copies of a session. Defaults to 60."`
+	// MaxIdleGapSec is the longest gap, in seconds, between two consecutive events of the trace. Longer gaps are
+	// clipped to MaxIdleGapSec, which pulls every later event forward. If zero, gaps are not clipped.
+	MaxIdleGapSec float64 `name:"max_idle_gap_sec" json:"max_idle_gap_sec,omitempty" yaml:"max_idle_gap_sec" description:"Inter-event gaps longer than this (in seconds) are clipped to it. Defaults to no clipping."`
+	// WindowStartSec and WindowEndSec bound the trace time, in seconds since the first event of the trace, during
+	// which sessions must arrive to be replayed. Sessions that arrive within the window are replayed in full, so
+	// that none of them is cut off in the middle of a training. If WindowEndSec is zero, the window is unbounded.
+	WindowStartSec float64 `name:"window_start_sec" json:"window_start_sec,omitempty" yaml:"window_start_sec" description:"Start of the trace-time window (in seconds since the start of the trace) in which sessions must arrive to be replayed."`
+	WindowEndSec   float64 `name:"window_end_sec" json:"window_end_sec,omitempty" yaml:"window_end_sec" description:"End of the trace-time window (in seconds since the start of the trace) in which sessions must arrive to be replayed. Defaults to the end of the trace."`
+}
+
+// Validate returns an error if the TraceTransformConfig is invalid.
+func (c *TraceTransformConfig) Validate() error {
+	if c.ArrivalRateMultiplier < 0 {
+		return fmt.Errorf("%w: arrival rate multiplier must be non-negative, got %d", ErrInvalidTraceTransform, c.ArrivalRateMultiplier)
+	}
+
+	if c.CopyOffsetSec < 0 {
+		return fmt.Errorf("%w: copy offset must be non-negative, got %v", ErrInvalidTraceTransform, c.CopyOffsetSec)
+	}
+
+	if c.MaxIdleGapSec < 0 {
+		return fmt.Errorf("%w: maximum idle gap must be non-negative, got %v", ErrInvalidTraceTransform, c.MaxIdleGapSec)
+	}
+
+	if c.WindowStartSec < 0 || c.WindowEndSec < 0 {
+		return fmt.Errorf("%w: window bounds must be non-negative, got [%v, %v]", ErrInvalidTraceTransform, c.WindowStartSec, c.WindowEndSec)
+	}
+
+	if c.WindowEndSec > 0 && c.WindowEndSec <= c.WindowStartSec {
+		return fmt.Errorf("%w: window end (%v) must be after window start (%v)", ErrInvalidTraceTransform, c.WindowEndSec, c.WindowStartSec)
+	}
+
+	return nil
+}
+
+// NumCopies returns the number of times that each session is replayed, including the original.
+func (c *TraceTransformConfig) NumCopies() int {
+	if c == nil || c.ArrivalRateMultiplier < 1 {
+		return 1
+	}
+
+	return c.ArrivalRateMultiplier
+}
+
+// CopyOffset returns the offset between consecutive copies of a session.
+func (c *TraceTransformConfig) CopyOffset() time.Duration {
+	if c.CopyOffsetSec == 0 {
+		return time.Second * DefaultCopyOffsetSec
+	}
+
+	return time.Duration(c.CopyOffsetSec * float64(time.Second))
+}
+
+// MaxIdleGap returns the longest gap between two consecutive events, or zero if gaps are not clipped.
+func (c *TraceTransformConfig) MaxIdleGap() time.Duration {
+	return time.Duration(c.MaxIdleGapSec * float64(time.Second))
+}
+
+// InWindow returns true if a session that arrives at the given offset from the start of the trace is replayed.
+func (c *TraceTransformConfig) InWindow(sinceTraceStart time.Duration) bool {
+	if sinceTraceStart < time.Duration(c.WindowStartSec*float64(time.Second)) {
+		return false
+	}
+
+	return c.WindowEndSec == 0 || sinceTraceStart <= time.Duration(c.WindowEndSec*float64(time.Second))
+}
+
+// SessionCopyId returns the ID of the specified copy of a session. Copy 0 is the original session.
+//
+// Copy IDs are derived deterministically from the ID of the original session so that they are stable across runs,
+// and they have the same length as a UUID, just like the sessions whose IDs are too long to use as-is.
+func SessionCopyId(sessionId string, copyIdx int) string {
+	if copyIdx == 0 {
+		return sessionId
+	}
+
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s/%d", sessionId, copyIdx))).String()
+}
+
+// AddSessionCopies gives each copy of each session of the MaxUtilizationWrapper the same maximum utilization as
+// the original session, where numCopies includes the original session.
+func (w *MaxUtilizationWrapper) AddSessionCopies(numCopies int) {
+	addSessionCopies(w.CpuSessionMap, numCopies)
+	addSessionCopies(w.MemSessionMap, numCopies)
+	addSessionCopies(w.VramSessionMap, numCopies)
+	addSessionCopies(w.GpuSessionMap, numCopies)
+	addSessionCopies(w.CpuTaskMap, numCopies)
+	addSessionCopies(w.MemTaskMap, numCopies)
+	addSessionCopies(w.GpuTaskMap, numCopies)
+}
+
+// addSessionCopies adds an entry for each copy of each session of the map.
+func addSessionCopies[V any](m map[string]V, numCopies int) {
+	if m == nil {
+		return
+	}
+
+	for sessionId, value := range maps.Clone(m) {
+		for copyIdx := 1; copyIdx < numCopies; copyIdx++ {
+			m[SessionCopyId(sessionId, copyIdx)] = value
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var _ = Describe("Trace Transform Tests", func() {
+	It("Will reject invalid configurations", func() {
+		Expect((&domain.TraceTransformConfig{ArrivalRateMultiplier: -1}).Validate()).To(MatchError(domain.ErrInvalidTraceTransform))
+		Expect((&domain.TraceTransformConfig{MaxIdleGapSec: -5}).Validate()).To(MatchError(domain.ErrInvalidTraceTransform))
+		Expect((&domain.TraceTransformConfig{WindowStartSec: 60, WindowEndSec: 30}).Validate()).To(MatchError(domain.ErrInvalidTraceTransform))
+		Expect((&domain.TraceTransformConfig{ArrivalRateMultiplier: 5, WindowStartSec: 60}).Validate()).To(BeNil())
+	})
+
+	It("Will apply defaults", func() {
+		var config *domain.TraceTransformConfig
+		Expect(config.NumCopies()).To(Equal(1))
+
+		config = &domain.TraceTransformConfig{}
+		Expect(config.CopyOffset()).To(Equal(time.Second * domain.DefaultCopyOffsetSec))
+		Expect(config.InWindow(time.Hour * 24)).To(BeTrue())
+	})
+
+	It("Will derive stable, distinct IDs for the copies of a session", func() {
+		Expect(domain.SessionCopyId("session", 0)).To(Equal("session"))
+		Expect(domain.SessionCopyId("session", 1)).To(Equal(domain.SessionCopyId("session", 1)))
+		Expect(domain.SessionCopyId("session", 1)).ToNot(Equal(domain.SessionCopyId("session", 2)))
+		Expect(domain.SessionCopyId("session", 1)).To(HaveLen(36))
+	})
+
+	It("Will give the copies of a session the maximum utilization of the original", func() {
+		wrapper := domain.NewMaxUtilizationWrapper(
+			map[string]float64{"a": 2}, map[string]float64{"a": 512}, map[string]int{"a": 4},
+			map[string][]float64{"a": {1, 2}}, nil, map[string][]int{"a": {2, 4}})
+
+		wrapper.AddSessionCopies(3)
+
+		Expect(wrapper.GpuSessionMap).To(HaveLen(3))
+		Expect(wrapper.GpuSessionMap[domain.SessionCopyId("a", 2)]).To(Equal(4))
+		Expect(wrapper.CpuTaskMap[domain.SessionCopyId("a", 1)]).To(Equal([]float64{1, 2}))
+		Expect(wrapper.MemTaskMap).To(BeNil())
+	})
+})
@@ -10,6 +10,7 @@ const (
 	Processed EventStatus = "Processed"
 	Discarded EventStatus = "Discarded"
 	Erred     EventStatus = "Error"
+	Audited   EventStatus = "Audit" // Audited events record manual changes made to a workload, rather than events that were processed.
 )
 
 type EventStatus string
@@ -25,6 +26,8 @@ type WorkloadEvent struct {
 	ProcessedSuccessfully bool        `json:"processed_successfully"`  // True if the event was processed without error.
 	ErrorMessage          string      `json:"error_message,omitempty"` // Error message from the error that caused the event to not be processed successfully.
 	Status                EventStatus `json:"status"`
+	Description           string      `json:"description,omitempty"` // Human-readable description of the event. Used primarily by Audited events.
+	Data                  interface{} `json:"data,omitempty"`        // Additional, event-specific data, such as a NotebookCellExecution.
 }
 
 // NewEmptyWorkloadEvent returns an "empty" workload event -- with none of its fields populated.
@@ -101,6 +104,16 @@ func (evt *WorkloadEvent) WithStatus(status EventStatus) *WorkloadEvent {
 	return evt
 }
 
+func (evt *WorkloadEvent) WithDescription(description string) *WorkloadEvent {
+	evt.Description = description
+	return evt
+}
+
+func (evt *WorkloadEvent) WithData(data interface{}) *WorkloadEvent {
+	evt.Data = data
+	return evt
+}
+
 func (evt *WorkloadEvent) WithProcessedAtTime(processedAt time.Time) *WorkloadEvent {
 	evt.ProcessedAt = processedAt.String()
 	return evt
@@ -111,6 +111,30 @@ type StopTrainingRequest struct {
 	SessionId string `json:"session_id"` // The associated session.
 }
 
+// InjectEventRequest is a request to inject an ad-hoc event into the event queue of a running workload.
+type InjectEventRequest struct {
+	WorkloadId    string `json:"workload_id"`
+	SessionId     string `json:"session_id"`     // SessionId is the ID of the session that the event will target.
+	EventName     string `json:"event_name"`     // EventName is either "training" or "session-stopped".
+	TicksFromNow  int64  `json:"ticks_from_now"` // TicksFromNow is the number of ticks after the current tick at which the event occurs. Defaults to 1.
+	DurationTicks int64  `json:"duration_ticks"` // DurationTicks is the duration of an injected training, in ticks.
+}
+
+// PostponeEventRequest is a request to postpone an event within the event queue of a running workload.
+type PostponeEventRequest struct {
+	WorkloadId   string `json:"workload_id"`
+	SessionId    string `json:"session_id"`
+	EventId      string `json:"event_id"`
+	AmountMillis int64  `json:"amount_ms"` // AmountMillis is the (positive) amount by which to postpone the event.
+}
+
+// ShiftSessionRequest is a request to move the entire schedule of a session of a running workload forward or back.
+type ShiftSessionRequest struct {
+	WorkloadId   string `json:"workload_id"`
+	SessionId    string `json:"session_id"`
+	AmountMillis int64  `json:"amount_ms"` // AmountMillis is negative to move the schedule forward and positive to move it back.
+}
+
 // PauseUnpauseWorkloadRequest is a request for pausing and un-pausing a workload.
 // PauseUnpauseWorkloadRequest this pauses or unpauses a workload depends on the value of the Operation field.
 type PauseUnpauseWorkloadRequest struct {
@@ -118,6 +142,27 @@ type PauseUnpauseWorkloadRequest struct {
 	WorkloadId string `json:"workload_id"` // ID of the workload to (un)pause.
 }
 
+// DebugWorkloadRequest is a request for step-debugging a workload. Which debugging operation is performed
+// depends on the value of the Operation field. Breakpoint is used when adding a breakpoint, and BreakpointId
+// is used when removing a breakpoint. NumUpcomingEvents bounds the number of enqueued events returned when
+// inspecting a workload.
+type DebugWorkloadRequest struct {
+	*BaseMessage
+	WorkloadId        string      `json:"workload_id"`
+	Breakpoint        *Breakpoint `json:"breakpoint,omitempty"`
+	BreakpointId      string      `json:"breakpoint_id,omitempty"`
+	NumUpcomingEvents int         `json:"num_upcoming_events,omitempty"`
+}
+
+func (r *DebugWorkloadRequest) String() string {
+	out, err := json.Marshal(r)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(out)
+}
+
 // GetSpecificWorkloadRequest is used to request the latest version of a specific workload.
 type GetSpecificWorkloadRequest struct {
 	*BaseMessage
@@ -185,6 +230,46 @@ type WorkloadRegistrationRequest struct {
 	//
 	// SessionsSamplePercentage must be > 0.
 	SessionsSamplePercentage float64 `name:"sessions_sample_percentage" json:"sessions_sample_percentage" yaml:"sessions_sample_percentage"`
+
+	// SessionSampling specifies how the SessionsSamplePercentage of the sessions are selected.
+	// If SessionSampling is nil, then the sessions are sampled uniformly at random, seeded from the workload.
+	SessionSampling *SessionSamplingConfig `name:"session_sampling" json:"session_sampling,omitempty" yaml:"session_sampling" description:"How the sampled sessions are selected. Defaults to seeded uniform sampling."`
+
+	// SessionBehavior specifies how the sessions react to their trainings being delayed.
+	// If SessionBehavior is nil, then the sessions follow the ClosedLoopBehavior.
+	SessionBehavior *SessionBehaviorModel `name:"session_behavior" json:"session_behavior,omitempty" yaml:"session_behavior" description:"How sessions react to delayed trainings (open-loop, closed-loop, or hybrid). Defaults to closed-loop."`
+
+	// TraceTransform specifies how the trace of a CSV preset is transformed before it is replayed.
+	// If TraceTransform is nil, then the trace is replayed as-is.
+	TraceTransform *TraceTransformConfig `name:"trace_transform" json:"trace_transform,omitempty" yaml:"trace_transform" description:"Arrival-rate scaling, idle-gap clipping, and trace-time windowing of the trace of a CSV preset."`
+
+	// Tenancy specifies how the sessions are assigned to simulated users and which quotas apply to those users.
+	// If Tenancy is nil, then the sessions are anonymous.
+	Tenancy *TenancyConfig `name:"tenancy" json:"tenancy,omitempty" yaml:"tenancy" description:"Assignment of sessions to simulated users, and per-user quotas."`
+
+	// GpuTypes specifies the GPU types required by the sessions of the workload. If GpuTypes is nil, then the
+	// GPU types of the workload's preset are used, if any.
+	GpuTypes *GpuTypeConfig `name:"gpu_types" json:"gpu_types,omitempty" yaml:"gpu_types" description:"The GPU types required by the sessions of the workload."`
+
+	// DistributedTraining specifies which trainings of the workload are gang-scheduled distributed trainings. If
+	// DistributedTraining is nil, then the configuration of the workload's preset is used, if any. Trainings of
+	// workload templates may specify their distributed training themselves.
+	DistributedTraining *DistributedTrainingConfig `name:"distributed_training" json:"distributed_training,omitempty" yaml:"distributed_training" description:"Which trainings of the workload are gang-scheduled distributed trainings."`
+
+	// InteractiveCells specifies the short, CPU-only cells that the sessions execute between their trainings.
+	// If InteractiveCells is nil, then only the sessions of a workload template that specify a rate execute them.
+	InteractiveCells *InteractiveCellConfig `name:"interactive_cells" json:"interactive_cells,omitempty" yaml:"interactive_cells" description:"Short, CPU-only cells that the sessions execute between their trainings."`
+
+	// Notebooks are the notebooks replayed by a notebook-based workload.
+	Notebooks []*WorkloadNotebook `name:"notebooks" json:"notebooks,omitempty" yaml:"notebooks" description:"The notebooks whose code cells are replayed by a notebook-based workload."`
+	// NotebookThinkTime is used to sample the think time between cells when a notebook's metadata does not specify it.
+	NotebookThinkTime *ThinkTimeDistribution `name:"notebook_think_time" json:"notebook_think_time,omitempty" yaml:"notebook_think_time" description:"Distribution of the think time between the cells of a notebook, used when the notebook's metadata does not specify it."`
+	// NotebookCellTimeoutSec bounds how long a single notebook cell may execute before the workload moves on.
+	NotebookCellTimeoutSec int `name:"notebook_cell_timeout_sec" json:"notebook_cell_timeout_sec,omitempty" yaml:"notebook_cell_timeout_sec" description:"How long a single notebook cell may execute before the workload moves on."`
+
+	// RoutingTable assigns the sessions of the workload to different Jupyter Servers and/or kernel specs.
+	// If RoutingTable is nil, then every session uses the configured Jupyter Server and kernel spec.
+	RoutingTable *SessionRoutingTable `name:"routing_table" json:"routing_table,omitempty" yaml:"routing_table" description:"Assigns the sessions of the workload to different Jupyter Servers and/or kernel specs."`
 }
 
 func (r *WorkloadRegistrationRequest) String() string {
@@ -23,6 +23,11 @@ type BaseWorkloadPreset struct {
 	Description string             `name:"description" yaml:"description" json:"description" description:"Human-readable description of the workload."`                           // Human-readable description of the workload.
 	Key         string             `name:"key"  yaml:"key" json:"key" description:"Key for code-use only (i.e., we don't intend to display this to the user for the most part)."` // Key for code-use only (i.e., we don't intend to display this to the user for the most part).
 	PresetType  WorkloadPresetType `name:"preset_type" yaml:"preset_type" json:"preset_type" description:"The type of workload preset. Could be CSV or XML."`
+
+	// GpuTypes and DistributedTraining are only read from the preset file. They have no JSON keys, as those keys
+	// would be repeated by the CsvWorkloadPreset and XmlWorkloadPreset that are both embedded by WorkloadPreset.
+	GpuTypes            *GpuTypeConfig             `name:"gpu_types" yaml:"gpu_types" json:"-" description:"The GPU types required by the sessions of the workload. Overridden by the GPU types of the workload registration request."`
+	DistributedTraining *DistributedTrainingConfig `name:"distributed_training" yaml:"distributed_training" json:"-" description:"Which trainings of the workload are distributed. Overridden by the distributed training of the workload registration request."`
 }
 
 type WorkloadPreset struct {
@@ -81,6 +86,30 @@ func (p *WorkloadPreset) String() string {
 	}
 }
 
+// GetGpuTypes returns the GPU types required by the sessions of the workload, which is nil if the preset
+// does not specify any.
+func (p *WorkloadPreset) GetGpuTypes() *GpuTypeConfig {
+	if p.IsCsv() {
+		return p.CsvWorkloadPreset.GpuTypes
+	} else if p.IsXml() {
+		return p.XmlWorkloadPreset.GpuTypes
+	} else {
+		panic(fmt.Sprintf("WorkloadPreset is of invalid type: %v", p.PresetType))
+	}
+}
+
+// GetDistributedTraining returns the configuration of the distributed trainings of the workload, which is nil
+// if the preset does not specify any.
+func (p *WorkloadPreset) GetDistributedTraining() *DistributedTrainingConfig {
+	if p.IsCsv() {
+		return p.CsvWorkloadPreset.DistributedTraining
+	} else if p.IsXml() {
+		return p.XmlWorkloadPreset.DistributedTraining
+	} else {
+		panic(fmt.Sprintf("WorkloadPreset is of invalid type: %v", p.PresetType))
+	}
+}
+
 func (p *WorkloadPreset) IsCsv() bool {
 	return p.PresetType == CsvWorkloadPresetType
 }
@@ -209,6 +209,16 @@ func validateSessionArgumentsAgainstTrainingArguments(session *domain.WorkloadTe
 		if session.GetMaxResourceRequest().MemoryMB < trainingEvent.MemUsageMB {
 			return fmt.Errorf("%w: incompatible max memory usage (%f MB) and training memory usage (%f GB) specified. Training memory usage cannot exceed maximum session memory usage", ErrInvalidConfiguration, session.GetMaxResourceRequest().MemoryMB, trainingEvent.MemUsageMB)
 		}
+
+		if trainingEvent.Distributed != nil {
+			if err := trainingEvent.Distributed.Validate(); err != nil {
+				return errors.Join(ErrInvalidConfiguration, err)
+			}
+
+			if trainingEvent.Distributed.NumGPUs() != trainingEvent.NumGPUs() {
+				return fmt.Errorf("%w: incompatible distributed training GPUs (%d) and training GPU utilization (%d) specified. The workers of a distributed training must use all of the training's GPUs", ErrInvalidConfiguration, trainingEvent.Distributed.NumGPUs(), trainingEvent.NumGPUs())
+			}
+		}
 	}
 
 	return nil
@@ -145,12 +145,29 @@ func (g *BasicWorkloadGenerator) generateWorkloadWithCsvPreset(consumer domain.E
 	}
 
 	maxUtilizationWrapper := domain.NewMaxUtilizationWrapper(cpuSessionMap, memSessionMap, gpuSessionMap, cpuTaskMap, memTaskMap, gpuTaskMap)
+
+	// The copies of the sessions created by the trace transformation have the same maximum utilization as the originals.
+	traceTransform := workloadRegistrationRequest.TraceTransform
+	if traceTransform != nil {
+		maxUtilizationWrapper.AddSessionCopies(traceTransform.NumCopies())
+	}
+
 	maxUtilizationConsumer.SetMaxUtilizationWrapper(maxUtilizationWrapper)
 
 	g.synthesizer = NewSynthesizer(g.opts, maxUtilizationWrapper, g.atom)
 	// Set the cluster as the EventHandler for the Synthesizer.
 	g.synthesizer.SetEventConsumer(consumer)
 
+	if traceTransform != nil {
+		g.logger.Debug("Transforming synthesized trace.", zap.Any("trace_transform", traceTransform))
+		g.synthesizer.SetTraceTransform(traceTransform)
+	}
+
+	if interactiveCells := workloadRegistrationRequest.InteractiveCells; interactiveCells != nil && interactiveCells.FromTrace {
+		g.logger.Debug("Replaying CPU bursts between trainings as interactive cells.")
+		g.synthesizer.SetInteractiveCellsFromTrace(true)
+	}
+
 	g.logger.Debug("Driving GPU now.")
 
 	// Drive GPU trace
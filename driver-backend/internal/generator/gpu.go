@@ -491,9 +491,11 @@ func (d *GPUDriver) HandleRecord(ctx context.Context, r Record) {
 		sugarLog.Warnf("Error on handling records: %v", err)
 	}
 
-	// if len(events) == 0 {
-	// 	events = append(events, EventGpuUpdateUtil)
-	// }
+	// Changes in the utilization of a busy GPU are reported, so that they can be replayed within trainings.
+	if len(events) == 0 && committed.Status == GPUBusy && committed.LastUtil != nil &&
+		committed.LastUtil.Status == GPUBusy && committed.Value != committed.LastUtil.Value {
+		events = append(events, EventGpuUpdateUtil)
+	}
 
 	// d.sugarLog.Debugf("GPUDriver. Processed record: %v. Committed Status: %v. Triggering %d event(s).", rec, committed.Status, len(events))
 	err = d.triggerMulti(ctx, events, committed)
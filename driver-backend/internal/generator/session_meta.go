@@ -100,6 +100,11 @@ type SessionMeta struct {
 	InitedAt  time.Time     `json:"initedAt"`
 	InitDelay time.Duration `json:"initDelay"`
 
+	// InteractiveCellDuration is the duration of the CPU burst that the session's user ran between trainings.
+	// This will only be set (i.e., have a non-zero/non-default value) when the SessionMeta is attached as data to an 'interactive-cell' event.
+	InteractiveCellDuration time.Duration `json:"interactiveCellDuration,omitempty"`
+	burstStartedAt          time.Time     // burstStartedAt is the start of the session's current CPU burst outside of a training, if any.
+
 	last    *domain.Event   // Track last event for debugging purpose.
 	pending []*domain.Event // For special cases, previous event will be saved here. See Transit implementation.
 }
@@ -121,6 +126,12 @@ func (s *SessionMeta) GetVRAM() float64 {
 	return s.VRAM
 }
 
+// GetInteractiveCellDuration returns the duration of the CPU burst that the session's user ran between trainings.
+// This will only be set (i.e., have a non-zero/non-default value) when the SessionMeta is attached as data to an 'interactive-cell' event.
+func (s *SessionMeta) GetInteractiveCellDuration() time.Duration {
+	return s.InteractiveCellDuration
+}
+
 func (s *SessionMeta) GetNumGPUs() int {
 	if s.GPU == nil {
 		return 0
@@ -338,11 +349,22 @@ func (s *SessionMeta) transit(evt *domain.Event) ([]domain.SessionEventName, err
 		}
 		return NoSessionEvent, nil
 	case SessionStatusIdle:
-		if evt.Name == EventCPUActivated || evt.Name == EventCPUDeactivated {
+		if evt.Name == EventCPUActivated {
+			s.burstStartedAt = evt.Timestamp
 			return NoSessionEvent, nil
+		} else if evt.Name == EventCPUDeactivated {
+			// A CPU burst that began and ended between two trainings is an interactive cell.
+			if s.burstStartedAt.IsZero() {
+				return NoSessionEvent, nil
+			}
+
+			s.InteractiveCellDuration = evt.Timestamp.Sub(s.burstStartedAt)
+			s.burstStartedAt = time.Time{}
+			return []domain.SessionEventName{domain.EventSessionInteractiveCell}, nil
 		} else if evt.Name == EventGPUActivated {
 			s.GPU = evt.Data.(*GPUUtil)
 			s.Status = SessionStatusTraining
+			s.burstStartedAt = time.Time{} // The burst belongs to the training.
 
 			if s.CPU != nil {
 				s.CPU.MaxTaskCPU = 0
@@ -389,13 +411,10 @@ func (s *SessionMeta) transit(evt *domain.Event) ([]domain.SessionEventName, err
 			s.Memory = s.MemoryQuerier.Lookup(evt.Timestamp)
 			// TODO: ignore memory events during stopping, for now.
 			break
+		} else if evt.Name == EventGpuUpdateUtil {
+			s.GPU = evt.Data.(*GPUUtil)
+			return []domain.SessionEventName{domain.EventSessionUpdateGpuUtil}, nil
 		}
-		// else if evt.Name == EventGpuUpdateUtil {
-		// 	s.GPU = evt.Data.(*GPUUtil)
-
-		// 	return NoSessionEvent, nil
-		// 	// return []SessionEventName{EventSessionUpdateGpuUtil}, nil
-		// }
 		return NoSessionEvent, Errorf(ErrUnexpectedSessionStTrans, "SessionStatusTraining on %v", evt)
 	case SessionStatusStopping:
 		if evt.Name == EventGPUStopped {
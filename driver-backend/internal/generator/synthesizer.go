@@ -43,6 +43,8 @@ type Synthesizer struct {
 	sessionIdMapping map[string]string
 
 	consumer              domain.EventConsumer
+	transform             *TraceTransform // transform transforms the events before they reach the consumer. Nil if the events are not transformed.
+	interactiveCells      bool            // interactiveCells is true if the CPU bursts between trainings are submitted as interactive cells.
 	bufferedEvents        chan domain.Event
 	eventsChannel         chan domain.Event
 	eventsHeap            domain.EventHeap
@@ -143,6 +145,20 @@ func (s *Synthesizer) SetEventConsumer(c domain.EventConsumer) {
 	s.consumer = c
 }
 
+// SetTraceTransform configures the Synthesizer to transform the session events that it generates according to
+// the given domain.TraceTransformConfig before submitting them to its domain.EventConsumer.
+//
+// SetTraceTransform must be called after SetEventConsumer.
+func (s *Synthesizer) SetTraceTransform(config *domain.TraceTransformConfig) {
+	s.transform = NewTraceTransform(config, s.consumer, s.log)
+}
+
+// SetInteractiveCellsFromTrace configures whether the Synthesizer submits the CPU bursts that sessions run between
+// their trainings as domain.EventSessionInteractiveCell events. They are dropped by default.
+func (s *Synthesizer) SetInteractiveCellsFromTrace(enabled bool) {
+	s.interactiveCells = enabled
+}
+
 func (s *Synthesizer) CpuSessionMap() map[string]float64 {
 	return s.maxUtilizationWrapper.CpuSessionMap
 }
@@ -192,6 +208,10 @@ func (s *Synthesizer) handleEventPreprocessMode(evtName domain.SessionEventName,
 }
 
 func (s *Synthesizer) handleEventStandard(evt *domain.Event, triggeredEventName domain.SessionEventName, sess *SessionMeta) {
+	if triggeredEventName == domain.EventSessionInteractiveCell && !s.interactiveCells {
+		return
+	}
+
 	eventData := sess.Snapshot()
 
 	trainingIdx := s.CurrentTrainingNumberMap()[sess.Pod]
@@ -243,7 +263,11 @@ func (s *Synthesizer) handleEventStandard(evt *domain.Event, triggeredEventName
 		SessionId:           eventData.Pod,
 	}
 
-	s.consumer.SubmitEvent(sessEvt)
+	if s.transform != nil {
+		s.transform.SubmitEvent(sessEvt)
+	} else {
+		s.consumer.SubmitEvent(sessEvt)
+	}
 }
 
 func (s *Synthesizer) initSession(evt *domain.Event, podData domain.PodData) *SessionMeta {
@@ -480,6 +504,10 @@ func (s *Synthesizer) Synthesize(ctx context.Context, opts *domain.Configuration
 	s.log.Info("Finished consuming events from drivers. Workload generation is done.",
 		zap.Duration("time_elapsed", time.Since(simulationStart)))
 
+	if s.transform != nil {
+		s.transform.Flush()
+	}
+
 	if s.executionMode == 1 {
 		workloadGenerationCompleteChan <- struct{}{}
 		s.log.Info("Informed the Workload Driver that the generator has finished generating events.")
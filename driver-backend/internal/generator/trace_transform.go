@@ -0,0 +1,172 @@
+package generator
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// TraceTransform transforms the stream of session events produced by the Synthesizer according to a
+// domain.TraceTransformConfig before passing the events on to a domain.EventConsumer.
+//
+// The consumer expects events in chronological order, as it advances its clock to the timestamp of each event that
+// it receives. So, the shifted copies of sessions are buffered until the stream of original events catches up with
+// them, and any copies that are still buffered once the Synthesizer is done are released by Flush.
+type TraceTransform struct {
+	config   *domain.TraceTransformConfig
+	consumer domain.EventConsumer
+	log      *zap.Logger
+
+	traceStart    time.Time        // traceStart is the (original) timestamp of the first event of the trace.
+	lastTimestamp time.Time        // lastTimestamp is the (original) timestamp of the last replayed event.
+	clipped       time.Duration    // clipped is the total idle time that has been clipped from the trace so far.
+	replayed      map[string]bool  // replayed records whether each session that has arrived so far is replayed.
+	pending       pendingEventHeap // pending are the shifted copies that have not yet been submitted.
+	numSubmitted  uint64           // numSubmitted is used to submit simultaneous copies in the order they were created.
+}
+
+// NewTraceTransform creates a new TraceTransform that passes the transformed events on to the given consumer.
+func NewTraceTransform(config *domain.TraceTransformConfig, consumer domain.EventConsumer, logger *zap.Logger) *TraceTransform {
+	return &TraceTransform{
+		config:   config,
+		consumer: consumer,
+		log:      logger,
+		replayed: make(map[string]bool),
+		pending:  make(pendingEventHeap, 0),
+	}
+}
+
+// SubmitEvent transforms the given event, which must be later than or simultaneous to every previously
+// submitted event, and passes the result on to the consumer.
+func (t *TraceTransform) SubmitEvent(evt *domain.Event) {
+	if t.traceStart.IsZero() {
+		t.traceStart = evt.Timestamp
+		t.lastTimestamp = evt.Timestamp
+	}
+
+	sessionId := evt.SessionID()
+	replayed, arrived := t.replayed[sessionId]
+	if !arrived {
+		replayed = t.config.InWindow(evt.Timestamp.Sub(t.traceStart))
+		t.replayed[sessionId] = replayed
+
+		if !replayed {
+			t.log.Debug("Session arrived outside of trace window. Skipping.",
+				zap.String("session_id", sessionId),
+				zap.Time("arrival_time", evt.Timestamp),
+				zap.Duration("since_trace_start", evt.Timestamp.Sub(t.traceStart)))
+		}
+	}
+
+	if !replayed {
+		return
+	}
+
+	// Clip the gap since the previous replayed event if it is too long.
+	if maxIdleGap := t.config.MaxIdleGap(); maxIdleGap > 0 {
+		if gap := evt.Timestamp.Sub(t.lastTimestamp); gap > maxIdleGap {
+			t.clipped += gap - maxIdleGap
+		}
+	}
+	t.lastTimestamp = evt.Timestamp
+
+	setEventTimestamp(evt, evt.Timestamp.Add(-t.clipped))
+
+	t.submitPendingUntil(evt.Timestamp)
+	t.consumer.SubmitEvent(evt)
+
+	for copyIdx := 1; copyIdx < t.config.NumCopies(); copyIdx++ {
+		t.numSubmitted += 1
+		heap.Push(&t.pending, &pendingEvent{
+			event: copyEvent(evt, copyIdx, t.config.CopyOffset()*time.Duration(copyIdx)),
+			seq:   t.numSubmitted,
+		})
+	}
+}
+
+// Flush submits every copy that has not yet been submitted. Flush is called once the Synthesizer has
+// finished generating events.
+func (t *TraceTransform) Flush() {
+	for t.pending.Len() > 0 {
+		t.consumer.SubmitEvent(heap.Pop(&t.pending).(*pendingEvent).event)
+	}
+}
+
+// submitPendingUntil submits the pending copies whose timestamps are not after the given timestamp.
+func (t *TraceTransform) submitPendingUntil(timestamp time.Time) {
+	for t.pending.Len() > 0 && !t.pending[0].event.Timestamp.After(timestamp) {
+		t.consumer.SubmitEvent(heap.Pop(&t.pending).(*pendingEvent).event)
+	}
+}
+
+// copyEvent returns a copy of the given event that targets the specified copy of the event's session
+// and that occurs the given offset after the event.
+func copyEvent(evt *domain.Event, copyIdx int, offset time.Duration) *domain.Event {
+	copyId := domain.SessionCopyId(evt.SessionID(), copyIdx)
+
+	var data interface{} = evt.Data
+	if sessionMeta, ok := evt.Data.(*SessionMeta); ok {
+		sessionMetaCopy := sessionMeta.Snapshot()
+		sessionMetaCopy.Pod = copyId
+		data = sessionMetaCopy
+	}
+
+	eventCopy := &domain.Event{
+		Name:                evt.Name,
+		EventSource:         evt.EventSource,
+		OriginalEventSource: evt.OriginalEventSource,
+		Data:                data,
+		ID:                  uuid.New().String(),
+		SessionId:           copyId,
+	}
+	setEventTimestamp(eventCopy, evt.Timestamp.Add(offset))
+
+	return eventCopy
+}
+
+// setEventTimestamp sets the timestamp of the event, which is also the timestamp of its session metadata.
+func setEventTimestamp(evt *domain.Event, timestamp time.Time) {
+	evt.Timestamp = timestamp
+	evt.OriginalTimestamp = timestamp
+
+	if sessionMeta, ok := evt.Data.(*SessionMeta); ok {
+		sessionMeta.Timestamp = timestamp
+	}
+}
+
+// pendingEvent is an event buffered by a TraceTransform.
+type pendingEvent struct {
+	event *domain.Event
+	seq   uint64
+}
+
+// pendingEventHeap is a min-heap of pendingEvent ordered by timestamp and then by the order of creation.
+type pendingEventHeap []*pendingEvent
+
+func (h pendingEventHeap) Len() int { return len(h) }
+
+func (h pendingEventHeap) Less(i, j int) bool {
+	if h[i].event.Timestamp.Equal(h[j].event.Timestamp) {
+		return h[i].seq < h[j].seq
+	}
+
+	return h[i].event.Timestamp.Before(h[j].event.Timestamp)
+}
+
+func (h pendingEventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pendingEventHeap) Push(x interface{}) {
+	*h = append(*h, x.(*pendingEvent))
+}
+
+func (h *pendingEventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	ret := old[n-1]
+	old[n-1] = nil // avoid memory leak
+	*h = old[0 : n-1]
+	return ret
+}
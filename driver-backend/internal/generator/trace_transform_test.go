@@ -0,0 +1,104 @@
+package generator
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+// recordingConsumer is a domain.EventConsumer that records the events submitted to it.
+type recordingConsumer struct {
+	events []*domain.Event
+}
+
+func (c *recordingConsumer) SubmitEvent(evt *domain.Event)                        { c.events = append(c.events, evt) }
+func (c *recordingConsumer) GetErrorChan() chan<- error                           { return nil }
+func (c *recordingConsumer) WorkloadExecutionCompleteChan() chan interface{}      { return nil }
+func (c *recordingConsumer) WorkloadEventGeneratorCompleteChan() chan interface{} { return nil }
+func (c *recordingConsumer) RegisterApproximateFinalTick(int64)                   {}
+
+var _ = Describe("TraceTransform", func() {
+	var (
+		consumer  *recordingConsumer
+		traceZero time.Time
+	)
+
+	newEvent := func(sessionId string, name domain.EventName, offset time.Duration) *domain.Event {
+		return &domain.Event{
+			Name:      name,
+			Data:      &SessionMeta{Pod: sessionId, Timestamp: traceZero.Add(offset)},
+			SessionId: sessionId,
+			Timestamp: traceZero.Add(offset),
+		}
+	}
+
+	replay := func(config *domain.TraceTransformConfig, events ...*domain.Event) {
+		transform := NewTraceTransform(config, consumer, zap.NewNop())
+		for _, evt := range events {
+			transform.SubmitEvent(evt)
+		}
+		transform.Flush()
+	}
+
+	BeforeEach(func() {
+		consumer = &recordingConsumer{}
+		traceZero = time.Unix(1_700_000_000, 0)
+	})
+
+	It("should overlay shifted copies of each session in chronological order", func() {
+		replay(&domain.TraceTransformConfig{ArrivalRateMultiplier: 3, CopyOffsetSec: 60},
+			newEvent("a", domain.EventSessionReady, 0),
+			newEvent("a", domain.EventSessionTrainingStarted, 90*time.Second),
+			newEvent("a", domain.EventSessionStopped, 300*time.Second))
+
+		Expect(consumer.events).To(HaveLen(9))
+		for i := 1; i < len(consumer.events); i++ {
+			Expect(consumer.events[i].Timestamp.Before(consumer.events[i-1].Timestamp)).To(BeFalse())
+		}
+
+		copyId := domain.SessionCopyId("a", 2)
+		copies := make([]*domain.Event, 0)
+		for _, evt := range consumer.events {
+			if evt.SessionID() == copyId {
+				copies = append(copies, evt)
+			}
+		}
+
+		Expect(copies).To(HaveLen(3))
+		Expect(copies[0].Name).To(Equal(domain.EventSessionReady))
+		Expect(copies[0].Timestamp).To(Equal(traceZero.Add(120 * time.Second)))
+		Expect(copies[1].Timestamp).To(Equal(traceZero.Add(210 * time.Second)))
+		Expect(copies[2].Timestamp).To(Equal(traceZero.Add(420 * time.Second)))
+	})
+
+	It("should clip long idle gaps", func() {
+		replay(&domain.TraceTransformConfig{MaxIdleGapSec: 60},
+			newEvent("a", domain.EventSessionReady, 0),
+			newEvent("a", domain.EventSessionTrainingStarted, 30*time.Second),
+			newEvent("a", domain.EventSessionTrainingEnded, 3600*time.Second),
+			newEvent("a", domain.EventSessionStopped, 3630*time.Second))
+
+		Expect(consumer.events).To(HaveLen(4))
+		Expect(consumer.events[2].Timestamp).To(Equal(traceZero.Add(90 * time.Second)))
+		Expect(consumer.events[2].Data.(*SessionMeta).Timestamp).To(Equal(traceZero.Add(90 * time.Second)))
+		Expect(consumer.events[3].Timestamp).To(Equal(traceZero.Add(120 * time.Second)))
+	})
+
+	It("should only replay the sessions that arrive within the window", func() {
+		replay(&domain.TraceTransformConfig{WindowStartSec: 60, WindowEndSec: 120},
+			newEvent("early", domain.EventSessionReady, 0),
+			newEvent("inside", domain.EventSessionReady, 90*time.Second),
+			newEvent("early", domain.EventSessionStopped, 100*time.Second),
+			newEvent("late", domain.EventSessionReady, 150*time.Second),
+			newEvent("inside", domain.EventSessionStopped, 200*time.Second))
+
+		Expect(consumer.events).To(HaveLen(2))
+		Expect(consumer.events[0].SessionID()).To(Equal("inside"))
+		Expect(consumer.events[1].SessionID()).To(Equal("inside"))
+		Expect(consumer.events[1].Name).To(Equal(domain.EventSessionStopped))
+	})
+})
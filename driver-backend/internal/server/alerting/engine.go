@@ -0,0 +1,118 @@
+package alerting
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Alert is created when an alert Rule fires.
+type Alert struct {
+	RuleName     string     `json:"rule_name"`
+	Description  string     `json:"description"`
+	Signal       string     `json:"signal"`
+	Comparator   Comparator `json:"comparator"`
+	Threshold    float64    `json:"threshold"`
+	Value        float64    `json:"value"`
+	Action       Action     `json:"action"`
+	Severity     Severity   `json:"severity"`
+	WorkloadId   string     `json:"workload_id"`
+	WorkloadName string     `json:"workload_name"`
+	FiredAt      time.Time  `json:"fired_at"`
+}
+
+// Title returns a short, human-readable title for the Alert.
+func (a *Alert) Title() string {
+	return fmt.Sprintf("Alert \"%s\" Fired for Workload \"%s\"", a.RuleName, a.WorkloadName)
+}
+
+// Message returns a human-readable description of why the Alert fired.
+func (a *Alert) Message() string {
+	msg := fmt.Sprintf("Signal \"%s\" of workload \"%s\" (ID=\"%s\") has value %v, which satisfies condition \"%s %s %v\". Action: %s.",
+		a.Signal, a.WorkloadName, a.WorkloadId, a.Value, a.Signal, a.Comparator, a.Threshold, a.Action)
+
+	if a.Description != "" {
+		return fmt.Sprintf("%s %s", a.Description, msg)
+	}
+
+	return msg
+}
+
+// ruleState tracks the evaluation state of a single Rule.
+type ruleState struct {
+	consecutive int  // Number of consecutive evaluations for which the rule's condition held.
+	firing      bool // Whether the rule is currently firing.
+}
+
+// Engine evaluates a collection of alert rules against the signals of a single workload.
+//
+// A Rule fires once its condition has held for Rule.ForTicks consecutive evaluations. A Rule that is firing
+// will not fire again until its condition stops holding, at which point it is reset.
+type Engine struct {
+	rules  []*Rule
+	states map[string]*ruleState
+	mu     sync.Mutex
+}
+
+// NewEngine creates a new Engine that evaluates the given rules.
+func NewEngine(rules []*Rule) *Engine {
+	engine := &Engine{
+		rules:  rules,
+		states: make(map[string]*ruleState, len(rules)),
+	}
+
+	for _, rule := range rules {
+		engine.states[rule.Name] = &ruleState{}
+	}
+
+	return engine
+}
+
+// NumRules returns the number of rules evaluated by the Engine.
+func (e *Engine) NumRules() int {
+	return len(e.rules)
+}
+
+// Evaluate evaluates each of the Engine's rules against the given signal values, returning an Alert for each rule
+// that began firing during this evaluation. Rules whose signal is not present are skipped.
+func (e *Engine) Evaluate(workloadId string, workloadName string, signals map[string]float64) []*Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]*Alert, 0)
+	for _, rule := range e.rules {
+		value, ok := signals[rule.Signal]
+		if !ok {
+			continue
+		}
+
+		state := e.states[rule.Name]
+		if !rule.Comparator.Compare(value, rule.Threshold) {
+			state.consecutive = 0
+			state.firing = false
+			continue
+		}
+
+		state.consecutive += 1
+		if state.firing || state.consecutive < rule.ForTicks {
+			continue
+		}
+
+		state.firing = true
+		alerts = append(alerts, &Alert{
+			RuleName:     rule.Name,
+			Description:  rule.Description,
+			Signal:       rule.Signal,
+			Comparator:   rule.Comparator,
+			Threshold:    rule.Threshold,
+			Value:        value,
+			Action:       rule.Action,
+			Severity:     rule.Severity,
+			WorkloadId:   workloadId,
+			WorkloadName: workloadName,
+			FiredAt:      time.Now(),
+		})
+	}
+
+	return alerts
+}
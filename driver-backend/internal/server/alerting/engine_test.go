@@ -0,0 +1,56 @@
+package alerting_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/alerting"
+)
+
+var _ = Describe("Alert Engine Tests", func() {
+	It("Will fill in the default values of a valid rule", func() {
+		rule := &alerting.Rule{Name: "rule", Signal: alerting.SignalLongTicks, Comparator: alerting.GreaterThan}
+		Expect(rule.Validate()).To(BeNil())
+		Expect(rule.ForTicks).To(Equal(1))
+		Expect(rule.Action).To(Equal(alerting.ActionNotify))
+		Expect(rule.Severity).To(Equal(alerting.SeverityWarning))
+	})
+
+	It("Will reject rules with an unknown signal or comparator", func() {
+		rule := &alerting.Rule{Name: "rule", Signal: "not_a_signal", Comparator: alerting.GreaterThan}
+		Expect(rule.Validate()).To(MatchError(alerting.ErrInvalidRule))
+
+		rule = &alerting.Rule{Name: "rule", Signal: alerting.SignalLongTicks, Comparator: "=>"}
+		Expect(rule.Validate()).To(MatchError(alerting.ErrInvalidRule))
+	})
+
+	It("Will only fire a rule once its condition has held for the configured number of evaluations", func() {
+		rule := &alerting.Rule{Name: "rule", Signal: alerting.SignalLongTicks, Comparator: alerting.GreaterThanOrEqual, Threshold: 2, ForTicks: 2}
+		Expect(rule.Validate()).To(BeNil())
+
+		engine := alerting.NewEngine([]*alerting.Rule{rule})
+
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 1})).To(BeEmpty())
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 2})).To(BeEmpty())
+
+		alerts := engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 3})
+		Expect(alerts).To(HaveLen(1))
+		Expect(alerts[0].RuleName).To(Equal("rule"))
+		Expect(alerts[0].Value).To(Equal(3.0))
+		Expect(alerts[0].WorkloadId).To(Equal("id"))
+
+		// The rule is already firing, so it should not fire again until its condition stops holding.
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 4})).To(BeEmpty())
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 0})).To(BeEmpty())
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 5})).To(BeEmpty())
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 5})).To(HaveLen(1))
+	})
+
+	It("Will skip rules whose signal is not present", func() {
+		rule := &alerting.Rule{Name: "rule", Signal: alerting.SignalGatewayDisconnected, Comparator: alerting.Equal, Threshold: 0}
+		Expect(rule.Validate()).To(BeNil())
+
+		engine := alerting.NewEngine([]*alerting.Rule{rule})
+		Expect(engine.Evaluate("id", "name", map[string]float64{alerting.SignalLongTicks: 0})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,188 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// SignalLongTicks is the number of ticks that took notably longer than the moving average tick duration.
+	SignalLongTicks = "long_ticks"
+
+	// SignalMaxSessionFailedTicks is the largest number of ticks that any single session failed to finish
+	// processing its events within.
+	SignalMaxSessionFailedTicks = "max_session_failed_ticks"
+
+	// SignalTrainingStartLatencyMillis is the most recently-observed training start latency in milliseconds.
+	SignalTrainingStartLatencyMillis = "training_start_latency_ms"
+
+	// SignalTrainingStartTimeouts is the number of times that we timed-out waiting for a training to start.
+	SignalTrainingStartTimeouts = "training_start_timeouts"
+
+	// SignalGatewayDisconnected is 1 if the dashboard backend is disconnected from the Cluster Gateway and 0 otherwise.
+	SignalGatewayDisconnected = "gateway_disconnected"
+
+	// SignalSessionDelays is the number of times that sessions were delayed due to resource contention.
+	SignalSessionDelays = "session_delays"
+
+	// SignalAggregateSessionDelayMillis is the aggregate delay, in milliseconds, of all sessions.
+	SignalAggregateSessionDelayMillis = "aggregate_session_delay_ms"
+
+	// SignalNumDiscardedSessions is the number of sessions that have been discarded.
+	SignalNumDiscardedSessions = "num_discarded_sessions"
+
+	// SignalNumActiveTrainings is the number of trainings that are actively running.
+	SignalNumActiveTrainings = "num_active_trainings"
+
+	// SignalEventQueueDepth is the number of events enqueued in the workload's event queue.
+	SignalEventQueueDepth = "event_queue_depth"
+
+	ActionNotify Action = "notify" // ActionNotify only notifies the dashboard (and the webhook, if one is configured).
+	ActionPause  Action = "pause"  // ActionPause notifies and then pauses the workload.
+	ActionAbort  Action = "abort"  // ActionAbort notifies and then aborts the workload.
+
+	GreaterThan        Comparator = ">"
+	GreaterThanOrEqual Comparator = ">="
+	LessThan           Comparator = "<"
+	LessThanOrEqual    Comparator = "<="
+	Equal              Comparator = "=="
+	NotEqual           Comparator = "!="
+
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var (
+	ErrInvalidRule = errors.New("invalid alert rule")
+
+	// Signals are the names of all the signals that alert rules may be defined against.
+	Signals = []string{SignalLongTicks, SignalMaxSessionFailedTicks, SignalTrainingStartLatencyMillis,
+		SignalTrainingStartTimeouts, SignalGatewayDisconnected, SignalSessionDelays, SignalAggregateSessionDelayMillis,
+		SignalNumDiscardedSessions, SignalNumActiveTrainings, SignalEventQueueDepth}
+)
+
+// Action is the action taken (in addition to sending notifications) when an alert Rule fires.
+type Action string
+
+func (a Action) String() string {
+	return string(a)
+}
+
+// Comparator defines how the value of a signal is compared against the threshold of a Rule.
+type Comparator string
+
+// Compare returns true if the given value satisfies the Comparator relative to the given threshold.
+func (c Comparator) Compare(value float64, threshold float64) bool {
+	switch c {
+	case GreaterThan:
+		return value > threshold
+	case GreaterThanOrEqual:
+		return value >= threshold
+	case LessThan:
+		return value < threshold
+	case LessThanOrEqual:
+		return value <= threshold
+	case Equal:
+		return value == threshold
+	case NotEqual:
+		return value != threshold
+	default:
+		return false
+	}
+}
+
+// Severity indicates how serious a fired alert is.
+type Severity string
+
+// Rule is a declarative alert rule that is evaluated against the statistics and live state of a running workload.
+type Rule struct {
+	Name        string     `name:"name" yaml:"name" json:"name" description:"Unique, human-readable name of the alert rule."`
+	Description string     `name:"description" yaml:"description" json:"description" description:"Human-readable description of the alert rule."`
+	Signal      string     `name:"signal" yaml:"signal" json:"signal" description:"The name of the signal that the rule is evaluated against."`
+	Comparator  Comparator `name:"comparator" yaml:"comparator" json:"comparator" description:"How the signal is compared against the threshold. One of >, >=, <, <=, ==, or !=."`
+	Threshold   float64    `name:"threshold" yaml:"threshold" json:"threshold" description:"The threshold that the signal is compared against."`
+	ForTicks    int        `name:"for_ticks" yaml:"for_ticks" json:"for_ticks" description:"Number of consecutive evaluations for which the condition must hold before the rule fires. Defaults to 1."`
+	Action      Action     `name:"action" yaml:"action" json:"action" description:"Action taken when the rule fires. One of notify, pause, or abort. Defaults to notify."`
+	Severity    Severity   `name:"severity" yaml:"severity" json:"severity" description:"Severity of the alert. One of warning or error. Defaults to warning."`
+}
+
+// Validate checks that the Rule is well-formed, populating any optional fields that were left unspecified
+// with their default values.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: rule name must be specified", ErrInvalidRule)
+	}
+
+	validSignal := false
+	for _, signal := range Signals {
+		if r.Signal == signal {
+			validSignal = true
+			break
+		}
+	}
+
+	if !validSignal {
+		return fmt.Errorf("%w: rule \"%s\" specifies unknown signal \"%s\"", ErrInvalidRule, r.Name, r.Signal)
+	}
+
+	switch r.Comparator {
+	case GreaterThan, GreaterThanOrEqual, LessThan, LessThanOrEqual, Equal, NotEqual:
+	default:
+		return fmt.Errorf("%w: rule \"%s\" specifies unknown comparator \"%s\"", ErrInvalidRule, r.Name, r.Comparator)
+	}
+
+	if r.ForTicks < 0 {
+		return fmt.Errorf("%w: rule \"%s\" specifies negative for_ticks (%d)", ErrInvalidRule, r.Name, r.ForTicks)
+	} else if r.ForTicks == 0 {
+		r.ForTicks = 1
+	}
+
+	switch r.Action {
+	case "":
+		r.Action = ActionNotify
+	case ActionNotify, ActionPause, ActionAbort:
+	default:
+		return fmt.Errorf("%w: rule \"%s\" specifies unknown action \"%s\"", ErrInvalidRule, r.Name, r.Action)
+	}
+
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityWarning
+	case SeverityWarning, SeverityError:
+	default:
+		return fmt.Errorf("%w: rule \"%s\" specifies unknown severity \"%s\"", ErrInvalidRule, r.Name, r.Severity)
+	}
+
+	return nil
+}
+
+// LoadRulesFromFile loads and validates the alert rules defined within the specified .YAML file.
+func LoadRulesFromFile(filepath string) ([]*Rule, error) {
+	file, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]*Rule, 0)
+	if err = yaml.Unmarshal(file, &rules); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if err = rule.Validate(); err != nil {
+			return nil, err
+		}
+
+		if _, loaded := names[rule.Name]; loaded {
+			return nil, fmt.Errorf("%w: duplicate rule name \"%s\"", ErrInvalidRule, rule.Name)
+		}
+
+		names[rule.Name] = struct{}{}
+	}
+
+	return rules, nil
+}
@@ -404,6 +404,21 @@ func (q *EventQueue) SetSessionDelay(sessionId string, delay time.Duration) erro
 	return nil
 }
 
+// GetSessionDelay returns the delay of the specified Session, which is applied to all of its events.
+//
+// GetSessionDelay returns an ErrUnregisteredSession error if the specified Session does not have an event queue.
+func (q *EventQueue) GetSessionDelay(sessionId string) (time.Duration, error) {
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	val, loaded := q.eventsPerSession.Get(sessionId)
+	if !loaded {
+		return 0, fmt.Errorf("%w: \"%s\"", ErrUnregisteredSession, sessionId)
+	}
+
+	return val.(*SessionEventQueue).Delay, nil
+}
+
 // Pop return the next event that occurs at or before the given timestamp, or nil if there are no such events.
 // This will remove the event from the main EventQueueServiceImpl::eventHeap, but it will NOT remove the
 // event from the EventQueueServiceImpl::eventsPerSession. To do that, you must call EventQueueServiceImpl::UnregisterEvent().
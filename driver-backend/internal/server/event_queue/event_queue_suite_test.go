@@ -3,6 +3,7 @@ package event_queue_test
 import (
 	"github.com/google/uuid"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/generator"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/mock_domain"
 	"go.uber.org/mock/gomock"
 	"testing"
@@ -29,6 +30,20 @@ func createEvent(name domain.EventName, sessionId string, index uint64, timestam
 	}
 }
 
+// createSessionEvent creates an event whose data is a generator.SessionMeta, rather than a mocked
+// domain.SessionMetadata, so that the event can be enqueued within an EventQueue.
+func createSessionEvent(name domain.EventName, sessionId string, index uint64, timestamp time.Time) *domain.Event {
+	return &domain.Event{
+		Name:        name,
+		GlobalIndex: index,
+		LocalIndex:  int(index),
+		ID:          uuid.NewString(),
+		Timestamp:   timestamp,
+		SessionId:   sessionId,
+		Data:        &generator.SessionMeta{Pod: sessionId},
+	}
+}
+
 func TestEventQueue(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "EventQueue Suite")
@@ -250,6 +250,25 @@ var _ = Describe("EventQueue Tests", func() {
 			Expect(errors.Is(err, event_queue.ErrUnregisteredSession)).To(BeTrue())
 		})
 
+		It("Will return the delay of a session", func() {
+			_, err := queue.GetSessionDelay("UnknownSession")
+			Expect(errors.Is(err, event_queue.ErrUnregisteredSession)).To(BeTrue())
+
+			session1Id := "Session1"
+			queue.EnqueueEvent(createSessionEvent(domain.EventSessionStarted, session1Id, 0, time.UnixMilli(0)))
+
+			delay, err := queue.GetSessionDelay(session1Id)
+			Expect(err).To(BeNil())
+			Expect(delay).To(BeZero())
+
+			Expect(queue.DelaySession(session1Id, time.Millisecond*50)).To(BeNil())
+			Expect(queue.DelaySession(session1Id, time.Millisecond*25)).To(BeNil())
+
+			delay, err = queue.GetSessionDelay(session1Id)
+			Expect(err).To(BeNil())
+			Expect(delay).To(Equal(time.Millisecond * 75))
+		})
+
 		It("Will correctly account for delay", func() {
 			Expect(queue.Len()).To(Equal(0))
 			Expect(queue.NumSessionQueues()).To(Equal(0))
@@ -0,0 +1,165 @@
+package event_queue
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+// QueuedEvent is a read-only snapshot of a domain.Event that is presently enqueued within an EventQueue.
+type QueuedEvent struct {
+	Id                 string           `json:"id"`
+	SessionId          string           `json:"session_id"`
+	Name               domain.EventName `json:"name"`
+	Timestamp          time.Time        `json:"timestamp"`           // Timestamp is the (possibly-delayed) timestamp of the event itself.
+	EffectiveTimestamp time.Time        `json:"effective_timestamp"` // EffectiveTimestamp is Timestamp plus the delay of the event's session queue.
+	OriginalTimestamp  time.Time        `json:"original_timestamp"`  // OriginalTimestamp is the timestamp of the event within the trace.
+	EventDelay         time.Duration    `json:"event_delay"`         // EventDelay is the delay applied to the event itself.
+	SessionDelay       time.Duration    `json:"session_delay"`       // SessionDelay is the delay applied to all events of the event's session.
+	HoldActive         bool             `json:"hold_active"`         // HoldActive indicates whether the event's session has an active hold.
+	NumTimesEnqueued   int32            `json:"num_times_enqueued"`
+	GlobalIndex        uint64           `json:"global_index"`
+}
+
+// SessionQueueSummary summarizes the SessionEventQueue of a single session.
+type SessionQueueSummary struct {
+	SessionId     string           `json:"session_id"`
+	NumEvents     int              `json:"num_events"`
+	Delay         time.Duration    `json:"delay"`
+	HoldActive    bool             `json:"hold_active"`
+	NextEventName domain.EventName `json:"next_event_name,omitempty"`
+	NextEventTime time.Time        `json:"next_event_time"` // NextEventTime includes the session's Delay.
+}
+
+// newQueuedEvent creates a QueuedEvent snapshot of the given domain.Event, which belongs to the given SessionEventQueue.
+func newQueuedEvent(evt *domain.Event, sessionQueue *SessionEventQueue) *QueuedEvent {
+	return &QueuedEvent{
+		Id:                 evt.ID,
+		SessionId:          sessionQueue.SessionId,
+		Name:               evt.Name,
+		Timestamp:          evt.Timestamp,
+		EffectiveTimestamp: evt.Timestamp.Add(sessionQueue.Delay),
+		OriginalTimestamp:  evt.OriginalTimestamp,
+		EventDelay:         evt.Delay,
+		SessionDelay:       sessionQueue.Delay,
+		HoldActive:         sessionQueue.HoldActive,
+		NumTimesEnqueued:   evt.GetNumTimesEnqueued(),
+		GlobalIndex:        evt.GlobalEventIndex(),
+	}
+}
+
+// UpcomingEvents returns snapshots of (up to) the next n events that are enqueued within the EventQueue, in the
+// order in which they will be processed. If n is non-positive, then all enqueued events are returned.
+//
+// If sessionId is non-empty, then only events targeting that session are returned, and an ErrUnregisteredSession
+// error is returned if the specified session does not have an event queue.
+//
+// UpcomingEvents does not modify the EventQueue.
+func (q *EventQueue) UpcomingEvents(sessionId string, n int) ([]*QueuedEvent, error) {
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	var sessionQueues []*SessionEventQueue
+	if sessionId != "" {
+		val, loaded := q.eventsPerSession.Get(sessionId)
+		if !loaded {
+			return nil, fmt.Errorf("%w: \"%s\"", ErrUnregisteredSession, sessionId)
+		}
+
+		sessionQueues = []*SessionEventQueue{val.(*SessionEventQueue)}
+	} else {
+		sessionQueues = q.events
+	}
+
+	queuedEvents := make([]*QueuedEvent, 0, q.lenUnsafe())
+	for _, sessionQueue := range sessionQueues {
+		// We iterate over the underlying slice rather than sorting it, as sorting a domain.EventHeap in-place
+		// would mutate the heap indices of the events.
+		for _, evt := range sessionQueue.InternalQueue {
+			queuedEvents = append(queuedEvents, newQueuedEvent(evt, sessionQueue))
+		}
+	}
+
+	sort.SliceStable(queuedEvents, func(i, j int) bool {
+		// Events from sessions with an active hold are processed after all other events.
+		if queuedEvents[i].HoldActive != queuedEvents[j].HoldActive {
+			return !queuedEvents[i].HoldActive
+		}
+
+		if !queuedEvents[i].EffectiveTimestamp.Equal(queuedEvents[j].EffectiveTimestamp) {
+			return queuedEvents[i].EffectiveTimestamp.Before(queuedEvents[j].EffectiveTimestamp)
+		}
+
+		// SessionReady events should always go first.
+		if (queuedEvents[i].Name == domain.EventSessionReady) != (queuedEvents[j].Name == domain.EventSessionReady) {
+			return queuedEvents[i].Name == domain.EventSessionReady
+		}
+
+		return queuedEvents[i].GlobalIndex < queuedEvents[j].GlobalIndex
+	})
+
+	if n > 0 && len(queuedEvents) > n {
+		queuedEvents = queuedEvents[:n]
+	}
+
+	return queuedEvents, nil
+}
+
+// SessionQueueSummaries returns a SessionQueueSummary for each session that has an event queue registered
+// with the EventQueue, sorted by session ID.
+func (q *EventQueue) SessionQueueSummaries() []*SessionQueueSummary {
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	summaries := make([]*SessionQueueSummary, 0, q.eventsPerSession.Len())
+	for keyValue := range q.eventsPerSession.Iter() {
+		sessionQueue := keyValue.Value.(*SessionEventQueue)
+
+		summary := &SessionQueueSummary{
+			SessionId:  sessionQueue.SessionId,
+			NumEvents:  sessionQueue.Len(),
+			Delay:      sessionQueue.Delay,
+			HoldActive: sessionQueue.HoldActive,
+		}
+
+		if nextEvent := sessionQueue.Peek(); nextEvent != nil {
+			summary.NextEventName = nextEvent.Name
+			summary.NextEventTime = nextEvent.Timestamp.Add(sessionQueue.Delay)
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].SessionId < summaries[j].SessionId
+	})
+
+	return summaries
+}
+
+// UpcomingSessionReadyEvents returns the "session-ready" events that are enqueued within the EventQueue and whose
+// timestamp (including any session delay) is at or before the given horizon, in chronological order.
+//
+// The returned events remain enqueued and must not be modified. UpcomingSessionReadyEvents is intended for
+// looking ahead at the sessions that are about to be created, such as to pre-warm their kernels.
+func (q *EventQueue) UpcomingSessionReadyEvents(horizon time.Time) []*domain.Event {
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	upcoming := make([]*domain.Event, 0)
+	for _, sessionQueue := range q.events {
+		for _, evt := range sessionQueue.InternalQueue {
+			if evt.Name == domain.EventSessionReady && !evt.Timestamp.Add(sessionQueue.Delay).After(horizon) {
+				upcoming = append(upcoming, evt)
+			}
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].Timestamp.Before(upcoming[j].Timestamp)
+	})
+
+	return upcoming
+}
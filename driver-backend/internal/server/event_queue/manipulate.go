@@ -0,0 +1,105 @@
+package event_queue
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrEventNotFound       = errors.New("specified event is not enqueued")
+	ErrInvalidPostponement = errors.New("events may only be postponed by a positive amount of time")
+)
+
+// unsafeGetEnqueuedEvent returns the SessionEventQueue of the specified session and the specified domain.Event,
+// which must presently be enqueued within that SessionEventQueue.
+//
+// unsafeGetEnqueuedEvent must be called with the eventHeapMutex held.
+func (q *EventQueue) unsafeGetEnqueuedEvent(sessionId string, eventId string) (*SessionEventQueue, *domain.Event, error) {
+	val, loaded := q.eventsPerSession.Get(sessionId)
+	if !loaded {
+		return nil, nil, fmt.Errorf("%w: \"%s\"", ErrUnregisteredSession, sessionId)
+	}
+
+	sessionEventQueue := val.(*SessionEventQueue)
+
+	// The EventsMap retains events after they've been popped, so we also verify that the event is still in the heap.
+	val, loaded = sessionEventQueue.EventsMap.Get(eventId)
+	if !loaded {
+		return nil, nil, fmt.Errorf("%w: \"%s\"", ErrEventNotFound, eventId)
+	}
+
+	evt := val.(*domain.Event)
+	if evt.HeapIndex < 0 || evt.HeapIndex >= sessionEventQueue.Len() || sessionEventQueue.InternalQueue[evt.HeapIndex] != evt {
+		return nil, nil, fmt.Errorf("%w: \"%s\"", ErrEventNotFound, eventId)
+	}
+
+	return sessionEventQueue, evt, nil
+}
+
+// RemoveEvent removes the specified event from the EventQueue so that it is never processed.
+//
+// RemoveEvent returns the removed event on success. RemoveEvent returns an ErrUnregisteredSession error if
+// the specified session does not have an event queue and an ErrEventNotFound error if the specified event
+// is not presently enqueued (e.g., because it has already been processed).
+func (q *EventQueue) RemoveEvent(sessionId string, eventId string) (*domain.Event, error) {
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	sessionEventQueue, evt, err := q.unsafeGetEnqueuedEvent(sessionId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	heap.Remove(&sessionEventQueue.InternalQueue, evt.HeapIndex)
+	sessionEventQueue.EventsMap.Del(eventId)
+	evt.SetIndex(-1)
+	evt.Dequeued()
+
+	heap.Fix(&q.events, sessionEventQueue.HeapIndex)
+
+	q.logger.Debug("Removed event from queue.",
+		zap.String("session_id", sessionId),
+		zap.String("event_id", eventId),
+		zap.String("event_name", evt.Name.String()),
+		zap.Time("event_timestamp", evt.Timestamp))
+
+	return evt, nil
+}
+
+// PostponeEvent pushes the timestamp of the specified event back by the specified (positive) amount of time.
+//
+// PostponeEvent returns the postponed event on success. PostponeEvent returns an ErrUnregisteredSession error
+// if the specified session does not have an event queue and an ErrEventNotFound error if the specified event
+// is not presently enqueued.
+func (q *EventQueue) PostponeEvent(sessionId string, eventId string, amount time.Duration) (*domain.Event, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPostponement, amount)
+	}
+
+	q.eventHeapMutex.Lock()
+	defer q.eventHeapMutex.Unlock()
+
+	sessionEventQueue, evt, err := q.unsafeGetEnqueuedEvent(sessionId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	evt.PushTimestampBack(amount)
+
+	heap.Fix(&sessionEventQueue.InternalQueue, evt.HeapIndex)
+	heap.Fix(&q.events, sessionEventQueue.HeapIndex)
+
+	q.logger.Debug("Postponed event.",
+		zap.String("session_id", sessionId),
+		zap.String("event_id", eventId),
+		zap.String("event_name", evt.Name.String()),
+		zap.Duration("amount", amount),
+		zap.Time("new_event_timestamp", evt.Timestamp))
+
+	return evt, nil
+}
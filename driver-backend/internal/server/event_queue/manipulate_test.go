@@ -0,0 +1,187 @@
+package event_queue
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/generator"
+)
+
+// expectHeapInvariant verifies that the MainEventQueue of the given EventQueue and each of its SessionEventQueue
+// instances satisfy the heap invariant and that the indices recorded by their elements are correct.
+func expectHeapInvariant(q *EventQueue) {
+	for i, sessionQueue := range q.events {
+		Expect(sessionQueue.HeapIndex).To(Equal(i))
+		if i > 0 {
+			Expect(q.events.Less(i, (i-1)/2)).To(BeFalse())
+		}
+
+		for j, evt := range sessionQueue.InternalQueue {
+			Expect(evt.HeapIndex).To(Equal(j))
+			if j > 0 {
+				Expect(sessionQueue.InternalQueue.Less(j, (j-1)/2)).To(BeFalse())
+			}
+		}
+	}
+}
+
+// popAll pops all the events of the given EventQueue, in order.
+func popAll(q *EventQueue) []*domain.Event {
+	events := make([]*domain.Event, 0, q.Len())
+	for evt := q.Pop(time.UnixMilli(1 << 40)); evt != nil; evt = q.Pop(time.UnixMilli(1 << 40)) {
+		events = append(events, evt)
+	}
+
+	return events
+}
+
+var _ = Describe("EventQueue Manipulation Tests", func() {
+	var (
+		queue    *EventQueue
+		sessions map[string][]*domain.Event
+	)
+
+	newEvent := func(name domain.EventName, sessionId string, index uint64, timestampMillis int64) *domain.Event {
+		return &domain.Event{
+			Name:              name,
+			GlobalIndex:       index,
+			LocalIndex:        int(index),
+			ID:                uuid.NewString(),
+			Timestamp:         time.UnixMilli(timestampMillis),
+			OriginalTimestamp: time.UnixMilli(timestampMillis),
+			SessionId:         sessionId,
+			Data:              &generator.SessionMeta{Pod: sessionId},
+		}
+	}
+
+	BeforeEach(func() {
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		queue = NewEventQueue(&atom)
+		sessions = make(map[string][]*domain.Event)
+
+		// Each session trains three times. The trainings of the sessions are interleaved.
+		var index uint64
+		for s, sessionId := range []string{"Session1", "Session2", "Session3"} {
+			queue.EnqueueEvent(newEvent(domain.EventSessionStarted, sessionId, index, 0))
+			index += 1
+
+			for training := 0; training < 3; training++ {
+				startedAt := int64(training*30 + s*10 + 1)
+				for _, evt := range []*domain.Event{
+					newEvent(domain.EventSessionTrainingStarted, sessionId, index, startedAt),
+					newEvent(domain.EventSessionTrainingEnded, sessionId, index+1, startedAt+5),
+				} {
+					queue.EnqueueEvent(evt)
+					sessions[sessionId] = append(sessions[sessionId], evt)
+				}
+				index += 2
+			}
+		}
+
+		Expect(queue.Len()).To(Equal(18))
+		expectHeapInvariant(queue)
+	})
+
+	It("Will maintain the heap invariant after removing events", func() {
+		// Remove the next event of one session and an event from the middle of another.
+		removed := []*domain.Event{sessions["Session1"][0], sessions["Session2"][3]}
+		for _, evt := range removed {
+			Expect(queue.RemoveEvent(evt.SessionId, evt.ID)).To(Equal(evt))
+			expectHeapInvariant(queue)
+		}
+
+		Expect(queue.Len()).To(Equal(16))
+
+		events := popAll(queue)
+		Expect(events).To(HaveLen(16))
+		Expect(events).ToNot(ContainElements(removed))
+
+		for i := 1; i < len(events); i++ {
+			Expect(events[i].Timestamp.Before(events[i-1].Timestamp)).To(BeFalse())
+		}
+	})
+
+	It("Will maintain the heap invariant after postponing events", func() {
+		// Postpone the next event of the session whose events come first to after all the other events.
+		postponed := sessions["Session1"][0]
+		Expect(queue.PostponeEvent(postponed.SessionId, postponed.ID, time.Millisecond*100)).To(Equal(postponed))
+		Expect(postponed.Timestamp).To(Equal(time.UnixMilli(101)))
+		expectHeapInvariant(queue)
+
+		// Postpone an event from the middle of another session.
+		Expect(queue.PostponeEvent("Session3", sessions["Session3"][2].ID, time.Millisecond*3)).ToNot(BeNil())
+		expectHeapInvariant(queue)
+
+		events := popAll(queue)
+		Expect(events).To(HaveLen(18))
+		Expect(events[len(events)-1]).To(Equal(postponed))
+
+		for i := 1; i < len(events); i++ {
+			Expect(events[i].Timestamp.Before(events[i-1].Timestamp)).To(BeFalse())
+		}
+	})
+
+	It("Will return an error when manipulating events that are no longer enqueued", func() {
+		popped := queue.Pop(time.UnixMilli(1))
+		Expect(popped).To(Equal(sessions["Session1"][0]))
+
+		_, err := queue.RemoveEvent(popped.SessionId, popped.ID)
+		Expect(errors.Is(err, ErrEventNotFound)).To(BeTrue())
+
+		_, err = queue.PostponeEvent(popped.SessionId, popped.ID, time.Second)
+		Expect(errors.Is(err, ErrEventNotFound)).To(BeTrue())
+
+		removed := sessions["Session2"][0]
+		Expect(queue.RemoveEvent(removed.SessionId, removed.ID)).To(Equal(removed))
+
+		_, err = queue.RemoveEvent(removed.SessionId, removed.ID)
+		Expect(errors.Is(err, ErrEventNotFound)).To(BeTrue())
+
+		_, err = queue.RemoveEvent("UnknownSession", removed.ID)
+		Expect(errors.Is(err, ErrUnregisteredSession)).To(BeTrue())
+
+		_, err = queue.PostponeEvent("Session3", sessions["Session3"][0].ID, 0)
+		Expect(errors.Is(err, ErrInvalidPostponement)).To(BeTrue())
+
+		Expect(queue.Len()).To(Equal(16))
+		expectHeapInvariant(queue)
+	})
+
+	It("Will return injected events in timestamp order", func() {
+		// Injected events are enqueued after the events of the trace, but with timestamps between them. The global
+		// indices of injected events are greater than those of the events of the trace.
+		injected := []*domain.Event{
+			newEvent(domain.EventSessionTrainingStarted, "Session2", 1<<62, 50),
+			newEvent(domain.EventSessionTrainingEnded, "Session2", 1<<62+1, 52),
+			newEvent(domain.EventSessionTrainingStarted, "Session3", 1<<62+2, 3),
+			newEvent(domain.EventSessionStopped, "Session1", 1<<62+3, 95),
+		}
+		for _, evt := range injected {
+			queue.EnqueueEvent(evt)
+			expectHeapInvariant(queue)
+		}
+
+		events := popAll(queue)
+		Expect(events).To(HaveLen(22))
+		Expect(events).To(ContainElements(injected))
+
+		for i := 1; i < len(events); i++ {
+			Expect(events[i].Timestamp.Before(events[i-1].Timestamp)).To(BeFalse())
+		}
+	})
+
+	It("Will shift the schedule of a session", func() {
+		// Shifting the schedule of a session forward moves all of its events before those of the other sessions.
+		Expect(queue.DelaySession("Session3", -time.Millisecond*21)).To(BeNil())
+		expectHeapInvariant(queue)
+
+		Expect(queue.Pop(time.UnixMilli(0))).To(Equal(sessions["Session3"][0]))
+		expectHeapInvariant(queue)
+	})
+})
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mattn/go-colorable"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultSubscriberBufferSize is the number of undelivered events that may be buffered for each Subscriber
+	// before additional events are dropped.
+	DefaultSubscriberBufferSize = 256
+)
+
+var (
+	ErrSubscriberAlreadyRegistered = errors.New("a subscriber with the specified name is already registered")
+	ErrSubscriberNotFound          = errors.New("no subscriber with the specified name is registered")
+)
+
+// Subscriber receives the events published to a Bus.
+type Subscriber interface {
+	// Name uniquely identifies the Subscriber within the Bus that it is subscribed to.
+	Name() string
+
+	// Deliver delivers the given Event to the Subscriber.
+	//
+	// Deliver is called from a goroutine that is dedicated to the Subscriber, so a slow Subscriber
+	// will not delay the delivery of events to other Subscriber instances.
+	Deliver(event *Event) error
+
+	// Close releases any resources held by the Subscriber.
+	Close() error
+}
+
+// subscription is an internal wrapper around a Subscriber that buffers the events to be delivered to it.
+type subscription struct {
+	subscriber Subscriber
+	queue      chan *Event
+	done       chan struct{}
+}
+
+// Bus delivers published events to a registry of Subscriber instances.
+//
+// Publish never blocks. Each Subscriber is served by its own goroutine and has its own buffer of undelivered
+// events. If a Subscriber falls too far behind, then new events destined for it are dropped.
+type Bus struct {
+	logger *zap.Logger
+
+	subscriptions map[string]*subscription
+	bufferSize    int
+	mu            sync.RWMutex
+}
+
+// NewBus creates a new Bus with no subscribers.
+func NewBus(atom *zap.AtomicLevel) *Bus {
+	bus := &Bus{
+		subscriptions: make(map[string]*subscription),
+		bufferSize:    DefaultSubscriberBufferSize,
+	}
+
+	zapConfig := zap.NewDevelopmentEncoderConfig()
+	zapConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zapConfig), zapcore.AddSync(colorable.NewColorableStdout()), atom)
+	logger := zap.New(core, zap.Development())
+	if logger == nil {
+		panic("failed to create logger for event bus")
+	}
+
+	bus.logger = logger
+
+	return bus
+}
+
+// Subscribe registers the given Subscriber with the Bus.
+func (b *Bus) Subscribe(subscriber Subscriber) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, loaded := b.subscriptions[subscriber.Name()]; loaded {
+		return fmt.Errorf("%w: \"%s\"", ErrSubscriberAlreadyRegistered, subscriber.Name())
+	}
+
+	sub := &subscription{
+		subscriber: subscriber,
+		queue:      make(chan *Event, b.bufferSize),
+		done:       make(chan struct{}),
+	}
+
+	b.subscriptions[subscriber.Name()] = sub
+	go b.serve(sub)
+
+	b.logger.Debug("Registered event subscriber.", zap.String("subscriber", subscriber.Name()))
+	return nil
+}
+
+// Unsubscribe removes the Subscriber with the given name from the Bus, closing it once all the events that
+// were already buffered for it have been delivered.
+func (b *Bus) Unsubscribe(name string) error {
+	b.mu.Lock()
+	sub, loaded := b.subscriptions[name]
+	if !loaded {
+		b.mu.Unlock()
+		return fmt.Errorf("%w: \"%s\"", ErrSubscriberNotFound, name)
+	}
+
+	delete(b.subscriptions, name)
+	b.mu.Unlock()
+
+	close(sub.queue)
+	<-sub.done
+
+	return sub.subscriber.Close()
+}
+
+// Subscribers returns the names of all the Subscriber instances registered with the Bus.
+func (b *Bus) Subscribers() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	names := make([]string, 0, len(b.subscriptions))
+	for name := range b.subscriptions {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Publish enqueues the given Event for delivery to each registered Subscriber.
+func (b *Bus) Publish(event *Event) {
+	if b == nil || event == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for name, sub := range b.subscriptions {
+		select {
+		case sub.queue <- event:
+		default:
+			b.logger.Warn("Event subscriber has fallen behind. Dropping event.",
+				zap.String("subscriber", name),
+				zap.String("event_type", event.Type.String()),
+				zap.String("workload_id", event.WorkloadId))
+		}
+	}
+}
+
+// Close unsubscribes and closes all registered Subscriber instances.
+func (b *Bus) Close() error {
+	errs := make([]error, 0)
+	for _, name := range b.Subscribers() {
+		if err := b.Unsubscribe(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// serve delivers the events buffered for a particular subscription until the subscription's queue is closed.
+func (b *Bus) serve(sub *subscription) {
+	defer close(sub.done)
+
+	for event := range sub.queue {
+		if err := sub.subscriber.Deliver(event); err != nil {
+			b.logger.Error("Failed to deliver event to subscriber.",
+				zap.String("subscriber", sub.subscriber.Name()),
+				zap.String("event_type", event.Type.String()),
+				zap.String("event_id", event.Id),
+				zap.String("workload_id", event.WorkloadId),
+				zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package events_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
+)
+
+type recordingSubscriber struct {
+	name   string
+	events []*events.Event
+	mu     sync.Mutex
+}
+
+func (s *recordingSubscriber) Name() string { return s.name }
+
+func (s *recordingSubscriber) Deliver(event *events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSubscriber) Close() error { return nil }
+
+func (s *recordingSubscriber) Received() []*events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*events.Event{}, s.events...)
+}
+
+var _ = Describe("Event Bus Tests", func() {
+	var atom zap.AtomicLevel
+
+	BeforeEach(func() {
+		atom = zap.NewAtomicLevelAt(zap.InfoLevel)
+	})
+
+	It("Will deliver published events to every subscriber in order", func() {
+		bus := events.NewBus(&atom)
+
+		first := &recordingSubscriber{name: "first"}
+		second := &recordingSubscriber{name: "second"}
+		Expect(bus.Subscribe(first)).To(BeNil())
+		Expect(bus.Subscribe(second)).To(BeNil())
+		Expect(bus.Subscribe(&recordingSubscriber{name: "first"})).To(MatchError(events.ErrSubscriberAlreadyRegistered))
+
+		bus.Publish(events.NewEvent(events.WorkloadStarted, "id", "name"))
+		bus.Publish(events.NewEvent(events.WorkloadCompleted, "id", "name"))
+
+		// Unsubscribing waits for buffered events to be delivered.
+		Expect(bus.Close()).To(BeNil())
+
+		for _, subscriber := range []*recordingSubscriber{first, second} {
+			received := subscriber.Received()
+			Expect(received).To(HaveLen(2))
+			Expect(received[0].Type).To(Equal(events.WorkloadStarted))
+			Expect(received[1].Type).To(Equal(events.WorkloadCompleted))
+		}
+
+		Expect(bus.Unsubscribe("first")).To(MatchError(events.ErrSubscriberNotFound))
+	})
+
+	It("Will sign webhook requests and retry failed deliveries", func() {
+		var attempts atomic.Int32
+		var signature, eventType string
+		var body []byte
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			signature = r.Header.Get(events.SignatureHeader)
+			eventType = r.Header.Get(events.EventTypeHeader)
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		subscriber := events.NewWebhookSubscriber("webhook", server.URL, "secret", 2)
+		subscriber.SetBackoff(time.Millisecond, time.Millisecond*5)
+
+		Expect(subscriber.Deliver(events.NewEvent(events.WorkloadCompleted, "id", "name"))).To(BeNil())
+		Expect(attempts.Load()).To(Equal(int32(2)))
+		Expect(eventType).To(Equal(events.WorkloadCompleted.String()))
+		Expect(signature).To(Equal("sha256=" + events.Sign([]byte("secret"), body)))
+	})
+
+	It("Will not retry webhook deliveries rejected with a client error", func() {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		subscriber := events.NewWebhookSubscriber("webhook", server.URL, "", 3)
+		subscriber.SetBackoff(time.Millisecond, time.Millisecond*5)
+
+		Expect(subscriber.Deliver(events.NewEvent(events.WorkloadCompleted, "id", "name"))).ToNot(BeNil())
+		Expect(attempts.Load()).To(Equal(int32(1)))
+	})
+
+	It("Will append events to a file as line-delimited JSON", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "events", "events.jsonl")
+		sink, err := events.NewFileSink(events.FileSinkName, path)
+		Expect(err).To(BeNil())
+
+		Expect(sink.Deliver(events.NewEvent(events.WorkloadRegistered, "id", "name"))).To(BeNil())
+		Expect(sink.Deliver(events.NewEvent(events.WorkloadErred, "id", "name"))).To(BeNil())
+		Expect(sink.Close()).To(BeNil())
+
+		file, err := os.Open(path)
+		Expect(err).To(BeNil())
+		defer func() { _ = file.Close() }()
+
+		types := make([]events.EventType, 0)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event events.Event
+			Expect(json.Unmarshal(scanner.Bytes(), &event)).To(BeNil())
+			types = append(types, event.Type)
+		}
+
+		Expect(types).To(Equal([]events.EventType{events.WorkloadRegistered, events.WorkloadErred}))
+	})
+})
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	FileSinkName      = "file-sink"
+	webhookNamePrefix = "webhook"
+)
+
+// NewBusFromConfig creates a new Bus and subscribes the webhooks and file sink specified in the given
+// domain.Configuration to it.
+//
+// NewBusFromConfig always returns a usable Bus. If one or more of the configured subscribers could not be
+// created, then the returned error describes why, and the remaining subscribers are still registered.
+func NewBusFromConfig(opts *domain.Configuration, atom *zap.AtomicLevel) (*Bus, error) {
+	bus := NewBus(atom)
+	if opts == nil {
+		return bus, nil
+	}
+
+	errs := make([]error, 0)
+	for i, url := range strings.Split(opts.EventWebhookUrls, ",") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+
+		subscriber := NewWebhookSubscriber(fmt.Sprintf("%s-%d", webhookNamePrefix, i), url, opts.EventWebhookSecret,
+			opts.EventWebhookMaxRetries)
+		if err := bus.Subscribe(subscriber); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if opts.EventLogFile != "" {
+		sink, err := NewFileSink(FileSinkName, opts.EventLogFile)
+		if err != nil {
+			errs = append(errs, err)
+		} else if err = bus.Subscribe(sink); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return bus, errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WorkloadRegistered    EventType = "workload.registered"     // A workload was registered and is ready to be started.
+	WorkloadStarted       EventType = "workload.started"        // A workload began running.
+	WorkloadPausing       EventType = "workload.pausing"        // A workload is finishing its current tick before pausing.
+	WorkloadPaused        EventType = "workload.paused"         // A workload has paused.
+	WorkloadResumed       EventType = "workload.resumed"        // A paused (or pausing) workload resumed running.
+	WorkloadCompleted     EventType = "workload.completed"      // A workload finished successfully after processing all of its events.
+	WorkloadErred         EventType = "workload.erred"          // A workload stopped due to an error.
+	WorkloadTerminated    EventType = "workload.terminated"     // A workload was explicitly terminated early.
+	WorkloadStateChanged  EventType = "workload.state_changed"  // A workload transitioned between two states not covered by a more specific EventType.
+	WorkloadCriticalError EventType = "workload.critical_error" // A critical error occurred during the execution of a workload.
+
+	KernelConnectionStateChanged EventType = "kernel.connection_state_changed" // The connection to one of a workload's kernels changed state.
+)
+
+// EventType identifies the kind of workload lifecycle Event.
+type EventType string
+
+func (t EventType) String() string {
+	return string(t)
+}
+
+// Event is a typed notification of a change to the lifecycle of a workload.
+type Event struct {
+	Id            string    `json:"id"`
+	Type          EventType `json:"type"`
+	WorkloadId    string    `json:"workload_id"`
+	WorkloadName  string    `json:"workload_name,omitempty"`
+	KernelId      string    `json:"kernel_id,omitempty"`
+	PreviousState string    `json:"previous_state,omitempty"`
+	State         string    `json:"state,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// NewEvent creates a new Event of the specified EventType for the specified workload.
+func NewEvent(eventType EventType, workloadId string, workloadName string) *Event {
+	return &Event{
+		Id:           uuid.NewString(),
+		Type:         eventType,
+		WorkloadId:   workloadId,
+		WorkloadName: workloadName,
+		Timestamp:    time.Now(),
+	}
+}
+
+// WithStates sets the previous and current state of the workload associated with the Event.
+func (e *Event) WithStates(previousState string, state string) *Event {
+	e.PreviousState = previousState
+	e.State = state
+	return e
+}
+
+// WithKernel sets the ID of the kernel associated with the Event.
+func (e *Event) WithKernel(kernelId string) *Event {
+	e.KernelId = kernelId
+	return e
+}
+
+// WithError sets the error associated with the Event.
+func (e *Event) WithError(err error) *Event {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
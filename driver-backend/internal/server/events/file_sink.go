@@ -0,0 +1,60 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink is a Subscriber that appends each event, encoded as a single line of JSON, to a file.
+type FileSink struct {
+	name string
+	file *os.File
+	mu   sync.Mutex
+}
+
+// NewFileSink creates a new FileSink that appends events to the file at the given path,
+// creating the file (and any parent directories) if necessary.
+func NewFileSink(name string, path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{
+		name: name,
+		file: file,
+	}, nil
+}
+
+// Name uniquely identifies the FileSink.
+func (s *FileSink) Name() string {
+	return s.name
+}
+
+// Deliver appends the given Event to the file as a single line of JSON.
+func (s *FileSink) Deliver(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.file.Write(append(payload, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
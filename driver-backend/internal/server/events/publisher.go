@@ -0,0 +1,63 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Publisher is implemented by clients of message brokers, such as NATS or Kafka, so that workload lifecycle
+// events can be published to the broker by a PublisherSubscriber.
+type Publisher interface {
+	// Publish publishes the given payload to the specified topic (or subject).
+	Publish(topic string, payload []byte) error
+
+	// Close closes the connection to the message broker.
+	Close() error
+}
+
+// PublisherSubscriber is a Subscriber that publishes events to a message broker via a Publisher.
+//
+// Each Event is published to the topic "<prefix>.<event type>", such as "workloads.workload.completed".
+type PublisherSubscriber struct {
+	name        string
+	topicPrefix string
+	publisher   Publisher
+}
+
+// NewPublisherSubscriber creates a new PublisherSubscriber that publishes events using the given Publisher.
+func NewPublisherSubscriber(name string, topicPrefix string, publisher Publisher) *PublisherSubscriber {
+	return &PublisherSubscriber{
+		name:        name,
+		topicPrefix: topicPrefix,
+		publisher:   publisher,
+	}
+}
+
+// Name uniquely identifies the PublisherSubscriber.
+func (s *PublisherSubscriber) Name() string {
+	return s.name
+}
+
+// Topic returns the topic to which the given Event is published.
+func (s *PublisherSubscriber) Topic(event *Event) string {
+	if s.topicPrefix == "" {
+		return event.Type.String()
+	}
+
+	return fmt.Sprintf("%s.%s", s.topicPrefix, event.Type)
+}
+
+// Deliver publishes the given Event, encoded as JSON, using the Publisher.
+func (s *PublisherSubscriber) Deliver(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return s.publisher.Publish(s.Topic(event), payload)
+}
+
+// Close closes the underlying Publisher.
+func (s *PublisherSubscriber) Close() error {
+	return s.publisher.Close()
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// SignatureHeader is the HTTP header containing the hex-encoded HMAC-SHA256 signature of the request body,
+	// prefixed with "sha256=". The header is only included if a secret is configured.
+	SignatureHeader = "X-Workload-Driver-Signature"
+
+	// EventTypeHeader is the HTTP header containing the EventType of the delivered Event.
+	EventTypeHeader = "X-Workload-Driver-Event"
+
+	// EventIdHeader is the HTTP header containing the ID of the delivered Event.
+	EventIdHeader = "X-Workload-Driver-Event-Id"
+
+	DefaultWebhookTimeout        = time.Second * 5
+	DefaultWebhookMaxRetries     = 3
+	DefaultWebhookInitialBackoff = time.Millisecond * 500
+	DefaultWebhookMaxBackoff     = time.Second * 30
+)
+
+// WebhookSubscriber is a Subscriber that posts events, encoded as JSON, to an HTTP endpoint.
+//
+// If a secret is configured, then each request is signed using HMAC-SHA256, and the signature is included
+// in the SignatureHeader header. Failed deliveries are retried with exponential backoff.
+type WebhookSubscriber struct {
+	name           string
+	url            string
+	secret         []byte
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	client         *http.Client
+}
+
+// NewWebhookSubscriber creates a new WebhookSubscriber that posts events to the given URL.
+//
+// If secret is empty, then requests are not signed. If maxRetries is negative, then DefaultWebhookMaxRetries is used.
+func NewWebhookSubscriber(name string, url string, secret string, maxRetries int) *WebhookSubscriber {
+	if maxRetries < 0 {
+		maxRetries = DefaultWebhookMaxRetries
+	}
+
+	return &WebhookSubscriber{
+		name:           name,
+		url:            url,
+		secret:         []byte(secret),
+		maxRetries:     maxRetries,
+		initialBackoff: DefaultWebhookInitialBackoff,
+		maxBackoff:     DefaultWebhookMaxBackoff,
+		client:         &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+}
+
+// Name uniquely identifies the WebhookSubscriber.
+func (s *WebhookSubscriber) Name() string {
+	return s.name
+}
+
+// SetBackoff configures the initial and maximum delays between successive delivery attempts.
+func (s *WebhookSubscriber) SetBackoff(initialBackoff time.Duration, maxBackoff time.Duration) {
+	s.initialBackoff = initialBackoff
+	s.maxBackoff = maxBackoff
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of the given payload computed using the given secret.
+func Sign(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts the given Event to the webhook, retrying with exponential backoff if the delivery fails.
+func (s *WebhookSubscriber) Deliver(event *Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.initialBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := s.post(event, payload)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable || attempt >= s.maxRetries {
+			return fmt.Errorf("failed to deliver event to webhook \"%s\" after %d attempt(s): %w", s.url, attempt+1, err)
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > s.maxBackoff {
+			backoff = s.maxBackoff
+		}
+	}
+}
+
+// post performs a single delivery attempt. post returns an error if the attempt failed, along with a flag
+// indicating whether the failed attempt should be retried.
+func (s *WebhookSubscriber) post(event *Event, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, event.Type.String())
+	req.Header.Set(EventIdHeader, event.Id)
+
+	if len(s.secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(s.secret, payload))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	// Server-side errors and rate-limiting are retried. Other client-side errors are not.
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("webhook responded with status %s", resp.Status)
+}
+
+// Close is a no-op for the WebhookSubscriber.
+func (s *WebhookSubscriber) Close() error {
+	return nil
+}
@@ -45,6 +45,7 @@ var (
 	ErrFailedToConnect           = errors.New("a connection to the Gateway could not be established within the configured timeout")
 	ErrProvisionerNotInitialized = errors.New("provisioner is not initialized")
 	ErrConcurrentSetupOperations = errors.New("there is already 'setup RPC resources' operation taking place")
+	ErrNotConnectedToGateway     = errors.New("not currently connected to the Cluster Gateway")
 
 	sig = make(chan os.Signal, 1)
 )
@@ -444,6 +445,26 @@ func (h *ClusterDashboardHandler) ConnectedToGateway() bool {
 	return h.connected.Load() > 0
 }
 
+// ListKernelIds returns the IDs of the kernels that are running on the cluster, as reported by the Cluster Gateway.
+func (h *ClusterDashboardHandler) ListKernelIds() ([]string, error) {
+	if !h.ConnectedToGateway() {
+		return nil, ErrNotConnectedToGateway
+	}
+
+	resp, err := h.ListKernels(context.Background(), &gateway.Void{})
+	if err != nil {
+		h.HandleConnectionError()
+		return nil, err
+	}
+
+	kernelIds := make([]string, 0, len(resp.Kernels))
+	for _, kernel := range resp.Kernels {
+		kernelIds = append(kernelIds, kernel.KernelId)
+	}
+
+	return kernelIds, nil
+}
+
 // connectionProvisioner is used to establish a 2-way (bidirectional) gRPC connection between
 // the Cluster Dashboard backend server and the Cluster Gateway component.
 type connectionProvisioner struct {
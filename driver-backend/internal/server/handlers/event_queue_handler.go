@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/event_queue"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/workload"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultNumQueuedEvents is the number of upcoming events returned if the request does not specify a number.
+	DefaultNumQueuedEvents = 50
+)
+
+// EventQueueResponse is returned when inspecting the event queue of a workload.
+type EventQueueResponse struct {
+	WorkloadId     string                             `json:"workload_id"`
+	UpcomingEvents []*event_queue.QueuedEvent         `json:"upcoming_events"`
+	Sessions       []*event_queue.SessionQueueSummary `json:"sessions"`
+}
+
+// EventQueueHttpHandler is used to inspect and manipulate the event queues of running workloads.
+//
+// Every modification of an event queue is recorded in the processed events of the associated workload.
+type EventQueueHttpHandler struct {
+	*BaseHandler
+
+	workloadManager *workload.BasicWorkloadManager
+}
+
+func NewEventQueueHttpHandler(opts *domain.Configuration, workloadManager *workload.BasicWorkloadManager, atom *zap.AtomicLevel) *EventQueueHttpHandler {
+	if workloadManager == nil {
+		panic("Workload manager cannot be nil.")
+	}
+
+	handler := &EventQueueHttpHandler{
+		BaseHandler:     newBaseHandler(opts, atom),
+		workloadManager: workloadManager,
+	}
+	handler.BackendHttpGetHandler = handler
+
+	handler.logger.Info("Creating server-side EventQueueHttpHandler.")
+
+	return handler
+}
+
+// HandleRequest returns the next events enqueued for the workload specified by the "workload_id" query parameter.
+//
+// If the "session_id" query parameter is specified, then only events targeting that session are returned.
+// The "n" query parameter bounds the number of returned events.
+func (h *EventQueueHttpHandler) HandleRequest(c *gin.Context) {
+	driver, ok := h.getWorkloadDriver(c, c.Query("workload_id"))
+	if !ok {
+		return
+	}
+
+	n := DefaultNumQueuedEvents
+	if numEvents := c.Query("n"); numEvents != "" {
+		var err error
+		if n, err = strconv.Atoi(numEvents); err != nil {
+			h.logger.Error("Invalid \"n\" query parameter.", zap.String("n", numEvents), zap.Error(err))
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	upcomingEvents, err := driver.EventQueue().UpcomingEvents(c.Query("session_id"), n)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &EventQueueResponse{
+		WorkloadId:     c.Query("workload_id"),
+		UpcomingEvents: upcomingEvents,
+		Sessions:       driver.EventQueue().SessionQueueSummaries(),
+	})
+}
+
+// HandleInjectRequest injects an ad-hoc event, such as an extra training, into the event queue of a workload.
+func (h *EventQueueHttpHandler) HandleInjectRequest(c *gin.Context) {
+	var req *domain.InjectEventRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.logger.Error("Failed to unmarshal InjectEventRequest.", zap.Error(err))
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	driver, ok := h.getWorkloadDriver(c, req.WorkloadId)
+	if !ok {
+		return
+	}
+
+	injectedEvents, err := driver.InjectEvent(req)
+	if err != nil {
+		h.logger.Error("Failed to inject event.", zap.String("workload_id", req.WorkloadId),
+			zap.String("session_id", req.SessionId), zap.String("event_name", req.EventName), zap.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, injectedEvents)
+}
+
+// HandleDeleteRequest drops the event specified by the "workload_id", "session_id", and "event_id" query parameters.
+func (h *EventQueueHttpHandler) HandleDeleteRequest(c *gin.Context) {
+	driver, ok := h.getWorkloadDriver(c, c.Query("workload_id"))
+	if !ok {
+		return
+	}
+
+	sessionId, eventId := c.Query("session_id"), c.Query("event_id")
+	if sessionId == "" || eventId == "" {
+		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("request must specify \"session_id\" and \"event_id\" query parameters"))
+		return
+	}
+
+	droppedEvent, err := driver.DropEvent(sessionId, eventId)
+	if err != nil {
+		h.logger.Error("Failed to drop event.", zap.String("workload_id", c.Query("workload_id")),
+			zap.String("session_id", sessionId), zap.String("event_id", eventId), zap.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, droppedEvent)
+}
+
+// HandlePatchRequest postpones an enqueued event of a workload.
+func (h *EventQueueHttpHandler) HandlePatchRequest(c *gin.Context) {
+	var req *domain.PostponeEventRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.logger.Error("Failed to unmarshal PostponeEventRequest.", zap.Error(err))
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	driver, ok := h.getWorkloadDriver(c, req.WorkloadId)
+	if !ok {
+		return
+	}
+
+	postponedEvent, err := driver.PostponeEvent(req.SessionId, req.EventId, time.Duration(req.AmountMillis)*time.Millisecond)
+	if err != nil {
+		h.logger.Error("Failed to postpone event.", zap.String("workload_id", req.WorkloadId),
+			zap.String("session_id", req.SessionId), zap.String("event_id", req.EventId), zap.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, postponedEvent)
+}
+
+// HandleShiftSessionRequest moves the entire schedule of a session of a workload forward or back.
+func (h *EventQueueHttpHandler) HandleShiftSessionRequest(c *gin.Context) {
+	var req *domain.ShiftSessionRequest
+	if err := c.BindJSON(&req); err != nil {
+		h.logger.Error("Failed to unmarshal ShiftSessionRequest.", zap.Error(err))
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	driver, ok := h.getWorkloadDriver(c, req.WorkloadId)
+	if !ok {
+		return
+	}
+
+	err := driver.ShiftSessionSchedule(req.SessionId, time.Duration(req.AmountMillis)*time.Millisecond)
+	if err != nil {
+		h.logger.Error("Failed to shift session schedule.", zap.String("workload_id", req.WorkloadId),
+			zap.String("session_id", req.SessionId), zap.Int64("amount_ms", req.AmountMillis), zap.Error(err))
+		h.abortWithError(c, err)
+		return
+	}
+
+	upcomingEvents, err := driver.EventQueue().UpcomingEvents(req.SessionId, DefaultNumQueuedEvents)
+	if err != nil {
+		h.abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, upcomingEvents)
+}
+
+// getWorkloadDriver returns the driver of the specified workload. If the driver cannot be found, then
+// getWorkloadDriver aborts the request and returns false.
+func (h *EventQueueHttpHandler) getWorkloadDriver(c *gin.Context, workloadId string) (*workload.BasicWorkloadDriver, bool) {
+	if workloadId == "" {
+		h.logger.Error("Event queue request did not specify a workload.")
+		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("request must specify a workload ID"))
+		return nil, false
+	}
+
+	driver := h.workloadManager.GetWorkloadDriver(workloadId)
+	if driver == nil {
+		h.logger.Error("Unknown workload specified.", zap.String("workload_id", workloadId))
+		_ = c.AbortWithError(http.StatusNotFound, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId))
+		return nil, false
+	}
+
+	return driver, true
+}
+
+// abortWithError aborts the request with a status code that reflects the given error.
+func (h *EventQueueHttpHandler) abortWithError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, event_queue.ErrEventNotFound), errors.Is(err, event_queue.ErrUnregisteredSession),
+		errors.Is(err, domain.ErrUnknownSession):
+		_ = c.AbortWithError(http.StatusNotFound, err)
+	case errors.Is(err, event_queue.ErrInvalidPostponement), errors.Is(err, workload.ErrUnsupportedInjectedEvent),
+		errors.Is(err, workload.ErrInvalidInjectionTime), errors.Is(err, workload.ErrInvalidTrainingDuration):
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+	case errors.Is(err, domain.ErrWorkloadNotRunning):
+		_ = c.AbortWithError(http.StatusConflict, err)
+	default:
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+	}
+}
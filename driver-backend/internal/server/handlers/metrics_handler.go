@@ -3,7 +3,6 @@ package handlers
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
 	"go.uber.org/zap"
@@ -47,21 +46,21 @@ func (h *MetricsHttpHandler) HandlePatchRequest(c *gin.Context) {
 	case "distributed_cluster_jupyter_session_creation_latency_seconds":
 		{
 			metrics.PrometheusMetricsWrapperInstance.JupyterSessionCreationLatencyMilliseconds.
-				With(prometheus.Labels{"workload_id": "no_workload"}).
+				With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(metrics.NoWorkloadLabelValue)).
 				Observe(req.Value)
 			break
 		}
 	case "distributed_cluster_jupyter_execute_request_e2e_latency_seconds":
 		{
 			metrics.PrometheusMetricsWrapperInstance.JupyterExecuteRequestEndToEndLatencyMilliseconds.
-				With(prometheus.Labels{"workload_id": "no_workload"}).
+				With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(metrics.NoWorkloadLabelValue)).
 				Observe(req.Value)
 			break
 		}
 	case "distributed_cluster_jupyter_session_termination_latency_seconds":
 		{
 			metrics.PrometheusMetricsWrapperInstance.JupyterSessionTerminationLatencyMilliseconds.
-				With(prometheus.Labels{"workload_id": "no_workload"}).
+				With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(metrics.NoWorkloadLabelValue)).
 				Observe(req.Value)
 			break
 		}
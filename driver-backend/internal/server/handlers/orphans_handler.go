@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/reconciler"
+	"go.uber.org/zap"
+)
+
+// OrphansHttpHandler is used to inspect and reconcile the Jupyter sessions and kernels that outlived their workload.
+type OrphansHttpHandler struct {
+	*BaseHandler
+
+	reconciler *reconciler.Reconciler
+}
+
+func NewOrphansHttpHandler(opts *domain.Configuration, reconciler *reconciler.Reconciler, atom *zap.AtomicLevel) *OrphansHttpHandler {
+	if reconciler == nil {
+		panic("Reconciler cannot be nil.")
+	}
+
+	handler := &OrphansHttpHandler{
+		BaseHandler: newBaseHandler(opts, atom),
+		reconciler:  reconciler,
+	}
+	handler.BackendHttpGetHandler = handler
+
+	handler.logger.Info("Creating server-side OrphansHttpHandler.")
+
+	return handler
+}
+
+// HandleRequest returns the report of the most recent reconciliation. If no reconciliation has been performed yet,
+// then a dry-run reconciliation is performed first.
+func (h *OrphansHttpHandler) HandleRequest(c *gin.Context) {
+	report := h.reconciler.LastReport()
+	if report == nil {
+		report = h.reconciler.Reconcile(true)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// HandleReconcileRequest performs a reconciliation on demand and returns its report.
+//
+// The "dry_run" query parameter overrides whether the orphans are only reported or also stopped.
+func (h *OrphansHttpHandler) HandleReconcileRequest(c *gin.Context) {
+	dryRun := h.reconciler.DryRun()
+	if dryRunParam := c.Query("dry_run"); dryRunParam != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(dryRunParam); err != nil {
+			h.logger.Error("Invalid \"dry_run\" query parameter.", zap.String("dry_run", dryRunParam), zap.Error(err))
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	h.logger.Debug("Reconciling orphaned sessions and kernels on demand.", zap.Bool("dry_run", dryRun))
+
+	c.JSON(http.StatusOK, h.reconciler.Reconcile(dryRun))
+}
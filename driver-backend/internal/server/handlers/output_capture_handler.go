@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/output_capture"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/workload"
+	"go.uber.org/zap"
+)
+
+// OutputCaptureResponse is returned when querying the captured outputs of a workload.
+type OutputCaptureResponse struct {
+	WorkloadId string                   `json:"workload_id"`
+	Records    []*output_capture.Record `json:"records"`
+}
+
+// OutputCaptureHttpHandler is used to query the captured outputs of the trainings of a workload, such as to
+// debug failed trainings after the fact.
+type OutputCaptureHttpHandler struct {
+	*BaseHandler
+
+	workloadManager *workload.BasicWorkloadManager
+}
+
+func NewOutputCaptureHttpHandler(opts *domain.Configuration, workloadManager *workload.BasicWorkloadManager, atom *zap.AtomicLevel) *OutputCaptureHttpHandler {
+	if workloadManager == nil {
+		panic("Workload manager cannot be nil.")
+	}
+
+	handler := &OutputCaptureHttpHandler{
+		BaseHandler:     newBaseHandler(opts, atom),
+		workloadManager: workloadManager,
+	}
+	handler.BackendHttpGetHandler = handler
+
+	handler.logger.Info("Creating server-side OutputCaptureHttpHandler.")
+
+	return handler
+}
+
+// HandleRequest returns the captured outputs of the workload specified by the "workload_id" query parameter.
+//
+// The outputs can be narrowed down using the "session_id", "training_index", "msg_id", and "output_type" query
+// parameters. Outputs that were spilled to disk are only returned if the "include_spilled" query parameter is true.
+func (h *OutputCaptureHttpHandler) HandleRequest(c *gin.Context) {
+	workloadId := c.Query("workload_id")
+	if workloadId == "" {
+		h.logger.Error("Output capture request did not specify a workload.")
+		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("request must specify a workload ID"))
+		return
+	}
+
+	driver := h.workloadManager.GetWorkloadDriver(workloadId)
+	if driver == nil {
+		h.logger.Error("Unknown workload specified.", zap.String("workload_id", workloadId))
+		_ = c.AbortWithError(http.StatusNotFound, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId))
+		return
+	}
+
+	query := &output_capture.Query{
+		SessionId:     c.Query("session_id"),
+		TrainingIndex: output_capture.AnyTrainingIndex,
+		MsgId:         c.Query("msg_id"),
+		OutputType:    c.Query("output_type"),
+	}
+
+	if trainingIndex := c.Query("training_index"); trainingIndex != "" {
+		var err error
+		if query.TrainingIndex, err = strconv.Atoi(trainingIndex); err != nil {
+			h.logger.Error("Invalid \"training_index\" query parameter.", zap.String("training_index", trainingIndex), zap.Error(err))
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if includeSpilled := c.Query("include_spilled"); includeSpilled != "" {
+		var err error
+		if query.IncludeSpilled, err = strconv.ParseBool(includeSpilled); err != nil {
+			h.logger.Error("Invalid \"include_spilled\" query parameter.", zap.String("include_spilled", includeSpilled), zap.Error(err))
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	records, err := driver.OutputCapture().Query(query)
+	if err != nil {
+		h.logger.Error("Failed to query captured outputs.", zap.String("workload_id", workloadId), zap.Error(err))
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &OutputCaptureResponse{
+		WorkloadId: workloadId,
+		Records:    records,
+	})
+}
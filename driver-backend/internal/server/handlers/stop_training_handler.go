@@ -3,10 +3,10 @@ package handlers
 import (
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
 	"net/http"
-	"path"
 
 	"github.com/gin-gonic/gin"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/workload"
 	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
 	"github.com/zhangjyr/hashmap"
 	"go.uber.org/zap"
@@ -20,19 +20,26 @@ type StopTrainingHandler struct {
 	kernelConnections *hashmap.HashMap
 }
 
-func NewStopTrainingHandler(opts *domain.Configuration, atom *zap.AtomicLevel) *StopTrainingHandler {
-	jupyterAddress := path.Join(opts.InternalJupyterServerAddress, opts.JupyterServerBasePath)
-
+// NewStopTrainingHandler creates a new StopTrainingHandler. An error is returned if the Jupyter Server client
+// cannot be configured with the authentication and TLS settings of the given domain.Configuration.
+func NewStopTrainingHandler(opts *domain.Configuration, atom *zap.AtomicLevel) (*StopTrainingHandler, error) {
 	handler := &StopTrainingHandler{
 		BaseHandler:       newBaseHandler(opts, atom),
-		manager:           jupyter.NewKernelSessionManager(jupyterAddress, true, atom, metrics.PrometheusMetricsWrapperInstance),
 		kernelConnections: hashmap.New(8),
 	}
 	handler.BackendHttpGetHandler = handler
 
+	manager, err := workload.NewKernelSessionManager(opts, atom, metrics.PrometheusMetricsWrapperInstance)
+	if err != nil {
+		handler.logger.Error("Error encountered while configuring Jupyter Server client.",
+			zap.String("jupyter_server_address", opts.InternalJupyterServerAddress), zap.Error(err))
+		return nil, err
+	}
+	handler.manager = manager
+
 	handler.logger.Info("Creating server-side StopTrainingHandler.")
 
-	return handler
+	return handler, nil
 }
 
 func (h *StopTrainingHandler) HandleRequest(c *gin.Context) {
@@ -0,0 +1,350 @@
+package kernel_console
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const (
+	// OpSetFilter replaces the Filter of a Console.
+	OpSetFilter = "set_filter"
+	// OpExecute sends a one-off "execute_request" to one of the kernels attached to a Console.
+	OpExecute = "execute"
+
+	// MessageFrame frames carry a message exchanged with a kernel.
+	MessageFrame = "message"
+	// AttachedFrame is the first frame sent by a Console. It lists the kernels that the Console is attached to.
+	AttachedFrame = "attached"
+	// ErrorFrame frames report a failed command.
+	ErrorFrame = "error"
+
+	// frameBufferSize is the number of frames that may be queued for a slow client before frames are dropped.
+	frameBufferSize = 1024
+
+	// maxTrackedRequests bounds the number of sent requests whose send times are remembered to compute latencies.
+	maxTrackedRequests = 4096
+)
+
+var (
+	ErrUnknownOp           = errors.New("unknown kernel console operation")
+	ErrExecuteNotAllowed   = errors.New("sending \"execute_request\" messages from the kernel console is disabled")
+	ErrKernelNotAttached   = errors.New("the kernel console is not attached to the specified session")
+	ErrNoKernelsToAttachTo = errors.New("there are no kernels to attach the kernel console to")
+)
+
+// Filter selects the messages streamed by a Console. Empty fields match every message.
+type Filter struct {
+	MessageTypes []string `json:"message_types"`
+	Channels     []string `json:"channels"`
+}
+
+func (f *Filter) matches(channel string, messageType string) bool {
+	if f == nil {
+		return true
+	}
+
+	return (len(f.MessageTypes) == 0 || slices.Contains(f.MessageTypes, messageType)) &&
+		(len(f.Channels) == 0 || slices.Contains(f.Channels, channel))
+}
+
+// Command is sent by the client of a Console.
+type Command struct {
+	Op string `json:"op"`
+
+	// Filter is the new Filter of an OpSetFilter command.
+	Filter *Filter `json:"filter,omitempty"`
+
+	// SessionId and Code specify the kernel and code of an OpExecute command.
+	SessionId string `json:"session_id,omitempty"`
+	Code      string `json:"code,omitempty"`
+}
+
+// Frame is sent by a Console to its client.
+type Frame struct {
+	Type string `json:"type"`
+
+	SessionId    string                       `json:"session_id,omitempty"`
+	KernelId     string                       `json:"kernel_id,omitempty"`
+	Direction    string                       `json:"direction,omitempty"`
+	Channel      string                       `json:"channel,omitempty"`
+	MessageType  string                       `json:"msg_type,omitempty"`
+	Header       *jupyter.KernelMessageHeader `json:"header,omitempty"`
+	ParentHeader *jupyter.KernelMessageHeader `json:"parent_header,omitempty"`
+	Metadata     map[string]interface{}       `json:"metadata,omitempty"`
+	Content      interface{}                  `json:"content,omitempty"`
+	Timestamp    time.Time                    `json:"timestamp"`
+
+	// LatencyMillis is the time elapsed since the request that this message is a response to was sent, if the
+	// request was sent while the Console was attached.
+	LatencyMillis *int64 `json:"latency_millis,omitempty"`
+
+	// Sessions lists the sessions that the Console is attached to. Set on AttachedFrame frames.
+	Sessions []string `json:"sessions,omitempty"`
+
+	// NumDropped is the number of frames that were dropped because the client was not keeping up.
+	NumDropped int64 `json:"num_dropped,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// Console streams the messages exchanged with one or more kernels to a websocket as they happen.
+//
+// A Console observes the shell and control messages via jupyter.MessageObserver and the IOPub messages via
+// jupyter.IOPubMessageHandler. Optionally, the client may send one-off "execute_request" messages to the kernels.
+type Console struct {
+	id           string
+	ws           domain.ConcurrentWebSocket
+	kernels      map[string]jupyter.KernelConnection // kernels is a map from session ID to kernel.
+	sessionIds   map[string]string                   // sessionIds is a map from kernel ID to session ID.
+	allowExecute bool
+	logger       *zap.Logger
+
+	filter      *Filter
+	filterMutex sync.RWMutex
+
+	sentAt      map[string]time.Time // sentAt is a map from request msg_id to the time at which it was sent.
+	sentAtOrder []string             // sentAtOrder are the keys of sentAt, oldest first.
+	sentAtMutex sync.Mutex
+
+	frames     chan *Frame
+	numDropped atomic.Int64
+	done       chan struct{}
+}
+
+// New creates a Console that streams the messages of the given kernels, keyed by session ID, to the websocket.
+func New(ws domain.ConcurrentWebSocket, kernels map[string]jupyter.KernelConnection, filter *Filter, allowExecute bool, logger *zap.Logger) (*Console, error) {
+	if len(kernels) == 0 {
+		return nil, ErrNoKernelsToAttachTo
+	}
+
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	console := &Console{
+		id:           "kernel-console-" + uuid.NewString(),
+		ws:           ws,
+		kernels:      kernels,
+		sessionIds:   make(map[string]string, len(kernels)),
+		allowExecute: allowExecute,
+		logger:       logger,
+		filter:       filter,
+		sentAt:       make(map[string]time.Time),
+		frames:       make(chan *Frame, frameBufferSize),
+		done:         make(chan struct{}),
+	}
+
+	for sessionId, kernel := range kernels {
+		console.sessionIds[kernel.KernelId()] = sessionId
+	}
+
+	return console, nil
+}
+
+// Serve attaches the Console to its kernels and streams their messages until the websocket is closed.
+func (c *Console) Serve() {
+	c.attach()
+	defer c.detach()
+
+	writerDone := make(chan struct{})
+	go c.writeFrames(writerDone)
+	defer func() {
+		close(c.done)
+		<-writerDone
+	}()
+
+	sessions := make([]string, 0, len(c.kernels))
+	for sessionId := range c.kernels {
+		sessions = append(sessions, sessionId)
+	}
+	c.enqueue(&Frame{Type: AttachedFrame, Sessions: sessions, Timestamp: time.Now()})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.logger.Debug("Kernel console websocket closed.", zap.String("console_id", c.id), zap.Error(err))
+			return
+		}
+
+		if err = c.handleCommand(data); err != nil {
+			c.enqueue(&Frame{Type: ErrorFrame, Error: err.Error(), Timestamp: time.Now()})
+		}
+	}
+}
+
+// attach registers the Console's observer and IOPub handler with each of its kernels.
+func (c *Console) attach() {
+	for sessionId, kernel := range c.kernels {
+		if err := kernel.RegisterMessageObserver(c.id, c.observe); err != nil {
+			c.logger.Warn("Failed to register kernel console message observer.",
+				zap.String("session_id", sessionId), zap.String("kernel_id", kernel.KernelId()), zap.Error(err))
+		}
+
+		if err := kernel.RegisterIoPubHandler(c.id, c.handleIoPubMessage); err != nil {
+			c.logger.Warn("Failed to register kernel console IOPub handler.",
+				zap.String("session_id", sessionId), zap.String("kernel_id", kernel.KernelId()), zap.Error(err))
+		}
+	}
+}
+
+// detach unregisters the Console's observer and IOPub handler from each of its kernels.
+func (c *Console) detach() {
+	for _, kernel := range c.kernels {
+		_ = kernel.UnregisterMessageObserver(c.id)
+		_ = kernel.UnregisterIoPubHandler(c.id)
+	}
+}
+
+func (c *Console) handleCommand(data []byte) error {
+	var command *Command
+	if err := json.Unmarshal(data, &command); err != nil {
+		return err
+	}
+
+	switch command.Op {
+	case OpSetFilter:
+		c.filterMutex.Lock()
+		c.filter = command.Filter
+		c.filterMutex.Unlock()
+		return nil
+	case OpExecute:
+		return c.execute(command.SessionId, command.Code)
+	default:
+		return fmt.Errorf("%w: \"%s\"", ErrUnknownOp, command.Op)
+	}
+}
+
+// execute sends a one-off "execute_request" to the kernel of the specified session. The reply and outputs are
+// streamed like any other message.
+func (c *Console) execute(sessionId string, code string) error {
+	if !c.allowExecute {
+		return ErrExecuteNotAllowed
+	}
+
+	kernel, loaded := c.kernels[sessionId]
+	if !loaded {
+		return fmt.Errorf("%w: \"%s\"", ErrKernelNotAttached, sessionId)
+	}
+
+	c.logger.Debug("Sending \"execute_request\" from kernel console.",
+		zap.String("console_id", c.id), zap.String("session_id", sessionId), zap.String("kernel_id", kernel.KernelId()))
+
+	args := jupyter.NewRequestExecuteArgsBuilder().Code(code).Silent(false).StoreHistory(false).AwaitResponse(false).Build()
+	_, err := kernel.RequestExecute(args)
+	return err
+}
+
+// observe is the jupyter.MessageObserver of the Console.
+func (c *Console) observe(conn jupyter.KernelConnection, direction jupyter.MessageDirection, kernelMessage jupyter.KernelMessage) {
+	c.onMessage(conn, direction, kernelMessage.GetChannel().String(), kernelMessage)
+}
+
+// handleIoPubMessage is the jupyter.IOPubMessageHandler of the Console.
+func (c *Console) handleIoPubMessage(conn jupyter.KernelConnection, kernelMessage jupyter.KernelMessage) interface{} {
+	c.onMessage(conn, jupyter.ReceivedMessage, jupyter.IOPubChannel.String(), kernelMessage)
+	return nil
+}
+
+func (c *Console) onMessage(conn jupyter.KernelConnection, direction jupyter.MessageDirection, channel string, kernelMessage jupyter.KernelMessage) {
+	now := time.Now()
+	header := kernelMessage.GetHeader()
+	parentHeader := kernelMessage.GetParentHeader()
+
+	var latencyMillis *int64
+	if direction == jupyter.SentMessage {
+		c.trackRequest(header.MessageId, now)
+	} else if parentHeader != nil {
+		latencyMillis = c.latencySince(parentHeader.MessageId, now)
+	}
+
+	c.filterMutex.RLock()
+	matches := c.filter.matches(channel, header.MessageType.String())
+	c.filterMutex.RUnlock()
+
+	if !matches {
+		return
+	}
+
+	c.enqueue(&Frame{
+		Type:          MessageFrame,
+		SessionId:     c.sessionIds[conn.KernelId()],
+		KernelId:      conn.KernelId(),
+		Direction:     string(direction),
+		Channel:       channel,
+		MessageType:   header.MessageType.String(),
+		Header:        header,
+		ParentHeader:  parentHeader,
+		Metadata:      kernelMessage.GetMetadata(),
+		Content:       kernelMessage.GetContent(),
+		Timestamp:     now,
+		LatencyMillis: latencyMillis,
+	})
+}
+
+// trackRequest remembers when the request with the given msg_id was sent, forgetting the oldest requests once
+// maxTrackedRequests is exceeded.
+func (c *Console) trackRequest(msgId string, sentAt time.Time) {
+	c.sentAtMutex.Lock()
+	defer c.sentAtMutex.Unlock()
+
+	c.sentAt[msgId] = sentAt
+	c.sentAtOrder = append(c.sentAtOrder, msgId)
+
+	if len(c.sentAtOrder) > maxTrackedRequests {
+		delete(c.sentAt, c.sentAtOrder[0])
+		c.sentAtOrder = c.sentAtOrder[1:]
+	}
+}
+
+// latencySince returns the milliseconds elapsed since the request with the given msg_id was sent, or nil if the
+// request was not sent while the Console was attached.
+func (c *Console) latencySince(msgId string, now time.Time) *int64 {
+	c.sentAtMutex.Lock()
+	defer c.sentAtMutex.Unlock()
+
+	sentAt, loaded := c.sentAt[msgId]
+	if !loaded {
+		return nil
+	}
+
+	latency := now.Sub(sentAt).Milliseconds()
+	return &latency
+}
+
+// enqueue queues a Frame to be written to the websocket. If the client is not keeping up, the Frame is dropped.
+func (c *Console) enqueue(frame *Frame) {
+	select {
+	case c.frames <- frame:
+	default:
+		c.numDropped.Add(1)
+	}
+}
+
+// writeFrames writes the queued frames to the websocket until the Console is done.
+func (c *Console) writeFrames(writerDone chan struct{}) {
+	defer close(writerDone)
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case frame := <-c.frames:
+			frame.NumDropped = c.numDropped.Swap(0)
+
+			if err := c.ws.WriteJSON(frame); err != nil {
+				c.logger.Debug("Failed to write frame to kernel console websocket.", zap.String("console_id", c.id), zap.Error(err))
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+)
+
+var (
+	ErrInvalidHistogramBuckets = errors.New("invalid histogram bucket specification")
+
+	// DefaultTrainingDurationBucketsMillis are the default buckets of the training_duration_milliseconds histogram.
+	DefaultTrainingDurationBucketsMillis = []float64{10 /* 10 ms */, 1e3 /* 1 sec */, 5e3 /* 5 sec */, 10e3 /* 10 sec */, 20e3, /* 20 sec */
+		30e3 /* 30 sec */, 60e3 /* 1 min */, 300e3 /* 5 min */, 600e3 /* 10 min */, 1.0e6 /* 30 min */, 3.6e6, /* 1hr */
+		7.2e6 /* 2 hr */, 6e7 /* 1,000 min, or 16.66hr */, 6e8 /* 10,000, or 166.66hr */, 6e9 /* 100,000 min, or 1,666.66hr */}
+
+	// DefaultSessionLifetimeBucketsSeconds are the default buckets of the session_lifetime_seconds histogram.
+	DefaultSessionLifetimeBucketsSeconds = []float64{60 /* 1 min */, 600 /* 10 min */, 1800 /* 30 min */, 3600, /* 1hr */
+		21600 /* 6 hr */, 43200 /* 12 hr */, 86400 /* 24 hr */, 259200 /* 72 hr */, 6.048e5, /* 1 week */
+		1.21e6 /* 2 weeks */, 1.814e6 /* 3 weeks */, 1.051e7 /* 1 month */}
+
+	// DefaultJupyterSessionLatencyBucketsMillis are the default buckets of the Jupyter session creation and
+	// termination latency histograms.
+	DefaultJupyterSessionLatencyBucketsMillis = []float64{1, 10, 30, 75, 150, 250, 500, 1000, 2000, 5000, 10e3, 20e3, 45e3, 90e3, 300e3}
+
+	// DefaultExecuteRequestLatencyBucketsMillis are the default buckets of the execute_request_e2e_latency_milliseconds histogram.
+	DefaultExecuteRequestLatencyBucketsMillis = []float64{10 /* 10 ms */, 100, 250, 500, 750, 1e3 /* 1 sec */, 5e3 /* 5 sec */, 10e3 /* 10 sec */, 20e3, /* 20 sec */
+		30e3 /* 30 sec */, 60e3 /* 1 min */, 300e3 /* 5 min */, 600e3 /* 10 min */, 1.0e6 /* 30 min */, 3.6e6, /* 1hr */
+		7.2e6 /* 2 hr */, 6e7 /* 1,000 min, or 16.66hr */, 6e8 /* 10,000, or 166.66hr */, 6e9 /* 100,000 min, or 1,666.66hr */}
+
+	// DefaultTickDurationBucketsMillis are the default buckets of the tick_processing_duration_milliseconds histogram.
+	DefaultTickDurationBucketsMillis = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1e3 /* 1 sec */, 2.5e3, 5e3, 10e3, /* 10 sec */
+		30e3 /* 30 sec */, 60e3 /* 1 min */, 120e3 /* 2 min */, 300e3 /* 5 min */}
+)
+
+// HistogramBuckets encapsulates the buckets used by each of the histograms of the PrometheusMetricsWrapper.
+type HistogramBuckets struct {
+	TrainingDurationMillis       []float64
+	SessionLifetimeSeconds       []float64
+	JupyterSessionLatencyMillis  []float64
+	ExecuteRequestLatencyMillis  []float64
+	TickProcessingDurationMillis []float64
+}
+
+// DefaultHistogramBuckets returns a HistogramBuckets struct populated with the default buckets of each histogram.
+func DefaultHistogramBuckets() *HistogramBuckets {
+	return &HistogramBuckets{
+		TrainingDurationMillis:       DefaultTrainingDurationBucketsMillis,
+		SessionLifetimeSeconds:       DefaultSessionLifetimeBucketsSeconds,
+		JupyterSessionLatencyMillis:  DefaultJupyterSessionLatencyBucketsMillis,
+		ExecuteRequestLatencyMillis:  DefaultExecuteRequestLatencyBucketsMillis,
+		TickProcessingDurationMillis: DefaultTickDurationBucketsMillis,
+	}
+}
+
+// HistogramBucketsFromConfig creates a HistogramBuckets struct using the bucket specifications in the given
+// domain.Configuration. Histograms whose buckets are left unspecified in the configuration use the default buckets.
+func HistogramBucketsFromConfig(opts *domain.Configuration) (*HistogramBuckets, error) {
+	buckets := DefaultHistogramBuckets()
+	if opts == nil {
+		return buckets, nil
+	}
+
+	var errs []error
+	parse := func(spec string, target *[]float64) {
+		if parsed, err := ParseHistogramBuckets(spec); err != nil {
+			errs = append(errs, err)
+		} else if parsed != nil {
+			*target = parsed
+		}
+	}
+
+	parse(opts.TrainingDurationBucketsMillis, &buckets.TrainingDurationMillis)
+	parse(opts.SessionLifetimeBucketsSeconds, &buckets.SessionLifetimeSeconds)
+	parse(opts.JupyterSessionLatencyBucketsMillis, &buckets.JupyterSessionLatencyMillis)
+	parse(opts.ExecuteRequestLatencyBucketsMillis, &buckets.ExecuteRequestLatencyMillis)
+	parse(opts.TickDurationBucketsMillis, &buckets.TickProcessingDurationMillis)
+
+	return buckets, errors.Join(errs...)
+}
+
+// ParseHistogramBuckets parses a comma-separated list of histogram bucket upper bounds, such as "10,100,1000".
+//
+// ParseHistogramBuckets returns nil (and a nil error) if the specification is empty, in which case the caller
+// should fall back to the default buckets. The bucket upper bounds must be strictly increasing.
+func ParseHistogramBuckets(spec string) ([]float64, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	buckets := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: \"%s\": %v", ErrInvalidHistogramBuckets, spec, err)
+		}
+
+		if len(buckets) > 0 && value <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("%w: \"%s\": bucket upper bounds must be strictly increasing", ErrInvalidHistogramBuckets, spec)
+		}
+
+		buckets = append(buckets, value)
+	}
+
+	return buckets, nil
+}
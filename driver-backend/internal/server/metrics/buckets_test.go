@@ -0,0 +1,40 @@
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
+)
+
+var _ = Describe("Histogram Bucket Tests", func() {
+	It("Will correctly parse a valid bucket specification", func() {
+		buckets, err := metrics.ParseHistogramBuckets(" 1, 10,100.5 ,1e3")
+		Expect(err).To(BeNil())
+		Expect(buckets).To(Equal([]float64{1, 10, 100.5, 1000}))
+	})
+
+	It("Will return nil for an empty bucket specification", func() {
+		buckets, err := metrics.ParseHistogramBuckets("  ")
+		Expect(err).To(BeNil())
+		Expect(buckets).To(BeNil())
+	})
+
+	It("Will reject non-numeric and non-increasing bucket specifications", func() {
+		_, err := metrics.ParseHistogramBuckets("1,abc,3")
+		Expect(err).To(MatchError(metrics.ErrInvalidHistogramBuckets))
+
+		_, err = metrics.ParseHistogramBuckets("1,10,10")
+		Expect(err).To(MatchError(metrics.ErrInvalidHistogramBuckets))
+	})
+
+	It("Will use the default buckets for histograms left unspecified in the configuration", func() {
+		buckets, err := metrics.HistogramBucketsFromConfig(&domain.Configuration{
+			TickDurationBucketsMillis: "5,50,500",
+		})
+		Expect(err).To(BeNil())
+		Expect(buckets.TickProcessingDurationMillis).To(Equal([]float64{5, 50, 500}))
+		Expect(buckets.TrainingDurationMillis).To(Equal(metrics.DefaultTrainingDurationBucketsMillis))
+	})
+})
@@ -0,0 +1,52 @@
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
+)
+
+var _ = Describe("Workload Label Tests", func() {
+	var wrapper *metrics.PrometheusMetricsWrapper
+
+	BeforeEach(func() {
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		wrapper, _ = metrics.NewPrometheusMetricsWrapper(&atom, nil)
+	})
+
+	It("Will use the same preset key before and after registering a non-preset workload", func() {
+		unregistered := wrapper.WorkloadLabels("workload1")
+
+		wrapper.RegisterWorkloadLabels("workload1", "static", "template", "")
+		registered := wrapper.WorkloadLabels("workload1")
+
+		Expect(registered["preset_key"]).To(Equal(unregistered["preset_key"]))
+		Expect(registered["scheduling_policy"]).To(Equal("static"))
+	})
+
+	It("Will discard the labels of unregistered workloads", func() {
+		wrapper.RegisterWorkloadLabels("workload1", "static", "preset", "my-preset")
+		Expect(wrapper.WorkloadLabels("workload1")["preset_key"]).To(Equal("my-preset"))
+
+		wrapper.UnregisterWorkloadLabels("workload1")
+		Expect(wrapper.WorkloadLabels("workload1")["preset_key"]).To(Equal(metrics.UnknownLabelValue))
+	})
+
+	It("Will delete the metric series of unregistered workloads", func() {
+		wrapper.RegisterWorkloadLabels("workload1", "static", "preset", "my-preset")
+		wrapper.RegisterWorkloadLabels("workload2", "static", "preset", "my-preset")
+
+		wrapper.TimeoutOccurred("workload1", metrics.TimeoutTrainingStart)
+		wrapper.TimeoutOccurred("workload2", metrics.TimeoutTrainingStart)
+		wrapper.WorkloadEventsProcessed.With(wrapper.WorkloadLabels("workload1")).Inc()
+		Expect(testutil.CollectAndCount(wrapper.WorkloadTimeoutsTotal)).To(Equal(2))
+		Expect(testutil.CollectAndCount(wrapper.WorkloadEventsProcessed)).To(Equal(1))
+
+		wrapper.UnregisterWorkloadLabels("workload1")
+		Expect(testutil.CollectAndCount(wrapper.WorkloadTimeoutsTotal)).To(Equal(1))
+		Expect(testutil.CollectAndCount(wrapper.WorkloadEventsProcessed)).To(BeZero())
+	})
+})
@@ -1,25 +1,95 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/mattn/go-colorable"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+const (
+	// UnknownLabelValue is used for the scheduling_policy, workload_type, and preset_key labels of metrics
+	// associated with a workload whose labels have not been registered via RegisterWorkloadLabels, as well as
+	// for the preset_key label of non-preset workloads, so that each workload produces a single label series.
+	UnknownLabelValue = "unknown"
+
+	// NoWorkloadLabelValue is used for the workload_id label of metrics that are not associated with a workload.
+	NoWorkloadLabelValue = "no_workload"
+
+	TimeoutSessionCreation = "session_creation"
+	TimeoutSessionEvents   = "session_events"
+	TimeoutTrainingStart   = "training_start"
+	TimeoutTrainingStop    = "training_stop"
+	TimeoutNotebookCell    = "notebook_cell"
+	TimeoutInteractiveCell = "interactive_cell"
+
+	TrainingFailedToSubmit = "submit"
+	TrainingFailedToStart  = "start"
+	TrainingFailedToStop   = "stop"
+)
+
 var (
 	PrometheusMetricsWrapperInstance *PrometheusMetricsWrapper
+
+	// workloadLabelNames are the labels attached to all workload-related metrics.
+	workloadLabelNames = []string{"workload_id", "scheduling_policy", "workload_type", "preset_key"}
 )
 
 func init() {
 	atom := zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	PrometheusMetricsWrapperInstance, _ = NewPrometheusMetricsWrapper(&atom)
+	PrometheusMetricsWrapperInstance, _ = NewPrometheusMetricsWrapper(&atom, DefaultHistogramBuckets())
+}
+
+// InitializePrometheusMetrics replaces the PrometheusMetricsWrapperInstance created during package
+// initialization with a new PrometheusMetricsWrapper whose histogram buckets are taken from the given
+// domain.Configuration. The metrics of the previous instance are unregistered first.
+//
+// InitializePrometheusMetrics should be called before any metrics are recorded, as values recorded
+// by the previous PrometheusMetricsWrapperInstance are discarded.
+func InitializePrometheusMetrics(opts *domain.Configuration, atom *zap.AtomicLevel) []error {
+	buckets, err := HistogramBucketsFromConfig(opts)
+
+	errs := make([]error, 0)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if PrometheusMetricsWrapperInstance != nil {
+		PrometheusMetricsWrapperInstance.Unregister()
+	}
+
+	var registrationErrors []error
+	PrometheusMetricsWrapperInstance, registrationErrors = NewPrometheusMetricsWrapper(atom, buckets)
+	errs = append(errs, registrationErrors...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// WorkloadMetricLabels are the values of the workload-level labels attached to the metrics of a particular workload.
+type WorkloadMetricLabels struct {
+	SchedulingPolicy string
+	WorkloadType     string
+	PresetKey        string
 }
 
 // PrometheusMetricsWrapper is a simple wrapper around several Prometheus metrics.
 type PrometheusMetricsWrapper struct {
 	logger *zap.Logger
 
+	// workloadLabels is a map from workload ID to the WorkloadMetricLabels of that workload.
+	workloadLabels      map[string]*WorkloadMetricLabels
+	workloadLabelsMutex sync.RWMutex
+
+	// collectors are all the metrics that were registered by the PrometheusMetricsWrapper.
+	collectors []prometheus.Collector
+
 	WorkloadTrainingEventsCompleted *prometheus.CounterVec
 	WorkloadEventsProcessed         *prometheus.CounterVec
 	WorkloadTotalNumSessions        *prometheus.CounterVec
@@ -31,6 +101,29 @@ type PrometheusMetricsWrapper struct {
 	// contention when attempting to create its container.
 	SessionDelayedDueToResourceContention *prometheus.CounterVec
 
+	// WorkloadSessionDelaysTotal counts the number of times any Session of a workload has been delayed.
+	WorkloadSessionDelaysTotal *prometheus.CounterVec
+
+	// WorkloadSessionDelayMillisecondsTotal is the cumulative amount of time, in milliseconds, by which the Sessions
+	// of a workload have been delayed.
+	WorkloadSessionDelayMillisecondsTotal *prometheus.CounterVec
+
+	// WorkloadTimeoutsTotal counts the number of timeouts that occur while a workload is running.
+	// The "timeout_type" label indicates what the workload driver was waiting for when the timeout occurred.
+	WorkloadTimeoutsTotal *prometheus.CounterVec
+
+	// WorkloadFailedTrainingsTotal counts the number of training events that could not be submitted, started,
+	// or stopped successfully. The "stage" label indicates at which point the training failed.
+	WorkloadFailedTrainingsTotal *prometheus.CounterVec
+
+	// WorkloadEventQueueDepth is the number of events enqueued in the event queue of a workload,
+	// as observed at the end of each tick.
+	WorkloadEventQueueDepth *prometheus.GaugeVec
+
+	// WorkloadTickProcessingDurationMilliseconds is the real-world time, in milliseconds, that the
+	// workload driver spent processing the events of each tick.
+	WorkloadTickProcessingDurationMilliseconds *prometheus.HistogramVec
+
 	// JupyterSessionCreationLatencyMilliseconds is a metric tracking the latency between when
 	// the network request to create a new Session is first sent and when the response
 	// is received, indicating that the new Session has been created successfully.
@@ -57,6 +150,10 @@ type PrometheusMetricsWrapper struct {
 	// This is from the perspective of Jupyter clients.
 	JupyterRequestExecuteTime *prometheus.GaugeVec
 
+	// JupyterKernelReconnectionsTotal counts the number of times that a Jupyter client attempted to reconnect
+	// to a kernel after its websocket connection was closed. The "outcome" label is either "success" or "failure".
+	JupyterKernelReconnectionsTotal *prometheus.CounterVec
+
 	WorkloadActiveTrainingSessions *prometheus.GaugeVec
 	WorkloadActiveNumSessions      *prometheus.GaugeVec
 
@@ -65,72 +162,118 @@ type PrometheusMetricsWrapper struct {
 	//JupyterTimeSpentIdle *prometheus.GaugeVec
 }
 
+// withLabels returns a new slice containing the workload-level label names followed by the given additional labels.
+func withLabels(additional ...string) []string {
+	labels := make([]string, 0, len(workloadLabelNames)+len(additional))
+	labels = append(labels, workloadLabelNames...)
+	return append(labels, additional...)
+}
+
 // NewPrometheusMetricsWrapper creates a new PrometheusMetricsWrapper struct and returns a pointer to it.
 // NewPrometheusMetricsWrapper initializes creates and registers all the metrics encapsulated by the
 // PrometheusMetricsWrapper struct after creating the struct.
-func NewPrometheusMetricsWrapper(atom *zap.AtomicLevel) (*PrometheusMetricsWrapper, []error) {
+//
+// If buckets is nil, then the default buckets are used for each histogram.
+func NewPrometheusMetricsWrapper(atom *zap.AtomicLevel, buckets *HistogramBuckets) (*PrometheusMetricsWrapper, []error) {
+	if buckets == nil {
+		buckets = DefaultHistogramBuckets()
+	}
+
 	// Counter metrics.
 	metricsWrapper := &PrometheusMetricsWrapper{
+		workloadLabels: make(map[string]*WorkloadMetricLabels),
+
 		WorkloadTrainingEventsCompleted: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "workload_training_events_completed_total",
-		}, []string{"workload_id"}),
+		}, withLabels()),
 		WorkloadEventsProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "workload_events_processed_total",
-		}, []string{"workload_id"}),
+		}, withLabels()),
 		WorkloadTotalNumSessions: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "sessions_created_total",
-		}, []string{"workload_id"}),
+		}, withLabels()),
 
 		SessionDelayedDueToResourceContention: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "session_delayed_resource_contention",
-		}, []string{"workload_id", "session_id"}),
+		}, withLabels("session_id")),
+		WorkloadSessionDelaysTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "session_delays_total",
+			Help:      "Number of times any session of the workload was delayed",
+		}, withLabels()),
+		WorkloadSessionDelayMillisecondsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "session_delay_milliseconds_total",
+			Help:      "Cumulative amount of time, in milliseconds, by which the sessions of the workload were delayed",
+		}, withLabels()),
+		WorkloadTimeoutsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "timeouts_total",
+			Help:      "Number of timeouts that occurred while waiting on sessions and kernels",
+		}, withLabels("timeout_type")),
+		WorkloadFailedTrainingsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "failed_trainings_total",
+			Help:      "Number of training events that could not be submitted, started, or stopped",
+		}, withLabels("stage")),
+		JupyterKernelReconnectionsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "jupyter",
+			Name:      "kernel_reconnections_total",
+			Help:      "Number of attempts made by Jupyter clients to reconnect to a kernel",
+		}, withLabels("kernel_id", "outcome")),
 
 		// Histogram metrics.
 		WorkloadTrainingEventDurationMilliseconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "training_duration_milliseconds",
-			Buckets: []float64{10 /* 10 ms */, 1e3 /* 1 sec */, 5e3 /* 5 sec */, 10e3 /* 10 sec */, 20e3, /* 20 sec */
-				30e3 /* 30 sec */, 60e3 /* 1 min */, 300e3 /* 5 min */, 600e3 /* 10 min */, 1.0e6 /* 30 min */, 3.6e6, /* 1hr */
-				7.2e6 /* 2 hr */, 6e7 /* 1,000 min, or 16.66hr */, 6e8 /* 10,000, or 166.66hr */, 6e9 /* 100,000 min, or 1,666.66hr */},
-		}, []string{"workload_id", "session_id"}),
+			Buckets:   buckets.TrainingDurationMillis,
+		}, withLabels("session_id")),
 		WorkloadSessionLifetimeSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "session_lifetime_seconds",
-			Buckets: []float64{60 /* 1 min */, 600 /* 10 min */, 1800 /* 30 min */, 3600, /* 1hr */
-				21600 /* 6 hr */, 43200 /* 12 hr */, 86400 /* 24 hr */, 259200 /* 72 hr */, 6.048e5, /* 1 week */
-				1.21e6 /* 2 weeks */, 1.814e6 /* 3 weeks */, 1.051e7 /* 1 month */},
-		}, []string{"workload_id"}),
+			Buckets:   buckets.SessionLifetimeSeconds,
+		}, withLabels()),
+		WorkloadTickProcessingDurationMilliseconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "tick_processing_duration_milliseconds",
+			Help:      "Real-world time spent processing the events of a single tick",
+			Buckets:   buckets.TickProcessingDurationMillis,
+		}, withLabels()),
 
 		JupyterSessionCreationLatencyMilliseconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "jupyter",
 			Name:      "session_creation_latency_milliseconds",
-			Buckets:   []float64{1, 10, 30, 75, 150, 250, 500, 1000, 2000, 5000, 10e3, 20e3, 45e3, 90e3, 300e3},
-		}, []string{"workload_id"}),
+			Buckets:   buckets.JupyterSessionLatencyMillis,
+		}, withLabels()),
 		JupyterSessionTerminationLatencyMilliseconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "jupyter",
 			Name:      "session_termination_latency_milliseconds",
-			Buckets:   []float64{1, 10, 30, 75, 150, 250, 500, 1000, 2000, 5000, 10e3, 20e3, 45e3, 90e3, 300e3},
-		}, []string{"workload_id"}),
+			Buckets:   buckets.JupyterSessionLatencyMillis,
+		}, withLabels()),
 		JupyterExecuteRequestEndToEndLatencyMilliseconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "jupyter",
 			Name:      "execute_request_e2e_latency_milliseconds",
-			Buckets: []float64{10 /* 10 ms */, 100, 250, 500, 750, 1e3 /* 1 sec */, 5e3 /* 5 sec */, 10e3 /* 10 sec */, 20e3, /* 20 sec */
-				30e3 /* 30 sec */, 60e3 /* 1 min */, 300e3 /* 5 min */, 600e3 /* 10 min */, 1.0e6 /* 30 min */, 3.6e6, /* 1hr */
-				7.2e6 /* 2 hr */, 6e7 /* 1,000 min, or 16.66hr */, 6e8 /* 10,000, or 166.66hr */, 6e9 /* 100,000 min, or 1,666.66hr */},
-		}, []string{"workload_id"}),
+			Buckets:   buckets.ExecuteRequestLatencyMillis,
+		}, withLabels()),
 
 		// Gauge metrics.
 		WorkloadActiveNumSessions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -138,18 +281,24 @@ func NewPrometheusMetricsWrapper(atom *zap.AtomicLevel) (*PrometheusMetricsWrapp
 			Subsystem: "workload_driver",
 			Name:      "active_workload_sessions",
 			Help:      "Number of actively-running kernels",
-		}, []string{"workload_id"}),
+		}, withLabels()),
 		WorkloadActiveTrainingSessions: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "workload_driver",
 			Name:      "active_trainings",
-		}, []string{"workload_id"}),
+		}, withLabels()),
+		WorkloadEventQueueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "distributed_cluster",
+			Subsystem: "workload_driver",
+			Name:      "event_queue_depth",
+			Help:      "Number of events enqueued in the workload's event queue at the end of the last tick",
+		}, withLabels()),
 		JupyterRequestExecuteTime: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: "distributed_cluster",
 			Subsystem: "jupyter",
 			Name:      "execute_request_active_seconds",
 			Help:      "The time, in seconds, that Jupyter clients spend waiting for an \"execute_reply\" response to their \"execute_request\" requests. Includes total training time and all overheads.",
-		}, []string{"workload_id", "kernel_id"}),
+		}, withLabels("kernel_id")),
 		//JupyterTimeSpentIdle: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		//	Namespace: "distributed_cluster",
 		//	Subsystem: "jupyter",
@@ -165,75 +314,218 @@ func NewPrometheusMetricsWrapper(atom *zap.AtomicLevel) (*PrometheusMetricsWrapp
 		panic("failed to create logger for workload driver")
 	}
 
+	metricsWrapper.logger = logger
+
+	collectors := []struct {
+		name      string
+		collector prometheus.Collector
+	}{
+		{"WorkloadTrainingEventsCompleted", metricsWrapper.WorkloadTrainingEventsCompleted},
+		{"WorkloadEventsProcessed", metricsWrapper.WorkloadEventsProcessed},
+		{"WorkloadTotalNumSessions", metricsWrapper.WorkloadTotalNumSessions},
+		{"SessionDelayedDueToResourceContention", metricsWrapper.SessionDelayedDueToResourceContention},
+		{"WorkloadSessionDelaysTotal", metricsWrapper.WorkloadSessionDelaysTotal},
+		{"WorkloadSessionDelayMillisecondsTotal", metricsWrapper.WorkloadSessionDelayMillisecondsTotal},
+		{"WorkloadTimeoutsTotal", metricsWrapper.WorkloadTimeoutsTotal},
+		{"WorkloadFailedTrainingsTotal", metricsWrapper.WorkloadFailedTrainingsTotal},
+		{"WorkloadTrainingEventDurationMilliseconds", metricsWrapper.WorkloadTrainingEventDurationMilliseconds},
+		{"WorkloadSessionLifetimeSeconds", metricsWrapper.WorkloadSessionLifetimeSeconds},
+		{"WorkloadTickProcessingDurationMilliseconds", metricsWrapper.WorkloadTickProcessingDurationMilliseconds},
+		{"JupyterSessionCreationLatencyMilliseconds", metricsWrapper.JupyterSessionCreationLatencyMilliseconds},
+		{"JupyterSessionTerminationLatencyMilliseconds", metricsWrapper.JupyterSessionTerminationLatencyMilliseconds},
+		{"JupyterExecuteRequestEndToEndLatencyMilliseconds", metricsWrapper.JupyterExecuteRequestEndToEndLatencyMilliseconds},
+		{"JupyterKernelReconnectionsTotal", metricsWrapper.JupyterKernelReconnectionsTotal},
+		{"WorkloadActiveNumSessions", metricsWrapper.WorkloadActiveNumSessions},
+		{"WorkloadActiveTrainingSessions", metricsWrapper.WorkloadActiveTrainingSessions},
+		{"WorkloadEventQueueDepth", metricsWrapper.WorkloadEventQueueDepth},
+		{"JupyterRequestExecuteTime", metricsWrapper.JupyterRequestExecuteTime},
+	}
+
 	errs := make([]error, 0)
 
-	if err := prometheus.Register(metricsWrapper.WorkloadTrainingEventsCompleted); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadTrainingEventsCompleted"), zap.Error(err))
-		errs = append(errs, err)
+	for _, c := range collectors {
+		if err := prometheus.Register(c.collector); err != nil {
+			metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", c.name), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+
+		metricsWrapper.collectors = append(metricsWrapper.collectors, c.collector)
 	}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadEventsProcessed); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadEventsProcessed"), zap.Error(err))
-		errs = append(errs, err)
+	if len(errs) > 0 {
+		return metricsWrapper, errs
+	} else {
+		return metricsWrapper, nil
 	}
+}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadTotalNumSessions); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadTotalNumSessions"), zap.Error(err))
-		errs = append(errs, err)
+// Unregister unregisters all the metrics that were successfully registered by the PrometheusMetricsWrapper.
+func (m *PrometheusMetricsWrapper) Unregister() {
+	for _, collector := range m.collectors {
+		prometheus.Unregister(collector)
 	}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadTrainingEventDurationMilliseconds); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadTrainingEventDurationMilliseconds"), zap.Error(err))
-		errs = append(errs, err)
+	m.collectors = nil
+}
+
+// RegisterWorkloadLabels records the scheduling policy, workload type, and preset key of the specified workload.
+// These values are attached as labels to all subsequently-recorded metrics of the workload.
+//
+// Empty values are replaced by UnknownLabelValue. RegisterWorkloadLabels may be called more than once for the
+// same workload, such as once the scheduling policy becomes known.
+func (m *PrometheusMetricsWrapper) RegisterWorkloadLabels(workloadId string, schedulingPolicy string, workloadType string, presetKey string) {
+	if schedulingPolicy == "" {
+		schedulingPolicy = UnknownLabelValue
 	}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadSessionLifetimeSeconds); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadSessionLifetimeSeconds"), zap.Error(err))
-		errs = append(errs, err)
+	if workloadType == "" {
+		workloadType = UnknownLabelValue
 	}
 
-	if err := prometheus.Register(metricsWrapper.JupyterSessionCreationLatencyMilliseconds); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "JupyterSessionCreationLatencyMilliseconds"), zap.Error(err))
-		errs = append(errs, err)
+	if presetKey == "" {
+		presetKey = UnknownLabelValue
 	}
 
-	if err := prometheus.Register(metricsWrapper.JupyterExecuteRequestEndToEndLatencyMilliseconds); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "JupyterExecuteRequestEndToEndLatencyMilliseconds"), zap.Error(err))
-		errs = append(errs, err)
+	m.workloadLabelsMutex.Lock()
+	defer m.workloadLabelsMutex.Unlock()
+
+	m.workloadLabels[workloadId] = &WorkloadMetricLabels{
+		SchedulingPolicy: schedulingPolicy,
+		WorkloadType:     workloadType,
+		PresetKey:        presetKey,
 	}
+}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadActiveNumSessions); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadActiveNumSessions"), zap.Error(err))
-		errs = append(errs, err)
+// UnregisterWorkloadLabels deletes the label series of the specified workload from each of the workload-related
+// metrics and then discards the labels registered for the workload via RegisterWorkloadLabels.
+//
+// UnregisterWorkloadLabels should be called once the last metric of the workload has been recorded, as metrics
+// recorded afterward are attached to a new series whose workload-level labels are UnknownLabelValue.
+func (m *PrometheusMetricsWrapper) UnregisterWorkloadLabels(workloadId string) {
+	labels := m.WorkloadLabels(workloadId)
+
+	// Several of the metrics have labels in addition to the workload-level labels, so every series that carries
+	// the workload-level labels of the workload is deleted.
+	for _, vec := range m.workloadVecs() {
+		vec.DeletePartialMatch(labels)
 	}
 
-	if err := prometheus.Register(metricsWrapper.WorkloadActiveTrainingSessions); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "WorkloadActiveTrainingSessions"), zap.Error(err))
-		errs = append(errs, err)
+	m.workloadLabelsMutex.Lock()
+	defer m.workloadLabelsMutex.Unlock()
+
+	delete(m.workloadLabels, workloadId)
+}
+
+// workloadVecs returns each of the metrics whose series are labeled with the workload-level labels.
+func (m *PrometheusMetricsWrapper) workloadVecs() []interface{ DeletePartialMatch(prometheus.Labels) int } {
+	return []interface{ DeletePartialMatch(prometheus.Labels) int }{
+		m.WorkloadTrainingEventsCompleted,
+		m.WorkloadEventsProcessed,
+		m.WorkloadTotalNumSessions,
+		m.WorkloadTrainingEventDurationMilliseconds,
+		m.WorkloadSessionLifetimeSeconds,
+		m.SessionDelayedDueToResourceContention,
+		m.WorkloadSessionDelaysTotal,
+		m.WorkloadSessionDelayMillisecondsTotal,
+		m.WorkloadTimeoutsTotal,
+		m.WorkloadFailedTrainingsTotal,
+		m.WorkloadEventQueueDepth,
+		m.WorkloadTickProcessingDurationMilliseconds,
+		m.JupyterSessionCreationLatencyMilliseconds,
+		m.JupyterSessionTerminationLatencyMilliseconds,
+		m.JupyterExecuteRequestEndToEndLatencyMilliseconds,
+		m.JupyterRequestExecuteTime,
+		m.JupyterKernelReconnectionsTotal,
+		m.WorkloadActiveTrainingSessions,
+		m.WorkloadActiveNumSessions,
 	}
+}
 
-	if err := prometheus.Register(metricsWrapper.JupyterRequestExecuteTime); err != nil {
-		metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "JupyterRequestExecuteTime"), zap.Error(err))
-		errs = append(errs, err)
+// WorkloadLabels returns the workload-level prometheus.Labels of the specified workload.
+//
+// If no labels were registered for the workload via RegisterWorkloadLabels, then UnknownLabelValue
+// is used for each of the scheduling_policy, workload_type, and preset_key labels.
+func (m *PrometheusMetricsWrapper) WorkloadLabels(workloadId string) prometheus.Labels {
+	if workloadId == "" {
+		workloadId = NoWorkloadLabelValue
 	}
 
-	//if err := prometheus.Register(metricsWrapper.JupyterTimeSpentIdle); err != nil {
-	//	metricsWrapper.logger.Error("Failed to register Prometheus metric.", zap.String("metric", "JupyterTimeSpentIdle"), zap.Error(err))
-	//	errs = append(errs, err)
-	//}
+	labels := prometheus.Labels{
+		"workload_id":       workloadId,
+		"scheduling_policy": UnknownLabelValue,
+		"workload_type":     UnknownLabelValue,
+		"preset_key":        UnknownLabelValue,
+	}
 
-	if len(errs) > 0 {
-		return metricsWrapper, errs
-	} else {
-		return metricsWrapper, nil
+	m.workloadLabelsMutex.RLock()
+	workloadLabels, loaded := m.workloadLabels[workloadId]
+	m.workloadLabelsMutex.RUnlock()
+
+	if loaded {
+		labels["scheduling_policy"] = workloadLabels.SchedulingPolicy
+		labels["workload_type"] = workloadLabels.WorkloadType
+		labels["preset_key"] = workloadLabels.PresetKey
 	}
+
+	return labels
+}
+
+// WorkloadSessionLabels returns the workload-level prometheus.Labels of the specified workload
+// with an additional "session_id" label.
+func (m *PrometheusMetricsWrapper) WorkloadSessionLabels(workloadId string, sessionId string) prometheus.Labels {
+	labels := m.WorkloadLabels(workloadId)
+	labels["session_id"] = sessionId
+	return labels
+}
+
+// SessionDelayed records that the specified Session of the specified workload was delayed by the given amount.
+func (m *PrometheusMetricsWrapper) SessionDelayed(workloadId string, sessionId string, delayMilliseconds int64) {
+	m.SessionDelayedDueToResourceContention.
+		With(m.WorkloadSessionLabels(workloadId, sessionId)).
+		Add(1)
+
+	m.WorkloadSessionDelaysTotal.
+		With(m.WorkloadLabels(workloadId)).
+		Add(1)
+
+	m.WorkloadSessionDelayMillisecondsTotal.
+		With(m.WorkloadLabels(workloadId)).
+		Add(float64(delayMilliseconds))
+}
+
+// TimeoutOccurred records that a timeout of the specified type occurred during the specified workload.
+func (m *PrometheusMetricsWrapper) TimeoutOccurred(workloadId string, timeoutType string) {
+	labels := m.WorkloadLabels(workloadId)
+	labels["timeout_type"] = timeoutType
+
+	m.WorkloadTimeoutsTotal.With(labels).Add(1)
+}
+
+// TrainingFailed records that a training event of the specified workload failed during the specified stage.
+func (m *PrometheusMetricsWrapper) TrainingFailed(workloadId string, stage string) {
+	labels := m.WorkloadLabels(workloadId)
+	labels["stage"] = stage
+
+	m.WorkloadFailedTrainingsTotal.With(labels).Add(1)
+}
+
+// TickProcessed records the real-world duration of a tick and the depth of the event queue once the tick completed.
+func (m *PrometheusMetricsWrapper) TickProcessed(workloadId string, tickDurationMilliseconds int64, eventQueueDepth int) {
+	m.WorkloadTickProcessingDurationMilliseconds.
+		With(m.WorkloadLabels(workloadId)).
+		Observe(float64(tickDurationMilliseconds))
+
+	m.WorkloadEventQueueDepth.
+		With(m.WorkloadLabels(workloadId)).
+		Set(float64(eventQueueDepth))
 }
 
 // ObserveJupyterSessionCreationLatency records the latency of creating a Jupyter session
 // during the execution of a particular workload, as identified by the given workload ID.
 func (m *PrometheusMetricsWrapper) ObserveJupyterSessionCreationLatency(latencyMilliseconds int64, workloadId string) {
 	m.JupyterSessionCreationLatencyMilliseconds.
-		With(prometheus.Labels{"workload_id": workloadId}).
+		With(m.WorkloadLabels(workloadId)).
 		Observe(float64(latencyMilliseconds))
 }
 
@@ -241,7 +533,7 @@ func (m *PrometheusMetricsWrapper) ObserveJupyterSessionCreationLatency(latencyM
 // during the execution of a particular workload, as identified by the given workload ID.
 func (m *PrometheusMetricsWrapper) ObserveJupyterSessionTerminationLatency(latencyMilliseconds int64, workloadId string) {
 	m.JupyterSessionTerminationLatencyMilliseconds.
-		With(prometheus.Labels{"workload_id": workloadId}).
+		With(m.WorkloadLabels(workloadId)).
 		Observe(float64(latencyMilliseconds))
 }
 
@@ -249,7 +541,7 @@ func (m *PrometheusMetricsWrapper) ObserveJupyterSessionTerminationLatency(laten
 // during the execution of a particular workload, as identified by the given workload ID.
 func (m *PrometheusMetricsWrapper) ObserveJupyterExecuteRequestE2ELatency(latencyMilliseconds int64, workloadId string) {
 	m.JupyterExecuteRequestEndToEndLatencyMilliseconds.
-		With(prometheus.Labels{"workload_id": workloadId}).
+		With(m.WorkloadLabels(workloadId)).
 		Observe(float64(latencyMilliseconds))
 
 }
@@ -257,7 +549,25 @@ func (m *PrometheusMetricsWrapper) ObserveJupyterExecuteRequestE2ELatency(latenc
 // AddJupyterRequestExecuteTime records the time taken to process an "execute_request" for the total, aggregate,
 // cumulative time spent processing "execute_request" messages.
 func (m *PrometheusMetricsWrapper) AddJupyterRequestExecuteTime(latencyMilliseconds int64, kernelId string, workloadId string) {
+	labels := m.WorkloadLabels(workloadId)
+	labels["kernel_id"] = kernelId
+
 	m.JupyterRequestExecuteTime.
-		With(prometheus.Labels{"workload_id": workloadId, "kernel_id": kernelId}).
+		With(labels).
 		Add(float64(latencyMilliseconds)) // Add another second.
 }
+
+// RecordKernelReconnectionAttempt records that a Jupyter client attempted to reconnect to the specified kernel,
+// along with whether the reconnection attempt succeeded.
+func (m *PrometheusMetricsWrapper) RecordKernelReconnectionAttempt(kernelId string, workloadId string, succeeded bool) {
+	labels := m.WorkloadLabels(workloadId)
+	labels["kernel_id"] = kernelId
+
+	if succeeded {
+		labels["outcome"] = "success"
+	} else {
+		labels["outcome"] = "failure"
+	}
+
+	m.JupyterKernelReconnectionsTotal.With(labels).Add(1)
+}
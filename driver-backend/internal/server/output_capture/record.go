@@ -0,0 +1,82 @@
+package output_capture
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StreamOutput        = "stream"
+	DisplayDataOutput   = "display_data"
+	ExecuteResultOutput = "execute_result"
+	ErrorOutput         = "error"
+)
+
+// IsOutputMessageType returns true if IOPub messages of the given type carry the output of an execution.
+func IsOutputMessageType(messageType string) bool {
+	switch messageType {
+	case StreamOutput, DisplayDataOutput, ExecuteResultOutput, ErrorOutput:
+		return true
+	default:
+		return false
+	}
+}
+
+// Record is a single output produced by one execution (i.e., training) of a session: the content of a "stream",
+// "display_data", "execute_result", or "error" IOPub message.
+//
+// Records are keyed by session, training index, and the Jupyter msg_id of the IOPub message. The ParentMsgId is
+// the msg_id of the "execute_request" that produced the output.
+type Record struct {
+	SessionId     string    `json:"session_id"`
+	TrainingIndex int       `json:"training_index"`
+	MsgId         string    `json:"msg_id"`
+	ParentMsgId   string    `json:"parent_msg_id"`
+	OutputType    string    `json:"output_type"`
+	Timestamp     time.Time `json:"timestamp"`
+
+	// Stream and Text are set for "stream" outputs.
+	Stream string `json:"stream,omitempty"`
+	Text   string `json:"text,omitempty"`
+
+	// Data and Metadata are set for "display_data" and "execute_result" outputs.
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Ename, Evalue, and Traceback are set for "error" outputs.
+	Ename     string   `json:"ename,omitempty"`
+	Evalue    string   `json:"evalue,omitempty"`
+	Traceback []string `json:"traceback,omitempty"`
+}
+
+// NewRecord creates a Record from the content of an IOPub message of the given type.
+func NewRecord(sessionId string, msgId string, parentMsgId string, outputType string, content map[string]interface{}) *Record {
+	record := &Record{
+		SessionId:   sessionId,
+		MsgId:       msgId,
+		ParentMsgId: parentMsgId,
+		OutputType:  outputType,
+		Timestamp:   time.Now(),
+	}
+
+	switch outputType {
+	case StreamOutput:
+		record.Stream, _ = content["name"].(string)
+		record.Text, _ = content["text"].(string)
+	case DisplayDataOutput, ExecuteResultOutput:
+		record.Data, _ = content["data"].(map[string]interface{})
+		record.Metadata, _ = content["metadata"].(map[string]interface{})
+	case ErrorOutput:
+		record.Ename, _ = content["ename"].(string)
+		record.Evalue, _ = content["evalue"].(string)
+
+		if traceback, ok := content["traceback"].([]interface{}); ok {
+			record.Traceback = make([]string, 0, len(traceback))
+			for _, line := range traceback {
+				record.Traceback = append(record.Traceback, fmt.Sprintf("%v", line))
+			}
+		}
+	}
+
+	return record
+}
@@ -0,0 +1,318 @@
+package output_capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxRecordsPerSession is the number of records retained in memory for each session if the Config
+	// does not specify a positive MaxRecordsPerSession.
+	DefaultMaxRecordsPerSession = 1000
+
+	// AnyTrainingIndex is used in a Query to match the records of every training.
+	AnyTrainingIndex = -2
+
+	// NoTrainingIndex is the training index of the records produced before the first training of a session was
+	// submitted, such as output printed while the kernel was starting.
+	NoTrainingIndex = -1
+
+	spillFileExtension = ".jsonl"
+)
+
+var (
+	ErrStoreClosed = errors.New("output capture store is closed")
+)
+
+// Config configures a Store.
+type Config struct {
+	// MaxRecordsPerSession bounds the number of records retained in memory for each session.
+	// Once exceeded, the oldest records are evicted (and spilled to disk, if a SpillDirectory is configured).
+	MaxRecordsPerSession int
+
+	// SpillDirectory is the directory to which evicted records are appended, one JSON Lines file per session.
+	// If empty, evicted records are discarded.
+	SpillDirectory string
+}
+
+// Query selects records from a Store. Empty fields match every record.
+type Query struct {
+	SessionId string
+	// TrainingIndex selects the records of one training. Use AnyTrainingIndex to match every training.
+	TrainingIndex int
+	// MsgId matches records whose MsgId or ParentMsgId (i.e., the msg_id of the "execute_request") is MsgId.
+	MsgId      string
+	OutputType string
+	// IncludeSpilled specifies whether records that were evicted to disk should be read back and returned.
+	IncludeSpilled bool
+}
+
+func (q *Query) matches(record *Record) bool {
+	if q.SessionId != "" && q.SessionId != record.SessionId {
+		return false
+	}
+
+	if q.TrainingIndex != AnyTrainingIndex && q.TrainingIndex != record.TrainingIndex {
+		return false
+	}
+
+	if q.MsgId != "" && q.MsgId != record.MsgId && q.MsgId != record.ParentMsgId {
+		return false
+	}
+
+	return q.OutputType == "" || q.OutputType == record.OutputType
+}
+
+// sessionOutput is the output captured for a single session.
+type sessionOutput struct {
+	records []*Record // records is a ring buffer of the most recent records.
+	start   int       // start is the index of the oldest record within records.
+	size    int       // size is the number of records within records.
+
+	numTrainings  int            // numTrainings is the number of trainings submitted to the session so far.
+	trainingIndex map[string]int // trainingIndex is a map from "execute_request" msg_id to training index.
+
+	spillFile  *os.File
+	numSpilled int
+}
+
+// Store captures the output of the executions of each session of a workload in bounded, per-session ring
+// buffers. Records evicted from the ring buffers are optionally spilled to disk so that they can still be
+// retrieved, such as to debug a failed training after the fact.
+type Store struct {
+	config   Config
+	logger   *zap.Logger
+	sessions map[string]*sessionOutput
+	closed   bool
+
+	mu sync.Mutex
+}
+
+// NewStore creates a new Store.
+func NewStore(config Config, logger *zap.Logger) *Store {
+	if config.MaxRecordsPerSession <= 0 {
+		config.MaxRecordsPerSession = DefaultMaxRecordsPerSession
+	}
+
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &Store{
+		config:   config,
+		logger:   logger,
+		sessions: make(map[string]*sessionOutput),
+	}
+}
+
+func (s *Store) getOrCreateSession(sessionId string) *sessionOutput {
+	session, loaded := s.sessions[sessionId]
+	if !loaded {
+		session = &sessionOutput{
+			records:       make([]*Record, s.config.MaxRecordsPerSession),
+			trainingIndex: make(map[string]int),
+		}
+		s.sessions[sessionId] = session
+	}
+
+	return session
+}
+
+// BeginTraining records that a new training is being submitted to the specified session and returns the index
+// of that training. Outputs whose "execute_request" has not been seen before are attributed to the most recent
+// training of their session.
+func (s *Store) BeginTraining(sessionId string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.getOrCreateSession(sessionId)
+	session.numTrainings += 1
+	return session.numTrainings - 1
+}
+
+// Add records an output of the specified session and returns the resulting Record.
+func (s *Store) Add(sessionId string, msgId string, parentMsgId string, outputType string, content map[string]interface{}) (*Record, error) {
+	record := NewRecord(sessionId, msgId, parentMsgId, outputType, content)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, ErrStoreClosed
+	}
+
+	session := s.getOrCreateSession(sessionId)
+
+	trainingIndex, loaded := session.trainingIndex[parentMsgId]
+	if !loaded {
+		trainingIndex = session.numTrainings - 1
+		if parentMsgId != "" && trainingIndex != NoTrainingIndex {
+			session.trainingIndex[parentMsgId] = trainingIndex
+		}
+	}
+	record.TrainingIndex = trainingIndex
+
+	capacity := len(session.records)
+	if session.size == capacity {
+		evicted := session.records[session.start]
+		session.records[session.start] = record
+		session.start = (session.start + 1) % capacity
+
+		if err := s.spill(session, evicted); err != nil {
+			s.logger.Warn("Failed to spill evicted output record to disk.",
+				zap.String("session_id", sessionId), zap.String("msg_id", evicted.MsgId), zap.Error(err))
+		}
+	} else {
+		session.records[(session.start+session.size)%capacity] = record
+		session.size += 1
+	}
+
+	return record, nil
+}
+
+// spillFilePath returns the path of the file to which the evicted records of the specified session are spilled.
+func (s *Store) spillFilePath(sessionId string) string {
+	return filepath.Join(s.config.SpillDirectory, url.PathEscape(sessionId)+spillFileExtension)
+}
+
+// spill appends the given record to the spill file of the given session, if spilling is enabled.
+func (s *Store) spill(session *sessionOutput, record *Record) error {
+	if s.config.SpillDirectory == "" {
+		return nil
+	}
+
+	if session.spillFile == nil {
+		if err := os.MkdirAll(s.config.SpillDirectory, 0750); err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(s.spillFilePath(record.SessionId), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return err
+		}
+
+		session.spillFile = file
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if _, err = session.spillFile.Write(append(encoded, '\n')); err != nil {
+		return err
+	}
+
+	session.numSpilled += 1
+	return nil
+}
+
+// readSpilled returns the records of the specified session that were spilled to disk.
+func (s *Store) readSpilled(sessionId string) ([]*Record, error) {
+	file, err := os.Open(s.spillFilePath(sessionId))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]*Record, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record *Record
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode spilled output record of session \"%s\": %w", sessionId, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Query returns the records selected by the given Query, oldest first.
+func (s *Store) Query(query *Query) ([]*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessionIds := make([]string, 0, len(s.sessions))
+	if query.SessionId != "" {
+		if _, loaded := s.sessions[query.SessionId]; loaded {
+			sessionIds = append(sessionIds, query.SessionId)
+		}
+	} else {
+		for sessionId := range s.sessions {
+			sessionIds = append(sessionIds, sessionId)
+		}
+	}
+
+	results := make([]*Record, 0)
+	for _, sessionId := range sessionIds {
+		session := s.sessions[sessionId]
+
+		if query.IncludeSpilled && session.numSpilled > 0 {
+			spilled, err := s.readSpilled(sessionId)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, record := range spilled {
+				if query.matches(record) {
+					results = append(results, record)
+				}
+			}
+		}
+
+		for i := 0; i < session.size; i++ {
+			record := session.records[(session.start+i)%len(session.records)]
+			if query.matches(record) {
+				results = append(results, record)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// NumSpilled returns the number of records of the specified session that were evicted and spilled to disk.
+func (s *Store) NumSpilled(sessionId string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, loaded := s.sessions[sessionId]; loaded {
+		return session.numSpilled
+	}
+
+	return 0
+}
+
+// Close closes the spill files of the Store. The records retained in memory can still be queried.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var errs []error
+	for _, session := range s.sessions {
+		if session.spillFile != nil {
+			errs = append(errs, session.spillFile.Close())
+			session.spillFile = nil
+		}
+	}
+
+	return errors.Join(errs...)
+}
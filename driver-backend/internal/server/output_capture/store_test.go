@@ -0,0 +1,88 @@
+package output_capture_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/output_capture"
+)
+
+func streamContent(text string) map[string]interface{} {
+	return map[string]interface{}{"name": "stdout", "text": text}
+}
+
+var _ = Describe("Output Capture Store Tests", func() {
+	It("Will attribute outputs to the training of their execute_request", func() {
+		store := output_capture.NewStore(output_capture.Config{}, nil)
+
+		_, err := store.Add("session", "startup", "", output_capture.StreamOutput, streamContent("starting"))
+		Expect(err).To(BeNil())
+
+		Expect(store.BeginTraining("session")).To(Equal(0))
+		_, err = store.Add("session", "msg-1", "request-1", output_capture.StreamOutput, streamContent("epoch 1"))
+		Expect(err).To(BeNil())
+
+		Expect(store.BeginTraining("session")).To(Equal(1))
+		_, err = store.Add("session", "msg-2", "request-2", output_capture.ErrorOutput, map[string]interface{}{
+			"ename":     "ValueError",
+			"evalue":    "bad value",
+			"traceback": []interface{}{"line 1", "line 2"},
+		})
+		Expect(err).To(BeNil())
+
+		// Late output of the first training is still attributed to the first training.
+		_, err = store.Add("session", "msg-3", "request-1", output_capture.StreamOutput, streamContent("done"))
+		Expect(err).To(BeNil())
+
+		records, err := store.Query(&output_capture.Query{SessionId: "session", TrainingIndex: 0})
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].Text).To(Equal("epoch 1"))
+		Expect(records[1].Text).To(Equal("done"))
+
+		records, err = store.Query(&output_capture.Query{TrainingIndex: output_capture.AnyTrainingIndex, MsgId: "request-2"})
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].TrainingIndex).To(Equal(1))
+		Expect(records[0].Ename).To(Equal("ValueError"))
+		Expect(records[0].Traceback).To(Equal([]string{"line 1", "line 2"}))
+
+		records, err = store.Query(&output_capture.Query{TrainingIndex: output_capture.NoTrainingIndex})
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(1))
+		Expect(records[0].MsgId).To(Equal("startup"))
+	})
+
+	It("Will evict the oldest outputs and spill them to disk", func() {
+		store := output_capture.NewStore(output_capture.Config{MaxRecordsPerSession: 3, SpillDirectory: GinkgoT().TempDir()}, nil)
+		store.BeginTraining("session")
+
+		for i := 0; i < 5; i++ {
+			_, err := store.Add("session", fmt.Sprintf("msg-%d", i), "request", output_capture.StreamOutput, streamContent(fmt.Sprintf("%d", i)))
+			Expect(err).To(BeNil())
+		}
+
+		Expect(store.NumSpilled("session")).To(Equal(2))
+
+		query := &output_capture.Query{SessionId: "session", TrainingIndex: output_capture.AnyTrainingIndex}
+		records, err := store.Query(query)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(3))
+		Expect(records[0].MsgId).To(Equal("msg-2"))
+
+		Expect(store.Close()).To(Succeed())
+
+		query.IncludeSpilled = true
+		records, err = store.Query(query)
+		Expect(err).To(BeNil())
+		Expect(records).To(HaveLen(5))
+		for i, record := range records {
+			Expect(record.MsgId).To(Equal(fmt.Sprintf("msg-%d", i)))
+		}
+
+		_, err = store.Add("session", "late", "request", output_capture.StreamOutput, streamContent("late"))
+		Expect(err).To(MatchError(output_capture.ErrStoreClosed))
+	})
+})
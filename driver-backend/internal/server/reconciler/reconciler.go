@@ -0,0 +1,393 @@
+package reconciler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-colorable"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultGracePeriod is how long a session or kernel must remain orphaned before it is stopped, unless
+	// otherwise configured.
+	DefaultGracePeriod = time.Minute * 5
+
+	// SessionOrphan orphans are Jupyter sessions created by a workload that is no longer alive.
+	SessionOrphan = "session"
+	// KernelOrphan orphans are kernels listed by the Cluster Gateway that do not belong to any Jupyter session.
+	KernelOrphan = "kernel"
+
+	// PendingStatus orphans have not yet been orphaned for the full grace period.
+	PendingStatus = "pending"
+	// ReportedStatus orphans were orphaned for the full grace period, but were not stopped because of dry-run mode.
+	ReportedStatus = "reported"
+	// StoppedStatus orphans were stopped.
+	StoppedStatus = "stopped"
+	// FailedStatus orphans could not be stopped.
+	FailedStatus = "failed"
+
+	// unknownWorkloadId is the workload ID embedded in the sessions that were not created on behalf of a workload.
+	unknownWorkloadId = "N/A"
+)
+
+var (
+	ErrNoJupyterServers = errors.New("there are no Jupyter Servers to reconcile")
+)
+
+// JupyterServer is a Jupyter Server whose sessions and kernels are reconciled. It is implemented by
+// jupyter.ServerClient.
+type JupyterServer interface {
+	Address() string
+	ListSessions() ([]*jupyter.ServerSession, error)
+	DeleteSession(sessionId string) error
+	DeleteKernel(kernelId string) error
+}
+
+// WorkloadSource reports which workloads are alive, and where their sessions may have been created.
+type WorkloadSource interface {
+	// LiveWorkloadIds returns the IDs of the workloads that have not yet finished. The sessions of any other
+	// workload are orphaned.
+	LiveWorkloadIds() map[string]struct{}
+
+	// JupyterServers returns the Jupyter Servers on which workloads may have created sessions. The first
+	// JupyterServer is the default Jupyter Server, through which orphaned kernels without a session are stopped.
+	JupyterServers() []JupyterServer
+}
+
+// KernelLister lists the IDs of the kernels that are running on the cluster, such as via the Cluster Gateway.
+type KernelLister interface {
+	ListKernelIds() ([]string, error)
+}
+
+// Config configures a Reconciler.
+type Config struct {
+	// Interval is the time between two periodic reconciliations. If Interval is zero, then reconciliations
+	// are only performed on demand.
+	Interval time.Duration
+
+	// GracePeriod is how long a session or kernel must remain orphaned before it is stopped.
+	GracePeriod time.Duration
+
+	// DryRun instructs the Reconciler to only report orphaned sessions and kernels rather than stop them.
+	DryRun bool
+}
+
+// NewConfig returns the Config specified by the given domain.Configuration.
+func NewConfig(opts *domain.Configuration) Config {
+	config := Config{
+		Interval:    time.Second * time.Duration(opts.OrphanGcIntervalSec),
+		GracePeriod: DefaultGracePeriod,
+		DryRun:      opts.OrphanGcDryRun,
+	}
+
+	if opts.OrphanGcIntervalSec < 0 {
+		config.Interval = 0
+	}
+
+	if opts.OrphanGcGracePeriodSec > 0 {
+		config.GracePeriod = time.Second * time.Duration(opts.OrphanGcGracePeriodSec)
+	} else if opts.OrphanGcGracePeriodSec < 0 {
+		config.GracePeriod = 0
+	}
+
+	return config
+}
+
+// Orphan is a Jupyter session or kernel that does not belong to a live workload.
+type Orphan struct {
+	Kind                 string    `json:"kind"`
+	SessionId            string    `json:"session_id,omitempty"`
+	SessionName          string    `json:"session_name,omitempty"`
+	KernelId             string    `json:"kernel_id,omitempty"`
+	WorkloadId           string    `json:"workload_id,omitempty"`
+	JupyterServerAddress string    `json:"jupyter_server_address"`
+	FirstSeen            time.Time `json:"first_seen"`
+	Status               string    `json:"status"`
+	Error                string    `json:"error,omitempty"`
+}
+
+// key uniquely identifies the Orphan across reconciliations.
+func (o *Orphan) key() string {
+	if o.Kind == SessionOrphan {
+		return fmt.Sprintf("%s/%s/%s", o.Kind, o.JupyterServerAddress, o.SessionId)
+	}
+
+	return fmt.Sprintf("%s/%s", o.Kind, o.KernelId)
+}
+
+// Report is the outcome of a single reconciliation.
+type Report struct {
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	DryRun      bool      `json:"dry_run"`
+
+	NumSessionsInspected int       `json:"num_sessions_inspected"`
+	NumKernelsInspected  int       `json:"num_kernels_inspected"`
+	Orphans              []*Orphan `json:"orphans"`
+
+	// Errors are the errors that prevented part of the reconciliation, such as an unreachable Jupyter Server.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Reconciler periodically matches the Jupyter sessions and the kernels running on the cluster against the live
+// workloads and reports or stops those that are orphaned, such as after the backend crashed or a workload was
+// aborted.
+//
+// Sessions are attributed to workloads using the jupyter.WorkloadIdMetadataKey embedded in the request that
+// created them. Sessions that are not attributed to any workload are never considered orphaned. Kernels listed
+// by the KernelLister are orphaned if no session on any of the Jupyter Servers refers to them.
+type Reconciler struct {
+	logger *zap.Logger
+
+	config       Config
+	workloads    WorkloadSource
+	kernelLister KernelLister // kernelLister is optional.
+
+	// firstSeen is a map from the key of each Orphan to the time at which it was first found to be orphaned.
+	firstSeen  map[string]time.Time
+	lastReport *Report
+	mu         sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a new Reconciler. The KernelLister may be nil.
+func New(config Config, workloads WorkloadSource, kernelLister KernelLister, atom *zap.AtomicLevel) *Reconciler {
+	reconciler := &Reconciler{
+		config:       config,
+		workloads:    workloads,
+		kernelLister: kernelLister,
+		firstSeen:    make(map[string]time.Time),
+		stop:         make(chan struct{}),
+	}
+
+	zapConfig := zap.NewDevelopmentEncoderConfig()
+	zapConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zapConfig), zapcore.AddSync(colorable.NewColorableStdout()), atom)
+	logger := zap.New(core, zap.Development())
+	if logger == nil {
+		panic("failed to create logger for orphan reconciler")
+	}
+
+	reconciler.logger = logger
+
+	return reconciler
+}
+
+// Start starts the periodic reconciliations, unless the Config of the Reconciler disables them.
+func (r *Reconciler) Start() {
+	if r.config.Interval <= 0 {
+		r.logger.Debug("Periodic reconciliation of orphaned sessions and kernels is disabled.")
+		return
+	}
+
+	r.logger.Debug("Starting periodic reconciliation of orphaned sessions and kernels.",
+		zap.Duration("interval", r.config.Interval),
+		zap.Duration("grace_period", r.config.GracePeriod),
+		zap.Bool("dry_run", r.config.DryRun))
+
+	go func() {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.Reconcile(r.config.DryRun)
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic reconciliations.
+func (r *Reconciler) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+// DryRun returns true if the Reconciler is configured to only report orphaned sessions and kernels.
+func (r *Reconciler) DryRun() bool {
+	return r.config.DryRun
+}
+
+// LastReport returns the Report of the most recent reconciliation, or nil if no reconciliation has been performed.
+func (r *Reconciler) LastReport() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lastReport
+}
+
+// Reconcile performs a single reconciliation. Orphans that have been orphaned for the full grace period are
+// stopped, unless dryRun is true.
+func (r *Reconciler) Reconcile(dryRun bool) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		StartedAt: time.Now(),
+		DryRun:    dryRun,
+		Orphans:   make([]*Orphan, 0),
+	}
+
+	servers := r.workloads.JupyterServers()
+	if len(servers) == 0 {
+		report.Errors = append(report.Errors, ErrNoJupyterServers.Error())
+	}
+
+	liveWorkloadIds := r.workloads.LiveWorkloadIds()
+
+	// referencedKernelIds are the kernels referred to by any session, orphaned or not.
+	referencedKernelIds := make(map[string]struct{})
+	orphans := make([]*Orphan, 0)
+	for _, server := range servers {
+		sessions, err := server.ListSessions()
+		if err != nil {
+			r.logger.Warn("Failed to list sessions of Jupyter Server.", zap.String("address", server.Address()), zap.Error(err))
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to list sessions of Jupyter Server \"%s\": %v", server.Address(), err))
+			continue
+		}
+
+		report.NumSessionsInspected += len(sessions)
+		for _, session := range sessions {
+			kernelId := ""
+			if session.Kernel != nil {
+				kernelId = session.Kernel.Id
+				referencedKernelIds[kernelId] = struct{}{}
+			}
+
+			if session.WorkloadId == "" || session.WorkloadId == unknownWorkloadId {
+				continue
+			}
+
+			if _, live := liveWorkloadIds[session.WorkloadId]; live {
+				continue
+			}
+
+			orphans = append(orphans, &Orphan{
+				Kind:                 SessionOrphan,
+				SessionId:            session.Id,
+				SessionName:          session.Name,
+				KernelId:             kernelId,
+				WorkloadId:           session.WorkloadId,
+				JupyterServerAddress: server.Address(),
+			})
+		}
+	}
+
+	// Only look for kernels without a session if every Jupyter Server could be listed, as the kernels of the
+	// sessions of an unreachable Jupyter Server would otherwise be mistaken for orphans.
+	if r.kernelLister != nil && len(servers) > 0 && len(report.Errors) == 0 {
+		kernelIds, err := r.kernelLister.ListKernelIds()
+		if err != nil {
+			r.logger.Warn("Failed to list kernels running on the cluster.", zap.Error(err))
+			report.Errors = append(report.Errors, fmt.Sprintf("failed to list kernels: %v", err))
+		}
+
+		report.NumKernelsInspected = len(kernelIds)
+		for _, kernelId := range kernelIds {
+			if _, referenced := referencedKernelIds[kernelId]; referenced {
+				continue
+			}
+
+			orphans = append(orphans, &Orphan{
+				Kind:                 KernelOrphan,
+				KernelId:             kernelId,
+				JupyterServerAddress: servers[0].Address(),
+			})
+		}
+	}
+
+	r.handleOrphans(orphans, servers, dryRun, report.StartedAt)
+
+	report.Orphans = orphans
+	report.CompletedAt = time.Now()
+	r.lastReport = report
+
+	if len(orphans) > 0 {
+		r.logger.Info("Reconciled orphaned sessions and kernels.",
+			zap.Int("num_orphans", len(orphans)),
+			zap.Bool("dry_run", dryRun),
+			zap.Duration("duration", report.CompletedAt.Sub(report.StartedAt)))
+	}
+
+	return report
+}
+
+// handleOrphans stops the orphans that have been orphaned for the full grace period, unless dryRun is true.
+// Sessions and kernels that are no longer orphaned are forgotten.
+func (r *Reconciler) handleOrphans(orphans []*Orphan, servers []JupyterServer, dryRun bool, now time.Time) {
+	serversByAddress := make(map[string]JupyterServer, len(servers))
+	for _, server := range servers {
+		serversByAddress[server.Address()] = server
+	}
+
+	stillOrphaned := make(map[string]time.Time, len(orphans))
+	for _, orphan := range orphans {
+		key := orphan.key()
+
+		firstSeen, loaded := r.firstSeen[key]
+		if !loaded {
+			firstSeen = now
+		}
+		orphan.FirstSeen = firstSeen
+
+		if now.Sub(firstSeen) < r.config.GracePeriod {
+			orphan.Status = PendingStatus
+			stillOrphaned[key] = firstSeen
+			continue
+		}
+
+		if dryRun {
+			r.logger.Info("Found orphan. Not stopping it because of dry-run mode.",
+				zap.String("kind", orphan.Kind),
+				zap.String("session_id", orphan.SessionId),
+				zap.String("kernel_id", orphan.KernelId),
+				zap.String("workload_id", orphan.WorkloadId))
+			orphan.Status = ReportedStatus
+			stillOrphaned[key] = firstSeen
+			continue
+		}
+
+		server := serversByAddress[orphan.JupyterServerAddress]
+
+		var err error
+		if orphan.Kind == SessionOrphan {
+			err = server.DeleteSession(orphan.SessionId)
+		} else {
+			err = server.DeleteKernel(orphan.KernelId)
+		}
+
+		if err != nil {
+			r.logger.Warn("Failed to stop orphan.",
+				zap.String("kind", orphan.Kind),
+				zap.String("session_id", orphan.SessionId),
+				zap.String("kernel_id", orphan.KernelId),
+				zap.String("workload_id", orphan.WorkloadId),
+				zap.Error(err))
+			orphan.Status = FailedStatus
+			orphan.Error = err.Error()
+			stillOrphaned[key] = firstSeen
+			continue
+		}
+
+		r.logger.Info("Stopped orphan.",
+			zap.String("kind", orphan.Kind),
+			zap.String("session_id", orphan.SessionId),
+			zap.String("kernel_id", orphan.KernelId),
+			zap.String("workload_id", orphan.WorkloadId))
+		orphan.Status = StoppedStatus
+	}
+
+	r.firstSeen = stillOrphaned
+}
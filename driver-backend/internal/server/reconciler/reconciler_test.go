@@ -0,0 +1,164 @@
+package reconciler_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/reconciler"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+)
+
+type fakeJupyterServer struct {
+	address         string
+	sessions        []*jupyter.ServerSession
+	listErr         error
+	deletedSessions []string
+	deletedKernels  []string
+}
+
+func (s *fakeJupyterServer) Address() string {
+	return s.address
+}
+
+func (s *fakeJupyterServer) ListSessions() ([]*jupyter.ServerSession, error) {
+	return s.sessions, s.listErr
+}
+
+func (s *fakeJupyterServer) DeleteSession(sessionId string) error {
+	s.deletedSessions = append(s.deletedSessions, sessionId)
+	return nil
+}
+
+func (s *fakeJupyterServer) DeleteKernel(kernelId string) error {
+	s.deletedKernels = append(s.deletedKernels, kernelId)
+	return nil
+}
+
+type fakeWorkloadSource struct {
+	live    map[string]struct{}
+	servers []reconciler.JupyterServer
+}
+
+func (s *fakeWorkloadSource) LiveWorkloadIds() map[string]struct{} {
+	return s.live
+}
+
+func (s *fakeWorkloadSource) JupyterServers() []reconciler.JupyterServer {
+	return s.servers
+}
+
+type fakeKernelLister []string
+
+func (l fakeKernelLister) ListKernelIds() ([]string, error) {
+	return l, nil
+}
+
+func newSession(id string, kernelId string, workloadId string) *jupyter.ServerSession {
+	return &jupyter.ServerSession{Id: id, Name: id, Kernel: &jupyter.ServerKernel{Id: kernelId}, WorkloadId: workloadId}
+}
+
+var _ = Describe("Orphan Reconciler Tests", func() {
+	var (
+		atom    zap.AtomicLevel
+		server  *fakeJupyterServer
+		source  *fakeWorkloadSource
+		kernels fakeKernelLister
+	)
+
+	BeforeEach(func() {
+		atom = zap.NewAtomicLevelAt(zap.InfoLevel)
+		server = &fakeJupyterServer{
+			address: "localhost:8888",
+			sessions: []*jupyter.ServerSession{
+				newSession("live-session", "kernel-1", "live-workload"),
+				newSession("orphaned-session", "kernel-2", "aborted-workload"),
+				newSession("user-session", "kernel-3", "N/A"),
+			},
+		}
+		source = &fakeWorkloadSource{
+			live:    map[string]struct{}{"live-workload": {}},
+			servers: []reconciler.JupyterServer{server},
+		}
+		kernels = fakeKernelLister{"kernel-1", "kernel-2", "kernel-3", "kernel-4"}
+	})
+
+	It("Will only report the sessions of dead workloads and the kernels without a session", func() {
+		r := reconciler.New(reconciler.Config{GracePeriod: 0}, source, kernels, &atom)
+
+		report := r.Reconcile(true)
+		Expect(report.Errors).To(BeEmpty())
+		Expect(report.NumSessionsInspected).To(Equal(3))
+		Expect(report.NumKernelsInspected).To(Equal(4))
+		Expect(report.Orphans).To(HaveLen(2))
+
+		Expect(report.Orphans[0].Kind).To(Equal(reconciler.SessionOrphan))
+		Expect(report.Orphans[0].SessionId).To(Equal("orphaned-session"))
+		Expect(report.Orphans[0].WorkloadId).To(Equal("aborted-workload"))
+		Expect(report.Orphans[0].Status).To(Equal(reconciler.ReportedStatus))
+
+		Expect(report.Orphans[1].Kind).To(Equal(reconciler.KernelOrphan))
+		Expect(report.Orphans[1].KernelId).To(Equal("kernel-4"))
+		Expect(report.Orphans[1].Status).To(Equal(reconciler.ReportedStatus))
+
+		Expect(server.deletedSessions).To(BeEmpty())
+		Expect(server.deletedKernels).To(BeEmpty())
+		Expect(r.LastReport()).To(Equal(report))
+	})
+
+	It("Will only stop orphans once they have been orphaned for the grace period", func() {
+		r := reconciler.New(reconciler.Config{GracePeriod: time.Millisecond * 50}, source, kernels, &atom)
+
+		report := r.Reconcile(false)
+		Expect(report.Orphans).To(HaveLen(2))
+		for _, orphan := range report.Orphans {
+			Expect(orphan.Status).To(Equal(reconciler.PendingStatus))
+		}
+		Expect(server.deletedSessions).To(BeEmpty())
+
+		time.Sleep(time.Millisecond * 75)
+
+		report = r.Reconcile(false)
+		Expect(report.Orphans).To(HaveLen(2))
+		for _, orphan := range report.Orphans {
+			Expect(orphan.Status).To(Equal(reconciler.StoppedStatus))
+		}
+		Expect(server.deletedSessions).To(Equal([]string{"orphaned-session"}))
+		Expect(server.deletedKernels).To(Equal([]string{"kernel-4"}))
+	})
+
+	It("Will restart the grace period of a session whose workload came back to life", func() {
+		r := reconciler.New(reconciler.Config{GracePeriod: time.Millisecond * 50}, source, nil, &atom)
+
+		Expect(r.Reconcile(false).Orphans).To(HaveLen(1))
+
+		source.live["aborted-workload"] = struct{}{}
+		Expect(r.Reconcile(false).Orphans).To(BeEmpty())
+
+		time.Sleep(time.Millisecond * 75)
+		delete(source.live, "aborted-workload")
+
+		report := r.Reconcile(false)
+		Expect(report.Orphans).To(HaveLen(1))
+		Expect(report.Orphans[0].Status).To(Equal(reconciler.PendingStatus))
+		Expect(server.deletedSessions).To(BeEmpty())
+	})
+
+	It("Will not look for kernels without a session if a Jupyter Server could not be listed", func() {
+		unreachable := &fakeJupyterServer{address: "localhost:9999", listErr: errors.New("connection refused")}
+		source.servers = append(source.servers, unreachable)
+
+		r := reconciler.New(reconciler.Config{GracePeriod: 0}, source, kernels, &atom)
+
+		report := r.Reconcile(false)
+		Expect(report.Errors).To(HaveLen(1))
+		Expect(report.NumKernelsInspected).To(Equal(0))
+		Expect(report.Orphans).To(HaveLen(1))
+		Expect(report.Orphans[0].Kind).To(Equal(reconciler.SessionOrphan))
+		Expect(server.deletedSessions).To(Equal([]string{"orphaned-session"}))
+		Expect(server.deletedKernels).To(BeEmpty())
+	})
+})
@@ -23,8 +23,12 @@ import (
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/auth"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/concurrent_websocket"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/handlers"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/kernel_console"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/proxy"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/reconciler"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/workload"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -72,6 +76,12 @@ type serverImpl struct {
 	// workloadManager is responsible for managing workloads submitted to the server for execution/orchestration.
 	workloadManager *workload.BasicWorkloadManager
 
+	// eventBus delivers workload lifecycle events to the configured webhooks, file sink, and other subscribers.
+	eventBus *events.Bus
+
+	// orphanReconciler reports or stops the Jupyter sessions and kernels that outlived their workload.
+	orphanReconciler *reconciler.Reconciler
+
 	// nodeHandler is responsible for handling HTTP GET and HTTP PATCH requests for the nodes within the cluster.
 	//
 	// Initially, nodeHandler returns HTTP 503 "Service Unavailable" for all requests.
@@ -153,6 +163,17 @@ func NewServer(opts *domain.Configuration) domain.Server {
 	s.logger = logger
 	s.sugaredLogger = logger.Sugar()
 
+	// Re-create the Prometheus metrics using the histogram buckets specified in the configuration.
+	if errs := metrics.InitializePrometheusMetrics(opts, &atom); len(errs) > 0 {
+		s.logger.Error("Error(s) encountered while initializing Prometheus metrics.", zap.Errors("errors", errs))
+	}
+
+	eventBus, err := events.NewBusFromConfig(opts, &atom)
+	if err != nil {
+		s.logger.Error("Error(s) encountered while creating workload lifecycle event subscribers.", zap.Error(err))
+	}
+	s.eventBus = eventBus
+
 	expectedOriginAddresses := strings.Split(opts.ExpectedOriginAddresses, ",")
 	for _, addr := range expectedOriginAddresses {
 		var expectedOrigin string
@@ -168,6 +189,8 @@ func NewServer(opts *domain.Configuration) domain.Server {
 	// TODO: Getting nil pointer exception because the callback occurs in the constructor, so s.gatewayRpcClient is still nil.
 	s.gatewayRpcClient = handlers.NewClusterDashboardHandler(s.opts, true, true, s.SendNotification, s.handleRpcRegistrationComplete)
 
+	s.orphanReconciler = reconciler.New(reconciler.NewConfig(opts), s.workloadManager, s.gatewayRpcClient, &atom)
+
 	if err := s.setupRoutes(); err != nil {
 		panic(err)
 	}
@@ -220,6 +243,20 @@ func (s *serverImpl) GetSchedulingPolicy() (string, bool) {
 	return policy, true
 }
 
+// PublishWorkloadEvent publishes a workload lifecycle event to the event bus.
+func (s *serverImpl) PublishWorkloadEvent(event *events.Event) {
+	s.eventBus.Publish(event)
+}
+
+// IsConnectedToGateway returns true if the dashboard backend is currently connected to the Cluster Gateway.
+func (s *serverImpl) IsConnectedToGateway() bool {
+	if s.gatewayRpcClient == nil {
+		return false
+	}
+
+	return s.gatewayRpcClient.ConnectedToGateway()
+}
+
 func (s *serverImpl) RefreshAndClearClusterStatistics(update bool, clear bool) (*workload.ClusterStatistics, error) {
 	if clear {
 		return s.clearClusterStatistics()
@@ -529,6 +566,10 @@ func (s *serverImpl) setupRoutes() error {
 		webSocketGroup.GET(domain.WorkloadEndpoint, s.workloadManager.GetWorkloadWebsocketHandler())
 		webSocketGroup.GET(domain.LogsEndpoint, s.serveLogWebsocket)
 		webSocketGroup.GET(domain.GeneralWebsocketEndpoint, s.serveGeneralWebsocket)
+
+		// The kernel console can send messages to kernels, including "execute_request" messages, and so it
+		// requires the same authentication as the API. Browsers pass the token via the "token" query parameter.
+		webSocketGroup.GET(domain.KernelConsoleEndpoint, authMiddleware.MiddlewareFunc(), s.serveKernelConsoleWebsocket)
 	}
 
 	s.sugaredLogger.Debugf("Creating route groups now. (gatewayRpcClient == nil: %v)", s.gatewayRpcClient == nil)
@@ -597,13 +638,32 @@ func (s *serverImpl) setupRoutes() error {
 		apiGroup.GET(path.Join(domain.VariablesEndpoint, ":variable_name"), handlers.NewVariablesHttpHandler(s.opts, s.gatewayRpcClient, &atom).HandleRequest)
 
 		// Used by the frontend to tell a kernel to stop training.
-		apiGroup.POST(domain.StopTrainingEndpoint, handlers.NewStopTrainingHandler(s.opts, s.atom).HandleRequest)
+		stopTrainingHandler, err := handlers.NewStopTrainingHandler(s.opts, s.atom)
+		if err != nil {
+			return err
+		}
+		apiGroup.POST(domain.StopTrainingEndpoint, stopTrainingHandler.HandleRequest)
 
 		clusterStatisticsHttpHandler := handlers.NewClusterStatisticsHttpHandler(s.opts, s.gatewayRpcClient, s.atom)
 		apiGroup.DELETE(domain.ClusterStatisticsEndpoint, clusterStatisticsHttpHandler.HandleDeleteRequest)
 
 		apiGroup.GET(domain.WorkloadStatisticsEndpoint, s.handleWorkloadStatisticsRequest)
 
+		// Used to inspect and manipulate the event queues of running workloads.
+		eventQueueHttpHandler := handlers.NewEventQueueHttpHandler(s.opts, s.workloadManager, s.atom)
+		apiGroup.GET(domain.EventQueueEndpoint, eventQueueHttpHandler.HandleRequest)
+		apiGroup.POST(path.Join(domain.EventQueueEndpoint, "events"), eventQueueHttpHandler.HandleInjectRequest)
+		apiGroup.PATCH(path.Join(domain.EventQueueEndpoint, "events"), eventQueueHttpHandler.HandlePatchRequest)
+		apiGroup.DELETE(path.Join(domain.EventQueueEndpoint, "events"), eventQueueHttpHandler.HandleDeleteRequest)
+		apiGroup.PATCH(path.Join(domain.EventQueueEndpoint, "sessions"), eventQueueHttpHandler.HandleShiftSessionRequest)
+
+		// Used to query the captured outputs of the trainings of workloads.
+		apiGroup.GET(domain.OutputCaptureEndpoint, handlers.NewOutputCaptureHttpHandler(s.opts, s.workloadManager, s.atom).HandleRequest)
+
+		orphansHttpHandler := handlers.NewOrphansHttpHandler(s.opts, s.orphanReconciler, s.atom)
+		apiGroup.GET(domain.OrphansEndpoint, orphansHttpHandler.HandleRequest)
+		apiGroup.POST(domain.OrphansEndpoint, orphansHttpHandler.HandleReconcileRequest)
+
 		apiGroup.GET(domain.ClusterStatisticsEndpoint, clusterStatisticsHttpHandler.HandleRequest)
 
 		// Used by the frontend to upload/share Prometheus metrics.
@@ -716,6 +776,8 @@ func (s *serverImpl) HandleCriticalWorkloadError(workloadId string, err error) {
 		zap.String("workload_id", workloadId),
 		zap.Error(err))
 
+	s.PublishWorkloadEvent(events.NewEvent(events.WorkloadCriticalError, workloadId, s.getWorkloadName(workloadId)).WithError(err))
+
 	s.SendNotification(&proto.Notification{
 		Title:            fmt.Sprintf("Critical Error Occurred in Workload \"%s\"", workloadId),
 		Message:          err.Error(),
@@ -724,6 +786,16 @@ func (s *serverImpl) HandleCriticalWorkloadError(workloadId string, err error) {
 	})
 }
 
+// getWorkloadName returns the name of the specified workload, or the empty string if there is no such workload.
+func (s *serverImpl) getWorkloadName(workloadId string) string {
+	driver := s.workloadManager.GetWorkloadDriver(workloadId)
+	if driver == nil || driver.GetWorkload() == nil {
+		return ""
+	}
+
+	return driver.GetWorkload().WorkloadName()
+}
+
 // HandlePrometheusRequest passes the request directly to the http.Handler returned by promhttp.Handler.
 func (s *serverImpl) HandlePrometheusRequest(c *gin.Context) {
 	s.prometheusHandler.ServeHTTP(c.Writer, c.Request)
@@ -880,6 +952,71 @@ func (s *serverImpl) serveGeneralWebsocket(c *gin.Context) {
 	}
 }
 
+// serveKernelConsoleWebsocket attaches a kernel_console.Console to the kernels of the workload specified by the
+// "workload_id" query parameter and streams their messages to the websocket.
+//
+// The kernels can be narrowed down using one or more "session_id" query parameters. The initial filter is specified
+// using the "msg_type" and "channel" query parameters, each of which may be repeated.
+func (s *serverImpl) serveKernelConsoleWebsocket(c *gin.Context) {
+	workloadId := c.Query("workload_id")
+	if workloadId == "" {
+		s.logger.Error("Kernel console request did not specify a workload.")
+		_ = c.AbortWithError(http.StatusBadRequest, fmt.Errorf("request must specify a workload ID"))
+		return
+	}
+
+	driver := s.workloadManager.GetWorkloadDriver(workloadId)
+	if driver == nil {
+		s.logger.Error("Unknown workload specified for kernel console.", zap.String("workload_id", workloadId))
+		_ = c.AbortWithError(http.StatusNotFound, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId))
+		return
+	}
+
+	filter := &kernel_console.Filter{
+		MessageTypes: c.QueryArray("msg_type"),
+		Channels:     c.QueryArray("channel"),
+	}
+
+	upgrader.CheckOrigin = func(r *http.Request) bool {
+		incomingOrigin := r.Header.Get("Origin")
+		for _, expectedOrigin := range s.expectedOriginAddresses {
+			if incomingOrigin == expectedOrigin {
+				return true
+			}
+		}
+
+		s.logger.Error("Incoming kernel console WebSocket connection had unexpected origin. Rejecting.",
+			zap.String("request-origin", c.Request.Header.Get("Origin")),
+			zap.String("request-host", c.Request.Host), zap.String("request-uri", c.Request.RequestURI))
+		return false
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		s.logger.Error("Failed to upgrade WebSocket connection.", zap.Error(err))
+		return
+	}
+	defer func(conn *websocket.Conn) {
+		err := conn.Close()
+		if err != nil {
+			s.logger.Error("Failed to close WebSocket connection.", zap.Error(err))
+		}
+	}(conn)
+
+	var concurrentConn domain.ConcurrentWebSocket = concurrent_websocket.NewConcurrentWebSocket(conn)
+	console, err := kernel_console.New(concurrentConn, driver.KernelConnections(c.QueryArray("session_id")...),
+		filter, s.opts.KernelConsoleAllowExecute, s.logger)
+	if err != nil {
+		s.logger.Error("Failed to create kernel console.", zap.String("workload_id", workloadId), zap.Error(err))
+		_ = concurrentConn.WriteJSON(&kernel_console.Frame{Type: kernel_console.ErrorFrame, Error: err.Error(), Timestamp: time.Now()})
+		return
+	}
+
+	s.logger.Debug("Serving kernel console.", zap.String("workload_id", workloadId),
+		zap.String("remote-address", concurrentConn.RemoteAddr().String()))
+	console.Serve()
+}
+
 func (s *serverImpl) serveLogWebsocket(c *gin.Context) {
 	s.logger.Debug("Inspecting origin of incoming log-related WebSocket connection.",
 		zap.String("request-origin", c.Request.Header.Get("Origin")),
@@ -1082,6 +1219,9 @@ func (s *serverImpl) Serve() error {
 	var wg sync.WaitGroup
 	wg.Add(3)
 
+	s.orphanReconciler.Start()
+	defer s.orphanReconciler.Stop()
+
 	s.serveHttp(&wg)
 	s.serveJupyterWebSocketProxy(&wg)
 
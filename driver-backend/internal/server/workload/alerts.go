@@ -0,0 +1,125 @@
+package workload
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/alerting"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
+	"go.uber.org/zap"
+)
+
+// recordSessionFailedTicks updates the maximum number of ticks that any one session failed to finish processing
+// its events within, which is used as an input to the alert rules.
+func (d *BasicWorkloadDriver) recordSessionFailedTicks(numFailedTicks int) {
+	for {
+		current := d.maxSessionFailedTicks.Load()
+		if int32(numFailedTicks) <= current || d.maxSessionFailedTicks.CompareAndSwap(current, int32(numFailedTicks)) {
+			return
+		}
+	}
+}
+
+// alertSignals returns the current value of each of the signals that alert rules may be defined against.
+func (d *BasicWorkloadDriver) alertSignals() map[string]float64 {
+	signals := map[string]float64{
+		alerting.SignalLongTicks:                  float64(d.numLongTicks.Load()),
+		alerting.SignalMaxSessionFailedTicks:      float64(d.maxSessionFailedTicks.Load()),
+		alerting.SignalTrainingStartLatencyMillis: float64(d.lastTrainingStartLatencyMillis.Load()),
+		alerting.SignalTrainingStartTimeouts:      float64(d.numTrainingStartTimeouts.Load()),
+		alerting.SignalEventQueueDepth:            float64(d.eventQueue.Len()),
+	}
+
+	if d.isConnectedToGateway != nil {
+		if d.isConnectedToGateway() {
+			signals[alerting.SignalGatewayDisconnected] = 0
+		} else {
+			signals[alerting.SignalGatewayDisconnected] = 1
+		}
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		signals[alerting.SignalSessionDelays] = float64(stats.NumTimesSessionDelayedResourceContention)
+		signals[alerting.SignalAggregateSessionDelayMillis] = float64(stats.AggregateSessionDelayMillis)
+		signals[alerting.SignalNumDiscardedSessions] = float64(stats.NumDiscardedSessions)
+		signals[alerting.SignalNumActiveTrainings] = float64(stats.NumActiveTrainings)
+	})
+
+	return signals
+}
+
+// evaluateAlertRules evaluates the configured alert rules against the workload's statistics and live state,
+// handling any alerts that fire.
+func (d *BasicWorkloadDriver) evaluateAlertRules() {
+	if d.alertEngine == nil || d.alertEngine.NumRules() == 0 {
+		return
+	}
+
+	alerts := d.alertEngine.Evaluate(d.workload.GetId(), d.workload.WorkloadName(), d.alertSignals())
+	for _, alert := range alerts {
+		d.handleAlert(alert)
+	}
+}
+
+// handleAlert notifies the frontend and the alert webhook (if one is configured) of a fired alert before
+// performing the alert's associated action.
+func (d *BasicWorkloadDriver) handleAlert(alert *alerting.Alert) {
+	d.logger.Warn("Alert rule fired.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String("rule", alert.RuleName),
+		zap.String("signal", alert.Signal),
+		zap.Float64("value", alert.Value),
+		zap.Float64("threshold", alert.Threshold),
+		zap.String("action", alert.Action.String()))
+
+	notificationType := domain.WarningNotification
+	if alert.Severity == alerting.SeverityError {
+		notificationType = domain.ErrorNotification
+	}
+
+	if d.notifyCallback != nil {
+		d.notifyCallback(&proto.Notification{
+			Id:               uuid.NewString(),
+			Title:            alert.Title(),
+			Message:          alert.Message(),
+			Panicked:         false,
+			NotificationType: notificationType.Int32(),
+		})
+	}
+
+	if d.alertWebhook != nil {
+		go func() {
+			if err := d.alertWebhook.Notify(alert); err != nil {
+				d.logger.Error("Failed to post alert to webhook.",
+					zap.String("workload_id", alert.WorkloadId),
+					zap.String("rule", alert.RuleName),
+					zap.Error(err))
+			}
+		}()
+	}
+
+	switch alert.Action {
+	case alerting.ActionPause:
+		{
+			err := d.PauseWorkload()
+			if err != nil && !errors.Is(err, ErrWorkloadAlreadyPaused) {
+				d.logger.Error("Failed to pause workload in response to alert.",
+					zap.String("workload_id", d.workload.GetId()),
+					zap.String("workload_name", d.workload.WorkloadName()),
+					zap.String("rule", alert.RuleName),
+					zap.Error(err))
+			}
+		}
+	case alerting.ActionAbort:
+		{
+			err := fmt.Errorf("%w: \"%s\"", ErrWorkloadAbortedByAlert, alert.RuleName)
+			d.errorChan <- err
+			if d.onCriticalErrorOccurred != nil {
+				go d.onCriticalErrorOccurred(d.workload.GetId(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package workload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// billing computes the costs of the sessions of a workload under a domain.BillingModel.
+type billing struct {
+	model    *domain.BillingModel
+	sessions map[string]*billedSession // sessions is a map from internal session ID to billedSession.
+	mu       sync.Mutex
+}
+
+// billedSession is the billing state of one session.
+type billedSession struct {
+	resources domain.BilledResources
+	startedAt time.Time
+
+	training          domain.BilledResources // training are the resources of the current training, if any.
+	trainingStartedAt time.Time              // trainingStartedAt is the zero time if the session is not training.
+	trainingTime      time.Duration          // trainingTime is the total duration of the session's completed trainings.
+
+	cost *SessionCost
+}
+
+// costDelta is the cost that a session incurred during one billing interval.
+type costDelta struct {
+	userCostUSD         float64
+	userCredits         float64
+	faasUserCostUSD     float64
+	dataTransferCostUSD float64
+	faasProviderCostUSD float64
+}
+
+// add adds the costDelta to the given SessionCost.
+func (c costDelta) add(cost *SessionCost) {
+	cost.UserCostUSD += c.userCostUSD
+	cost.UserCostResourceCredits += c.userCredits
+	cost.FaasUserCostUSD += c.faasUserCostUSD
+	cost.DataTransferCostUSD += c.dataTransferCostUSD
+	cost.FaasProviderCostUSD += c.faasProviderCostUSD
+}
+
+// configureBilling creates the billing of the workload from the billing options of the driver's
+// domain.Configuration. configureBilling must be called after the workload is assigned to the driver.
+func (d *BasicWorkloadDriver) configureBilling(model *domain.BillingModel) {
+	d.billing = &billing{
+		model:    model,
+		sessions: make(map[string]*billedSession),
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.SessionCosts = make(map[string]*SessionCost)
+	})
+
+	d.logger.Debug("Configured billing of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String("serverful_cost_model", model.ServerfulCostModel),
+		zap.String("faas_cost_model", model.FaasCostModel),
+		zap.Bool("use_resource_credits", model.UseResourceCredits))
+}
+
+// billSessionStarted starts billing the specified session for the resources that it requested.
+func (d *BasicWorkloadDriver) billSessionStarted(internalSessionId string, meta domain.SessionMetadata) {
+	if d.billing == nil {
+		return
+	}
+
+	cost := &SessionCost{
+		SessionId: internalSessionId,
+		Username:  d.usernameOf(internalSessionId),
+	}
+
+	d.billing.mu.Lock()
+	d.billing.sessions[internalSessionId] = &billedSession{
+		resources: domain.BilledResources{
+			Gpus:     meta.GetMaxSessionGPUs(),
+			Cpus:     meta.GetMaxSessionCPUs(),
+			MemoryGB: meta.GetMaxSessionMemory(),
+		},
+		startedAt: d.clockTime.GetClockTime(),
+		cost:      cost,
+	}
+	d.billing.mu.Unlock()
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.SessionCosts[internalSessionId] = cost
+	})
+}
+
+// billTrainingStarted starts billing the specified session for the training of the given 'training-started' event.
+func (d *BasicWorkloadDriver) billTrainingStarted(evt *domain.Event, internalSessionId string) {
+	if d.billing == nil {
+		return
+	}
+
+	meta := evt.Data.(domain.SessionMetadata)
+
+	d.billing.mu.Lock()
+	defer d.billing.mu.Unlock()
+
+	session, loaded := d.billing.sessions[internalSessionId]
+	if !loaded {
+		return
+	}
+
+	session.training = domain.BilledResources{
+		Gpus:     meta.GetCurrentTrainingMaxGPUs(),
+		Cpus:     meta.GetCurrentTrainingMaxCPUs(),
+		MemoryGB: meta.GetCurrentTrainingMaxMemory(),
+	}
+	session.trainingStartedAt = d.clockTime.GetClockTime()
+}
+
+// billTrainingStopped stops billing the specified session for its current training. If the training completed, then
+// the session is charged for the training; otherwise, the training is discarded, as it will be submitted again.
+func (d *BasicWorkloadDriver) billTrainingStopped(internalSessionId string, completed bool) {
+	if d.billing == nil {
+		return
+	}
+
+	d.billing.mu.Lock()
+	session, loaded := d.billing.sessions[internalSessionId]
+	if !loaded || session.trainingStartedAt.IsZero() {
+		d.billing.mu.Unlock()
+		return
+	}
+
+	duration := max(d.clockTime.GetClockTime().Sub(session.trainingStartedAt), 0)
+	session.trainingStartedAt = time.Time{}
+
+	if !completed {
+		d.billing.mu.Unlock()
+		return
+	}
+
+	session.trainingTime += duration
+
+	model := d.billing.model
+	var delta costDelta
+	delta.userCostUSD, delta.userCredits = model.UserSessionCost(session.resources, duration, duration)
+	delta.faasUserCostUSD = model.UserFaasCost(session.training, duration)
+	delta.dataTransferCostUSD = model.DataTransferCost(session.training)
+	delta.faasProviderCostUSD = model.ProviderFaasCost(session.training, duration)
+	d.billing.mu.Unlock()
+
+	d.chargeSession(session, func(cost *SessionCost) {
+		cost.NumTrainings += 1
+		cost.TrainingTimeSec += duration.Seconds()
+	}, delta)
+}
+
+// billSessionStopped stops billing the specified session, charging it for the time that it was not training.
+func (d *BasicWorkloadDriver) billSessionStopped(internalSessionId string) {
+	if d.billing == nil {
+		return
+	}
+
+	d.billing.mu.Lock()
+	session, loaded := d.billing.sessions[internalSessionId]
+	if !loaded {
+		d.billing.mu.Unlock()
+		return
+	}
+	delete(d.billing.sessions, internalSessionId)
+
+	lifetime := max(d.clockTime.GetClockTime().Sub(session.startedAt), session.trainingTime)
+
+	// The trainings of the session were already charged when they completed, so only the idle time remains.
+	var delta costDelta
+	delta.userCostUSD, delta.userCredits = d.billing.model.UserSessionCost(session.resources, lifetime-session.trainingTime, 0)
+	d.billing.mu.Unlock()
+
+	d.chargeSession(session, func(cost *SessionCost) {
+		cost.LifetimeSec = lifetime.Seconds()
+	}, delta)
+}
+
+// chargeSession applies the given function to the SessionCost of the given session, and it adds the given costDelta
+// to the costs of the workload and of the user that owns the session.
+func (d *BasicWorkloadDriver) chargeSession(session *billedSession, f func(cost *SessionCost), delta costDelta) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		f(session.cost)
+		delta.add(session.cost)
+
+		stats.UserCostUSD += delta.userCostUSD
+		stats.UserCostResourceCredits += delta.userCredits
+		stats.FaasUserCostUSD += delta.faasUserCostUSD
+		stats.DataTransferCostUSD += delta.dataTransferCostUSD
+		stats.FaasProviderCostUSD += delta.faasProviderCostUSD
+	})
+
+	if d.tenancy == nil {
+		return
+	}
+
+	d.updateTenantStatistics(session.cost.Username, func(user *tenant) {
+		user.stats.CostUSD += delta.userCostUSD + delta.dataTransferCostUSD
+		user.stats.CostResourceCredits += delta.userCredits
+		user.stats.FaasCostUSD += delta.faasUserCostUSD
+	})
+}
+
+// billProvider updates the serverful cost of the provider from the host lifetimes of the given ClusterStatistics.
+func (d *BasicWorkloadDriver) billProvider(clusterStatistics *ClusterStatistics) {
+	if d.billing == nil || clusterStatistics == nil {
+		return
+	}
+
+	aggregateHostLifetime := time.Duration(clusterStatistics.AggregateHostLifetime * float64(time.Second))
+	providerCost := d.billing.model.ProviderServerfulCost(aggregateHostLifetime)
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.ProviderCostUSD = providerCost
+	})
+}
@@ -2,6 +2,7 @@ package workload
 
 import (
 	"github.com/mattn/go-colorable"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -16,6 +17,7 @@ type Builder struct {
 	debugLoggingEnabled       bool
 	timescaleAdjustmentFactor float64
 	sessionsSamplePercentage  float64
+	sessionSampling           *domain.SessionSamplingConfig
 	remoteStorageDefinition   *proto.RemoteStorageDefinition
 	atom                      *zap.AtomicLevel
 }
@@ -67,6 +69,12 @@ func (b *Builder) SetSessionsSamplePercentage(percentage float64) *Builder {
 	return b
 }
 
+// SetSessionSampling sets the strategy with which sessions are sampled.
+func (b *Builder) SetSessionSampling(config *domain.SessionSamplingConfig) *Builder {
+	b.sessionSampling = config
+	return b
+}
+
 // SetRemoteStorageDefinition sets the remote storage definition.
 func (b *Builder) SetRemoteStorageDefinition(def *proto.RemoteStorageDefinition) *Builder {
 	b.remoteStorageDefinition = def
@@ -92,7 +100,9 @@ func (b *Builder) Build() *BasicWorkload {
 		SampledSessions:           make(map[string]interface{}),
 		UnsampledSessions:         make(map[string]interface{}),
 		Statistics:                NewStatistics(b.sessionsSamplePercentage),
+		sessionSampler:            domain.NewSessionSampler(b.sessionSampling, b.sessionsSamplePercentage, b.seed),
 	}
+	workload.Statistics.SessionSampling = workload.sessionSampler.Config()
 
 	zapConfig := zap.NewDevelopmentEncoderConfig()
 	zapConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
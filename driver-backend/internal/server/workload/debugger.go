@@ -0,0 +1,600 @@
+package workload
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/event_queue"
+	"go.uber.org/zap"
+)
+
+const (
+	stepModeNone  debugStepMode = iota // stepModeNone indicates that the workload is not being stepped.
+	stepModeTick                       // stepModeTick pauses the workload again once the current tick completes.
+	stepModeEvent                      // stepModeEvent holds every event that is not covered by an event permit.
+)
+
+const (
+	// DefaultNumUpcomingEvents is the number of upcoming events returned by InspectWorkload if no number is specified.
+	DefaultNumUpcomingEvents = 50
+
+	// PauseReasonStep is the pause reason reported when the workload is paused after being stepped.
+	PauseReasonStep = "step"
+)
+
+type debugStepMode int
+
+// HeldEvent is an event that the debugger is holding (i.e., not letting the driver process) until it is released.
+type HeldEvent struct {
+	EventId   string    `json:"event_id"`
+	SessionId string    `json:"session_id"`
+	EventName string    `json:"event_name"`
+	Timestamp time.Time `json:"timestamp"`
+	HeldAt    time.Time `json:"held_at"`
+	Reason    string    `json:"reason"`
+
+	globalIndex uint64
+	released    bool
+}
+
+// SessionInspection describes the state of a single session of a workload at the time it was inspected.
+type SessionInspection struct {
+	SessionId          string              `json:"session_id"`
+	State              domain.SessionState `json:"state"`
+	TrainingsCompleted int                 `json:"trainings_completed"`
+	NumFailedTicks     int                 `json:"num_failed_ticks"`
+	CreatedAt          time.Time           `json:"created_at"`
+	TrainingStartedAt  time.Time           `json:"training_started_at"`
+}
+
+// PendingKernelRequest is a request sent to a kernel for which the driver is still awaiting a response.
+type PendingKernelRequest struct {
+	SessionId   string    `json:"session_id"`
+	RequestType string    `json:"request_type"`
+	AwaitingFor string    `json:"awaiting_for"` // AwaitingFor is the response or notification that the driver is waiting for.
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// WorkloadInspection is a snapshot of the internal state of a paused workload.
+type WorkloadInspection struct {
+	WorkloadId            string                             `json:"workload_id"`
+	WorkloadName          string                             `json:"workload_name"`
+	WorkloadState         string                             `json:"workload_state"`
+	CurrentTick           int64                              `json:"current_tick"`
+	CurrentTickTimestamp  time.Time                          `json:"current_tick_timestamp"`
+	PauseReason           string                             `json:"pause_reason"`
+	HeldEvents            []*HeldEvent                       `json:"held_events"`
+	Breakpoints           []*domain.Breakpoint               `json:"breakpoints"`
+	UpcomingEvents        []*event_queue.QueuedEvent         `json:"upcoming_events"`
+	SessionQueues         []*event_queue.SessionQueueSummary `json:"session_queues"`
+	Sessions              []*SessionInspection               `json:"sessions"`
+	PendingKernelRequests []*PendingKernelRequest            `json:"pending_kernel_requests"`
+}
+
+// debugger maintains the breakpoints and step-debugging state of a BasicWorkloadDriver.
+type debugger struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	breakpoints  []*domain.Breakpoint                    // Registered breakpoints, in the order in which they were added.
+	stepMode     debugStepMode                           // How the workload is presently being stepped.
+	eventPermits int                                     // Number of events that may be processed before events are held again in stepModeEvent.
+	heldEvents   []*HeldEvent                            // Events that are presently being held.
+	conditions   map[domain.BreakpointCondition]struct{} // Conditions that have occurred during the current tick.
+	pauseReason  string                                  // Why the debugger most recently paused the workload.
+}
+
+func newDebugger() *debugger {
+	dbg := &debugger{
+		breakpoints: make([]*domain.Breakpoint, 0),
+		heldEvents:  make([]*HeldEvent, 0),
+		conditions:  make(map[domain.BreakpointCondition]struct{}),
+	}
+
+	dbg.cond = sync.NewCond(&dbg.mu)
+
+	return dbg
+}
+
+// unsafeHit records that the given breakpoint was hit, removing it if it is a one-shot breakpoint.
+// Returns the reason to report for the resulting pause.
+func (dbg *debugger) unsafeHit(bp *domain.Breakpoint) string {
+	bp.HitCount += 1
+
+	if bp.OneShot {
+		dbg.unsafeRemove(bp.Id)
+	}
+
+	return fmt.Sprintf("breakpoint %s (%s)", bp.Id, bp.Kind)
+}
+
+func (dbg *debugger) unsafeRemove(id string) bool {
+	for i, bp := range dbg.breakpoints {
+		if bp.Id == id {
+			dbg.breakpoints = append(dbg.breakpoints[:i], dbg.breakpoints[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// unsafeRelease releases the given held events and wakes up the goroutines that are waiting on them.
+func (dbg *debugger) unsafeRelease(held ...*HeldEvent) {
+	for _, heldEvent := range held {
+		heldEvent.released = true
+
+		for i, other := range dbg.heldEvents {
+			if other == heldEvent {
+				dbg.heldEvents = append(dbg.heldEvents[:i], dbg.heldEvents[i+1:]...)
+				break
+			}
+		}
+	}
+
+	dbg.cond.Broadcast()
+}
+
+// releaseAll releases all held events and stops stepping.
+func (dbg *debugger) releaseAll() {
+	dbg.mu.Lock()
+	defer dbg.mu.Unlock()
+
+	dbg.stepMode = stepModeNone
+	dbg.eventPermits = 0
+	dbg.unsafeRelease(append([]*HeldEvent(nil), dbg.heldEvents...)...)
+}
+
+// numHeldEvents returns the number of events presently being held.
+func (dbg *debugger) numHeldEvents() int {
+	dbg.mu.Lock()
+	defer dbg.mu.Unlock()
+
+	return len(dbg.heldEvents)
+}
+
+// conditionOccurred records that the given condition occurred during the current tick.
+func (dbg *debugger) conditionOccurred(condition domain.BreakpointCondition) {
+	dbg.mu.Lock()
+	defer dbg.mu.Unlock()
+
+	dbg.conditions[condition] = struct{}{}
+}
+
+// AddBreakpoint registers a breakpoint with the workload. If the breakpoint does not have an ID, then one is
+// assigned to it. Returns a copy of the registered breakpoint.
+func (d *BasicWorkloadDriver) AddBreakpoint(breakpoint *domain.Breakpoint) (*domain.Breakpoint, error) {
+	if breakpoint == nil {
+		return nil, fmt.Errorf("%w: breakpoint is nil", domain.ErrInvalidBreakpoint)
+	}
+
+	if err := breakpoint.Validate(); err != nil {
+		return nil, err
+	}
+
+	bp := *breakpoint
+	if bp.Id == "" {
+		bp.Id = uuid.NewString()
+	}
+	bp.HitCount = 0
+
+	d.debugger.mu.Lock()
+	defer d.debugger.mu.Unlock()
+
+	d.debugger.unsafeRemove(bp.Id) // Re-adding a breakpoint replaces it.
+	d.debugger.breakpoints = append(d.debugger.breakpoints, &bp)
+
+	d.logger.Debug("Added breakpoint.",
+		zap.String("workload_id", d.id),
+		zap.String("breakpoint_id", bp.Id),
+		zap.String("breakpoint_kind", bp.Kind.String()))
+
+	copied := bp
+	return &copied, nil
+}
+
+// RemoveBreakpoint removes the specified breakpoint from the workload.
+func (d *BasicWorkloadDriver) RemoveBreakpoint(breakpointId string) error {
+	d.debugger.mu.Lock()
+	defer d.debugger.mu.Unlock()
+
+	if !d.debugger.unsafeRemove(breakpointId) {
+		return fmt.Errorf("%w: \"%s\"", domain.ErrBreakpointNotFound, breakpointId)
+	}
+
+	return nil
+}
+
+// Breakpoints returns copies of the breakpoints registered with the workload.
+func (d *BasicWorkloadDriver) Breakpoints() []*domain.Breakpoint {
+	d.debugger.mu.Lock()
+	defer d.debugger.mu.Unlock()
+
+	return d.debugger.unsafeBreakpoints()
+}
+
+func (dbg *debugger) unsafeBreakpoints() []*domain.Breakpoint {
+	breakpoints := make([]*domain.Breakpoint, 0, len(dbg.breakpoints))
+	for _, bp := range dbg.breakpoints {
+		copied := *bp
+		breakpoints = append(breakpoints, &copied)
+	}
+
+	return breakpoints
+}
+
+// isSuspended returns true if the workload is paused or if the debugger is holding at least one event.
+func (d *BasicWorkloadDriver) isSuspended() bool {
+	d.pauseMutex.Lock()
+	paused := d.paused
+	d.pauseMutex.Unlock()
+
+	return paused || d.debugger.numHeldEvents() > 0
+}
+
+// StepTick processes exactly one tick of a paused workload, after which the workload is paused again.
+//
+// If the debugger is holding events within the current tick, then they are released, and the workload is
+// paused again once the remainder of the current tick has been processed.
+func (d *BasicWorkloadDriver) StepTick() error {
+	if !d.isSuspended() {
+		return domain.ErrWorkloadNotPaused
+	}
+
+	d.logger.Debug("Stepping workload by one tick.", zap.String("workload_id", d.id))
+
+	d.debugger.mu.Lock()
+	d.debugger.stepMode = stepModeTick
+	d.debugger.eventPermits = 0
+	d.debugger.unsafeRelease(append([]*HeldEvent(nil), d.debugger.heldEvents...)...)
+	d.debugger.mu.Unlock()
+
+	d.resumeFromDebugger()
+	return nil
+}
+
+// StepEvent processes exactly one event of a paused workload, after which the workload is paused again.
+//
+// If the debugger is holding events, then the earliest held event is released. Otherwise, the workload resumes
+// until it has processed a single event, and the event after that is held.
+func (d *BasicWorkloadDriver) StepEvent() error {
+	if !d.isSuspended() {
+		return domain.ErrWorkloadNotPaused
+	}
+
+	d.debugger.mu.Lock()
+	d.debugger.stepMode = stepModeEvent
+
+	if len(d.debugger.heldEvents) > 0 {
+		earliest := d.debugger.heldEvents[0]
+		for _, heldEvent := range d.debugger.heldEvents[1:] {
+			if heldEvent.Timestamp.Before(earliest.Timestamp) ||
+				(heldEvent.Timestamp.Equal(earliest.Timestamp) && heldEvent.globalIndex < earliest.globalIndex) {
+				earliest = heldEvent
+			}
+		}
+
+		d.logger.Debug("Stepping workload by one event. Releasing held event.",
+			zap.String("workload_id", d.id),
+			zap.String("event_id", earliest.EventId),
+			zap.String("event_name", earliest.EventName),
+			zap.String("session_id", earliest.SessionId))
+
+		d.debugger.unsafeRelease(earliest)
+		d.debugger.mu.Unlock()
+		return nil
+	}
+
+	d.logger.Debug("Stepping workload by one event.", zap.String("workload_id", d.id))
+	d.debugger.eventPermits = 1
+	d.debugger.mu.Unlock()
+
+	d.resumeFromDebugger()
+	return nil
+}
+
+// ContinueWorkload stops stepping a paused workload, releases any held events, and resumes the workload.
+// The workload will run until it is paused again or until a breakpoint is hit.
+func (d *BasicWorkloadDriver) ContinueWorkload() error {
+	if !d.isSuspended() {
+		return domain.ErrWorkloadNotPaused
+	}
+
+	d.logger.Debug("Continuing workload.", zap.String("workload_id", d.id))
+
+	d.debugger.releaseAll()
+	d.resumeFromDebugger()
+	return nil
+}
+
+// resumeFromDebugger unpauses the workload if it is paused.
+func (d *BasicWorkloadDriver) resumeFromDebugger() {
+	d.pauseMutex.Lock()
+	defer d.pauseMutex.Unlock()
+
+	if !d.paused {
+		return
+	}
+
+	d.paused = false
+
+	// If the clock goroutine never observed the pause, then handlePause will not transition the workload
+	// back to the running state, so we do it here instead.
+	if d.workload.IsPausing() {
+		_ = d.workload.Unpause()
+	}
+
+	d.pauseCond.Broadcast()
+}
+
+// pauseForDebugger pauses the workload (if it isn't already paused) on behalf of the debugger.
+func (d *BasicWorkloadDriver) pauseForDebugger(reason string) {
+	d.debugger.mu.Lock()
+	d.debugger.pauseReason = reason
+	d.debugger.mu.Unlock()
+
+	d.pauseMutex.Lock()
+	defer d.pauseMutex.Unlock()
+
+	if d.paused || !d.workload.IsRunning() {
+		return
+	}
+
+	d.logger.Debug("Debugger is pausing workload.",
+		zap.String("workload_id", d.id),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String("reason", reason))
+
+	d.paused = true
+	if err := d.workload.SetPausing(); err != nil {
+		d.logger.Error("Failed to transition workload to 'pausing' state.",
+			zap.String("workload_id", d.id),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.Error(err))
+	}
+
+	if reason != PauseReasonStep && d.notifyCallback != nil {
+		d.notifyCallback(&proto.Notification{
+			Id:               uuid.NewString(),
+			Title:            fmt.Sprintf("Workload %s Hit a Breakpoint", d.workload.WorkloadName()),
+			Message:          fmt.Sprintf("Workload %s (ID=%s) was paused by %s.", d.workload.WorkloadName(), d.id, reason),
+			Panicked:         false,
+			NotificationType: domain.InfoNotification.Int32(),
+		})
+	}
+}
+
+// checkTickBreakpoints is called before the specified tick is issued. If a tick breakpoint matches the tick,
+// then the workload is paused, and checkTickBreakpoints returns true.
+func (d *BasicWorkloadDriver) checkTickBreakpoints(tickNumber int64) bool {
+	d.debugger.mu.Lock()
+
+	reason := ""
+	for _, bp := range d.debugger.breakpoints {
+		if bp.MatchesTick(tickNumber) {
+			reason = d.debugger.unsafeHit(bp)
+			break
+		}
+	}
+
+	d.debugger.mu.Unlock()
+
+	if reason == "" {
+		return false
+	}
+
+	d.pauseForDebugger(reason)
+	return true
+}
+
+// checkBreakpointsAfterTick is called after a tick has been processed. It pauses the workload if we're stepping
+// tick-by-tick or if the condition of a condition breakpoint occurred during the tick.
+func (d *BasicWorkloadDriver) checkBreakpointsAfterTick() {
+	d.debugger.mu.Lock()
+
+	reason := ""
+	if d.debugger.stepMode == stepModeTick {
+		d.debugger.stepMode = stepModeNone
+		reason = PauseReasonStep
+	}
+
+	for _, bp := range d.debugger.breakpoints {
+		if bp.Kind != domain.BreakpointOnCondition {
+			continue
+		}
+
+		if _, occurred := d.debugger.conditions[bp.Condition]; occurred {
+			reason = d.debugger.unsafeHit(bp)
+			break
+		}
+	}
+
+	clear(d.debugger.conditions)
+	d.debugger.mu.Unlock()
+
+	if reason != "" {
+		d.pauseForDebugger(reason)
+	}
+}
+
+// gateEvent is called before the given event is processed. If the event matches an event breakpoint, or if
+// we're stepping event-by-event, then gateEvent pauses the workload and blocks until the event is released.
+func (d *BasicWorkloadDriver) gateEvent(evt *domain.Event) {
+	dbg := d.debugger
+	dbg.mu.Lock()
+
+	reason := ""
+	for _, bp := range dbg.breakpoints {
+		if bp.MatchesEvent(evt.SessionID(), evt.Name.String()) {
+			reason = dbg.unsafeHit(bp)
+			break
+		}
+	}
+
+	if reason == "" && dbg.stepMode == stepModeEvent {
+		if dbg.eventPermits > 0 {
+			dbg.eventPermits -= 1
+		} else {
+			reason = PauseReasonStep
+		}
+	}
+
+	if reason == "" {
+		dbg.mu.Unlock()
+		return
+	}
+
+	heldEvent := &HeldEvent{
+		EventId:     evt.Id(),
+		SessionId:   evt.SessionID(),
+		EventName:   evt.Name.String(),
+		Timestamp:   evt.Timestamp,
+		HeldAt:      time.Now(),
+		Reason:      reason,
+		globalIndex: evt.GlobalEventIndex(),
+	}
+	dbg.heldEvents = append(dbg.heldEvents, heldEvent)
+	dbg.mu.Unlock()
+
+	d.logger.Debug("Debugger is holding event.",
+		zap.String("workload_id", d.id),
+		zap.String("event_id", heldEvent.EventId),
+		zap.String("event_name", heldEvent.EventName),
+		zap.String("session_id", heldEvent.SessionId),
+		zap.String("reason", reason))
+
+	d.pauseForDebugger(reason)
+
+	dbg.mu.Lock()
+	for !heldEvent.released {
+		dbg.cond.Wait()
+	}
+	dbg.mu.Unlock()
+}
+
+// InspectWorkload returns a snapshot of the internal state of the workload, including the contents of the
+// event queue, the state of each session, and the requests for which we're awaiting a response from a kernel.
+//
+// The workload must be paused (or the debugger must be holding at least one event).
+func (d *BasicWorkloadDriver) InspectWorkload(numUpcomingEvents int) (*WorkloadInspection, error) {
+	if !d.isSuspended() {
+		return nil, domain.ErrWorkloadNotPaused
+	}
+
+	if numUpcomingEvents <= 0 {
+		numUpcomingEvents = DefaultNumUpcomingEvents
+	}
+
+	upcomingEvents, err := d.eventQueue.UpcomingEvents("", numUpcomingEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTick := d.currentTick.GetClockTime()
+	inspection := &WorkloadInspection{
+		WorkloadId:            d.workload.GetId(),
+		WorkloadName:          d.workload.WorkloadName(),
+		WorkloadState:         d.workload.GetState().String(),
+		CurrentTick:           d.convertTimestampToTickNumber(currentTick),
+		CurrentTickTimestamp:  currentTick,
+		UpcomingEvents:        upcomingEvents,
+		SessionQueues:         d.eventQueue.SessionQueueSummaries(),
+		Sessions:              d.inspectSessions(),
+		PendingKernelRequests: d.pendingKernelRequests(),
+	}
+
+	d.debugger.mu.Lock()
+	inspection.PauseReason = d.debugger.pauseReason
+	inspection.Breakpoints = d.debugger.unsafeBreakpoints()
+	inspection.HeldEvents = make([]*HeldEvent, 0, len(d.debugger.heldEvents))
+	for _, heldEvent := range d.debugger.heldEvents {
+		copied := *heldEvent
+		inspection.HeldEvents = append(inspection.HeldEvents, &copied)
+	}
+	d.debugger.mu.Unlock()
+
+	return inspection, nil
+}
+
+// inspectSessions returns a SessionInspection for each of the workload's sessions, sorted by session ID.
+func (d *BasicWorkloadDriver) inspectSessions() []*SessionInspection {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sessions := make([]*SessionInspection, 0, d.sessions.Len())
+	for keyValue := range d.sessions.Iter() {
+		session := keyValue.Value.(Session)
+
+		// The state and trainings of the session are maintained by the workload, whereas the failed ticks of the
+		// session are recorded by the driver.
+		inspection, loaded := d.workload.InspectSession(session.GetId())
+		if !loaded {
+			inspection = &SessionInspection{
+				SessionId: session.GetId(),
+				State:     session.GetState(),
+				CreatedAt: session.GetCreatedAt(),
+			}
+		}
+
+		inspection.NumFailedTicks = session.NumFailedTicks()
+		sessions = append(sessions, inspection)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].SessionId < sessions[j].SessionId
+	})
+
+	return sessions
+}
+
+// pendingKernelRequests returns the "execute_request" messages for which we're still awaiting either the
+// notification that the training has started or the "execute_reply" indicating that the training has ended.
+func (d *BasicWorkloadDriver) pendingKernelRequests() []*PendingKernelRequest {
+	submittedAt := func(sessionId string) time.Time {
+		val, ok := d.trainingSubmittedTimes.Get(sessionId)
+		if !ok {
+			return time.Time{}
+		}
+
+		return time.UnixMilli(val.(int64))
+	}
+
+	pending := make([]*PendingKernelRequest, 0)
+	awaitingStart := make(map[string]struct{})
+
+	d.trainingStartedChannelMutex.Lock()
+	for sessionId := range d.trainingStartedChannels {
+		awaitingStart[sessionId] = struct{}{}
+		pending = append(pending, &PendingKernelRequest{
+			SessionId:   sessionId,
+			RequestType: "execute_request",
+			AwaitingFor: "training_started",
+			SubmittedAt: submittedAt(sessionId),
+		})
+	}
+	d.trainingStartedChannelMutex.Unlock()
+
+	for _, session := range d.inspectSessions() {
+		if _, loaded := awaitingStart[session.SessionId]; loaded || session.State != domain.SessionTraining {
+			continue
+		}
+
+		pending = append(pending, &PendingKernelRequest{
+			SessionId:   session.SessionId,
+			RequestType: "execute_request",
+			AwaitingFor: "execute_reply",
+			SubmittedAt: submittedAt(session.SessionId),
+		})
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].SessionId < pending[j].SessionId
+	})
+
+	return pending
+}
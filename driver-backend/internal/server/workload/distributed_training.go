@@ -0,0 +1,171 @@
+package workload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+// distributedTrainings keeps track of the gang-scheduled distributed trainings of a workload.
+type distributedTrainings struct {
+	config *domain.DistributedTrainingConfig
+	gangs  map[string]*gang // gangs is a map from internal session ID to the session's submitted, not-yet-started gang.
+	mu     sync.Mutex
+}
+
+// gang is a distributed training whose workers have not all started yet.
+type gang struct {
+	training       *domain.DistributedTraining
+	workersStarted map[int]struct{} // workersStarted are the ranks of the workers that have started.
+	firstStartedAt int64            // firstStartedAt is the unix millisecond timestamp at which the first worker started.
+}
+
+// configureDistributedTraining configures which trainings of the workload are distributed as specified by the given
+// domain.DistributedTrainingConfig, which may be nil. configureDistributedTraining must be called after the workload
+// is assigned to the driver.
+func (d *BasicWorkloadDriver) configureDistributedTraining(config *domain.DistributedTrainingConfig) {
+	if config == nil && d.workloadPreset != nil {
+		config = d.workloadPreset.GetDistributedTraining()
+	}
+
+	d.distributedTrainings = &distributedTrainings{
+		config: config,
+		gangs:  make(map[string]*gang),
+	}
+
+	d.logger.Debug("Configured distributed trainings of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.Any("distributed_training", config))
+}
+
+// trainingDistribution returns the distributed training of the training of the given 'training-started' event, which
+// is specified by the workload template, if any, or otherwise by the DistributedTrainingConfig of the workload.
+// trainingDistribution returns nil if the training is not distributed.
+func (d *BasicWorkloadDriver) trainingDistribution(evt *domain.Event, internalSessionId string) *domain.DistributedTraining {
+	if d.distributedTrainings == nil {
+		return nil
+	}
+
+	if trainingEvent := d.templateTrainingEvent(evt); trainingEvent != nil && trainingEvent.Distributed != nil {
+		return trainingEvent.Distributed
+	}
+
+	meta := evt.Data.(domain.SessionMetadata)
+	return d.distributedTrainings.config.DistributedTrainingOf(internalSessionId, meta.GetCurrentTrainingMaxGPUs())
+}
+
+// gangTrainingSubmitted begins waiting for the workers of the training of the given 'training-started' event to
+// start, if the training is a gang-scheduled distributed training.
+func (d *BasicWorkloadDriver) gangTrainingSubmitted(evt *domain.Event, internalSessionId string) {
+	training := d.trainingDistribution(evt, internalSessionId)
+	if !training.IsGang() {
+		return
+	}
+
+	d.distributedTrainings.mu.Lock()
+	d.distributedTrainings.gangs[internalSessionId] = &gang{
+		training:       training,
+		workersStarted: make(map[int]struct{}, training.WorldSize),
+	}
+	d.distributedTrainings.mu.Unlock()
+
+	d.logger.Debug("Submitted distributed training.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Int("world_size", training.WorldSize),
+		zap.Int("gpus_per_worker", training.GpusPerWorker),
+		zap.String("colocation", training.GetColocation()))
+}
+
+// gangWorkerStarted records that a worker of the current training of the specified session started, as reported by
+// the given "smr_lead_task" IOPub message. Each worker of a distributed training sends its own "smr_lead_task"
+// message, which identifies the worker by its "gang_rank"; messages without a rank are counted as distinct workers.
+//
+// gangWorkerStarted returns true if the training is now running, which is the case once every worker of a distributed
+// training has started and immediately for other trainings. For distributed trainings, gangWorkerStarted also returns
+// the gang-wait time, which is the delay between the start of the first and the last worker.
+func (d *BasicWorkloadDriver) gangWorkerStarted(internalSessionId string, kernelMessage jupyter.KernelMessage) (bool, time.Duration) {
+	if d.distributedTrainings == nil {
+		return true, 0
+	}
+
+	startedAt := time.Now().UnixMilli()
+	rank := -1
+	if content, ok := kernelMessage.GetContent().(map[string]interface{}); ok {
+		if val, ok := content["msg_created_at_unix_milliseconds"].(float64); ok {
+			startedAt = int64(val)
+		}
+
+		if val, ok := content["gang_rank"].(float64); ok {
+			rank = int(val)
+		}
+	}
+
+	d.distributedTrainings.mu.Lock()
+	g, loaded := d.distributedTrainings.gangs[internalSessionId]
+	if !loaded {
+		d.distributedTrainings.mu.Unlock()
+		return true, 0
+	}
+
+	if rank < 0 {
+		rank = len(g.workersStarted)
+	}
+
+	if len(g.workersStarted) == 0 {
+		g.firstStartedAt = startedAt
+	}
+	g.workersStarted[rank] = struct{}{}
+
+	numStarted := len(g.workersStarted)
+	if numStarted < g.training.WorldSize {
+		d.distributedTrainings.mu.Unlock()
+
+		d.logger.Debug("Worker of distributed training started. Waiting for the other workers.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Int("gang_rank", rank),
+			zap.Int("workers_started", numStarted),
+			zap.Int("world_size", g.training.WorldSize))
+
+		return false, 0
+	}
+
+	delete(d.distributedTrainings.gangs, internalSessionId)
+	d.distributedTrainings.mu.Unlock()
+
+	gangWait := time.Duration(max(startedAt-g.firstStartedAt, 0)) * time.Millisecond
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.NumDistributedTrainings += 1
+		stats.GangWaitTimesMillis = append(stats.GangWaitTimesMillis, gangWait.Milliseconds())
+		stats.CumulativeGangWaitTimeMillis += gangWait.Milliseconds()
+	})
+
+	d.logger.Debug("All workers of distributed training started.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Int("world_size", g.training.WorldSize),
+		zap.Duration("gang_wait", gangWait))
+
+	return true, gangWait
+}
+
+// gangTrainingStopped stops waiting for the workers of the current training of the specified session to start,
+// as the training failed to start or the session stopped.
+func (d *BasicWorkloadDriver) gangTrainingStopped(internalSessionId string) {
+	if d.distributedTrainings == nil {
+		return
+	}
+
+	d.distributedTrainings.mu.Lock()
+	delete(d.distributedTrainings.gangs, internalSessionId)
+	d.distributedTrainings.mu.Unlock()
+}
@@ -5,9 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/output_capture"
 	"github.com/scusemua/workload-driver-react/m/v2/pkg/statistics"
 	"github.com/shopspring/decimal"
 	"github.com/zhangjyr/gocsv"
@@ -16,7 +16,6 @@ import (
 	"math/rand"
 	"net/http"
 	"os"
-	"path"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -32,8 +31,10 @@ import (
 	"github.com/mgutz/ansi"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/generator"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/alerting"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/clock"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/event_queue"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
 	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
 	"github.com/zhangjyr/hashmap"
 	"go.uber.org/zap"
@@ -51,7 +52,7 @@ const (
 	ZapTraceSessionIDKey    = "trace-session-id"
 
 	// AnyGPU is used by ResourceRequest structs when they do not require/request a specific GPU.
-	AnyGPU = "ANY_GPU"
+	AnyGPU = domain.AnyGpuType
 
 	// TrainingCode is the code executed by kernels to simulate GPU training.
 	// TODO: Figure out a good way to do this, such as via a library like:
@@ -90,6 +91,7 @@ var (
 	ErrInvalidTemplateFileSpecified        = errors.New("invalid template file path specified")
 	ErrTrainingFailed                      = errors.New("training event could not be processed")
 	ErrKernelCreationFailed                = errors.New("failed to create kernel")
+	ErrWorkloadAbortedByAlert              = errors.New("workload was aborted by an alert rule")
 
 	ErrNoSessionConnection = errors.New("received 'training-started' or 'training-ended' event for session for which no session connection exists")
 	ErrNoKernelConnection  = errors.New("received 'training-started' or 'training-ended' event for session for which no kernel connection exists")
@@ -124,6 +126,10 @@ type InternalWorkload interface {
 	// SetState sets the State of the InternalWorkload.
 	SetState(State)
 
+	// RegisterOnStateChangeHandler registers a handler that is called whenever the InternalWorkload transitions
+	// from one State to another.
+	RegisterOnStateChangeHandler(handler StateChangeHandler)
+
 	// GetKind gets the Kind of InternalWorkload (TRACE, PRESET, or TEMPLATE).
 	GetKind() Kind
 
@@ -135,6 +141,21 @@ type InternalWorkload interface {
 
 	RecordSessionExecutionTime(sessionId string, execTimeMillis int64)
 
+	// GetSessionState returns the current domain.SessionState of the specified session, or false if there is no
+	// such session.
+	GetSessionState(sessionId string) (domain.SessionState, bool)
+
+	// GetSessionTrainingsCompleted returns the number of trainings that the specified session has completed, or
+	// false if there is no such session.
+	GetSessionTrainingsCompleted(sessionId string) (int, bool)
+
+	// InspectSession returns a SessionInspection of the specified session, or false if there is no such session.
+	InspectSession(sessionId string) (*SessionInspection, bool)
+
+	// RecordSessionGpuUtilization appends the given GpuUtilizationSample to the GPU utilization time series of the
+	// specified session.
+	RecordSessionGpuUtilization(sessionId string, sample *domain.GpuUtilizationSample)
+
 	getSessionTrainingEvent(sessionId string, trainingIndex int) *domain.TrainingEvent
 }
 
@@ -191,6 +212,8 @@ type BasicWorkloadDriver struct {
 	misbehavingSessions                map[string]interface{}                // Map from session ID to sessions for sessions whose events we did not finish processing in a previous tick.
 	misbehavingSessionsMutex           sync.Mutex                            // misbehavingSessionsMutex ensures atomic access to the misbehavingSessions
 	trainingStartedChannels            map[string]chan interface{}           // trainingStartedChannels are channels used to notify that training has started
+	notebookCellOutputs                map[string][]interface{}              // notebookCellOutputs are the outputs of the notebook cells that are currently executing. Keys are internal session IDs.
+	notebookCellOutputsMutex           sync.Mutex                            // notebookCellOutputsMutex ensures atomic access to the notebookCellOutputs
 	trainingStartedChannelMutex        sync.Mutex                            // trainingStartedChannelMutex ensures atomic access to the trainingStartedChannels
 	trainingStoppedChannels            map[string]chan interface{}           // trainingStartedChannels are channels used to notify that training has ended
 	trainingStoppedChannelsMutex       sync.Mutex                            // trainingStoppedChannelsMutex ensures atomic access to the trainingStoppedChannels
@@ -198,12 +221,50 @@ type BasicWorkloadDriver struct {
 	pauseMutex sync.Mutex
 	pauseCond  *sync.Cond
 
+	debugger *debugger // debugger maintains the breakpoints and step-debugging state of the workload.
+
+	injectedEventIndex atomic.Uint64 // injectedEventIndex is used to assign global indices to events injected into the event queue.
+
+	alertEngine                    *alerting.Engine          // alertEngine evaluates the configured alert rules against the workload. Nil if no alert rules are configured.
+	alertWebhook                   *alerting.WebhookNotifier // alertWebhook posts fired alerts to the configured webhook. Nil if no webhook is configured.
+	isConnectedToGateway           func() bool               // isConnectedToGateway returns true if the dashboard backend is connected to the Cluster Gateway.
+	numLongTicks                   atomic.Int32              // numLongTicks is the number of ticks that took notably longer than the moving average tick duration.
+	maxSessionFailedTicks          atomic.Int32              // maxSessionFailedTicks is the largest number of ticks that any one session failed to finish processing its events within.
+	numTrainingStartTimeouts       atomic.Int32              // numTrainingStartTimeouts is the number of times we timed-out waiting for a training to start.
+	lastTrainingStartLatencyMillis atomic.Int64              // lastTrainingStartLatencyMillis is the most recently-observed training start latency.
+
+	sessionRouter      *domain.SessionRouter    // sessionRouter assigns sessions to routes. Nil if the workload does not specify a domain.SessionRoutingTable.
+	routes             map[string]*sessionRoute // routes is a map from route name to route. Empty if sessionRouter is nil.
+	defaultRoute       *sessionRoute            // defaultRoute is the route of every session if sessionRouter is nil.
+	sessionRoutes      map[string]*sessionRoute // sessionRoutes is a map from internal session ID to the route of that session.
+	kernelRoutes       map[string]*sessionRoute // kernelRoutes is a map from kernel ID to the route of that kernel's session.
+	sessionRoutesMutex sync.Mutex               // sessionRoutesMutex ensures atomic access to the sessionRoutes and kernelRoutes
+
+	outputCapture *output_capture.Store // outputCapture holds the captured outputs of the trainings of each session.
+
+	gpuTypes             *gpuTypes             // gpuTypes keeps track of the GPU types required by the sessions and trainings of the workload.
+	distributedTrainings *distributedTrainings // distributedTrainings keeps track of the gang-scheduled distributed trainings of the workload.
+	sessionDependencies  *sessionDependencies  // sessionDependencies enforces the dependencies between sessions. Nil if no session depends on another.
+	interactiveCells     *interactiveCells     // interactiveCells generates the interactive cells of the sessions. Nil if no session executes them at a rate.
+	billing              *billing              // billing computes the costs of the sessions of the workload.
+	tenancy              *tenancy              // tenancy keeps track of the simulated users of the workload. Nil if the workload does not simulate users.
+
+	sessionBehavior      *domain.SessionBehavior  // sessionBehavior decides how delayed trainings affect the rest of their session.
+	transientDelays      map[string]time.Duration // transientDelays is a map from internal session ID to the delay to lift once the session's current training ends.
+	transientDelaysMutex sync.Mutex               // transientDelaysMutex ensures atomic access to the transientDelays
+
+	gpuUtilizationComms      map[string]string // gpuUtilizationComms is a map from internal session ID to the ID of the comm used to send GPU utilization updates to the session's kernel.
+	gpuUtilizationCommsMutex sync.Mutex        // gpuUtilizationCommsMutex ensures atomic access to the gpuUtilizationComms
+
 	// refreshClusterStatistics is used to fresh the ClusterStatistics from the Cluster Gateway.
 	refreshClusterStatistics ClusterStatisticsRefresher
 
 	// notifyCallback is a function used to send notifications related to this workload directly to the frontend.
 	notifyCallback func(notification *proto.Notification)
 
+	// publishEvent is a function used to publish lifecycle events related to this workload to the event bus.
+	publishEvent func(event *events.Event)
+
 	// onCriticalErrorOccurred is a handler that is called when a critical error occurs.
 	// The onCriticalErrorOccurred handler is called in its own goroutine.
 	onCriticalErrorOccurred domain.WorkloadErrorHandler
@@ -213,10 +274,10 @@ type BasicWorkloadDriver struct {
 	onNonCriticalErrorOccurred domain.WorkloadErrorHandler
 }
 
+// NewBasicWorkloadDriver creates a new BasicWorkloadDriver. An error is returned if the Jupyter Server client
+// cannot be configured with the authentication and TLS settings of the given domain.Configuration.
 func NewBasicWorkloadDriver(opts *domain.Configuration, performClockTicks bool, timescaleAdjustmentFactor float64,
-	websocket domain.ConcurrentWebSocket, atom *zap.AtomicLevel, callbackProvider CallbackProvider) *BasicWorkloadDriver {
-
-	jupyterAddress := path.Join(opts.InternalJupyterServerAddress, opts.JupyterServerBasePath)
+	websocket domain.ConcurrentWebSocket, atom *zap.AtomicLevel, callbackProvider CallbackProvider) (*BasicWorkloadDriver, error) {
 
 	driver := &BasicWorkloadDriver{
 		id:                                 GenerateWorkloadID(8),
@@ -231,6 +292,7 @@ func NewBasicWorkloadDriver(opts *domain.Configuration, performClockTicks bool,
 		misbehavingSessions:                make(map[string]interface{}),
 		atom:                               atom,
 		trainingStartedChannels:            make(map[string]chan interface{}),
+		notebookCellOutputs:                make(map[string][]interface{}),
 		trainingStoppedChannels:            make(map[string]chan interface{}),
 		targetTickDuration:                 time.Second * time.Duration(opts.TraceStep),
 		targetTickDurationSeconds:          opts.TraceStep,
@@ -238,6 +300,11 @@ func NewBasicWorkloadDriver(opts *domain.Configuration, performClockTicks bool,
 		tickDurationsAll:                   make([]time.Duration, 0),
 		driverTimescale:                    opts.DriverTimescale,
 		sessionConnections:                 make(map[string]*jupyter.SessionConnection),
+		sessionRoutes:                      make(map[string]*sessionRoute),
+		kernelRoutes:                       make(map[string]*sessionRoute),
+		transientDelays:                    make(map[string]time.Duration),
+		gpuUtilizationComms:                make(map[string]string),
+		sessionBehavior:                    domain.NewSessionBehavior(nil, 0),
 		performClockTicks:                  performClockTicks,
 		eventQueue:                         event_queue.NewEventQueue(atom),
 		trainingSubmittedTimes:             hashmap.New(100),
@@ -249,9 +316,12 @@ func NewBasicWorkloadDriver(opts *domain.Configuration, performClockTicks bool,
 		onCriticalErrorOccurred:            callbackProvider.HandleCriticalWorkloadError,
 		onNonCriticalErrorOccurred:         callbackProvider.HandleWorkloadError,
 		notifyCallback:                     callbackProvider.SendNotification,
+		publishEvent:                       callbackProvider.PublishWorkloadEvent,
 		refreshClusterStatistics:           callbackProvider.RefreshAndClearClusterStatistics,
 		getSchedulingPolicyCallback:        callbackProvider.GetSchedulingPolicy,
+		isConnectedToGateway:               callbackProvider.IsConnectedToGateway,
 		paused:                             false,
+		debugger:                           newDebugger(),
 	}
 
 	driver.pauseCond = sync.NewCond(&driver.pauseMutex)
@@ -283,16 +353,32 @@ func NewBasicWorkloadDriver(opts *domain.Configuration, performClockTicks bool,
 		driver.workloadPresets[preset.GetKey()] = preset
 	}
 
-	driver.kernelManager = jupyter.NewKernelSessionManager(jupyterAddress, true, atom, driver)
+	if opts.AlertRulesFilepath != "" {
+		rules, err := alerting.LoadRulesFromFile(opts.AlertRulesFilepath)
+		if err != nil {
+			driver.logger.Error("Error encountered while loading alert rules from file.",
+				zap.String("filepath", opts.AlertRulesFilepath), zap.Error(err))
+		} else {
+			driver.alertEngine = alerting.NewEngine(rules)
+		}
+	}
+
+	if opts.AlertWebhookUrl != "" {
+		driver.alertWebhook = alerting.NewWebhookNotifier(opts.AlertWebhookUrl, alerting.DefaultWebhookTimeout)
+	}
 
-	if driver.onNonCriticalErrorOccurred != nil {
-		driver.kernelManager.RegisterOnErrorHandler(func(sessionId string, kernelId string, err error) {
-			err = fmt.Errorf("error occurred for kernel=%s,session=%s: %w", kernelId, sessionId, err)
-			driver.onNonCriticalErrorOccurred(driver.id, err)
-		})
+	driver.kernelManager, err = NewKernelSessionManager(opts, atom, driver)
+	if err != nil {
+		driver.logger.Error("Error encountered while configuring Jupyter Server client.",
+			zap.String("jupyter_server_address", opts.InternalJupyterServerAddress), zap.Error(err))
+		return nil, err
 	}
 
-	return driver
+	driver.registerKernelManagerErrorHandler(driver.kernelManager)
+	driver.defaultRoute = driver.newDefaultSessionRoute()
+	driver.outputCapture = driver.newOutputCaptureStore()
+
+	return driver, nil
 }
 
 //// GetStatisticsFileOutputPath returns the path to the statistics CSV file.
@@ -451,6 +537,7 @@ func (d *BasicWorkloadDriver) createWorkloadFromPreset(workloadRegistrationReque
 		SetTimescaleAdjustmentFactor(workloadRegistrationRequest.TimescaleAdjustmentFactor).
 		SetRemoteStorageDefinition(workloadRegistrationRequest.RemoteStorageDefinition).
 		SetSessionsSamplePercentage(workloadRegistrationRequest.SessionsSamplePercentage).
+		SetSessionSampling(workloadRegistrationRequest.SessionSampling).
 		Build()
 
 	workloadFromPreset := NewWorkloadFromPreset(basicWorkload, d.workloadPreset)
@@ -563,6 +650,7 @@ func (d *BasicWorkloadDriver) createWorkloadFromTemplate(workloadRegistrationReq
 		SetTimescaleAdjustmentFactor(workloadRegistrationRequest.TimescaleAdjustmentFactor).
 		SetRemoteStorageDefinition(workloadRegistrationRequest.RemoteStorageDefinition).
 		SetSessionsSamplePercentage(workloadRegistrationRequest.SessionsSamplePercentage).
+		SetSessionSampling(workloadRegistrationRequest.SessionSampling).
 		Build()
 
 	workloadFromTemplate, err := NewWorkloadFromTemplate(basicWorkload, workloadRegistrationRequest.Sessions)
@@ -584,6 +672,86 @@ func (d *BasicWorkloadDriver) RegisterWorkload(workloadRegistrationRequest *doma
 		return nil, ErrWorkloadAlreadyRegistered
 	}
 
+	if workloadRegistrationRequest.RoutingTable != nil {
+		if err := workloadRegistrationRequest.RoutingTable.Validate(); err != nil {
+			d.logger.Error("Invalid session routing table.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.SessionSampling != nil {
+		if err := workloadRegistrationRequest.SessionSampling.Validate(); err != nil {
+			d.logger.Error("Invalid session sampling configuration.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.SessionBehavior != nil {
+		if err := workloadRegistrationRequest.SessionBehavior.Validate(); err != nil {
+			d.logger.Error("Invalid session behavior model.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.GpuTypes != nil {
+		if err := workloadRegistrationRequest.GpuTypes.Validate(); err != nil {
+			d.logger.Error("Invalid GPU types.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.DistributedTraining != nil {
+		if err := workloadRegistrationRequest.DistributedTraining.Validate(); err != nil {
+			d.logger.Error("Invalid distributed training configuration.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.InteractiveCells != nil {
+		if err := workloadRegistrationRequest.InteractiveCells.Validate(); err != nil {
+			d.logger.Error("Invalid interactive cell configuration.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if workloadRegistrationRequest.Tenancy != nil {
+		if err := workloadRegistrationRequest.Tenancy.Validate(); err != nil {
+			d.logger.Error("Invalid tenancy configuration.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
+	billingModel, billingErr := domain.NewBillingModel(d.opts)
+	if billingErr != nil {
+		d.logger.Error("Invalid billing configuration.",
+			zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+			zap.Error(billingErr))
+		return nil, billingErr
+	}
+
+	if workloadRegistrationRequest.TraceTransform != nil {
+		if err := workloadRegistrationRequest.TraceTransform.Validate(); err != nil {
+			d.logger.Error("Invalid trace transformation.",
+				zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+				zap.Error(err))
+			return nil, err
+		}
+	}
+
 	d.workloadRegistrationRequest = workloadRegistrationRequest
 
 	// Setup log-level.
@@ -632,6 +800,18 @@ func (d *BasicWorkloadDriver) RegisterWorkload(workloadRegistrationRequest *doma
 				return nil, err
 			}
 		}
+	case "notebook":
+		{
+			// Notebook-workload-specific workload creation and initialization steps.
+			workload, err = d.createWorkloadFromNotebooks(workloadRegistrationRequest)
+
+			if err != nil {
+				d.logger.Error("Failed to create workload from notebooks.",
+					zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+					zap.Error(err))
+				return nil, err
+			}
+		}
 	default:
 		{
 			d.logger.Error("Unsupported workload type.",
@@ -672,6 +852,8 @@ func (d *BasicWorkloadDriver) RegisterWorkload(workloadRegistrationRequest *doma
 			zap.String("workload_driver_id", d.id))
 	}
 
+	workload.RegisterOnStateChangeHandler(d.handleWorkloadStateChange)
+
 	// If the workload seed is negative, then assign it a random value.
 	if workloadRegistrationRequest.Seed < 0 {
 		workload.SetSeed(rand.Int63n(2147483647)) // We restrict the user to the range 0-2,147,483,647 when they specify a seed.
@@ -681,8 +863,40 @@ func (d *BasicWorkloadDriver) RegisterWorkload(workloadRegistrationRequest *doma
 	}
 
 	d.workload = workload
-	d.kernelManager.AddMetadata(jupyter.WorkloadIdMetadataKey, d.workload.GetId())
-	d.kernelManager.AddMetadata(jupyter.RemoteStorageDefinitionMetadataKey, d.workload.GetRemoteStorageDefinition())
+	d.setSessionBehavior(workloadRegistrationRequest.SessionBehavior)
+
+	if workloadRegistrationRequest.Tenancy != nil {
+		d.configureTenancy(workloadRegistrationRequest.Tenancy)
+	}
+
+	d.configureGpuTypes(workloadRegistrationRequest.GpuTypes)
+	d.configureDistributedTraining(workloadRegistrationRequest.DistributedTraining)
+	d.configureInteractiveCells(workloadRegistrationRequest.InteractiveCells)
+	d.configureBilling(billingModel)
+
+	if err = d.configureSessionDependencies(); err != nil {
+		d.workload = nil
+		return nil, err
+	}
+
+	if workloadRegistrationRequest.RoutingTable != nil {
+		if err = d.configureSessionRoutes(workloadRegistrationRequest.RoutingTable); err != nil {
+			d.workload = nil
+			return nil, err
+		}
+	}
+
+	d.registerMetricLabels()
+	for _, kernelManager := range d.kernelManagers() {
+		kernelManager.AddMetadata(jupyter.WorkloadIdMetadataKey, d.workload.GetId())
+		kernelManager.AddMetadata(jupyter.RemoteStorageDefinitionMetadataKey, d.workload.GetRemoteStorageDefinition())
+	}
+
+	if d.publishEvent != nil {
+		d.publishEvent(events.NewEvent(events.WorkloadRegistered, d.workload.GetId(), d.workload.WorkloadName()).
+			WithStates("", d.workload.GetState().String()))
+	}
+
 	return d.workload, nil
 }
 
@@ -718,6 +932,7 @@ func (d *BasicWorkloadDriver) StopWorkload() error {
 	}
 
 	d.logger.Debug("Stopping workload.", zap.String("workload_id", d.id), zap.String("workload-state", string(d.workload.GetState())))
+	d.debugger.releaseAll()
 	d.stopChan <- struct{}{}
 	d.logger.Debug("Sent 'STOP' instruction via BasicWorkloadDriver::stopChan.", zap.String("workload_id", d.id))
 
@@ -765,6 +980,7 @@ func (d *BasicWorkloadDriver) abortWorkload() error {
 	}
 
 	d.workloadGenerator.StopGeneratingWorkload()
+	d.debugger.releaseAll()
 
 	// TODO(Ben): Clean-up any sessions/kernels.
 	d.logger.Warn("TODO: Clean up sessions and kernels.")
@@ -835,6 +1051,8 @@ func (d *BasicWorkloadDriver) publishStatisticsReport() {
 		}
 	}
 
+	d.billProvider(clusterStatistics)
+
 	stats := d.workload.GetStatistics()
 	stats.ClusterStatistics = clusterStatistics
 	PatchCSVHeader(stats)
@@ -875,6 +1093,9 @@ func (d *BasicWorkloadDriver) publishStatisticsReport() {
 func (d *BasicWorkloadDriver) DriveWorkload() {
 	var err error
 
+	// The scheduling policy may not have been known when the workload was registered, so refresh the labels now.
+	d.registerMetricLabels()
+
 	outputSubdir := time.Now().Format("01-02-2006 15:04:05")
 	outputSubdir = strings.ReplaceAll(outputSubdir, ":", "-")
 	outputSubdir = fmt.Sprintf("%s - %s", outputSubdir, d.workload.GetId())
@@ -1226,6 +1447,14 @@ func (d *BasicWorkloadDriver) issueClockTicks(timestamp time.Time) error {
 		}
 
 		tickNumber := int(d.convertTimestampToTickNumber(tick))
+
+		// If there's a breakpoint on this tick, then pause before issuing it.
+		if d.checkTickBreakpoints(int64(tickNumber)) {
+			if err := d.handlePause(); err != nil {
+				return err
+			}
+		}
+
 		d.logger.Debug("Issuing tick.",
 			zap.Int("tick_number", tickNumber),
 			zap.Time("tick_timestamp", tick),
@@ -1284,6 +1513,8 @@ func (d *BasicWorkloadDriver) issueClockTicks(timestamp time.Time) error {
 		tickDuration := time.Since(tickStart)
 		tickDurationSec := decimal.NewFromFloat(tickDuration.Seconds())
 		d.checkForLongTick(tickNumber, tickDurationSec)
+		d.evaluateAlertRules()
+		d.checkBreakpointsAfterTick()
 
 		// Update the average now, after we check if the tick was too long.
 		d.tickDurationsSecondsMovingWindow.Add(tickDurationSec)
@@ -1328,6 +1559,8 @@ func (d *BasicWorkloadDriver) checkForLongTick(tickNumber int, tickDurationSec d
 	stdDevTickDuration := d.tickDurationsSecondsMovingWindow.SampleStandardDeviation()
 
 	if tickDurationSec.GreaterThanOrEqual(avgTickDurationSec.Mul(decimal.NewFromFloat(3))) {
+		d.numLongTicks.Add(1)
+
 		d.logger.Warn("Last tick took longer than expected.",
 			zap.Int("tick_number", tickNumber),
 			zap.String("tick_duration_sec", tickDurationSec.StringFixed(4)),
@@ -1391,6 +1624,10 @@ func (d *BasicWorkloadDriver) ProcessWorkload() {
 	d.workloadGenerator = generator.NewWorkloadGenerator(d.opts, d.atom, d)
 	d.mu.Unlock()
 
+	// This is deferred first so that it runs last, after the deferred cleanup below, which still records metrics
+	// of the workload as it stops the workload's sessions and pools.
+	defer d.unregisterMetricLabels()
+
 	if d.workload.IsPresetWorkload() {
 		go func() {
 			presetWorkload := d.workload.(*Preset)
@@ -1402,7 +1639,7 @@ func (d *BasicWorkloadDriver) ProcessWorkload() {
 					zap.Error(err))
 			}
 		}()
-	} else if d.workload.IsTemplateWorkload() {
+	} else if d.workload.IsTemplateWorkload() || d.workload.IsNotebookWorkload() {
 		go func() {
 			err := d.workloadGenerator.GenerateTemplateWorkload(d, d.workloadSessions, d.workloadRegistrationRequest)
 			if err != nil {
@@ -1420,6 +1657,16 @@ func (d *BasicWorkloadDriver) ProcessWorkload() {
 		zap.String("workload_id", d.id),
 		zap.String("workload_name", d.workload.WorkloadName()))
 
+	// Idle pooled kernels are stopped once the workload is no longer being processed.
+	d.enableSessionPools()
+	defer d.closeSessionPools()
+
+	defer func() {
+		if err := d.outputCapture.Close(); err != nil {
+			d.logger.Warn("Failed to close output capture.", zap.String("workload_id", d.id), zap.Error(err))
+		}
+	}()
+
 	numTicksServed := 0
 	d.servingTicks.Store(true)
 	for d.workload.IsInProgress() {
@@ -1563,6 +1810,9 @@ func (d *BasicWorkloadDriver) handleTick(tick time.Time) error {
 		}
 	}
 
+	// Pre-create the kernels of sessions that are about to be created, if the session pool is enabled.
+	d.prewarmUpcomingSessions(tick)
+
 	// Process "start/stop training" events.
 	d.processEventsForTick(tick)
 
@@ -1582,6 +1832,10 @@ func (d *BasicWorkloadDriver) doneServingTick(tickStart time.Time) {
 	numEventsEnqueued := d.eventQueue.Len()
 	d.workload.TickCompleted(tick, d.clockTime.GetClockTime())
 
+	if metrics.PrometheusMetricsWrapperInstance != nil {
+		metrics.PrometheusMetricsWrapperInstance.TickProcessed(d.workload.GetId(), tickDuration.Milliseconds(), numEventsEnqueued)
+	}
+
 	if d.sugaredLogger.Level() == zapcore.DebugLevel {
 		d.sugaredLogger.Debugf("[%v] Done serving tick #%d. "+
 			"Real-world tick duration: %v. "+
@@ -1618,7 +1872,13 @@ func (d *BasicWorkloadDriver) EventQueue() *event_queue.EventQueue {
 
 func (d *BasicWorkloadDriver) processSessionReadyEvents(sessionReadyEvents []*domain.Event, tick time.Time, timeoutInterval time.Duration) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutInterval)
-	defer cancel()
+	defer func() { cancel() }() // Calls whichever cancel function is current, as the context may be renewed.
+
+	// renewTimeout releases the expired context and replaces it, which is done while the debugger is holding events.
+	renewTimeout := func() {
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), timeoutInterval)
+	}
 
 	sessionFinishedChannel := make(chan string, len(sessionReadyEvents))
 
@@ -1683,6 +1943,14 @@ func (d *BasicWorkloadDriver) processSessionReadyEvents(sessionReadyEvents []*do
 			}
 		case <-ctx.Done():
 			{
+				// Don't time out while the debugger is holding events.
+				if d.debugger.numHeldEvents() > 0 {
+					renewTimeout()
+					continue
+				}
+
+				d.recordTimeout(metrics.TimeoutSessionCreation)
+
 				d.logger.Error("Timed-out waiting for sessions to finish processing their events.",
 					zap.Int("num_responses_received", len(responsesReceived)),
 					zap.Int("num_responses_expected", expectedNumResponses),
@@ -1731,6 +1999,7 @@ func (d *BasicWorkloadDriver) processSessionReadyEvents(sessionReadyEvents []*do
 					// This should never come up again, since we disabled the session, but nevertheless
 					// it should be recorded, as the session did fail to process its events.
 					misbehavingSession.TickFailed()
+					d.debugger.conditionOccurred(domain.ConditionAnySessionFailedTick)
 
 					d.misbehavingSessionsMutex.Lock()
 					d.misbehavingSessions[sessionId] = misbehavingSession
@@ -1856,7 +2125,13 @@ func (d *BasicWorkloadDriver) processEventsForTick(tick time.Time) {
 
 	// We'll wait up to 5-minutes before giving up.
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
-	defer cancel()
+	defer func() { cancel() }() // Calls whichever cancel function is current, as the context may be renewed.
+
+	// renewTimeout releases the expired context and replaces it, which is done while the debugger is holding events.
+	renewTimeout := func() {
+		cancel()
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute*5)
+	}
 
 	startedWaitingAt := time.Now()
 	// Keep looping until we've either received all responses, or until the context's timeout expires and we give up.
@@ -1907,6 +2182,14 @@ func (d *BasicWorkloadDriver) processEventsForTick(tick time.Time) {
 			}
 		case <-ctx.Done():
 			{
+				// Don't time out while the debugger is holding events.
+				if d.debugger.numHeldEvents() > 0 {
+					renewTimeout()
+					continue
+				}
+
+				d.recordTimeout(metrics.TimeoutSessionEvents)
+
 				d.logger.Error("Timed-out waiting for sessions to finish processing their events.",
 					zap.Int("num_responses_received", len(responsesReceived)),
 					zap.Int("num_responses_expected", len(sessionEventMap)),
@@ -1938,6 +2221,8 @@ func (d *BasicWorkloadDriver) processEventsForTick(tick time.Time) {
 
 					// Record that the session failed to process all of its events in this tick.
 					numFailedTicks := misbehavingSession.TickFailed()
+					d.recordSessionFailedTicks(numFailedTicks)
+					d.debugger.conditionOccurred(domain.ConditionAnySessionFailedTick)
 
 					d.misbehavingSessionsMutex.Lock()
 					// Check if this session has a history of poor behavior. For now, we just log a message if so.
@@ -1976,6 +2261,12 @@ func (d *BasicWorkloadDriver) processEventsForSession(sessionId string, events [
 			zap.String("event_name", event.Name.String()),
 			zap.String("workload_name", d.workload.WorkloadName()),
 			zap.String("workload_id", d.workload.GetId()))
+
+		// 'session-ready' events are gated by handleSessionReadyEvent.
+		if event.Name != domain.EventSessionReady {
+			d.gateEvent(event)
+		}
+
 		err := d.handleEvent(event, tick)
 
 		// Record it as processed even if there was an error when processing the event.
@@ -2089,10 +2380,12 @@ func (d *BasicWorkloadDriver) newSession(id string, meta domain.SessionMetadata,
 	}
 
 	// The Session only exposes the CPUs, Memory, and
-	resourceRequest := domain.NewResourceRequest(meta.GetMaxSessionCPUs(), meta.GetMaxSessionMemory(), meta.GetMaxSessionGPUs(), meta.GetMaxSessionVRAM(), AnyGPU)
-	session = domain.NewWorkloadSession(id, meta, resourceRequest, createdAtTime, d.atom)
+	internalSessionId := d.getInternalSessionId(id)
 
-	internalSessionId := d.getInternalSessionId(session.GetId())
+	resourceRequest := domain.NewResourceRequest(meta.GetMaxSessionCPUs(), meta.GetMaxSessionMemory(), meta.GetMaxSessionGPUs(), meta.GetMaxSessionVRAM(), AnyGPU).
+		WithGpuTypes(d.sessionGpuTypes(internalSessionId))
+	session = domain.NewWorkloadSession(id, meta, resourceRequest, createdAtTime, d.atom)
+	d.gpuTypeSessionCreated(internalSessionId)
 
 	d.workload.SessionCreated(id, meta)
 
@@ -2149,6 +2442,13 @@ func (d *BasicWorkloadDriver) handleSessionReadyEvent(sessionReadyEvent *domain.
 		d.sugaredLogger.Debugf("Handling EventSessionReady %d targeting Session %s [ts: %v].", eventIndex+1, sessionId, sessionReadyEvent.Timestamp)
 	}
 
+	d.gateEvent(sessionReadyEvent)
+
+	if !d.admitSession(sessionReadyEvent) {
+		doneChan <- sessionId
+		return
+	}
+
 	provisionStart := time.Now()
 	_, err := d.provisionSession(sessionId, sessionMeta, sessionReadyEvent.Timestamp)
 
@@ -2191,6 +2491,9 @@ func (d *BasicWorkloadDriver) handleSessionReadyEvent(sessionReadyEvent *domain.
 			}
 		}()
 
+		// The session will be admitted again when it is retried.
+		d.tenantSessionStopped(d.getInternalSessionId(sessionId))
+
 		// We need to inspect the error here.
 		// Depending on what the error is, we'll treat it as a critical error or not.
 		err = d.handleFailureToCreateNewSession(err, sessionReadyEvent)
@@ -2201,6 +2504,9 @@ func (d *BasicWorkloadDriver) handleSessionReadyEvent(sessionReadyEvent *domain.
 			return
 		}
 	} else {
+		d.billSessionStarted(d.getInternalSessionId(sessionId), sessionMeta)
+		d.dependencySessionStarted(d.getInternalSessionId(sessionId))
+		d.scheduleInteractiveCell(d.getInternalSessionId(sessionId), sessionMeta)
 		d.logger.Debug("Successfully handled SessionStarted event.",
 			zap.String("workload_id", d.workload.GetId()),
 			zap.String("workload_name", d.workload.WorkloadName()),
@@ -2218,13 +2524,66 @@ func (d *BasicWorkloadDriver) delaySession(sessionId string, delayAmount time.Du
 	}
 
 	d.workload.SessionDelayed(sessionId, delayAmount)
+	d.tenantSessionDelayed(sessionId, delayAmount)
+	d.debugger.conditionOccurred(domain.ConditionAnySessionDelayed)
 
 	if metrics.PrometheusMetricsWrapperInstance != nil {
-		metrics.PrometheusMetricsWrapperInstance.SessionDelayedDueToResourceContention.
-			With(prometheus.Labels{
-				"workload_id": d.workload.GetId(),
-				"session_id":  sessionId,
-			}).Add(1)
+		metrics.PrometheusMetricsWrapperInstance.SessionDelayed(d.workload.GetId(), sessionId, delayAmount.Milliseconds())
+	}
+}
+
+// handleWorkloadStateChange is registered as the StateChangeHandler of the workload, and it publishes
+// a typed lifecycle event to the event bus whenever the workload transitions from one State to another.
+func (d *BasicWorkloadDriver) handleWorkloadStateChange(workloadId string, workloadName string, from State, to State) {
+	if d.publishEvent == nil {
+		return
+	}
+
+	d.publishEvent(events.NewEvent(stateChangeEventType(from, to), workloadId, workloadName).
+		WithStates(from.String(), to.String()))
+}
+
+// registerMetricLabels registers the scheduling policy, workload type, and workload preset of the workload with
+// the metrics.PrometheusMetricsWrapper so that they are attached as labels to the workload's Prometheus metrics.
+func (d *BasicWorkloadDriver) registerMetricLabels() {
+	if metrics.PrometheusMetricsWrapperInstance == nil || d.workload == nil {
+		return
+	}
+
+	var workloadType, presetKey string
+	if d.workloadRegistrationRequest != nil {
+		workloadType = strings.ToLower(d.workloadRegistrationRequest.Type)
+	}
+
+	if d.workloadPreset != nil {
+		presetKey = d.workloadPreset.GetKey()
+	}
+
+	metrics.PrometheusMetricsWrapperInstance.RegisterWorkloadLabels(d.workload.GetId(), d.getSchedulingPolicy(),
+		workloadType, presetKey)
+}
+
+// unregisterMetricLabels deletes the metric series of the workload and discards the labels that
+// registerMetricLabels registered for the workload.
+func (d *BasicWorkloadDriver) unregisterMetricLabels() {
+	if metrics.PrometheusMetricsWrapperInstance == nil || d.workload == nil {
+		return
+	}
+
+	metrics.PrometheusMetricsWrapperInstance.UnregisterWorkloadLabels(d.workload.GetId())
+}
+
+// recordTimeout records that a timeout of the specified type occurred with the metrics.PrometheusMetricsWrapper.
+func (d *BasicWorkloadDriver) recordTimeout(timeoutType string) {
+	if metrics.PrometheusMetricsWrapperInstance != nil {
+		metrics.PrometheusMetricsWrapperInstance.TimeoutOccurred(d.workload.GetId(), timeoutType)
+	}
+}
+
+// recordFailedTraining records that a training failed at the specified stage with the metrics.PrometheusMetricsWrapper.
+func (d *BasicWorkloadDriver) recordFailedTraining(stage string) {
+	if metrics.PrometheusMetricsWrapperInstance != nil {
+		metrics.PrometheusMetricsWrapperInstance.TrainingFailed(d.workload.GetId(), stage)
 	}
 }
 
@@ -2273,26 +2632,10 @@ func (d *BasicWorkloadDriver) handleFailureToCreateNewSession(err error, session
 	return errors.Join(ErrKernelCreationFailed, err)
 }
 
-// handleUpdateGpuUtilizationEvent handles a 'update-gpu-util' event.
-func (d *BasicWorkloadDriver) handleUpdateGpuUtilizationEvent(evt *domain.Event) error {
-	traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
-	internalSessionId := d.getInternalSessionId(traceSessionId)
-
-	d.logger.Debug("Received UpdateGpuUtil event.",
-		zap.String("workload_id", d.workload.GetId()), zap.String("workload_name", d.workload.WorkloadName()),
-		zap.String(ZapInternalSessionIDKey, internalSessionId), zap.String(ZapTraceSessionIDKey, traceSessionId))
-	// TODO: Update GPU utilization.
-	d.logger.Debug("Handled UpdateGpuUtil event.",
-		zap.String("workload_id", d.workload.GetId()), zap.String("workload_name", d.workload.WorkloadName()),
-		zap.String(ZapInternalSessionIDKey, internalSessionId), zap.String(ZapTraceSessionIDKey, traceSessionId))
-
-	return nil
-}
-
-// createExecuteRequestArguments creates the arguments for an "execute_request" from the given event.
+// createExecuteRequestArguments creates the arguments for an "execute_request" that executes the given code.
 //
 // The event must be of type "training-started", or this will return nil.
-func (d *BasicWorkloadDriver) createExecuteRequestArguments(evt *domain.Event, callback func(resp jupyter.KernelMessage)) (*jupyter.RequestExecuteArgs, error) {
+func (d *BasicWorkloadDriver) createExecuteRequestArguments(evt *domain.Event, code string, callback func(resp jupyter.KernelMessage)) (*jupyter.RequestExecuteArgs, error) {
 	if evt.Name != domain.EventSessionTrainingStarted {
 		d.logger.Error("Attempted to create \"execute_request\" arguments for event of invalid type.",
 			zap.String("event_type", evt.Name.String()),
@@ -2321,15 +2664,17 @@ func (d *BasicWorkloadDriver) createExecuteRequestArguments(evt *domain.Event, c
 		gpus = sessionMetadata.GetGPUs()
 	}
 
-	resourceRequest := &domain.ResourceRequest{
+	internalSessionId := d.getInternalSessionId(sessionMetadata.GetPod())
+	resourceRequest := (&domain.ResourceRequest{
 		Cpus:     sessionMetadata.GetCurrentTrainingMaxCPUs(),
 		MemoryMB: sessionMetadata.GetCurrentTrainingMaxMemory(),
 		VRAM:     sessionMetadata.GetVRAM(),
 		Gpus:     gpus,
-	}
+	}).WithGpuTypes(d.trainingGpuTypes(evt, internalSessionId)).
+		WithDistributedTraining(d.trainingDistribution(evt, internalSessionId))
 
 	argsBuilder := jupyter.NewRequestExecuteArgsBuilder().
-		Code(TrainingCode).
+		Code(code).
 		Silent(false).
 		StoreHistory(true).
 		UserExpressions(nil).
@@ -2401,7 +2746,7 @@ func (d *BasicWorkloadDriver) submitTrainingToKernel(evt *domain.Event,
 	}
 
 	var executeRequestArgs *jupyter.RequestExecuteArgs
-	executeRequestArgs, err = d.createExecuteRequestArguments(evt, handleExecuteReplyWrapper)
+	executeRequestArgs, err = d.createExecuteRequestArguments(evt, TrainingCode, handleExecuteReplyWrapper)
 	if executeRequestArgs == nil || err != nil {
 		d.logger.Error("Failed to create 'execute_request' arguments.",
 			zap.String("workload_id", d.workload.GetId()),
@@ -2411,6 +2756,8 @@ func (d *BasicWorkloadDriver) submitTrainingToKernel(evt *domain.Event,
 		return time.Time{}, nil, err
 	}
 
+	d.outputCapture.BeginTraining(internalSessionId)
+
 	sentRequestAt = time.Now()
 	_, err = kernelConnection.RequestExecute(executeRequestArgs)
 	if err != nil {
@@ -2473,6 +2820,13 @@ func (d *BasicWorkloadDriver) waitForTrainingToStart(evt *domain.Event, internal
 						zap.Duration("time_elapsed", time.Since(sentRequestAt)),
 						zap.Error(err))
 
+					d.recordFailedTraining(metrics.TrainingFailedToStart)
+					d.tenantTrainingStopped(internalSessionId, false)
+					d.billTrainingStopped(internalSessionId, false)
+					d.gpuTypeTrainingStopped(internalSessionId, false)
+					d.gpuUtilizationTrainingStopped(internalSessionId)
+					d.gangTrainingStopped(internalSessionId)
+
 					// If we fail to start training for some reason, then we'll just try again later.
 					d.delaySession(internalSessionId, time.Since(startedHandlingAt)+d.targetTickDuration*2)
 
@@ -2482,6 +2836,7 @@ func (d *BasicWorkloadDriver) waitForTrainingToStart(evt *domain.Event, internal
 			default:
 				{
 					startLatency := time.Since(sentRequestAt)
+					d.lastTrainingStartLatencyMillis.Store(startLatency.Milliseconds())
 					d.logger.Debug("Session started training",
 						zap.String("workload_id", d.workload.GetId()),
 						zap.String("workload_name", d.workload.WorkloadName()),
@@ -2502,6 +2857,9 @@ func (d *BasicWorkloadDriver) waitForTrainingToStart(evt *domain.Event, internal
 // trainingStartTimedOut is called by waitForTrainingToStart when we don't receive a notification that the submitted
 // training event started being processed after the timeout interval elapses.
 func (d *BasicWorkloadDriver) trainingStartTimedOut(internalSessionId string, sentRequestAt time.Time, startedHandlingAt time.Time) {
+	d.recordTimeout(metrics.TimeoutTrainingStart)
+	d.numTrainingStartTimeouts.Add(1)
+
 	d.logger.Warn("Have not received 'training started' notification for over 1 minute. Assuming message was lost.",
 		zap.String("workload_id", d.workload.GetId()),
 		zap.String("workload_name", d.workload.WorkloadName()),
@@ -2546,14 +2904,33 @@ func (d *BasicWorkloadDriver) handleTrainingStartedEvent(evt *domain.Event) erro
 	traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
 	internalSessionId := d.getInternalSessionId(traceSessionId)
 
+	if !d.awaitDependencies(evt, internalSessionId) {
+		return nil
+	}
+
+	if !d.admitTraining(evt, internalSessionId) {
+		return nil
+	}
+
+	d.billTrainingStarted(evt, internalSessionId)
+	d.gpuTypeTrainingSubmitted(evt, internalSessionId)
+	d.gangTrainingSubmitted(evt, internalSessionId)
+
 	sentRequestAt, trainingStartedChannel, err := d.submitTrainingToKernel(evt, internalSessionId)
 	if err != nil {
+		d.tenantTrainingStopped(internalSessionId, false)
+		d.billTrainingStopped(internalSessionId, false)
+		d.gpuTypeTrainingStopped(internalSessionId, false)
+		d.gpuUtilizationTrainingStopped(internalSessionId)
+		d.gangTrainingStopped(internalSessionId)
+
 		d.logger.Error("Failed to submit training to kernel.",
 			zap.String("workload_id", d.workload.GetId()),
 			zap.String("workload_name", d.workload.WorkloadName()),
 			zap.String("kernel_id", internalSessionId),
 			zap.String("event", evt.StringJson()),
 			zap.Error(err))
+		d.recordFailedTraining(metrics.TrainingFailedToSubmit)
 		return err
 	}
 
@@ -2627,6 +3004,13 @@ func (d *BasicWorkloadDriver) handleTrainingEndedEvent(evt *domain.Event, tick t
 		return err
 	} else {
 		d.workload.TrainingStopped(traceSessionId, evt, d.convertTimestampToTickNumber(tick))
+		d.liftTransientDelay(internalSessionId)
+		d.tenantTrainingStopped(internalSessionId, true)
+		d.billTrainingStopped(internalSessionId, true)
+		d.gpuTypeTrainingStopped(internalSessionId, true)
+		d.gpuUtilizationTrainingStopped(internalSessionId)
+		d.dependencyTrainingCompleted(internalSessionId)
+		d.recordRouteTaskExecuted(internalSessionId)
 		d.logger.Debug("Successfully sent 'stop-training' message'.",
 			zap.String("workload_id", d.workload.GetId()),
 			zap.String("workload_name", d.workload.WorkloadName()),
@@ -2746,6 +3130,8 @@ func (d *BasicWorkloadDriver) waitForTrainingToEnd(internalSessionId string, evt
 						zap.Duration("e2e_latency", e2eLatency),
 						zap.Error(err))
 
+					d.recordFailedTraining(metrics.TrainingFailedToStop)
+
 					return nil // to prevent workload from ending outright
 				}
 			case jupyter.KernelMessage:
@@ -2794,6 +3180,8 @@ func (d *BasicWorkloadDriver) waitForTrainingToEnd(internalSessionId string, evt
 		}
 	case <-ctx.Done():
 		{
+			d.recordTimeout(metrics.TimeoutTrainingStop)
+
 			err := ctx.Err()
 			if err != nil {
 				d.logger.Error("Timed-out waiting for \"execute_reply\" message while stopping training.",
@@ -2905,7 +3293,7 @@ func (d *BasicWorkloadDriver) handleSessionStoppedEvent(evt *domain.Event) error
 		zap.String("workload_name", d.workload.WorkloadName()),
 		zap.String("kernel_id", internalSessionId))
 
-	err := d.kernelManager.StopKernel(internalSessionId)
+	err := d.sessionRouteOf(internalSessionId).kernelManager.StopKernel(internalSessionId)
 	if err != nil {
 		d.logger.Error("Error encountered while stopping session.",
 			zap.String("workload_id", d.workload.GetId()),
@@ -2932,11 +3320,23 @@ func (d *BasicWorkloadDriver) handleSessionStoppedEvent(evt *domain.Event) error
 	} else {
 		sessionLifetimeDuration := time.Since(session.GetCreatedAt())
 		metrics.PrometheusMetricsWrapperInstance.WorkloadSessionLifetimeSeconds.
-			With(prometheus.Labels{"workload_id": d.workload.GetId()}).
+			With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(d.workload.GetId())).
 			Observe(sessionLifetimeDuration.Seconds())
 	}
 
 	d.workload.SessionStopped(traceSessionId, evt)
+
+	// Completed trainings are recorded when they end, so a training that is still running when the session stops
+	// did not complete.
+	d.tenantTrainingStopped(internalSessionId, false)
+	d.tenantSessionStopped(internalSessionId)
+	d.billTrainingStopped(internalSessionId, false)
+	d.billSessionStopped(internalSessionId)
+	d.gpuTypeTrainingStopped(internalSessionId, false)
+	d.gangTrainingStopped(internalSessionId)
+	d.dependencySessionStopped(internalSessionId)
+	d.unscheduleInteractiveCell(internalSessionId)
+	d.gpuUtilizationSessionStopped(internalSessionId)
 	d.logger.Debug("Handled SessionStopped event.",
 		zap.String("workload_id", d.workload.GetId()), zap.String("workload_name", d.workload.WorkloadName()),
 		zap.String(ZapInternalSessionIDKey, internalSessionId), zap.String(ZapTraceSessionIDKey, traceSessionId))
@@ -2948,13 +3348,23 @@ func (d *BasicWorkloadDriver) handleSessionStoppedEvent(evt *domain.Event) error
 func (d *BasicWorkloadDriver) handleEvent(evt *domain.Event, tick time.Time) error {
 	switch evt.Name {
 	case domain.EventSessionTrainingStarted:
+		if d.workload.IsNotebookWorkload() {
+			return d.handleNotebookCellStartedEvent(evt)
+		}
 		return d.handleTrainingStartedEvent(evt)
 	case domain.EventSessionTrainingEnded:
+		if d.workload.IsNotebookWorkload() {
+			return d.handleNotebookCellEndedEvent(evt, tick)
+		}
 		return d.handleTrainingEndedEvent(evt, tick)
 	case domain.EventSessionStopped:
 		return d.handleSessionStoppedEvent(evt)
 	case domain.EventSessionReady:
 		d.processSessionReadyEvents([]*domain.Event{evt}, tick, time.Minute*3)
+	case domain.EventSessionInteractiveCell:
+		return d.handleInteractiveCellEvent(evt)
+	case domain.EventSessionUpdateGpuUtil:
+		return d.handleUpdateGpuUtilizationEvent(evt)
 	default:
 		traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
 		internalSessionId := d.getInternalSessionId(traceSessionId)
@@ -2975,7 +3385,7 @@ func (d *BasicWorkloadDriver) handleEvent(evt *domain.Event, tick time.Time) err
 
 func (d *BasicWorkloadDriver) stopSession(sessionId string) error {
 	d.logger.Debug("Stopping session.", zap.String("workload_id", d.workload.GetId()), zap.String("workload_name", d.workload.WorkloadName()), zap.String("kernel_id", sessionId))
-	return d.kernelManager.StopKernel(sessionId)
+	return d.sessionRouteOf(sessionId).kernelManager.StopKernel(sessionId)
 }
 
 func (d *BasicWorkloadDriver) handleIoPubMessage() {
@@ -2991,56 +3401,40 @@ func (d *BasicWorkloadDriver) provisionSession(sessionId string, meta domain.Ses
 		zap.String("ZapInternalSessionIDKey", internalSessionId))
 	st := time.Now()
 
-	var resourceSpec *jupyter.ResourceSpec
-	schedulingPolicy := d.getSchedulingPolicy()
-	if schedulingPolicy == "static" || schedulingPolicy == "dynamic-v3" || schedulingPolicy == "dynamic-v4" {
-		// Try to get the first training event of the session, and just reserve those resources.
-		firstTrainingEvent := d.workload.getSessionTrainingEvent(sessionId, 0)
+	resourceSpec := d.getSessionResourceSpec(sessionId, meta)
+	route := d.routeSession(sessionId, resourceSpec)
 
-		if firstTrainingEvent != nil {
-			resourceSpec = &jupyter.ResourceSpec{
-				Cpu:  int(firstTrainingEvent.Millicpus),
-				Mem:  firstTrainingEvent.MemUsageMB,
-				Gpu:  firstTrainingEvent.NumGPUs(),
-				Vram: firstTrainingEvent.VRamUsageGB,
-			}
-		} else {
-			d.logger.Warn("Could not find first training event of session.",
-				zap.String("workload_id", d.workload.GetId()),
-				zap.String("workload_name", d.workload.WorkloadName()),
-				zap.String("ZapInternalSessionIDKey", internalSessionId))
-		}
-	}
+	notebookPath := fmt.Sprintf("%s.ipynb", internalSessionId)
 
-	// If we're either not using static/dynamic scheduling or we couldn't find the first training event for some
-	// reason, then we'll create the resource request using the maximum values of the session's resource usage.
-	if resourceSpec == nil {
-		resourceSpec = &jupyter.ResourceSpec{
-			Cpu:  int(meta.GetMaxSessionCPUs()),
-			Mem:  meta.GetMaxSessionMemory(),
-			Gpu:  meta.GetMaxSessionGPUs(),
-			Vram: meta.GetMaxSessionVRAM(),
+	// For notebook-based workloads, upload the notebook first so that the session refers to the real notebook.
+	if d.workload.IsNotebookWorkload() {
+		if err := d.uploadNotebook(sessionId, notebookPath); err != nil {
+			return nil, err
 		}
 	}
 
 	// Create the kernel in Jupyter.
-	sessionConnection, err := d.kernelManager.CreateSession(
+	sessionConnection, err := route.kernelManager.CreateSession(
 		internalSessionId, /*strings.ToLower(sessionId) */
-		fmt.Sprintf("%s.ipynb", internalSessionId),
-		"notebook", "distributed", resourceSpec)
+		notebookPath,
+		"notebook", route.kernelSpec, resourceSpec, d.usernameOf(internalSessionId))
 
 	if err != nil {
 		d.logger.Warn("Failed to create session.",
 			zap.String("workload_id", d.workload.GetId()),
 			zap.String("workload_name", d.workload.WorkloadName()),
 			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.String("route", route.Name),
 			zap.Error(err))
+		d.recordRouteSessionCreation(route, "", time.Since(st), err)
 
 		// We call our OnError handlers after returning; no need to call them here.
 		return nil, err
 	}
 
 	timeElapsed := time.Since(st)
+	d.recordSessionCreation(sessionConnection.Pooled(), timeElapsed)
+	d.recordRouteSessionCreation(route, sessionConnection.Kernel().KernelId(), timeElapsed, nil)
 
 	d.sessionConnectionsMutex.Lock()
 	d.sessionConnections[internalSessionId] = sessionConnection
@@ -3050,6 +3444,7 @@ func (d *BasicWorkloadDriver) provisionSession(sessionId string, meta domain.Ses
 		zap.String("workload_id", d.workload.GetId()),
 		zap.String("workload_name", d.workload.WorkloadName()),
 		zap.Duration("time-elapsed", timeElapsed),
+		zap.Bool("pooled", sessionConnection.Pooled()),
 		zap.String(ZapInternalSessionIDKey, internalSessionId))
 
 	// Create a new workload session.
@@ -3095,9 +3490,100 @@ func (d *BasicWorkloadDriver) provisionSession(sessionId string, meta domain.Ses
 			zap.String("id", d.id), zap.Error(err))
 	}
 
+	outputCaptureHandlerId := d.id + outputCaptureHandlerIdSuffix
+	if err := sessionConnection.RegisterIoPubHandler(outputCaptureHandlerId, d.captureOutput(internalSessionId)); err != nil {
+		d.logger.Warn("Failed to register IOPub message handler for output capture.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String("id", outputCaptureHandlerId), zap.Error(err))
+	}
+
+	sessionConnection.Kernel().SetOnConnectionStatusChanged(d.handleKernelConnectionStatusChanged)
+
+	if d.workload.IsNotebookWorkload() {
+		handlerId := d.id + notebookOutputHandlerIdSuffix
+		if err := sessionConnection.RegisterIoPubHandler(handlerId, d.recordNotebookCellOutput); err != nil {
+			d.logger.Warn("Failed to register IOPub message handler for notebook cell outputs.",
+				zap.String("workload_id", d.workload.GetId()),
+				zap.String("workload_name", d.workload.WorkloadName()),
+				zap.String("id", handlerId), zap.Error(err))
+		}
+	}
+
 	return sessionConnection, nil
 }
 
+// getSessionResourceSpec returns the resources to request when creating the kernel of the specified session.
+func (d *BasicWorkloadDriver) getSessionResourceSpec(sessionId string, meta domain.SessionMetadata) *jupyter.ResourceSpec {
+	var resourceSpec *jupyter.ResourceSpec
+	schedulingPolicy := d.getSchedulingPolicy()
+	if schedulingPolicy == "static" || schedulingPolicy == "dynamic-v3" || schedulingPolicy == "dynamic-v4" {
+		// Try to get the first training event of the session, and just reserve those resources.
+		firstTrainingEvent := d.workload.getSessionTrainingEvent(sessionId, 0)
+
+		if firstTrainingEvent != nil {
+			resourceSpec = &jupyter.ResourceSpec{
+				Cpu:      int(firstTrainingEvent.Millicpus),
+				Mem:      firstTrainingEvent.MemUsageMB,
+				Gpu:      firstTrainingEvent.NumGPUs(),
+				Vram:     firstTrainingEvent.VRamUsageGB,
+				GpuTypes: d.sessionGpuTypes(d.getInternalSessionId(sessionId)),
+			}
+		} else {
+			d.logger.Warn("Could not find first training event of session.",
+				zap.String("workload_id", d.workload.GetId()),
+				zap.String("workload_name", d.workload.WorkloadName()),
+				zap.String(ZapInternalSessionIDKey, d.getInternalSessionId(sessionId)))
+		}
+	}
+
+	// If we're either not using static/dynamic scheduling or we couldn't find the first training event for some
+	// reason, then we'll create the resource request using the maximum values of the session's resource usage.
+	if resourceSpec == nil {
+		resourceSpec = &jupyter.ResourceSpec{
+			Cpu:      int(meta.GetMaxSessionCPUs()),
+			Mem:      meta.GetMaxSessionMemory(),
+			Gpu:      meta.GetMaxSessionGPUs(),
+			Vram:     meta.GetMaxSessionVRAM(),
+			GpuTypes: d.sessionGpuTypes(d.getInternalSessionId(sessionId)),
+		}
+	}
+
+	return resourceSpec
+}
+
+// handleKernelConnectionStatusChanged is registered with each of the workload's kernel connections, and it publishes
+// a KernelConnectionStateChanged event to the event bus whenever the status of the connection changes.
+//
+// If the connection is lost for good, then a warning notification is also sent to the frontend.
+func (d *BasicWorkloadDriver) handleKernelConnectionStatusChanged(conn jupyter.KernelConnection, previous jupyter.KernelConnectionStatus, current jupyter.KernelConnectionStatus) {
+	d.logger.Debug("Kernel connection status changed.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("kernel_id", conn.KernelId()),
+		zap.String("previous_status", previous.String()),
+		zap.String("current_status", current.String()))
+
+	if d.publishEvent != nil {
+		d.publishEvent(events.NewEvent(events.KernelConnectionStateChanged, d.workload.GetId(), d.workload.WorkloadName()).
+			WithKernel(conn.KernelId()).
+			WithStates(previous.String(), current.String()))
+	}
+
+	// Connections are closed intentionally when sessions stop, so we only notify while the workload is running.
+	if current != jupyter.KernelDead || !d.workload.IsRunning() || d.notifyCallback == nil {
+		return
+	}
+
+	go d.notifyCallback(&proto.Notification{
+		Id:    uuid.NewString(),
+		Title: "Lost Connection to Kernel",
+		Message: fmt.Sprintf("Lost connection to kernel %s of workload %s (ID=%s)",
+			conn.KernelId(), d.workload.WorkloadName(), d.workload.GetId()),
+		Panicked:         false,
+		NotificationType: domain.WarningNotification.Int32(),
+	})
+}
+
 type parsedIoPubMessage struct {
 	Stream string
 	Text   string
@@ -3178,6 +3664,12 @@ func (d *BasicWorkloadDriver) handleIOPubSmrLeadTaskMessage(conn jupyter.KernelC
 		zap.String("workload_name", d.workload.WorkloadName()),
 		zap.String("kernel_id", conn.KernelId()))
 
+	// The training of a distributed training only starts once all of its workers are running.
+	gangReady, gangWait := d.gangWorkerStarted(conn.KernelId(), kernelMessage)
+	if !gangReady {
+		return conn.KernelId()
+	}
+
 	d.workload.TrainingStarted(conn.KernelId(), d.convertTimestampToTickNumber(d.currentTick.GetClockTime()))
 	err := d.eventQueue.ReleaseEventHoldForSession(conn.KernelId())
 	if err != nil {
@@ -3240,11 +3732,13 @@ func (d *BasicWorkloadDriver) handleIOPubSmrLeadTaskMessage(conn jupyter.KernelC
 		delayMilliseconds = 0
 	}
 
+	// The gang-wait time of distributed trainings is recorded separately, so it is excluded from the start latency.
+	startLatencyMilliseconds := max(delayMilliseconds-gangWait.Milliseconds(), 0)
 	d.workload.UpdateStatistics(func(stats *Statistics) {
 		stats.JupyterTrainingStartLatenciesDashboardMillis = append(
-			stats.JupyterTrainingStartLatenciesDashboardMillis, float64(delayMilliseconds))
+			stats.JupyterTrainingStartLatenciesDashboardMillis, float64(startLatencyMilliseconds))
 
-		stats.JupyterTrainingStartLatencyDashboardMillis += float64(delayMilliseconds)
+		stats.JupyterTrainingStartLatencyDashboardMillis += float64(startLatencyMilliseconds)
 	})
 
 	d.logger.Debug("Computed training-started delay for session.",
@@ -3255,7 +3749,8 @@ func (d *BasicWorkloadDriver) handleIOPubSmrLeadTaskMessage(conn jupyter.KernelC
 		zap.Int64("training_started_at", trainingStartedAt),
 		zap.Int64("computed_delay", delayMilliseconds))
 
-	d.delaySession(conn.KernelId(), time.Millisecond*time.Duration(delayMilliseconds))
+	d.trainingStartDelayed(conn.KernelId(), time.Millisecond*time.Duration(delayMilliseconds))
+	d.gpuTypeTrainingStartDelayed(conn.KernelId(), time.Millisecond*time.Duration(delayMilliseconds))
 
 	d.trainingStartedChannelMutex.Lock()
 	channel, loadedChan := d.trainingStartedChannels[conn.KernelId()]
@@ -3313,6 +3808,56 @@ func (d *BasicWorkloadDriver) handleIOPubStreamMessage(conn jupyter.KernelConnec
 	}
 }
 
+// recordSessionCreation records how long it took to provision the kernel of a session, separating kernels
+// handed out by the session pool from those that were created on demand (i.e., cold starts).
+func (d *BasicWorkloadDriver) recordSessionCreation(pooled bool, latency time.Duration) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		if pooled {
+			stats.NumPooledSessionCreations += 1
+			stats.PooledSessionCreationLatenciesMillis = append(stats.PooledSessionCreationLatenciesMillis, latency.Milliseconds())
+		} else {
+			stats.NumColdSessionCreations += 1
+			stats.ColdSessionCreationLatenciesMillis = append(stats.ColdSessionCreationLatenciesMillis, latency.Milliseconds())
+		}
+	})
+}
+
+// prewarmUpcomingSessions pre-creates the kernels of the sessions whose "session-ready" events are within the
+// configured lookahead window of the given tick, provided that the session pool permits pre-warming.
+func (d *BasicWorkloadDriver) prewarmUpcomingSessions(tick time.Time) {
+	if d.opts.SessionPoolPrewarmLookaheadTicks <= 0 || d.opts.SessionPoolMaxPrewarmed <= 0 {
+		return
+	}
+
+	horizon := tick.Add(d.targetTickDuration * time.Duration(d.opts.SessionPoolPrewarmLookaheadTicks))
+	for _, evt := range d.eventQueue.UpcomingSessionReadyEvents(horizon) {
+		meta, ok := evt.Data.(domain.SessionMetadata)
+		if !ok {
+			continue
+		}
+
+		sessionId := meta.GetPod()
+		internalSessionId := d.getInternalSessionId(sessionId)
+
+		d.sessionConnectionsMutex.Lock()
+		_, provisioned := d.sessionConnections[internalSessionId]
+		d.sessionConnectionsMutex.Unlock()
+
+		if provisioned {
+			continue
+		}
+
+		resourceSpec := d.getSessionResourceSpec(sessionId, meta)
+		route := d.routeSession(sessionId, resourceSpec)
+
+		// Each route's pool fills up independently, so a full pool only prevents pre-warming for its own route.
+		err := route.kernelManager.PrewarmSession(internalSessionId, route.kernelSpec, resourceSpec)
+		if (errors.Is(err, jupyter.ErrSessionPoolFull) || errors.Is(err, jupyter.ErrSessionPoolDisabled)) && d.sessionRouter == nil {
+			return
+		}
+	}
+}
+
 // ObserveJupyterSessionCreationLatency records the latency of creating a Jupyter session
 // during the execution of a particular workload, as identified by the given workload ID.
 func (d *BasicWorkloadDriver) ObserveJupyterSessionCreationLatency(latencyMilliseconds int64, workloadId string) {
@@ -3358,7 +3903,17 @@ func (d *BasicWorkloadDriver) ObserveJupyterExecuteRequestE2ELatency(latencyMill
 // AddJupyterRequestExecuteTime records the time taken to process an "execute_request" for the total, aggregate,
 // cumulative time spent processing "execute_request" messages.
 func (d *BasicWorkloadDriver) AddJupyterRequestExecuteTime(latencyMilliseconds int64, kernelId string, workloadId string) {
+	d.recordRouteExecRequestTime(kernelId, latencyMilliseconds)
+
 	if metrics.PrometheusMetricsWrapperInstance != nil {
 		metrics.PrometheusMetricsWrapperInstance.AddJupyterRequestExecuteTime(latencyMilliseconds, kernelId, workloadId)
 	}
 }
+
+// RecordKernelReconnectionAttempt records an attempt to reconnect to a kernel, as well as whether the attempt
+// succeeded, during the execution of a particular workload, as identified by the given workload ID.
+func (d *BasicWorkloadDriver) RecordKernelReconnectionAttempt(kernelId string, workloadId string, succeeded bool) {
+	if metrics.PrometheusMetricsWrapperInstance != nil {
+		metrics.PrometheusMetricsWrapperInstance.RecordKernelReconnectionAttempt(kernelId, workloadId, succeeded)
+	}
+}
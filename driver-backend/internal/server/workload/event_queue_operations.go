@@ -0,0 +1,185 @@
+package workload
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// InjectedTrainingEventName is the event name used to inject an extra training into a session's schedule.
+	// Injecting a training enqueues a "training-started" event and the corresponding "training-ended" event.
+	InjectedTrainingEventName = "training"
+
+	// injectedEventGlobalIndexBase is the base from which the global indices of injected events are assigned.
+	// Injected events are thus ordered after any trace events that share the same timestamp.
+	injectedEventGlobalIndexBase uint64 = 1 << 62
+)
+
+var (
+	ErrUnsupportedInjectedEvent = errors.New("unsupported event type specified for injection")
+	ErrInvalidInjectionTime     = errors.New("injected events must occur at least one tick in the future")
+	ErrInvalidTrainingDuration  = errors.New("injected trainings must last for at least one tick")
+)
+
+// InjectEvent injects an ad-hoc event into the event queue of the workload.
+//
+// If the EventName of the request is InjectedTrainingEventName, then both a "training-started" and a
+// "training-ended" event are injected. The only other supported EventName is "session-stopped".
+//
+// InjectEvent returns the injected events on success.
+func (d *BasicWorkloadDriver) InjectEvent(req *domain.InjectEventRequest) ([]*domain.Event, error) {
+	if !d.workload.IsInProgress() {
+		return nil, domain.ErrWorkloadNotRunning
+	}
+
+	session := d.GetSession(d.getInternalSessionId(req.SessionId))
+	if session == nil {
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrUnknownSession, req.SessionId)
+	}
+
+	ticksFromNow := req.TicksFromNow
+	if ticksFromNow == 0 {
+		ticksFromNow = 1
+	}
+
+	if ticksFromNow < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidInjectionTime, ticksFromNow)
+	}
+
+	timestamp := d.currentTick.GetClockTime().Add(time.Duration(ticksFromNow) * d.targetTickDuration)
+	meta := session.GetMeta()
+
+	var events []*domain.Event
+	switch req.EventName {
+	case InjectedTrainingEventName:
+		{
+			if req.DurationTicks <= 0 {
+				return nil, fmt.Errorf("%w: %d", ErrInvalidTrainingDuration, req.DurationTicks)
+			}
+
+			endTimestamp := timestamp.Add(time.Duration(req.DurationTicks) * d.targetTickDuration)
+			events = []*domain.Event{
+				d.newInjectedEvent(req.SessionId, domain.EventSessionTrainingStarted, meta, timestamp, 0),
+				d.newInjectedEvent(req.SessionId, domain.EventSessionTrainingEnded, meta, endTimestamp, 0),
+			}
+		}
+	case domain.EventSessionStopped.String():
+		{
+			// The local index ensures that any "training-ended" events of the session are processed first.
+			events = []*domain.Event{
+				d.newInjectedEvent(req.SessionId, domain.EventSessionStopped, meta, timestamp, math.MaxInt32),
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: \"%s\"", ErrUnsupportedInjectedEvent, req.EventName)
+	}
+
+	for _, evt := range events {
+		d.eventQueue.EnqueueEvent(evt)
+
+		d.auditEventQueueMutation(domain.EventQueueEventInjected, evt.SessionId, evt.ID, evt.Timestamp,
+			fmt.Sprintf("Injected \"%s\" event for session \"%s\".", evt.Name.String(), evt.SessionId))
+	}
+
+	d.logger.Debug("Injected event(s) into event queue.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapTraceSessionIDKey, req.SessionId),
+		zap.String("event_name", req.EventName),
+		zap.Int("num_events", len(events)),
+		zap.Time("timestamp", timestamp))
+
+	return events, nil
+}
+
+// DropEvent removes the specified event from the event queue of the workload so that it is never processed.
+func (d *BasicWorkloadDriver) DropEvent(sessionId string, eventId string) (*domain.Event, error) {
+	if !d.workload.IsInProgress() {
+		return nil, domain.ErrWorkloadNotRunning
+	}
+
+	evt, err := d.eventQueue.RemoveEvent(sessionId, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	d.auditEventQueueMutation(domain.EventQueueEventDropped, sessionId, eventId, evt.Timestamp,
+		fmt.Sprintf("Dropped \"%s\" event of session \"%s\".", evt.Name.String(), sessionId))
+
+	return evt, nil
+}
+
+// PostponeEvent pushes the timestamp of the specified event back by the specified (positive) amount of time.
+func (d *BasicWorkloadDriver) PostponeEvent(sessionId string, eventId string, amount time.Duration) (*domain.Event, error) {
+	if !d.workload.IsInProgress() {
+		return nil, domain.ErrWorkloadNotRunning
+	}
+
+	evt, err := d.eventQueue.PostponeEvent(sessionId, eventId, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	d.auditEventQueueMutation(domain.EventQueueEventPostponed, sessionId, eventId, evt.Timestamp,
+		fmt.Sprintf("Postponed \"%s\" event of session \"%s\" by %v.", evt.Name.String(), sessionId, amount))
+
+	return evt, nil
+}
+
+// ShiftSessionSchedule moves all the enqueued events of the specified session by the specified amount of time.
+// A negative amount moves the session's schedule forward (i.e., earlier), while a positive amount moves it back.
+//
+// Unlike delays incurred while processing events, shifting a session's schedule is not counted as a session delay.
+func (d *BasicWorkloadDriver) ShiftSessionSchedule(sessionId string, amount time.Duration) error {
+	if !d.workload.IsInProgress() {
+		return domain.ErrWorkloadNotRunning
+	}
+
+	err := d.eventQueue.DelaySession(sessionId, amount)
+	if err != nil {
+		return err
+	}
+
+	d.auditEventQueueMutation(domain.EventQueueSessionShifted, sessionId, "", d.currentTick.GetClockTime(),
+		fmt.Sprintf("Shifted schedule of session \"%s\" by %v.", sessionId, amount))
+
+	return nil
+}
+
+// newInjectedEvent creates a new domain.Event targeting the specified session for injection into the event queue.
+func (d *BasicWorkloadDriver) newInjectedEvent(sessionId string, name domain.SessionEventName, meta domain.SessionMetadata,
+	timestamp time.Time, localIndex int) *domain.Event {
+
+	return &domain.Event{
+		Name:              name,
+		Data:              meta,
+		SessionId:         sessionId,
+		LocalIndex:        localIndex,
+		Timestamp:         timestamp,
+		OriginalTimestamp: timestamp,
+		ID:                uuid.NewString(),
+		GlobalIndex:       injectedEventGlobalIndexBase + d.injectedEventIndex.Add(1),
+		HeapIndex:         -1,
+	}
+}
+
+// auditEventQueueMutation records a manual modification of the event queue in the workload's processed events.
+func (d *BasicWorkloadDriver) auditEventQueueMutation(name domain.WorkloadEventName, sessionId string, eventId string,
+	eventTimestamp time.Time, description string) {
+
+	d.workload.ProcessedEvent(domain.NewEmptyWorkloadEvent().
+		WithEventId(eventId).
+		WithSessionId(sessionId).
+		WithEventName(name).
+		WithEventTimestamp(eventTimestamp).
+		WithStatus(domain.Audited).
+		WithDescription(description).
+		WithProcessedAtTime(time.Now()).
+		WithSimProcessedAtTime(d.clockTime.GetClockTime()))
+}
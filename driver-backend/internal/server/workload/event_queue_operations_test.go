@@ -0,0 +1,155 @@
+package workload
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/generator"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/event_queue"
+)
+
+var _ = Describe("Event Queue Operation Tests", func() {
+	var (
+		driver      *BasicWorkloadDriver
+		traceEvents []*domain.Event
+	)
+
+	// traceEvent creates an event of session "A" that occurs the specified number of seconds into the workload.
+	traceEvent := func(name domain.EventName, index uint64, seconds int) *domain.Event {
+		timestamp := time.Unix(int64(seconds), 0)
+		return &domain.Event{
+			Name:              name,
+			GlobalIndex:       index,
+			LocalIndex:        int(index),
+			ID:                uuid.NewString(),
+			Timestamp:         timestamp,
+			OriginalTimestamp: timestamp,
+			SessionId:         "A",
+			Data:              &generator.SessionMeta{Pod: "A"},
+		}
+	}
+
+	// popAll pops all the events of the driver's event queue, in order.
+	popAll := func() []*domain.Event {
+		events := make([]*domain.Event, 0, driver.eventQueue.Len())
+		for evt := driver.eventQueue.Pop(time.Unix(1<<20, 0)); evt != nil; evt = driver.eventQueue.Pop(time.Unix(1<<20, 0)) {
+			events = append(events, evt)
+		}
+
+		return events
+	}
+
+	BeforeEach(func() {
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		driver = newTestDriver(&atom)
+
+		driver.workloadSessions = []*domain.WorkloadTemplateSession{templateTestSession("A", 2)}
+		workload, err := NewWorkloadFromTemplate(NewBuilder(&atom).SetID(driver.id).Build(), driver.workloadSessions)
+		Expect(err).To(BeNil())
+		driver.workload = workload
+
+		driver.sessions.Set("A", domain.NewWorkloadSession("A", &generator.SessionMeta{Pod: "A"},
+			domain.NewResourceRequest(0, 0, 0, 0, AnyGPU), driver.clockTime.GetClockTime(), &atom))
+
+		traceEvents = []*domain.Event{
+			traceEvent(domain.EventSessionTrainingStarted, 0, 30),
+			traceEvent(domain.EventSessionTrainingEnded, 1, 90),
+			traceEvent(domain.EventSessionTrainingStarted, 2, 150),
+		}
+		for _, evt := range traceEvents {
+			driver.eventQueue.EnqueueEvent(evt)
+		}
+	})
+
+	It("Will only manipulate the event queue of running workloads", func() {
+		_, err := driver.InjectEvent(&domain.InjectEventRequest{SessionId: "A", EventName: InjectedTrainingEventName, DurationTicks: 1})
+		Expect(errors.Is(err, domain.ErrWorkloadNotRunning)).To(BeTrue())
+
+		_, err = driver.DropEvent("A", traceEvents[0].ID)
+		Expect(errors.Is(err, domain.ErrWorkloadNotRunning)).To(BeTrue())
+
+		err = driver.ShiftSessionSchedule("A", time.Second)
+		Expect(errors.Is(err, domain.ErrWorkloadNotRunning)).To(BeTrue())
+
+		Expect(driver.eventQueue.Len()).To(Equal(len(traceEvents)))
+	})
+
+	Context("Running workloads", func() {
+		BeforeEach(func() {
+			Expect(driver.workload.StartWorkload()).To(BeNil())
+		})
+
+		It("Will return injected events in timestamp order", func() {
+			injected, err := driver.InjectEvent(&domain.InjectEventRequest{
+				SessionId:     "A",
+				EventName:     InjectedTrainingEventName,
+				TicksFromNow:  1,
+				DurationTicks: 1,
+			})
+			Expect(err).To(BeNil())
+			Expect(injected).To(HaveLen(2))
+
+			stopped, err := driver.InjectEvent(&domain.InjectEventRequest{
+				SessionId:    "A",
+				EventName:    domain.EventSessionStopped.String(),
+				TicksFromNow: 2,
+			})
+			Expect(err).To(BeNil())
+			Expect(stopped).To(HaveLen(1))
+
+			// The injected "training-ended" event and "session-stopped" event occur at the same time, in which case
+			// the "training-ended" event is returned first.
+			Expect(popAll()).To(Equal([]*domain.Event{
+				traceEvents[0], injected[0], traceEvents[1], injected[1], stopped[0], traceEvents[2],
+			}))
+		})
+
+		It("Will reject injected events that are not supported", func() {
+			_, err := driver.InjectEvent(&domain.InjectEventRequest{SessionId: "B", EventName: InjectedTrainingEventName, DurationTicks: 1})
+			Expect(errors.Is(err, domain.ErrUnknownSession)).To(BeTrue())
+
+			_, err = driver.InjectEvent(&domain.InjectEventRequest{SessionId: "A", EventName: InjectedTrainingEventName})
+			Expect(errors.Is(err, ErrInvalidTrainingDuration)).To(BeTrue())
+
+			_, err = driver.InjectEvent(&domain.InjectEventRequest{SessionId: "A", EventName: InjectedTrainingEventName, TicksFromNow: -1, DurationTicks: 1})
+			Expect(errors.Is(err, ErrInvalidInjectionTime)).To(BeTrue())
+
+			_, err = driver.InjectEvent(&domain.InjectEventRequest{SessionId: "A", EventName: domain.EventSessionReady.String()})
+			Expect(errors.Is(err, ErrUnsupportedInjectedEvent)).To(BeTrue())
+
+			Expect(driver.eventQueue.Len()).To(Equal(len(traceEvents)))
+		})
+
+		It("Will drop enqueued events", func() {
+			dropped, err := driver.DropEvent("A", traceEvents[1].ID)
+			Expect(err).To(BeNil())
+			Expect(dropped).To(Equal(traceEvents[1]))
+
+			_, err = driver.DropEvent("A", traceEvents[1].ID)
+			Expect(errors.Is(err, event_queue.ErrEventNotFound)).To(BeTrue())
+
+			Expect(popAll()).To(Equal([]*domain.Event{traceEvents[0], traceEvents[2]}))
+
+			// Dropping an event is recorded in the workload's processed events, but it is not counted as processed.
+			Expect(driver.workload.GetStatistics().EventsProcessed).To(HaveLen(1))
+			Expect(driver.workload.GetStatistics().NumEventsProcessed).To(BeZero())
+		})
+
+		It("Will shift the schedule of a session without delaying the session", func() {
+			Expect(driver.ShiftSessionSchedule("A", -time.Second*30)).To(BeNil())
+
+			delay, err := driver.eventQueue.GetSessionDelay("A")
+			Expect(err).To(BeNil())
+			Expect(delay).To(Equal(-time.Second * 30))
+
+			Expect(driver.eventQueue.Pop(time.Unix(0, 0))).To(Equal(traceEvents[0]))
+			Expect(driver.workloadSessions[0].TotalDelayMilliseconds).To(BeZero())
+		})
+	})
+})
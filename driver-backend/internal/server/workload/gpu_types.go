@@ -0,0 +1,189 @@
+package workload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// gpuTypes keeps track of the GPU types required by the sessions and trainings of a workload.
+type gpuTypes struct {
+	assigner  *domain.GpuTypeAssigner
+	trainings map[string]*gpuTypeTraining // trainings is a map from internal session ID to the session's current training.
+	mu        sync.Mutex
+}
+
+// gpuTypeTraining is a training that has been submitted and that has not yet stopped.
+type gpuTypeTraining struct {
+	gpuType     string
+	gpus        int
+	utilization float64
+	submittedAt time.Time
+}
+
+// configureGpuTypes assigns GPU types to the sessions of the workload as specified by the given domain.GpuTypeConfig,
+// which may be nil. configureGpuTypes must be called after the workload is assigned to the driver.
+func (d *BasicWorkloadDriver) configureGpuTypes(config *domain.GpuTypeConfig) {
+	if config == nil && d.workloadPreset != nil {
+		config = d.workloadPreset.GetGpuTypes()
+	}
+
+	d.gpuTypes = &gpuTypes{
+		assigner:  domain.NewGpuTypeAssigner(config, d.workload.GetSeed()),
+		trainings: make(map[string]*gpuTypeTraining),
+	}
+
+	// The GPU types of template sessions are known from the template itself.
+	for _, session := range d.workloadSessions {
+		if session != nil {
+			d.gpuTypes.assigner.SetSessionGpuTypes(d.getInternalSessionId(session.GetId()), session.GpuTypes)
+		}
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.GpuTypeStatistics = make(map[string]*GpuTypeStatistics)
+	})
+
+	d.logger.Debug("Configured GPU types of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.Any("gpu_types", config))
+}
+
+// sessionGpuTypes returns the GPU types that are acceptable for the specified session.
+func (d *BasicWorkloadDriver) sessionGpuTypes(internalSessionId string) domain.GpuTypes {
+	if d.gpuTypes == nil {
+		return nil
+	}
+
+	return d.gpuTypes.assigner.AssignGpuTypes(internalSessionId)
+}
+
+// templateTrainingEvent returns the domain.TrainingEvent of the workload template that corresponds to the training of
+// the given 'training-started' event, or nil if the workload is not based on a template.
+func (d *BasicWorkloadDriver) templateTrainingEvent(evt *domain.Event) *domain.TrainingEvent {
+	// The trainings completed by the session are counted by the workload, rather than by the driver's own Session.
+	traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
+	trainingsCompleted, loaded := d.workload.GetSessionTrainingsCompleted(traceSessionId)
+	if !loaded {
+		return nil
+	}
+
+	return d.workload.getSessionTrainingEvent(traceSessionId, trainingsCompleted)
+}
+
+// trainingGpuTypes returns the GPU types that are acceptable for the training of the given 'training-started' event,
+// which are those of the training's session unless the workload template specifies others for the training.
+func (d *BasicWorkloadDriver) trainingGpuTypes(evt *domain.Event, internalSessionId string) domain.GpuTypes {
+	if trainingEvent := d.templateTrainingEvent(evt); trainingEvent != nil && len(trainingEvent.GpuTypes) > 0 {
+		return trainingEvent.GpuTypes
+	}
+
+	return d.sessionGpuTypes(internalSessionId)
+}
+
+// updateGpuTypeStatistics applies the given function to the statistics of the specified GPU type.
+func (d *BasicWorkloadDriver) updateGpuTypeStatistics(gpuType string, f func(stats *GpuTypeStatistics)) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		gpuTypeStats, loaded := stats.GpuTypeStatistics[gpuType]
+		if !loaded {
+			gpuTypeStats = &GpuTypeStatistics{
+				GpuType:                 gpuType,
+				TrainingWaitTimesMillis: make([]int64, 0),
+			}
+			stats.GpuTypeStatistics[gpuType] = gpuTypeStats
+		}
+
+		f(gpuTypeStats)
+	})
+}
+
+// gpuTypeSessionCreated records the demand of a new session for its preferred GPU type.
+func (d *BasicWorkloadDriver) gpuTypeSessionCreated(internalSessionId string) {
+	if d.gpuTypes == nil {
+		return
+	}
+
+	d.updateGpuTypeStatistics(d.sessionGpuTypes(internalSessionId).Preferred(), func(stats *GpuTypeStatistics) {
+		stats.NumSessions += 1
+	})
+}
+
+// gpuTypeTrainingSubmitted records the demand of the training of the given 'training-started' event
+// for its preferred GPU type.
+func (d *BasicWorkloadDriver) gpuTypeTrainingSubmitted(evt *domain.Event, internalSessionId string) {
+	if d.gpuTypes == nil {
+		return
+	}
+
+	meta := evt.Data.(domain.SessionMetadata)
+	training := &gpuTypeTraining{
+		gpuType:     d.trainingGpuTypes(evt, internalSessionId).Preferred(),
+		gpus:        meta.GetCurrentTrainingMaxGPUs(),
+		utilization: meta.GetGpuUtilization(),
+		submittedAt: d.clockTime.GetClockTime(),
+	}
+
+	d.gpuTypes.mu.Lock()
+	d.gpuTypes.trainings[internalSessionId] = training
+	d.gpuTypes.mu.Unlock()
+
+	d.updateGpuTypeStatistics(training.gpuType, func(stats *GpuTypeStatistics) {
+		stats.NumTrainings += 1
+		stats.GpusRequested += int64(training.gpus)
+	})
+}
+
+// gpuTypeTrainingStartDelayed records the delay between the submission and the start of the current training
+// of the specified session against the training's preferred GPU type.
+func (d *BasicWorkloadDriver) gpuTypeTrainingStartDelayed(internalSessionId string, delay time.Duration) {
+	if d.gpuTypes == nil {
+		return
+	}
+
+	d.gpuTypes.mu.Lock()
+	training, loaded := d.gpuTypes.trainings[internalSessionId]
+	d.gpuTypes.mu.Unlock()
+
+	if !loaded {
+		return
+	}
+
+	d.updateGpuTypeStatistics(training.gpuType, func(stats *GpuTypeStatistics) {
+		stats.TrainingWaitTimesMillis = append(stats.TrainingWaitTimesMillis, delay.Milliseconds())
+		stats.CumulativeTrainingWaitTimeMillis += delay.Milliseconds()
+	})
+}
+
+// gpuTypeTrainingStopped records the GPU-time and utilization of the current training of the specified session if
+// the training completed. Otherwise, the training's demand is withdrawn, as the training will be submitted again.
+func (d *BasicWorkloadDriver) gpuTypeTrainingStopped(internalSessionId string, completed bool) {
+	if d.gpuTypes == nil {
+		return
+	}
+
+	d.gpuTypes.mu.Lock()
+	training, loaded := d.gpuTypes.trainings[internalSessionId]
+	delete(d.gpuTypes.trainings, internalSessionId)
+	d.gpuTypes.mu.Unlock()
+
+	if !loaded {
+		return
+	}
+
+	duration := max(d.clockTime.GetClockTime().Sub(training.submittedAt), 0)
+
+	d.updateGpuTypeStatistics(training.gpuType, func(stats *GpuTypeStatistics) {
+		if !completed {
+			stats.NumTrainings -= 1
+			stats.GpusRequested -= int64(training.gpus)
+			return
+		}
+
+		stats.NumTrainingsCompleted += 1
+		stats.GpuSeconds += float64(training.gpus) * duration.Seconds()
+		stats.AverageGpuUtilization += (training.utilization - stats.AverageGpuUtilization) / float64(stats.NumTrainingsCompleted)
+	})
+}
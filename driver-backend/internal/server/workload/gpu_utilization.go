@@ -0,0 +1,152 @@
+package workload
+
+import (
+	"fmt"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const (
+	// GpuUtilizationCommTarget is the target of the comm over which the GPU utilization of a session's trainings is
+	// sent to the session's kernel, which may use it to adjust the load that it generates.
+	GpuUtilizationCommTarget = "gpu_utilization"
+)
+
+// handleUpdateGpuUtilizationEvent handles an 'update-gpu-util' event, which reports a change in the GPU utilization
+// of a training session. The utilization is recorded in the session's GPU utilization time series and forwarded
+// both to the session's kernel and, as a resource-usage hint, to the Cluster Gateway.
+//
+// Updates that cannot be forwarded do not abort the workload.
+func (d *BasicWorkloadDriver) handleUpdateGpuUtilizationEvent(evt *domain.Event) error {
+	meta := evt.Data.(domain.SessionMetadata)
+	traceSessionId := meta.GetPod()
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+
+	// The state of the session is maintained by the workload, which identifies the session by its trace ID.
+	state, loaded := d.workload.GetSessionState(traceSessionId)
+	if !loaded {
+		return fmt.Errorf("%w: session \"%s\"", domain.ErrUnknownSession, traceSessionId)
+	}
+
+	// The update no longer applies if the training failed to start or was stopped early.
+	if state != domain.SessionTraining {
+		d.logger.Debug("Ignoring GPU utilization update of session that is not training.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.String(ZapTraceSessionIDKey, traceSessionId),
+			zap.String("session_state", state.String()))
+		return nil
+	}
+
+	sample := domain.NewGpuUtilizationSample(d.clockTime.GetClockTime(), meta)
+	d.workload.RecordSessionGpuUtilization(traceSessionId, sample)
+
+	if err := d.sendGpuUtilization(internalSessionId, sample); err != nil {
+		d.workload.UpdateStatistics(func(stats *Statistics) {
+			stats.NumGpuUtilizationUpdatesFailed += 1
+		})
+
+		d.logger.Warn("Failed to forward GPU utilization update of session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Float64("gpu_utilization", sample.Utilization),
+			zap.Error(err))
+		return nil
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.NumGpuUtilizationUpdates += 1
+	})
+
+	d.logger.Debug("Forwarded GPU utilization update of session.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Float64("gpu_utilization", sample.Utilization),
+		zap.Int("num_gpus", sample.NumGpus))
+
+	return nil
+}
+
+// sendGpuUtilization attaches the given domain.GpuUtilizationSample to the metadata of the messages sent to the
+// kernel of the specified session and sends it to the kernel via the GpuUtilizationCommTarget comm, which is opened
+// by the first update of the session.
+//
+// The hint is attached before the comm message is sent, so that the comm message itself carries the hint to the
+// Cluster Gateway, as do all later messages sent to the kernel.
+func (d *BasicWorkloadDriver) sendGpuUtilization(internalSessionId string, sample *domain.GpuUtilizationSample) error {
+	kernelConnection, err := d.getKernelConnection(internalSessionId)
+	if err != nil {
+		return err
+	}
+
+	if err = kernelConnection.AddMetadata(jupyter.ResourceUsageHintMetadataKey, sample); err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"utilization":         sample.Utilization,
+		"per_gpu_utilization": sample.PerGpuUtilization(),
+		"num_gpus":            sample.NumGpus,
+		"vram_gb":             sample.VramGb,
+	}
+
+	d.gpuUtilizationCommsMutex.Lock()
+	commId, loaded := d.gpuUtilizationComms[internalSessionId]
+	d.gpuUtilizationCommsMutex.Unlock()
+
+	if loaded {
+		return kernelConnection.SendCommMessage(commId, data)
+	}
+
+	// The first update of the session is sent with the "comm_open" message.
+	commId, err = kernelConnection.OpenComm(GpuUtilizationCommTarget, data)
+	if err != nil {
+		return err
+	}
+
+	d.gpuUtilizationCommsMutex.Lock()
+	d.gpuUtilizationComms[internalSessionId] = commId
+	d.gpuUtilizationCommsMutex.Unlock()
+
+	return nil
+}
+
+// gpuUtilizationTrainingStopped resets the resource-usage hint of the specified session once its current training
+// stops, so that the Cluster Gateway does not keep seeing the utilization of the training.
+func (d *BasicWorkloadDriver) gpuUtilizationTrainingStopped(internalSessionId string) {
+	d.gpuUtilizationCommsMutex.Lock()
+	_, loaded := d.gpuUtilizationComms[internalSessionId]
+	d.gpuUtilizationCommsMutex.Unlock()
+
+	// Sessions that never received an update have no hint to reset.
+	if !loaded {
+		return
+	}
+
+	kernelConnection, err := d.getKernelConnection(internalSessionId)
+	if err != nil {
+		return
+	}
+
+	hint := &domain.GpuUtilizationSample{Timestamp: d.clockTime.GetClockTime()}
+	if err = kernelConnection.AddMetadata(jupyter.ResourceUsageHintMetadataKey, hint); err != nil {
+		d.logger.Warn("Failed to reset resource-usage hint of session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Error(err))
+	}
+}
+
+// gpuUtilizationSessionStopped discards the GpuUtilizationCommTarget comm of the specified session, as the session
+// stopped.
+func (d *BasicWorkloadDriver) gpuUtilizationSessionStopped(internalSessionId string) {
+	d.gpuUtilizationCommsMutex.Lock()
+	delete(d.gpuUtilizationComms, internalSessionId)
+	d.gpuUtilizationCommsMutex.Unlock()
+}
@@ -0,0 +1,276 @@
+package workload
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const (
+	// InteractiveCellCode is the code executed by kernels to simulate an interactive cell. It keeps the CPU busy
+	// for the duration of the cell, in seconds, without using a GPU.
+	InteractiveCellCode = `
+# This is the code we run in a notebook cell to simulate a short, interactive execution.
+import time
+deadline = time.time() + %f
+while time.time() < deadline:
+    pass
+`
+
+	// DefaultInteractiveCellTimeout is how long the driver waits for the "execute_reply" of an interactive cell.
+	DefaultInteractiveCellTimeout = time.Minute * 2
+)
+
+// interactiveCells generates the interactive cells of a workload's sessions from their per-session rates.
+type interactiveCells struct {
+	config        *domain.InteractiveCellConfig
+	sampler       *domain.InteractiveCellSampler
+	templateRates map[string]float64 // templateRates is a map from session ID to the rate specified by the workload template.
+	scheduled     map[string]string  // scheduled is a map from internal session ID to the ID of the session's next generated cell.
+	mu            sync.Mutex
+}
+
+// configureInteractiveCells configures the interactive cells of the workload as specified by the given
+// domain.InteractiveCellConfig, which may be nil, and by the sessions of the workload template, if any.
+// configureInteractiveCells must be called after the workload is assigned to the driver.
+func (d *BasicWorkloadDriver) configureInteractiveCells(config *domain.InteractiveCellConfig) {
+	templateRates := make(map[string]float64)
+	for _, session := range d.workloadSessions {
+		if session != nil && session.InteractiveCellsPerHour > 0 {
+			templateRates[session.GetId()] = session.InteractiveCellsPerHour
+		}
+	}
+
+	if config == nil && len(templateRates) == 0 {
+		return
+	}
+
+	d.interactiveCells = &interactiveCells{
+		config:        config,
+		sampler:       domain.NewInteractiveCellSampler(config, d.workload.GetSeed()),
+		templateRates: templateRates,
+		scheduled:     make(map[string]string),
+	}
+
+	d.logger.Debug("Configured interactive cells of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.Any("interactive_cells", config),
+		zap.Int("num_template_rates", len(templateRates)))
+}
+
+// scheduleInteractiveCell enqueues the next generated interactive cell of the specified session, if the session
+// executes interactive cells at a positive rate.
+func (d *BasicWorkloadDriver) scheduleInteractiveCell(internalSessionId string, meta domain.SessionMetadata) {
+	if d.interactiveCells == nil {
+		return
+	}
+
+	cells := d.interactiveCells
+	traceSessionId := meta.GetPod()
+
+	gap, ok := cells.sampler.NextArrival(cells.config.RateOf(traceSessionId, cells.templateRates[traceSessionId]))
+	if !ok {
+		return
+	}
+
+	// The session's delay is applied to the cell by the event queue, so it is subtracted here, as the gap is
+	// measured from the current time.
+	delay, err := d.eventQueue.GetSessionDelay(internalSessionId)
+	if err != nil {
+		d.logger.Error("Could not schedule interactive cell of session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Error(err))
+		return
+	}
+
+	cell := &domain.InteractiveCell{
+		SessionMetadata: meta,
+		Duration:        cells.sampler.Duration(),
+	}
+
+	timestamp := d.clockTime.GetClockTime().Add(gap - delay)
+	evt := d.newInjectedEvent(traceSessionId, domain.EventSessionInteractiveCell, cell, timestamp, 0)
+
+	cells.mu.Lock()
+	cells.scheduled[internalSessionId] = evt.ID
+	cells.mu.Unlock()
+
+	d.eventQueue.EnqueueEvent(evt)
+}
+
+// unscheduleInteractiveCell removes the next generated interactive cell of the specified session from the event
+// queue, as the session stopped.
+func (d *BasicWorkloadDriver) unscheduleInteractiveCell(internalSessionId string) {
+	if d.interactiveCells == nil {
+		return
+	}
+
+	d.interactiveCells.mu.Lock()
+	eventId, loaded := d.interactiveCells.scheduled[internalSessionId]
+	delete(d.interactiveCells.scheduled, internalSessionId)
+	d.interactiveCells.mu.Unlock()
+
+	if !loaded {
+		return
+	}
+
+	// The cell may already have been dequeued, in which case it is ignored once it is handled.
+	_, _ = d.eventQueue.RemoveEvent(internalSessionId, eventId)
+}
+
+// handleInteractiveCellEvent handles an 'interactive-cell' event by submitting a short, CPU-only cell to the
+// session's kernel. Cells that arrive while the session is training are skipped, as the user would have to wait
+// for the training to finish.
+//
+// The cell executes in the background, and its failure does not abort the workload.
+func (d *BasicWorkloadDriver) handleInteractiveCellEvent(evt *domain.Event) error {
+	meta := evt.Data.(domain.SessionMetadata)
+	traceSessionId := meta.GetPod()
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+
+	// The state of the session is maintained by the workload, which identifies the session by its trace ID.
+	state, loaded := d.workload.GetSessionState(traceSessionId)
+	if !loaded {
+		return fmt.Errorf("%w: session \"%s\"", domain.ErrUnknownSession, traceSessionId)
+	}
+
+	if state == domain.SessionStopped {
+		return nil
+	}
+
+	// Generated cells are always followed by the next cell of the session.
+	if cell, ok := evt.Data.(*domain.InteractiveCell); ok {
+		d.scheduleInteractiveCell(internalSessionId, cell.SessionMetadata)
+	}
+
+	if state == domain.SessionTraining {
+		d.workload.UpdateStatistics(func(stats *Statistics) {
+			stats.NumInteractiveCellsSkipped += 1
+		})
+
+		d.logger.Debug("Skipping interactive cell of training session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.String(ZapTraceSessionIDKey, traceSessionId))
+		return nil
+	}
+
+	var duration time.Duration
+	if cellMetadata, ok := evt.Data.(domain.InteractiveCellMetadata); ok {
+		duration = cellMetadata.GetInteractiveCellDuration()
+	}
+
+	kernelConnection, err := d.getKernelConnection(internalSessionId)
+	if err != nil {
+		d.interactiveCellFailed(internalSessionId, err)
+		return nil
+	}
+
+	// The cell runs for its duration in real time, which is scaled just like the duration of the ticks.
+	executionTime := time.Duration(d.timescaleAdjustmentFactor * float64(duration))
+
+	replyChan := make(chan jupyter.KernelMessage, 1)
+	executeRequestArgs := jupyter.NewRequestExecuteArgsBuilder().
+		Code(fmt.Sprintf(InteractiveCellCode, executionTime.Seconds())).
+		Silent(false).
+		StoreHistory(true).
+		UserExpressions(nil).
+		AllowStdin(true).
+		StopOnError(false).
+		AwaitResponse(false).
+		OnResponseCallback(func(resp jupyter.KernelMessage) {
+			replyChan <- resp
+		}).
+		AddMetadata("resource_request", &domain.ResourceRequest{}). // Interactive cells do not use any GPUs.
+		Build()
+
+	sentRequestAt := time.Now()
+	if _, err = kernelConnection.RequestExecute(executeRequestArgs); err != nil {
+		d.interactiveCellFailed(internalSessionId, err)
+		return nil
+	}
+
+	go d.awaitInteractiveCellReply(kernelConnection, internalSessionId, executionTime, sentRequestAt, replyChan)
+
+	return nil
+}
+
+// awaitInteractiveCellReply waits for the "execute_reply" of an interactive cell and records its latency, which
+// is the time from the submission of the cell until its reply, as well as its overhead, which is the part of the
+// latency during which the cell was not executing.
+//
+// Important: this will be called in its own goroutine.
+func (d *BasicWorkloadDriver) awaitInteractiveCellReply(kernelConnection jupyter.KernelConnection, internalSessionId string,
+	executionTime time.Duration, sentRequestAt time.Time, replyChan chan jupyter.KernelMessage) {
+
+	select {
+	case reply := <-replyChan:
+		{
+			latency := time.Since(sentRequestAt)
+
+			content, _ := reply.GetContent().(map[string]interface{})
+			status := &jupyter.ReplyStatus{}
+			status.Status, _ = content["status"].(string)
+			status.EName, _ = content["ename"].(string)
+			status.EValue, _ = content["evalue"].(string)
+
+			if err := status.Err(); err != nil {
+				d.interactiveCellFailed(internalSessionId, err)
+				return
+			}
+
+			overhead := max(latency-executionTime, 0)
+
+			d.workload.UpdateStatistics(func(stats *Statistics) {
+				stats.NumInteractiveCells += 1
+				stats.InteractiveCellLatenciesMillis = append(stats.InteractiveCellLatenciesMillis, latency.Milliseconds())
+				stats.CumulativeInteractiveCellLatencyMillis += latency.Milliseconds()
+				stats.InteractiveCellOverheadsMillis = append(stats.InteractiveCellOverheadsMillis, overhead.Milliseconds())
+				stats.CumulativeInteractiveCellOverheadMillis += overhead.Milliseconds()
+			})
+
+			d.logger.Debug("Interactive cell finished executing.",
+				zap.String("workload_id", d.workload.GetId()),
+				zap.String("workload_name", d.workload.WorkloadName()),
+				zap.String(ZapInternalSessionIDKey, internalSessionId),
+				zap.Duration("execution_time", executionTime),
+				zap.Duration("latency", latency),
+				zap.Duration("overhead", overhead))
+		}
+	case <-time.After(executionTime + DefaultInteractiveCellTimeout):
+		{
+			d.recordTimeout(metrics.TimeoutInteractiveCell)
+			d.interactiveCellFailed(internalSessionId, jupyter.ErrRequestTimedOut)
+
+			// Interrupt the cell so that it does not hold up the session's trainings.
+			if err := kernelConnection.InterruptKernel(); err != nil {
+				d.logger.Error("Failed to interrupt kernel after interactive cell timed out.",
+					zap.String("workload_id", d.workload.GetId()),
+					zap.String(ZapInternalSessionIDKey, internalSessionId),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+// interactiveCellFailed records that an interactive cell of the specified session could not be executed.
+func (d *BasicWorkloadDriver) interactiveCellFailed(internalSessionId string, err error) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.NumInteractiveCellsFailed += 1
+	})
+
+	d.logger.Warn("Interactive cell failed.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Error(err))
+}
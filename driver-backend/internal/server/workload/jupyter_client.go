@@ -0,0 +1,119 @@
+package workload
+
+import (
+	"strings"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+// NewJupyterClientConfig returns the jupyter.ClientConfig specified by the given domain.Configuration.
+func NewJupyterClientConfig(opts *domain.Configuration) *jupyter.ClientConfig {
+	config := &jupyter.ClientConfig{
+		BaseUrl:            opts.JupyterServerBasePath,
+		Token:              opts.JupyterServerToken,
+		Password:           opts.JupyterServerPassword,
+		UseTLS:             opts.JupyterServerUseTLS,
+		CACertFile:         opts.JupyterServerCACertFile,
+		InsecureSkipVerify: opts.JupyterServerInsecureSkipVerify,
+		Headers:            make(map[string]string),
+		ReconnectionPolicy: NewReconnectionPolicy(opts),
+	}
+
+	for _, header := range strings.Split(opts.JupyterServerHeaders, ",") {
+		name, value, found := strings.Cut(header, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			continue
+		}
+
+		config.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return config
+}
+
+// NewReconnectionPolicy returns the jupyter.ReconnectionPolicy specified by the given domain.Configuration.
+//
+// Unset (i.e., zero-valued) parameters take the value of the jupyter.DefaultReconnectionPolicy, whereas negative
+// values disable the associated limit.
+func NewReconnectionPolicy(opts *domain.Configuration) *jupyter.ReconnectionPolicy {
+	policy := jupyter.DefaultReconnectionPolicy()
+
+	if opts.KernelReconnectInitialBackoffMillis > 0 {
+		policy.InitialBackoff = time.Millisecond * time.Duration(opts.KernelReconnectInitialBackoffMillis)
+	}
+
+	if opts.KernelReconnectMaxBackoffMillis > 0 {
+		policy.MaxBackoff = time.Millisecond * time.Duration(opts.KernelReconnectMaxBackoffMillis)
+	}
+
+	if opts.KernelReconnectMaxAttempts > 0 {
+		policy.MaxAttempts = opts.KernelReconnectMaxAttempts
+	} else if opts.KernelReconnectMaxAttempts < 0 {
+		policy.MaxAttempts = 0
+	}
+
+	if opts.KernelReconnectMaxElapsedSec > 0 {
+		policy.MaxElapsedTime = time.Second * time.Duration(opts.KernelReconnectMaxElapsedSec)
+	} else if opts.KernelReconnectMaxElapsedSec < 0 {
+		policy.MaxElapsedTime = 0
+	}
+
+	if opts.KernelReconnectCircuitThreshold > 0 {
+		policy.CircuitBreakerThreshold = opts.KernelReconnectCircuitThreshold
+	} else if opts.KernelReconnectCircuitThreshold < 0 {
+		policy.CircuitBreakerThreshold = 0
+	}
+
+	if opts.KernelReconnectCircuitCooldownSec > 0 {
+		policy.CircuitBreakerCooldown = time.Second * time.Duration(opts.KernelReconnectCircuitCooldownSec)
+	}
+
+	policy.ReplayPendingRequests = !opts.KernelReconnectDisableReplay
+
+	return policy
+}
+
+// defaultPooledKernelSpec is the kernel spec for which generic kernels are kept warm if none are configured.
+const defaultPooledKernelSpec = "distributed"
+
+// NewSessionPoolConfig returns the jupyter.SessionPoolConfig specified by the given domain.Configuration,
+// or nil if the domain.Configuration does not enable the session pool.
+func NewSessionPoolConfig(opts *domain.Configuration) *jupyter.SessionPoolConfig {
+	config := &jupyter.SessionPoolConfig{
+		WarmKernelsPerSpec: opts.SessionPoolWarmKernels,
+		KernelSpecs:        make([]string, 0),
+		WarmResourceSpec: &jupyter.ResourceSpec{
+			Cpu:  opts.SessionPoolWarmMillicpus,
+			Mem:  opts.SessionPoolWarmMemoryMb,
+			Gpu:  opts.SessionPoolWarmGpus,
+			Vram: opts.SessionPoolWarmVramGb,
+		},
+		MaxPrewarmedSessions: opts.SessionPoolMaxPrewarmed,
+	}
+
+	for _, kernelSpec := range strings.Split(opts.SessionPoolKernelSpecs, ",") {
+		if kernelSpec = strings.TrimSpace(kernelSpec); kernelSpec != "" {
+			config.KernelSpecs = append(config.KernelSpecs, kernelSpec)
+		}
+	}
+
+	if len(config.KernelSpecs) == 0 {
+		config.KernelSpecs = append(config.KernelSpecs, defaultPooledKernelSpec)
+	}
+
+	if !config.Enabled() {
+		return nil
+	}
+
+	return config
+}
+
+// NewKernelSessionManager creates a new jupyter.BasicKernelSessionManager that connects to the Jupyter Server
+// as specified by the given domain.Configuration.
+func NewKernelSessionManager(opts *domain.Configuration, atom *zap.AtomicLevel, metricsConsumer jupyter.MetricsConsumer) (*jupyter.BasicKernelSessionManager, error) {
+	return jupyter.NewKernelSessionManagerWithConfig(opts.InternalJupyterServerAddress, NewJupyterClientConfig(opts),
+		true, atom, metricsConsumer)
+}
@@ -0,0 +1,38 @@
+package workload
+
+import (
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+)
+
+// KernelConnections returns the kernels of the specified sessions, keyed by session ID, so that a kernel console
+// can be attached to them. If no session IDs are specified, then the kernels of all the workload's sessions are
+// returned. Sessions without a kernel are omitted.
+func (d *BasicWorkloadDriver) KernelConnections(sessionIds ...string) map[string]jupyter.KernelConnection {
+	d.sessionConnectionsMutex.Lock()
+	defer d.sessionConnectionsMutex.Unlock()
+
+	kernels := make(map[string]jupyter.KernelConnection)
+	addKernel := func(sessionId string, sessionConnection *jupyter.SessionConnection) {
+		if sessionConnection == nil {
+			return
+		}
+
+		if kernel := sessionConnection.Kernel(); kernel != nil {
+			kernels[sessionId] = kernel
+		}
+	}
+
+	if len(sessionIds) == 0 {
+		for sessionId, sessionConnection := range d.sessionConnections {
+			addKernel(sessionId, sessionConnection)
+		}
+
+		return kernels
+	}
+
+	for _, sessionId := range sessionIds {
+		addKernel(sessionId, d.sessionConnections[d.getInternalSessionId(sessionId)])
+	}
+
+	return kernels
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
 	"github.com/zhangjyr/gocsv"
 	"sync"
 	"sync/atomic"
@@ -17,6 +18,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mattn/go-colorable"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -38,6 +40,7 @@ type BasicWorkloadManager struct {
 	mu                       sync.Mutex                                           // Synchronizes access to the workload drivers and the workloads themselves (both the map and the slice).
 	workloadStartedChan      chan string                                          // Channel of workload IDs. When a workload is started, its ID is submitted to this channel.
 	callbackProvider         CallbackProvider                                     // callbackProvider provides a number of functions required by the WorkloadManager, WorkloadDriver instances, or Workload instances themselves.
+	jupyterServers           map[string]*jupyter.ServerClient                     // Map from address to a client of each Jupyter Server whose orphaned sessions are reconciled. Guarded by mu.
 }
 
 func init() {
@@ -73,6 +76,12 @@ type CallbackProvider interface {
 	// GetSchedulingPolicy returns the configured scheduling policy along with a flag indicating whether the returned
 	// policy name is valid.
 	GetSchedulingPolicy() (string, bool)
+
+	// IsConnectedToGateway returns true if the dashboard backend is currently connected to the Cluster Gateway.
+	IsConnectedToGateway() bool
+
+	// PublishWorkloadEvent publishes a workload lifecycle event to the event bus.
+	PublishWorkloadEvent(event *events.Event)
 }
 
 func NewWorkloadManager(configuration *domain.Configuration, atom *zap.AtomicLevel, callbackProvider CallbackProvider) *BasicWorkloadManager {
@@ -90,6 +99,7 @@ func NewWorkloadManager(configuration *domain.Configuration, atom *zap.AtomicLev
 		//refreshClusterStatistics: provider.RefreshAndClearClusterStatistics,
 		//getSchedulingPolicy:      provider.GetSchedulingPolicy,
 		callbackProvider: callbackProvider,
+		jupyterServers:   make(map[string]*jupyter.ServerClient),
 	}
 
 	zapConfig := zap.NewDevelopmentEncoderConfig()
@@ -296,14 +306,109 @@ func (m *BasicWorkloadManager) UnpauseWorkload(workloadId string) (domain.Worklo
 	return workloadDriver.GetWorkload(), nil
 }
 
+// StepWorkloadTick steps the specified paused workload by exactly one tick.
+func (m *BasicWorkloadManager) StepWorkloadTick(workloadId string) (domain.Workload, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	err := workloadDriver.StepTick()
+	if err != nil {
+		return nil, err
+	}
+
+	return workloadDriver.GetWorkload(), nil
+}
+
+// StepWorkloadEvent steps the specified paused workload by exactly one event.
+func (m *BasicWorkloadManager) StepWorkloadEvent(workloadId string) (domain.Workload, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	err := workloadDriver.StepEvent()
+	if err != nil {
+		return nil, err
+	}
+
+	return workloadDriver.GetWorkload(), nil
+}
+
+// ContinueWorkload resumes the specified paused workload, which will run until a breakpoint is hit.
+func (m *BasicWorkloadManager) ContinueWorkload(workloadId string) (domain.Workload, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	err := workloadDriver.ContinueWorkload()
+	if err != nil {
+		return nil, err
+	}
+
+	return workloadDriver.GetWorkload(), nil
+}
+
+// AddBreakpoint adds a breakpoint to the specified workload.
+// If successful, then this returns all the breakpoints of the workload.
+func (m *BasicWorkloadManager) AddBreakpoint(workloadId string, breakpoint *domain.Breakpoint) ([]*domain.Breakpoint, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	if _, err := workloadDriver.AddBreakpoint(breakpoint); err != nil {
+		return nil, err
+	}
+
+	return workloadDriver.Breakpoints(), nil
+}
+
+// RemoveBreakpoint removes a breakpoint from the specified workload.
+// If successful, then this returns the remaining breakpoints of the workload.
+func (m *BasicWorkloadManager) RemoveBreakpoint(workloadId string, breakpointId string) ([]*domain.Breakpoint, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	if err := workloadDriver.RemoveBreakpoint(breakpointId); err != nil {
+		return nil, err
+	}
+
+	return workloadDriver.Breakpoints(), nil
+}
+
+// InspectWorkload returns a snapshot of the internal state of the specified paused workload.
+func (m *BasicWorkloadManager) InspectWorkload(workloadId string, numUpcomingEvents int) (*WorkloadInspection, error) {
+	workloadDriver := m.GetWorkloadDriver(workloadId)
+	if workloadDriver == nil {
+		m.logger.Error("Could not find workload driver with specified workload ID.", zap.String("workload_id", workloadId))
+		return nil, fmt.Errorf("%w: \"%s\"", domain.ErrWorkloadNotFound, workloadId)
+	}
+
+	return workloadDriver.InspectWorkload(numUpcomingEvents)
+}
+
 // RegisterWorkload registers a new workload.
 func (m *BasicWorkloadManager) RegisterWorkload(request *domain.WorkloadRegistrationRequest, ws domain.ConcurrentWebSocket) (domain.Workload, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// Create a new workload driver.
-	workloadDriver := NewBasicWorkloadDriver(m.configuration, true, request.TimescaleAdjustmentFactor,
+	workloadDriver, err := NewBasicWorkloadDriver(m.configuration, true, request.TimescaleAdjustmentFactor,
 		ws, m.atom, m.callbackProvider)
+	if err != nil {
+		m.logger.Error("Failed to create workload driver.", zap.Any("workload-registration-request", request), zap.Error(err))
+		return nil, err
+	}
 
 	// Register a new workload with the workload driver.
 	workload, err := workloadDriver.RegisterWorkload(request)
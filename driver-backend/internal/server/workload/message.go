@@ -1,6 +1,8 @@
 package workload
 
 import (
+	"encoding/json"
+
 	"github.com/google/uuid"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 )
@@ -111,3 +113,19 @@ func (b *responseBuilder) BuildResponse() *domain.WorkloadResponse {
 
 	return response
 }
+
+// DebugWorkloadResponse is the response to a step-debugging request that returns the breakpoints of a workload
+// or a snapshot of the internal state of a workload, rather than the workload itself.
+type DebugWorkloadResponse struct {
+	Operation   string               `json:"op"`     // The operation of the original request.
+	Status      string               `json:"status"` // OK or ERROR.
+	MessageId   string               `json:"msg_id"` // Unique ID of the message.
+	WorkloadId  string               `json:"workload_id"`
+	Breakpoints []*domain.Breakpoint `json:"breakpoints"`
+	Inspection  *WorkloadInspection  `json:"inspection,omitempty"`
+}
+
+// Encode the response to a JSON format.
+func (r *DebugWorkloadResponse) Encode() ([]byte, error) {
+	return json.Marshal(r)
+}
@@ -0,0 +1,343 @@
+package workload
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const (
+	// notebookOutputHandlerIdSuffix is appended to the driver's ID to form the ID of the IOPub handler that
+	// captures the outputs of notebook cells.
+	notebookOutputHandlerIdSuffix = "-notebook-outputs"
+
+	// notebookCellTimedOutStatus is the status recorded for notebook cells whose "execute_reply" never arrived.
+	notebookCellTimedOutStatus = "timed-out"
+)
+
+var (
+	ErrNoRemainingNotebookCells = errors.New("session has already executed all of its notebook's cells")
+	ErrNotebookCellFailed       = errors.New("notebook cell raised an error")
+	ErrNotebookCellTimedOut     = errors.New("timed-out waiting for notebook cell to finish executing")
+)
+
+// createWorkloadFromNotebooks creates a workload that replays the code cells of the notebooks specified
+// in the workload registration request.
+func (d *BasicWorkloadDriver) createWorkloadFromNotebooks(workloadRegistrationRequest *domain.WorkloadRegistrationRequest) (*Notebook, error) {
+	if len(workloadRegistrationRequest.Notebooks) == 0 {
+		d.logger.Error("Workload Registration Request for notebook-based workload is missing the notebooks!")
+		return nil, ErrWorkloadRegistrationMissingNotebooks
+	}
+
+	d.logger.Debug("Creating new workload from notebooks.",
+		zap.String("workload_name", workloadRegistrationRequest.WorkloadName),
+		zap.Int("num_notebooks", len(workloadRegistrationRequest.Notebooks)))
+
+	// Every notebook is replayed unless the request explicitly specifies otherwise.
+	sessionsSamplePercentage := workloadRegistrationRequest.SessionsSamplePercentage
+	if sessionsSamplePercentage <= 0 {
+		sessionsSamplePercentage = 1.0
+	}
+
+	d.workloadRegistrationRequest = workloadRegistrationRequest
+	basicWorkload := NewBuilder(d.atom).
+		SetID(d.id).
+		SetWorkloadName(workloadRegistrationRequest.WorkloadName).
+		SetSeed(workloadRegistrationRequest.Seed).
+		EnableDebugLogging(workloadRegistrationRequest.DebugLogging).
+		SetTimescaleAdjustmentFactor(workloadRegistrationRequest.TimescaleAdjustmentFactor).
+		SetRemoteStorageDefinition(workloadRegistrationRequest.RemoteStorageDefinition).
+		SetSessionsSamplePercentage(sessionsSamplePercentage).
+		SetSessionSampling(workloadRegistrationRequest.SessionSampling).
+		Build()
+
+	workloadFromNotebooks, err := NewWorkloadFromNotebooks(basicWorkload, workloadRegistrationRequest.Notebooks,
+		workloadRegistrationRequest.NotebookThinkTime, d.targetTickDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	d.workloadSessions = workloadFromNotebooks.Sessions
+
+	return workloadFromNotebooks, nil
+}
+
+// uploadNotebook uploads the notebook replayed by the specified session via the Jupyter contents API so that
+// the session's kernel is associated with the actual notebook.
+func (d *BasicWorkloadDriver) uploadNotebook(sessionId string, notebookPath string) error {
+	content, loaded := d.workload.(*Notebook).GetNotebookContent(sessionId)
+	if !loaded {
+		return fmt.Errorf("%w: no notebook found for session \"%s\"", domain.ErrUnknownSession, sessionId)
+	}
+
+	err := d.sessionRouteOf(d.getInternalSessionId(sessionId)).kernelManager.UploadNotebook(notebookPath, content)
+	if err != nil {
+		d.logger.Error("Failed to upload notebook.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapTraceSessionIDKey, sessionId),
+			zap.String("path", notebookPath),
+			zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// handleNotebookCellStartedEvent handles a 'training-started' event of a notebook-based workload by submitting
+// the next code cell of the session's notebook to the session's kernel.
+//
+// The session's events are held until the cell finishes executing so that the session's subsequent cells
+// are not submitted until the current cell has finished.
+func (d *BasicWorkloadDriver) handleNotebookCellStartedEvent(evt *domain.Event) error {
+	traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+
+	if _, ok := d.sessions.Get(internalSessionId); !ok {
+		return fmt.Errorf("%w: session \"%s\"", domain.ErrUnknownSession, internalSessionId)
+	}
+
+	kernelConnection, err := d.getKernelConnection(internalSessionId)
+	if err != nil {
+		return err
+	}
+
+	cell, cellIndex := d.workload.(*Notebook).NextCell(traceSessionId)
+	if cell == nil {
+		return fmt.Errorf("%w: \"%s\"", ErrNoRemainingNotebookCells, traceSessionId)
+	}
+
+	replyChan := make(chan jupyter.KernelMessage, 1)
+	executeRequestArgs, err := d.createExecuteRequestArguments(evt, string(cell.Source), func(resp jupyter.KernelMessage) {
+		replyChan <- resp
+	})
+	if err != nil {
+		return err
+	}
+
+	d.resetNotebookCellOutputs(internalSessionId)
+	d.outputCapture.BeginTraining(internalSessionId)
+
+	sentRequestAt := time.Now()
+	if _, err = kernelConnection.RequestExecute(executeRequestArgs); err != nil {
+		d.logger.Error("Error while submitting notebook cell to kernel.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Int("cell_index", cellIndex),
+			zap.Error(err))
+		return err
+	}
+
+	d.trainingSubmittedTimes.Set(internalSessionId, sentRequestAt.UnixMilli())
+	d.workload.TrainingSubmitted(internalSessionId, evt)
+	d.workload.TrainingStarted(internalSessionId, d.convertTimestampToTickNumber(d.currentTick.GetClockTime()))
+
+	if err = d.eventQueue.HoldEventsForSession(internalSessionId); err != nil {
+		d.logger.Error("Could not place hold on session events.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Error(err))
+		return err
+	}
+
+	go d.awaitNotebookCellReply(kernelConnection, traceSessionId, cellIndex, sentRequestAt, replyChan)
+
+	return nil
+}
+
+// handleNotebookCellEndedEvent handles a 'training-ended' event of a notebook-based workload.
+//
+// Because the session's events are held until its current cell finishes executing, the cell has already
+// finished by the time that the 'training-ended' event is processed, so there is nothing to stop.
+func (d *BasicWorkloadDriver) handleNotebookCellEndedEvent(evt *domain.Event, tick time.Time) error {
+	traceSessionId := evt.Data.(domain.SessionMetadata).GetPod()
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+
+	if _, ok := d.sessions.Get(internalSessionId); !ok {
+		return fmt.Errorf("%w: session \"%s\"", domain.ErrUnknownSession, internalSessionId)
+	}
+
+	d.workload.TrainingStopped(traceSessionId, evt, d.convertTimestampToTickNumber(tick))
+	d.recordRouteTaskExecuted(internalSessionId)
+	return nil
+}
+
+// awaitNotebookCellReply waits for the "execute_reply" of a notebook cell, records the execution of the cell
+// in the workload's events, and then releases the hold on the session's events.
+//
+// Important: this will be called in its own goroutine.
+func (d *BasicWorkloadDriver) awaitNotebookCellReply(kernelConnection jupyter.KernelConnection, traceSessionId string,
+	cellIndex int, sentRequestAt time.Time, replyChan chan jupyter.KernelMessage) {
+
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+
+	timeout := DefaultNotebookCellTimeout
+	if d.workloadRegistrationRequest.NotebookCellTimeoutSec > 0 {
+		timeout = time.Duration(d.workloadRegistrationRequest.NotebookCellTimeoutSec) * time.Second
+	}
+
+	execution := &domain.NotebookCellExecution{
+		Notebook:  traceSessionId,
+		CellIndex: cellIndex,
+	}
+
+	var err error
+	select {
+	case reply := <-replyChan:
+		{
+			execution.LatencyMilli = time.Since(sentRequestAt).Milliseconds()
+
+			content, ok := reply.GetContent().(map[string]interface{})
+			if !ok {
+				d.logger.Error("\"execute_reply\" message of notebook cell does not have any content.",
+					zap.String("workload_id", d.workload.GetId()),
+					zap.String(ZapInternalSessionIDKey, internalSessionId))
+			}
+
+			status := &jupyter.ReplyStatus{}
+			status.Status, _ = content["status"].(string)
+			status.EName, _ = content["ename"].(string)
+			status.EValue, _ = content["evalue"].(string)
+
+			execution.Status = status.Status
+			execution.ErrorName = status.EName
+			execution.ErrorValue = status.EValue
+
+			if status.Err() != nil {
+				err = fmt.Errorf("%w: cell %d of notebook \"%s\": %s: %s",
+					ErrNotebookCellFailed, cellIndex, traceSessionId, status.EName, status.EValue)
+			}
+		}
+	case <-time.After(timeout):
+		{
+			execution.LatencyMilli = time.Since(sentRequestAt).Milliseconds()
+			execution.Status = notebookCellTimedOutStatus
+			err = fmt.Errorf("%w: cell %d of notebook \"%s\" after %v", ErrNotebookCellTimedOut, cellIndex, traceSessionId, timeout)
+
+			d.recordTimeout(metrics.TimeoutNotebookCell)
+
+			// Interrupt the cell so that the session's subsequent cells can be executed.
+			if interruptErr := kernelConnection.InterruptKernel(); interruptErr != nil {
+				d.logger.Error("Failed to interrupt kernel after notebook cell timed out.",
+					zap.String("workload_id", d.workload.GetId()),
+					zap.String(ZapInternalSessionIDKey, internalSessionId),
+					zap.Error(interruptErr))
+			}
+		}
+	}
+
+	execution.Outputs = d.takeNotebookCellOutputs(internalSessionId)
+
+	d.logger.Debug("Notebook cell finished executing.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Int("cell_index", cellIndex),
+		zap.String("status", execution.Status),
+		zap.Int64("latency_ms", execution.LatencyMilli),
+		zap.Int("num_outputs", len(execution.Outputs)))
+
+	d.workload.ProcessedEvent(domain.NewEmptyWorkloadEvent().
+		WithEventId(uuid.NewString()).
+		WithSessionId(traceSessionId).
+		WithEventName(domain.EventNotebookCellExecuted).
+		WithEventTimestamp(d.currentTick.GetClockTime()).
+		WithStatus(domain.Processed).
+		WithDescription(fmt.Sprintf("Executed cell %d of notebook \"%s\" in %d ms with status \"%s\".",
+			cellIndex, traceSessionId, execution.LatencyMilli, execution.Status)).
+		WithData(execution).
+		WithProcessedAtTime(time.Now()).
+		WithSimProcessedAtTime(d.clockTime.GetClockTime()).
+		WithError(err))
+
+	if err = d.eventQueue.ReleaseEventHoldForSession(internalSessionId); err != nil {
+		d.logger.Error("Could not release hold on session events.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Error(err))
+	}
+}
+
+// recordNotebookCellOutput is an IOPubMessageHandler that captures the outputs of notebook cells.
+func (d *BasicWorkloadDriver) recordNotebookCellOutput(conn jupyter.KernelConnection, kernelMessage jupyter.KernelMessage) interface{} {
+	messageType := kernelMessage.GetHeader().MessageType
+	switch messageType {
+	case "stream", "execute_result", "display_data", "error":
+	default:
+		return false
+	}
+
+	content, ok := kernelMessage.GetContent().(map[string]interface{})
+	if !ok {
+		d.logger.Warn("Notebook cell output message does not have any content.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("kernel_id", conn.KernelId()),
+			zap.String("message_type", messageType.String()))
+		return false
+	}
+
+	output := make(map[string]interface{}, len(content)+1)
+	for key, val := range content {
+		output[key] = val
+	}
+	output["output_type"] = messageType.String()
+
+	d.notebookCellOutputsMutex.Lock()
+	defer d.notebookCellOutputsMutex.Unlock()
+
+	d.notebookCellOutputs[conn.KernelId()] = append(d.notebookCellOutputs[conn.KernelId()], output)
+	return true
+}
+
+// resetNotebookCellOutputs discards any outputs captured for the specified session.
+func (d *BasicWorkloadDriver) resetNotebookCellOutputs(internalSessionId string) {
+	d.notebookCellOutputsMutex.Lock()
+	defer d.notebookCellOutputsMutex.Unlock()
+
+	delete(d.notebookCellOutputs, internalSessionId)
+}
+
+// takeNotebookCellOutputs returns and discards the outputs captured for the specified session.
+func (d *BasicWorkloadDriver) takeNotebookCellOutputs(internalSessionId string) []interface{} {
+	d.notebookCellOutputsMutex.Lock()
+	defer d.notebookCellOutputsMutex.Unlock()
+
+	outputs := d.notebookCellOutputs[internalSessionId]
+	delete(d.notebookCellOutputs, internalSessionId)
+	return outputs
+}
+
+// getKernelConnection returns the kernel connection of the specified session.
+func (d *BasicWorkloadDriver) getKernelConnection(internalSessionId string) (jupyter.KernelConnection, error) {
+	d.sessionConnectionsMutex.Lock()
+	sessionConnection, loaded := d.sessionConnections[internalSessionId]
+	d.sessionConnectionsMutex.Unlock()
+
+	if !loaded {
+		d.logger.Error("No session connection found for session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId))
+		return nil, ErrNoSessionConnection
+	}
+
+	kernelConnection := sessionConnection.Kernel()
+	if kernelConnection == nil {
+		d.logger.Error("No kernel connection found for session connection.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId))
+		return nil, ErrNoKernelConnection
+	}
+
+	return kernelConnection, nil
+}
@@ -0,0 +1,69 @@
+package workload
+
+import (
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/reconciler"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+// LiveWorkloadIds returns the IDs of the registered workloads that have not yet finished. The Jupyter sessions
+// of any other workload are orphaned.
+func (m *BasicWorkloadManager) LiveWorkloadIds() map[string]struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	liveWorkloadIds := make(map[string]struct{})
+	for _, workload := range m.workloads {
+		if !workload.IsFinished() {
+			liveWorkloadIds[workload.GetId()] = struct{}{}
+		}
+	}
+
+	return liveWorkloadIds
+}
+
+// JupyterServers returns the Jupyter Servers on which the registered workloads may have created sessions,
+// starting with the configured Jupyter Server.
+func (m *BasicWorkloadManager) JupyterServers() []reconciler.JupyterServer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	addresses := []string{m.configuration.InternalJupyterServerAddress}
+	for el := m.workloadDrivers.Front(); el != nil; el = el.Next() {
+		addresses = append(addresses, el.Value.jupyterServerAddresses()...)
+	}
+
+	servers := make([]reconciler.JupyterServer, 0, len(addresses))
+	seen := make(map[string]struct{}, len(addresses))
+	for _, address := range addresses {
+		if _, loaded := seen[address]; loaded {
+			continue
+		}
+		seen[address] = struct{}{}
+
+		client, loaded := m.jupyterServers[address]
+		if !loaded {
+			var err error
+			if client, err = jupyter.NewServerClient(address, NewJupyterClientConfig(m.configuration)); err != nil {
+				m.logger.Error("Failed to create client for Jupyter Server.", zap.String("address", address), zap.Error(err))
+				continue
+			}
+
+			m.jupyterServers[address] = client
+		}
+
+		servers = append(servers, client)
+	}
+
+	return servers
+}
+
+// jupyterServerAddresses returns the addresses of the Jupyter Servers of the routes of the workload.
+func (d *BasicWorkloadDriver) jupyterServerAddresses() []string {
+	addresses := []string{d.defaultRoute.address}
+	for _, route := range d.routes {
+		addresses = append(addresses, route.address)
+	}
+
+	return addresses
+}
@@ -0,0 +1,70 @@
+package workload
+
+import (
+	"path/filepath"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/output_capture"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const (
+	// outputCaptureHandlerIdSuffix is appended to the driver's ID to form the ID of the IOPub handler that
+	// captures the outputs of each training.
+	outputCaptureHandlerIdSuffix = "-output-capture"
+)
+
+// newOutputCaptureStore creates the output_capture.Store of the driver. Records evicted from memory are spilled
+// to a driver-specific subdirectory of the configured spill directory.
+func (d *BasicWorkloadDriver) newOutputCaptureStore() *output_capture.Store {
+	config := output_capture.Config{MaxRecordsPerSession: d.opts.OutputCaptureMaxRecordsPerSession}
+	if d.opts.OutputCaptureSpillDirectory != "" {
+		config.SpillDirectory = filepath.Join(d.opts.OutputCaptureSpillDirectory, d.id)
+	}
+
+	return output_capture.NewStore(config, d.logger)
+}
+
+// OutputCapture returns the output_capture.Store that holds the captured outputs of the workload's trainings.
+func (d *BasicWorkloadDriver) OutputCapture() *output_capture.Store {
+	return d.outputCapture
+}
+
+// captureOutput returns an IOPubMessageHandler that records the outputs of the specified session.
+func (d *BasicWorkloadDriver) captureOutput(internalSessionId string) jupyter.IOPubMessageHandler {
+	return func(conn jupyter.KernelConnection, kernelMessage jupyter.KernelMessage) interface{} {
+		messageType := kernelMessage.GetHeader().MessageType.String()
+		if !output_capture.IsOutputMessageType(messageType) {
+			return false
+		}
+
+		content, ok := kernelMessage.GetContent().(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		parentMsgId := ""
+		if parentHeader := kernelMessage.GetParentHeader(); parentHeader != nil {
+			parentMsgId = parentHeader.MessageId
+		}
+
+		record, err := d.outputCapture.Add(internalSessionId, kernelMessage.GetHeader().MessageId, parentMsgId, messageType, content)
+		if err != nil {
+			d.logger.Debug("Discarding output received after output capture was closed.",
+				zap.String(ZapInternalSessionIDKey, internalSessionId),
+				zap.String("kernel_id", conn.KernelId()),
+				zap.String("message_type", messageType))
+			return false
+		}
+
+		if record.OutputType == output_capture.ErrorOutput {
+			d.logger.Debug("Captured error output of training.",
+				zap.String(ZapInternalSessionIDKey, internalSessionId),
+				zap.Int("training_index", record.TrainingIndex),
+				zap.String("ename", record.Ename),
+				zap.String("evalue", record.Evalue))
+		}
+
+		return true
+	}
+}
@@ -17,12 +17,18 @@ type Session interface {
 	GetTrainingsCompleted() int
 	GetState() domain.SessionState
 	GetCreatedAt() time.Time
+	// GetMeta returns the SessionMetadata with which the Session was created.
+	GetMeta() domain.SessionMetadata
 	GetTrainingStartedAt() time.Time
 	GetTrainings() []*domain.TrainingEvent
 	GetStderrIoPubMessages() []string
 	GetStdoutIoPubMessages() []string
 	AddStderrIoPubMessage(message string)
 	AddStdoutIoPubMessage(message string)
+	// GetGpuUtilizationSeries returns the time series of the Session's GPU utilization during its trainings.
+	GetGpuUtilizationSeries() []*domain.GpuUtilizationSample
+	// AddGpuUtilizationSample appends the given GpuUtilizationSample to the Session's GPU utilization time series.
+	AddGpuUtilizationSample(sample *domain.GpuUtilizationSample)
 	// NumFailedTicks returns the number of times that this Session failed to process all of its events during a tick
 	// of a workload.
 	NumFailedTicks() int
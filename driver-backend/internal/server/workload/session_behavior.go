@@ -0,0 +1,88 @@
+package workload
+
+import (
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// setSessionBehavior configures the domain.SessionBehaviorModel of the workload being driven.
+//
+// setSessionBehavior must be called after the workload is assigned to the driver, as the patience of the
+// users is sampled using the workload's seed.
+func (d *BasicWorkloadDriver) setSessionBehavior(model *domain.SessionBehaviorModel) {
+	d.sessionBehavior = domain.NewSessionBehavior(model, d.workload.GetSeed())
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.SessionBehavior = d.sessionBehavior.Model()
+	})
+
+	d.logger.Debug("Configured session behavior model.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String("behavior_model", model.Type()))
+}
+
+// trainingStartDelayed applies the delay between the submission and the start of a training of the specified
+// session according to the domain.SessionBehaviorModel of the workload.
+//
+// The retained part of the delay is applied via delaySession. The transient part of the delay only pushes back
+// the events of the session until the training ends, at which point liftTransientDelay removes it again.
+func (d *BasicWorkloadDriver) trainingStartDelayed(sessionId string, delay time.Duration) {
+	trainingDelay := d.sessionBehavior.TrainingDelayed(delay)
+
+	if trainingDelay.Retained > 0 {
+		d.delaySession(sessionId, trainingDelay.Retained)
+	}
+
+	if trainingDelay.Transient > 0 {
+		if err := d.eventQueue.DelaySession(sessionId, trainingDelay.Transient); err != nil {
+			panic(err)
+		}
+
+		d.transientDelaysMutex.Lock()
+		d.transientDelays[sessionId] += trainingDelay.Transient
+		d.transientDelaysMutex.Unlock()
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.AbsorbedQueuingDelayMillis += trainingDelay.Transient.Milliseconds()
+
+		if trainingDelay.Abandoned {
+			stats.NumAbandonedTrainings += 1
+		}
+	})
+
+	if trainingDelay.Abandoned {
+		d.logger.Debug("User abandoned training after exhausting their patience.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, sessionId),
+			zap.Duration("delay", delay),
+			zap.Duration("patience", trainingDelay.Patience))
+	}
+}
+
+// liftTransientDelay removes the transient delay applied by trainingStartDelayed from the events of the specified
+// session, so that the session's next training is submitted as if the previous training had not been delayed.
+func (d *BasicWorkloadDriver) liftTransientDelay(sessionId string) {
+	d.transientDelaysMutex.Lock()
+	transientDelay, loaded := d.transientDelays[sessionId]
+	delete(d.transientDelays, sessionId)
+	d.transientDelaysMutex.Unlock()
+
+	if !loaded || transientDelay == 0 {
+		return
+	}
+
+	// The session may have no more events, in which case there is nothing to lift the delay from.
+	if err := d.eventQueue.DelaySession(sessionId, -transientDelay); err != nil {
+		d.logger.Debug("Could not lift transient delay of session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, sessionId),
+			zap.Duration("transient_delay", transientDelay),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,250 @@
+package workload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// sessionDependencies enforces the domain.SessionDependencyGraph of a template-based workload by holding the events
+// of sessions whose next training depends on sessions that have not yet made enough progress.
+type sessionDependencies struct {
+	graph *domain.SessionDependencyGraph
+
+	trainingsCompleted map[string]int                    // trainingsCompleted is a map from internal session ID to the number of completed trainings.
+	stopped            map[string]struct{}               // stopped are the internal IDs of the sessions that stopped.
+	waiting            map[string]*sessionDependencyWait // waiting is a map from internal session ID to the wait of the session, if its events are held.
+	releasedBy         map[string]string                 // releasedBy is a map from internal session ID to the session whose progress last released it.
+	waitedMillis       map[string]int64                  // waitedMillis is a map from internal session ID to the total time that the session waited.
+	startedAt          map[string]time.Time              // startedAt is a map from internal session ID to the time at which the session started.
+	mu                 sync.Mutex
+}
+
+// sessionDependencyWait is a session whose events are held until the dependencies of its next training are satisfied.
+type sessionDependencyWait struct {
+	trainingIndex int
+	heldAt        time.Time
+}
+
+// configureSessionDependencies builds the domain.SessionDependencyGraph of the sessions of a template-based workload.
+// configureSessionDependencies must be called after the workload is assigned to the driver.
+func (d *BasicWorkloadDriver) configureSessionDependencies() error {
+	if len(d.workloadSessions) == 0 {
+		return nil
+	}
+
+	graph, err := domain.NewSessionDependencyGraph(d.workloadSessions)
+	if err != nil {
+		d.logger.Error("Invalid session dependencies.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.Error(err))
+		return err
+	}
+
+	if graph.IsEmpty() {
+		return nil
+	}
+
+	d.sessionDependencies = &sessionDependencies{
+		graph:              graph,
+		trainingsCompleted: make(map[string]int),
+		stopped:            make(map[string]struct{}),
+		waiting:            make(map[string]*sessionDependencyWait),
+		releasedBy:         make(map[string]string),
+		waitedMillis:       make(map[string]int64),
+		startedAt:          make(map[string]time.Time),
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.CriticalPathSessionIds = make([]string, 0)
+	})
+
+	d.logger.Debug("Configured session dependencies of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.Strings("topological_order", graph.TopologicalOrder()))
+
+	return nil
+}
+
+// unsafeDependenciesSatisfied returns true if all the dependencies of the specified training of the specified session
+// are satisfied. Dependencies on sessions that are not being sampled are ignored, as those sessions never run.
+// unsafeDependenciesSatisfied must be called with the mutex of the sessionDependencies held.
+func (d *BasicWorkloadDriver) unsafeDependenciesSatisfied(internalSessionId string, trainingIndex int) bool {
+	deps := d.sessionDependencies
+	for _, dependency := range deps.graph.Dependencies(internalSessionId) {
+		if dependency.BeforeTraining > trainingIndex {
+			continue
+		}
+
+		upstreamId := d.getInternalSessionId(dependency.SessionId)
+		_, stopped := deps.stopped[upstreamId]
+		if !dependency.IsSatisfied(deps.trainingsCompleted[upstreamId], stopped) && d.workload.IsSessionBeingSampled(upstreamId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// awaitDependencies returns true if the training of the given 'training-started' event may start. Otherwise, the
+// event is placed back in the event queue, and the events of the session are held until the sessions that the
+// training depends on have made enough progress.
+func (d *BasicWorkloadDriver) awaitDependencies(evt *domain.Event, internalSessionId string) bool {
+	if d.sessionDependencies == nil || len(d.sessionDependencies.graph.Dependencies(internalSessionId)) == 0 {
+		return true
+	}
+
+	deps := d.sessionDependencies
+	deps.mu.Lock()
+	defer deps.mu.Unlock()
+
+	// The index of the training is the number of trainings that the session has completed so far.
+	trainingIndex := deps.trainingsCompleted[internalSessionId]
+
+	if d.unsafeDependenciesSatisfied(internalSessionId, trainingIndex) {
+		return true
+	}
+
+	// The hold is placed while the mutex is held so that the session cannot be released before it is held.
+	d.eventQueue.EnqueueEvent(evt)
+	if err := d.eventQueue.HoldEventsForSession(internalSessionId); err != nil {
+		d.logger.Error("Could not place hold on events of session with unsatisfied dependencies.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.Error(err))
+		return false
+	}
+
+	deps.waiting[internalSessionId] = &sessionDependencyWait{
+		trainingIndex: trainingIndex,
+		heldAt:        d.clockTime.GetClockTime(),
+	}
+
+	d.logger.Debug("Training depends on sessions that have not made enough progress. Holding events of session.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.Int("training_index", trainingIndex))
+
+	return false
+}
+
+// dependencySessionStarted records the time at which the specified session started.
+func (d *BasicWorkloadDriver) dependencySessionStarted(internalSessionId string) {
+	if d.sessionDependencies == nil {
+		return
+	}
+
+	d.sessionDependencies.mu.Lock()
+	d.sessionDependencies.startedAt[internalSessionId] = d.clockTime.GetClockTime()
+	d.sessionDependencies.mu.Unlock()
+}
+
+// dependencyTrainingCompleted records that a training of the specified session completed, and it releases the
+// sessions whose dependencies are now satisfied.
+func (d *BasicWorkloadDriver) dependencyTrainingCompleted(internalSessionId string) {
+	if d.sessionDependencies == nil {
+		return
+	}
+
+	d.sessionDependencies.mu.Lock()
+	d.sessionDependencies.trainingsCompleted[internalSessionId] += 1
+	d.sessionDependencies.mu.Unlock()
+
+	d.releaseDependents(internalSessionId)
+}
+
+// dependencySessionStopped records that the specified session stopped, releases the sessions whose dependencies are
+// now satisfied, and updates the critical path of the workload if the session's path is the longest so far.
+func (d *BasicWorkloadDriver) dependencySessionStopped(internalSessionId string) {
+	if d.sessionDependencies == nil {
+		return
+	}
+
+	deps := d.sessionDependencies
+	stoppedAt := d.clockTime.GetClockTime()
+
+	deps.mu.Lock()
+	deps.stopped[internalSessionId] = struct{}{}
+
+	path := deps.graph.CriticalPath(internalSessionId, deps.releasedBy)
+	startedAt, started := deps.startedAt[path[0]]
+
+	var pathWaitMillis int64
+	for _, sessionId := range path {
+		pathWaitMillis += deps.waitedMillis[sessionId]
+	}
+	deps.mu.Unlock()
+
+	if started {
+		pathMillis := max(stoppedAt.Sub(startedAt), 0).Milliseconds()
+
+		d.workload.UpdateStatistics(func(stats *Statistics) {
+			if pathMillis < stats.CriticalPathMillis {
+				return
+			}
+
+			stats.CriticalPathSessionIds = path
+			stats.CriticalPathMillis = pathMillis
+			stats.CriticalPathDependencyWaitMillis = pathWaitMillis
+		})
+	}
+
+	d.releaseDependents(internalSessionId)
+}
+
+// releaseDependents releases the sessions that depend on the specified session and whose dependencies are now
+// satisfied. Each released session is delayed by the time that it waited.
+func (d *BasicWorkloadDriver) releaseDependents(internalSessionId string) {
+	deps := d.sessionDependencies
+	now := d.clockTime.GetClockTime()
+
+	released := make(map[string]time.Duration)
+
+	deps.mu.Lock()
+	for _, dependentId := range deps.graph.Dependents(internalSessionId) {
+		dependentId = d.getInternalSessionId(dependentId)
+
+		wait, loaded := deps.waiting[dependentId]
+		if !loaded || !d.unsafeDependenciesSatisfied(dependentId, wait.trainingIndex) {
+			continue
+		}
+
+		waited := max(now.Sub(wait.heldAt), 0)
+		delete(deps.waiting, dependentId)
+		deps.releasedBy[dependentId] = internalSessionId
+		deps.waitedMillis[dependentId] += waited.Milliseconds()
+		released[dependentId] = waited
+	}
+	deps.mu.Unlock()
+
+	for dependentId, waited := range released {
+		d.delaySession(dependentId, waited)
+
+		if err := d.eventQueue.ReleaseEventHoldForSession(dependentId); err != nil {
+			d.logger.Error("Could not release hold on events of session whose dependencies are satisfied.",
+				zap.String("workload_id", d.workload.GetId()),
+				zap.String("workload_name", d.workload.WorkloadName()),
+				zap.String(ZapInternalSessionIDKey, dependentId),
+				zap.Error(err))
+		}
+
+		d.workload.UpdateStatistics(func(stats *Statistics) {
+			stats.NumDependencyWaits += 1
+			stats.DependencyWaitTimesMillis = append(stats.DependencyWaitTimesMillis, waited.Milliseconds())
+			stats.CumulativeDependencyWaitMillis += waited.Milliseconds()
+		})
+
+		d.logger.Debug("Dependencies of session are satisfied. Released events of session.",
+			zap.String("workload_id", d.workload.GetId()),
+			zap.String("workload_name", d.workload.WorkloadName()),
+			zap.String(ZapInternalSessionIDKey, dependentId),
+			zap.String("released_by", internalSessionId),
+			zap.Duration("waited", waited))
+	}
+}
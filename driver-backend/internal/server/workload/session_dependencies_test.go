@@ -0,0 +1,65 @@
+package workload
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/generator"
+)
+
+var _ = Describe("Session Dependency Tests", func() {
+	var driver *BasicWorkloadDriver
+
+	trainingStartedEvent := func(sessionId string) *domain.Event {
+		return &domain.Event{
+			Name:      domain.EventSessionTrainingStarted,
+			ID:        uuid.NewString(),
+			Timestamp: time.UnixMilli(0),
+			SessionId: sessionId,
+			Data:      &generator.SessionMeta{Pod: sessionId},
+		}
+	}
+
+	BeforeEach(func() {
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		driver = newTestDriver(&atom)
+
+		// The second training of "B" depends on the first training of "A".
+		driver.workloadSessions = []*domain.WorkloadTemplateSession{
+			templateTestSession("A", 1),
+			templateTestSession("B", 2, &domain.SessionDependency{SessionId: "A", AfterTrainings: 1, BeforeTraining: 1}),
+		}
+
+		workload, err := NewWorkloadFromTemplate(NewBuilder(&atom).SetID(driver.id).Build(), driver.workloadSessions)
+		Expect(err).To(BeNil())
+		driver.workload = workload
+
+		Expect(driver.configureSessionDependencies()).To(BeNil())
+		Expect(driver.sessionDependencies).ToNot(BeNil())
+	})
+
+	It("Will hold the second training of a session until the session that it depends on has trained", func() {
+		Expect(driver.awaitDependencies(trainingStartedEvent("B"), "B")).To(BeTrue())
+		driver.dependencyTrainingCompleted("B")
+
+		Expect(driver.awaitDependencies(trainingStartedEvent("B"), "B")).To(BeFalse())
+		Expect(driver.sessionDependencies.waiting).To(HaveKey("B"))
+		Expect(driver.eventQueue.Len()).To(Equal(1))
+		Expect(driver.eventQueue.Peek(time.UnixMilli(0))).To(BeNil())
+
+		driver.dependencyTrainingCompleted("A")
+
+		Expect(driver.sessionDependencies.waiting).ToNot(HaveKey("B"))
+		Expect(driver.sessionDependencies.releasedBy).To(HaveKeyWithValue("B", "A"))
+		Expect(driver.workload.GetStatistics().NumDependencyWaits).To(Equal(int64(1)))
+		Expect(driver.eventQueue.Peek(time.UnixMilli(0))).ToNot(BeNil())
+
+		// The dependency of the second training was satisfied, so it may now start.
+		Expect(driver.awaitDependencies(trainingStartedEvent("B"), "B")).To(BeTrue())
+	})
+})
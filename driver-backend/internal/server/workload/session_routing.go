@@ -0,0 +1,265 @@
+package workload
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+// sessionRoute is a domain.SessionRoute together with the jupyter.KernelSessionManager of its Jupyter Server.
+type sessionRoute struct {
+	*domain.SessionRoute
+
+	kernelManager jupyter.KernelSessionManager
+	kernelSpec    string // kernelSpec is the kernel spec of the route, or the configured kernel spec if the route does not specify one.
+	address       string // address is the Jupyter Server address of the route, or the configured address if the route does not specify one.
+}
+
+// defaultKernelSpec returns the kernel spec used by sessions whose route does not specify one.
+func (d *BasicWorkloadDriver) defaultKernelSpec() string {
+	if d.opts.WorkloadDriverKernelSpec != "" {
+		return d.opts.WorkloadDriverKernelSpec
+	}
+
+	return defaultPooledKernelSpec
+}
+
+// newDefaultSessionRoute returns the sessionRoute used by every session of a workload that does not specify a
+// domain.SessionRoutingTable.
+func (d *BasicWorkloadDriver) newDefaultSessionRoute() *sessionRoute {
+	return &sessionRoute{
+		SessionRoute:  &domain.SessionRoute{Name: domain.DefaultRouteName},
+		kernelManager: d.kernelManager,
+		kernelSpec:    d.defaultKernelSpec(),
+		address:       d.opts.InternalJupyterServerAddress,
+	}
+}
+
+// registerKernelManagerErrorHandler forwards the errors reported by the given jupyter.KernelSessionManager to the
+// driver's non-critical error handler.
+func (d *BasicWorkloadDriver) registerKernelManagerErrorHandler(kernelManager jupyter.KernelSessionManager) {
+	if d.onNonCriticalErrorOccurred == nil {
+		return
+	}
+
+	kernelManager.RegisterOnErrorHandler(func(sessionId string, kernelId string, err error) {
+		err = fmt.Errorf("error occurred for kernel=%s,session=%s: %w", kernelId, sessionId, err)
+		d.onNonCriticalErrorOccurred(d.id, err)
+	})
+}
+
+// configureSessionRoutes creates a jupyter.KernelSessionManager for each distinct Jupyter Server of the given
+// domain.SessionRoutingTable, as well as the per-route statistics of the workload.
+//
+// The routes that do not specify a Jupyter Server share the driver's existing jupyter.KernelSessionManager.
+func (d *BasicWorkloadDriver) configureSessionRoutes(table *domain.SessionRoutingTable) error {
+	router, err := domain.NewSessionRouter(table, d.workload.GetSeed())
+	if err != nil {
+		return err
+	}
+
+	kernelManagers := map[string]jupyter.KernelSessionManager{d.opts.InternalJupyterServerAddress: d.kernelManager}
+	routes := make(map[string]*sessionRoute, len(table.Routes))
+	routeStatistics := make(map[string]*RouteStatistics, len(table.Routes))
+
+	for _, route := range router.Routes() {
+		address := route.JupyterServerAddress
+		if address == "" {
+			address = d.opts.InternalJupyterServerAddress
+		}
+
+		kernelManager, loaded := kernelManagers[address]
+		if !loaded {
+			kernelManager, err = jupyter.NewKernelSessionManagerWithConfig(address, NewJupyterClientConfig(d.opts), true, d.atom, d)
+			if err != nil {
+				d.logger.Error("Failed to create kernel session manager for session route.",
+					zap.String("route", route.Name), zap.String("jupyter_server_address", address), zap.Error(err))
+				return err
+			}
+
+			d.registerKernelManagerErrorHandler(kernelManager)
+			kernelManagers[address] = kernelManager
+		}
+
+		kernelSpec := route.KernelSpec
+		if kernelSpec == "" {
+			kernelSpec = d.defaultKernelSpec()
+		}
+
+		routes[route.Name] = &sessionRoute{
+			SessionRoute:  route,
+			kernelManager: kernelManager,
+			kernelSpec:    kernelSpec,
+			address:       address,
+		}
+
+		routeStatistics[route.Name] = &RouteStatistics{
+			JupyterServerAddress:           address,
+			KernelSpec:                     kernelSpec,
+			SessionCreationLatenciesMillis: make([]int64, 0),
+			ExecRequestTimesMillis:         make([]int64, 0),
+		}
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.RouteStatistics = routeStatistics
+	})
+
+	d.sessionRouter = router
+	d.routes = routes
+
+	d.logger.Debug("Configured session routes.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.Int("num_routes", len(routes)),
+		zap.Int("num_jupyter_servers", len(kernelManagers)))
+
+	return nil
+}
+
+// kernelManagers returns the distinct jupyter.KernelSessionManager instances used by the workload's routes.
+func (d *BasicWorkloadDriver) kernelManagers() []jupyter.KernelSessionManager {
+	kernelManagers := []jupyter.KernelSessionManager{d.kernelManager}
+
+	for _, route := range d.routes {
+		seen := false
+		for _, kernelManager := range kernelManagers {
+			if kernelManager == route.kernelManager {
+				seen = true
+				break
+			}
+		}
+
+		if !seen {
+			kernelManagers = append(kernelManagers, route.kernelManager)
+		}
+	}
+
+	return kernelManagers
+}
+
+// enableSessionPools enables the session pool of each of the workload's jupyter.KernelSessionManager instances.
+//
+// Unless kernel specs are explicitly configured for the session pool, each jupyter.KernelSessionManager keeps
+// warm kernels of the kernel specs of the routes that use it.
+func (d *BasicWorkloadDriver) enableSessionPools() {
+	for _, kernelManager := range d.kernelManagers() {
+		config := NewSessionPoolConfig(d.opts)
+
+		if config != nil && d.opts.SessionPoolKernelSpecs == "" && len(d.routes) > 0 {
+			config.KernelSpecs = make([]string, 0, 1)
+			for _, route := range d.routes {
+				if route.kernelManager == kernelManager && !slices.Contains(config.KernelSpecs, route.kernelSpec) {
+					config.KernelSpecs = append(config.KernelSpecs, route.kernelSpec)
+				}
+			}
+		}
+
+		kernelManager.EnableSessionPool(config)
+	}
+}
+
+// closeSessionPools closes the session pool of each of the workload's jupyter.KernelSessionManager instances.
+func (d *BasicWorkloadDriver) closeSessionPools() {
+	for _, kernelManager := range d.kernelManagers() {
+		kernelManager.CloseSessionPool()
+	}
+}
+
+// routeSession returns the route of the specified session, assigning the session to a route if it has not
+// already been assigned to one.
+func (d *BasicWorkloadDriver) routeSession(traceSessionId string, resourceSpec *jupyter.ResourceSpec) *sessionRoute {
+	if d.sessionRouter == nil {
+		return d.defaultRoute
+	}
+
+	gpus := 0
+	if resourceSpec != nil {
+		gpus = resourceSpec.Gpu
+	}
+
+	internalSessionId := d.getInternalSessionId(traceSessionId)
+	route := d.routes[d.sessionRouter.Route(traceSessionId, gpus).Name]
+
+	d.sessionRoutesMutex.Lock()
+	defer d.sessionRoutesMutex.Unlock()
+
+	if _, loaded := d.sessionRoutes[internalSessionId]; !loaded {
+		d.logger.Debug("Assigned session to route.",
+			zap.String(ZapInternalSessionIDKey, internalSessionId),
+			zap.String("route", route.Name),
+			zap.String("jupyter_server_address", route.address),
+			zap.String("kernel_spec", route.kernelSpec))
+		d.sessionRoutes[internalSessionId] = route
+	}
+
+	return route
+}
+
+// sessionRouteOf returns the route to which the specified session was assigned, or the default route if the
+// session has not been assigned to a route.
+func (d *BasicWorkloadDriver) sessionRouteOf(internalSessionId string) *sessionRoute {
+	d.sessionRoutesMutex.Lock()
+	defer d.sessionRoutesMutex.Unlock()
+
+	if route, loaded := d.sessionRoutes[internalSessionId]; loaded {
+		return route
+	}
+
+	return d.defaultRoute
+}
+
+// updateRouteStatistics atomically updates the RouteStatistics of the specified route using the given function.
+// updateRouteStatistics does nothing if the workload does not break its statistics down by route.
+func (d *BasicWorkloadDriver) updateRouteStatistics(route *sessionRoute, f func(stats *RouteStatistics)) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		if routeStatistics, loaded := stats.RouteStatistics[route.Name]; loaded {
+			f(routeStatistics)
+		}
+	})
+}
+
+// recordRouteSessionCreation records the (attempted) creation of a session with the given kernel ID via the
+// specified route.
+func (d *BasicWorkloadDriver) recordRouteSessionCreation(route *sessionRoute, kernelId string, latency time.Duration, err error) {
+	if err == nil && kernelId != "" {
+		d.sessionRoutesMutex.Lock()
+		d.kernelRoutes[kernelId] = route
+		d.sessionRoutesMutex.Unlock()
+	}
+
+	d.updateRouteStatistics(route, func(stats *RouteStatistics) {
+		if err != nil {
+			stats.NumFailedSessionCreations += 1
+			return
+		}
+
+		stats.NumSessionsCreated += 1
+		stats.SessionCreationLatenciesMillis = append(stats.SessionCreationLatenciesMillis, latency.Milliseconds())
+	})
+}
+
+// recordRouteTaskExecuted records that the specified session finished executing a task.
+func (d *BasicWorkloadDriver) recordRouteTaskExecuted(internalSessionId string) {
+	d.updateRouteStatistics(d.sessionRouteOf(internalSessionId), func(stats *RouteStatistics) {
+		stats.NumTasksExecuted += 1
+	})
+}
+
+// recordRouteExecRequestTime records the latency of an "execute_request" sent to the specified kernel.
+func (d *BasicWorkloadDriver) recordRouteExecRequestTime(kernelId string, latencyMilliseconds int64) {
+	d.sessionRoutesMutex.Lock()
+	route, loaded := d.kernelRoutes[kernelId]
+	d.sessionRoutesMutex.Unlock()
+
+	if !loaded {
+		return
+	}
+
+	d.updateRouteStatistics(route, func(stats *RouteStatistics) {
+		stats.ExecRequestTimesMillis = append(stats.ExecRequestTimesMillis, latencyMilliseconds)
+	})
+}
@@ -38,6 +38,13 @@ type Statistics struct {
 	CumulativeJupyterSessionCreationLatencyMillis int64   `json:"cumulative_jupyter_session_creation_latency_millis" csv:"cumulative_jupyter_session_creation_latency_millis"`
 	JupyterSessionCreationLatenciesMillis         []int64 `json:"jupyter_session_creation_latencies_millis" csv:"-"`
 
+	// NumPooledSessionCreations is the number of sessions whose kernels were handed out by the session pool, whereas
+	// NumColdSessionCreations is the number of sessions whose kernels were created on demand.
+	NumPooledSessionCreations            int64   `json:"num_pooled_session_creations" csv:"num_pooled_session_creations"`
+	NumColdSessionCreations              int64   `json:"num_cold_session_creations" csv:"num_cold_session_creations"`
+	PooledSessionCreationLatenciesMillis []int64 `json:"pooled_session_creation_latencies_millis" csv:"-"`
+	ColdSessionCreationLatenciesMillis   []int64 `json:"cold_session_creation_latencies_millis" csv:"-"`
+
 	CumulativeJupyterSessionTerminationLatencyMillis int64   `json:"cumulative_jupyter_session_termination_latency_millis" csv:"cumulative_jupyter_session_termination_latency_millis"`
 	JupyterSessionTerminationLatenciesMillis         []int64 `json:"jupyter_session_termination_latencies_millis" csv:"-"`
 
@@ -49,6 +56,13 @@ type Statistics struct {
 	// JupyterTrainingStartLatenciesDashboardMillis field of ClustStatistics; however, it is measured from the dashboard directly.
 	JupyterTrainingStartLatenciesDashboardMillis []float64 `json:"jupyter_training_start_latencies_dashboard_millis" csv:"-"`
 
+	// NumDistributedTrainings is the number of distributed trainings all of whose workers started, and
+	// GangWaitTimesMillis are the delays between the start of the first and the last worker of each of them.
+	// Gang-wait time is not included in the training start latency.
+	NumDistributedTrainings      int64   `json:"num_distributed_trainings" csv:"num_distributed_trainings"`
+	CumulativeGangWaitTimeMillis int64   `json:"cumulative_gang_wait_time_millis" csv:"cumulative_gang_wait_time_millis"`
+	GangWaitTimesMillis          []int64 `json:"gang_wait_times_millis" csv:"-"`
+
 	TotalReplyLatencyMillis   int64   `json:"total_reply_latency_millis" csv:"total_reply_latency_millis"`
 	TotalReplyLatenciesMillis []int64 `json:"total_reply_latencies_millis" csv:"total_reply_latencies_millis"`
 
@@ -58,29 +72,149 @@ type Statistics struct {
 	// including any associated overheads.
 	CumulativeTrainingTimeTicks int64 `json:"cumulative_training_time_ticks" csv:"cumulative_training_time_ticks"`
 
-	AggregateSessionDelayMillis int64                   `json:"aggregate_session_delay_ms" csv:"aggregate_session_delay_ms"`
-	CurrentTick                 int64                   `json:"current_tick" csv:"current_tick"`
-	NextEventExpectedTick       int64                   `json:"next_event_expected_tick"  csv:"next_event_expected_tick"`
-	NextExpectedEventName       domain.EventName        `json:"next_expected_event_name"  csv:"next_expected_event_name"`
-	NextExpectedEventTarget     string                  `json:"next_expected_event_target"  csv:"next_expected_event_target"`
-	NumActiveSessions           int64                   `json:"num_active_sessions"  csv:"num_active_sessions"`
-	NumActiveTrainings          int64                   `json:"num_active_trainings"  csv:"num_active_trainings"`
-	NumDiscardedSessions        int                     `json:"num_discarded_sessions"  csv:"num_discarded_sessions"`
-	NumEventsProcessed          int64                   `json:"num_events_processed"  csv:"num_events_processed"`
-	NumSampledSessions          int                     `json:"num_sampled_sessions"  csv:"num_sampled_sessions"`
-	NumSessionsCreated          int64                   `json:"num_sessions_created"  csv:"num_sessions_created"`
-	NumSubmittedTrainings       int64                   `json:"num_submitted_trainings"  csv:"num_submitted_trainings"` // NumSubmittedTrainings is the number of trainings that have been submitted but not yet started.
-	NumTasksExecuted            int64                   `json:"num_tasks_executed"  csv:"num_tasks_executed"`
-	SessionsSamplePercentage    float64                 `json:"sessions_sample_percentage"  csv:"sessions_sample_percentage"`
-	TickDurationsMillis         []int64                 `json:"tick_durations_milliseconds"  csv:"-"`
-	TimeElapsed                 time.Duration           `json:"time_elapsed"  csv:"time_elapsed"` // Computed at the time that the data is requested by the user. This is the time elapsed SO far.
-	TimeElapsedStr              string                  `json:"time_elapsed_str"  csv:"time_elapsed_str"`
-	TimeSpentPausedMillis       int64                   `json:"time_spent_paused_milliseconds"  csv:"time_spent_paused_milliseconds"`
-	TotalNumSessions            int                     `json:"total_num_sessions" csv:"total_num_sessions"  csv:"total_num_sessions"`
-	TotalNumTicks               int64                   `json:"total_num_ticks"  csv:"total_num_ticks"`
-	WorkloadDuration            time.Duration           `json:"workload_duration"  csv:"-"` // The total time that the workload executed for. This is only set once the workload has completed.
-	WorkloadState               State                   `json:"workload_state"  csv:"workload_state"`
-	EventsProcessed             []*domain.WorkloadEvent `json:"events_processed"  csv:"-"`
+	// NumDependencyWaits is the number of times that the events of a session were held until the sessions that it
+	// depends on made enough progress, and DependencyWaitTimesMillis are the durations of those holds.
+	NumDependencyWaits             int64   `json:"num_dependency_waits" csv:"num_dependency_waits"`
+	CumulativeDependencyWaitMillis int64   `json:"cumulative_dependency_wait_millis" csv:"cumulative_dependency_wait_millis"`
+	DependencyWaitTimesMillis      []int64 `json:"dependency_wait_times_millis" csv:"-"`
+	// CriticalPathSessionIds is the longest chain of stopped sessions in which each session was held until the
+	// session before it made progress. CriticalPathMillis is the time from the start of the first session to the
+	// end of the last session of the chain, of which the sessions spent CriticalPathDependencyWaitMillis waiting.
+	CriticalPathSessionIds           []string `json:"critical_path_session_ids,omitempty" csv:"-"`
+	CriticalPathMillis               int64    `json:"critical_path_millis" csv:"critical_path_millis"`
+	CriticalPathDependencyWaitMillis int64    `json:"critical_path_dependency_wait_millis" csv:"critical_path_dependency_wait_millis"`
+
+	// NumInteractiveCells is the number of short, CPU-only cells that executed between trainings, and
+	// InteractiveCellLatenciesMillis are the delays between their submission and their "execute_reply".
+	// InteractiveCellOverheadsMillis are the parts of those latencies during which the cells were not executing.
+	NumInteractiveCells                     int64   `json:"num_interactive_cells" csv:"num_interactive_cells"`
+	NumInteractiveCellsSkipped              int64   `json:"num_interactive_cells_skipped" csv:"num_interactive_cells_skipped"` // Skipped because their session was training.
+	NumInteractiveCellsFailed               int64   `json:"num_interactive_cells_failed" csv:"num_interactive_cells_failed"`
+	CumulativeInteractiveCellLatencyMillis  int64   `json:"cumulative_interactive_cell_latency_millis" csv:"cumulative_interactive_cell_latency_millis"`
+	InteractiveCellLatenciesMillis          []int64 `json:"interactive_cell_latencies_millis" csv:"-"`
+	CumulativeInteractiveCellOverheadMillis int64   `json:"cumulative_interactive_cell_overhead_millis" csv:"cumulative_interactive_cell_overhead_millis"`
+	InteractiveCellOverheadsMillis          []int64 `json:"interactive_cell_overheads_millis" csv:"-"`
+
+	// NumGpuUtilizationUpdates is the number of changes in the GPU utilization of training sessions that were
+	// forwarded to their kernels. The time series of each session is recorded by the session itself.
+	NumGpuUtilizationUpdates       int64 `json:"num_gpu_utilization_updates" csv:"num_gpu_utilization_updates"`
+	NumGpuUtilizationUpdatesFailed int64 `json:"num_gpu_utilization_updates_failed" csv:"num_gpu_utilization_updates_failed"`
+
+	// SessionBehavior is the model that decides how delayed trainings affect the rest of their session.
+	SessionBehavior *domain.SessionBehaviorModel `json:"session_behavior" csv:"-"`
+	// AbsorbedQueuingDelayMillis is the total delay in the start of trainings that was not carried over to
+	// the later trainings of their sessions, as is the case for open-loop sessions and abandoned trainings.
+	AbsorbedQueuingDelayMillis int64 `json:"absorbed_queuing_delay_ms" csv:"absorbed_queuing_delay_ms"`
+	// NumAbandonedTrainings is the number of trainings that users gave up on because they exceeded their patience.
+	NumAbandonedTrainings int64 `json:"num_abandoned_trainings" csv:"num_abandoned_trainings"`
+
+	AggregateSessionDelayMillis int64                         `json:"aggregate_session_delay_ms" csv:"aggregate_session_delay_ms"`
+	CurrentTick                 int64                         `json:"current_tick" csv:"current_tick"`
+	NextEventExpectedTick       int64                         `json:"next_event_expected_tick"  csv:"next_event_expected_tick"`
+	NextExpectedEventName       domain.EventName              `json:"next_expected_event_name"  csv:"next_expected_event_name"`
+	NextExpectedEventTarget     string                        `json:"next_expected_event_target"  csv:"next_expected_event_target"`
+	NumActiveSessions           int64                         `json:"num_active_sessions"  csv:"num_active_sessions"`
+	NumActiveTrainings          int64                         `json:"num_active_trainings"  csv:"num_active_trainings"`
+	NumDiscardedSessions        int                           `json:"num_discarded_sessions"  csv:"num_discarded_sessions"`
+	NumEventsProcessed          int64                         `json:"num_events_processed"  csv:"num_events_processed"`
+	NumSampledSessions          int                           `json:"num_sampled_sessions"  csv:"num_sampled_sessions"`
+	NumSessionsCreated          int64                         `json:"num_sessions_created"  csv:"num_sessions_created"`
+	NumSubmittedTrainings       int64                         `json:"num_submitted_trainings"  csv:"num_submitted_trainings"` // NumSubmittedTrainings is the number of trainings that have been submitted but not yet started.
+	NumTasksExecuted            int64                         `json:"num_tasks_executed"  csv:"num_tasks_executed"`
+	SessionsSamplePercentage    float64                       `json:"sessions_sample_percentage"  csv:"sessions_sample_percentage"`
+	SessionSampling             *domain.SessionSamplingConfig `json:"session_sampling"  csv:"-"`    // SessionSampling is the strategy with which the sampled sessions were selected.
+	SampledSessionIds           []string                      `json:"sampled_session_ids"  csv:"-"` // SampledSessionIds are the sampled sessions, in the order in which they were selected.
+	TickDurationsMillis         []int64                       `json:"tick_durations_milliseconds"  csv:"-"`
+	TimeElapsed                 time.Duration                 `json:"time_elapsed"  csv:"time_elapsed"` // Computed at the time that the data is requested by the user. This is the time elapsed SO far.
+	TimeElapsedStr              string                        `json:"time_elapsed_str"  csv:"time_elapsed_str"`
+	TimeSpentPausedMillis       int64                         `json:"time_spent_paused_milliseconds"  csv:"time_spent_paused_milliseconds"`
+	TotalNumSessions            int                           `json:"total_num_sessions" csv:"total_num_sessions"  csv:"total_num_sessions"`
+	TotalNumTicks               int64                         `json:"total_num_ticks"  csv:"total_num_ticks"`
+	WorkloadDuration            time.Duration                 `json:"workload_duration"  csv:"-"` // The total time that the workload executed for. This is only set once the workload has completed.
+	WorkloadState               State                         `json:"workload_state"  csv:"workload_state"`
+	EventsProcessed             []*domain.WorkloadEvent       `json:"events_processed"  csv:"-"`
+
+	// UserCostUSD is the total cost that users incurred under the serverful cost model, and UserCostResourceCredits
+	// is the same cost in resource credits, if resource credits are used.
+	UserCostUSD             float64 `json:"user_cost_usd" csv:"user_cost_usd"`
+	UserCostResourceCredits float64 `json:"user_cost_resource_credits" csv:"user_cost_resource_credits"`
+	// FaasUserCostUSD is the total cost that users incurred for their trainings under the serverless cost model.
+	FaasUserCostUSD     float64 `json:"faas_user_cost_usd" csv:"faas_user_cost_usd"`
+	DataTransferCostUSD float64 `json:"data_transfer_cost_usd" csv:"data_transfer_cost_usd"`
+	// ProviderCostUSD is the cost that the provider incurred for its hosts, based on their aggregate lifetime.
+	ProviderCostUSD float64 `json:"provider_cost_usd" csv:"provider_cost_usd"`
+	// FaasProviderCostUSD is the cost that the provider incurred for running the trainings as serverless functions.
+	FaasProviderCostUSD float64 `json:"faas_provider_cost_usd" csv:"faas_provider_cost_usd"`
+	// SessionCosts is a map from internal session ID to the cost of that session.
+	SessionCosts map[string]*SessionCost `json:"session_costs,omitempty" csv:"-"`
+
+	// GpuTypeStatistics is a map from GPU type to the statistics of the sessions and trainings that prefer that
+	// GPU type. Sessions and trainings that accept any GPU are recorded under domain.AnyGpuType.
+	GpuTypeStatistics map[string]*GpuTypeStatistics `json:"gpu_type_statistics,omitempty" csv:"-"`
+
+	// Users is a map from username to the statistics of the sessions owned by that user.
+	// Users is only populated if the workload specifies a domain.TenancyConfig.
+	Users map[string]*UserStatistics `json:"users,omitempty" csv:"-"`
+	// JainFairnessIndex is Jain's fairness index over the GPU-time received by each user.
+	JainFairnessIndex float64 `json:"jain_fairness_index" csv:"jain_fairness_index"`
+
+	// RouteStatistics is a map from route name to the statistics of the sessions assigned to that route.
+	// RouteStatistics is only populated if the workload specifies a domain.SessionRoutingTable.
+	RouteStatistics map[string]*RouteStatistics `json:"route_statistics,omitempty" csv:"-"`
+}
+
+// UserStatistics are the statistics of the sessions owned by one simulated user.
+type UserStatistics struct {
+	Username            string  `json:"username"`
+	NumSessions         int64   `json:"num_sessions"`
+	NumActiveSessions   int     `json:"num_active_sessions"`
+	NumTrainings        int64   `json:"num_trainings"`
+	NumQuotaDeferrals   int64   `json:"num_quota_deferrals"` // NumQuotaDeferrals is the number of times a session or training was deferred because the user was at their quota.
+	GpuSeconds          float64 `json:"gpu_seconds"`         // GpuSeconds is the GPU-time received by the user's trainings.
+	DelayMillis         int64   `json:"delay_ms"`            // DelayMillis is the total delay incurred by the user's sessions.
+	CostResourceCredits float64 `json:"cost_resource_credits"`
+	CostUSD             float64 `json:"cost_usd"`
+	FaasCostUSD         float64 `json:"faas_cost_usd"`
+}
+
+// GpuTypeStatistics are the statistics of the sessions and trainings that prefer one GPU type.
+type GpuTypeStatistics struct {
+	GpuType      string `json:"gpu_type"`
+	NumSessions  int64  `json:"num_sessions"`
+	NumTrainings int64  `json:"num_trainings"` // NumTrainings is the number of trainings that have been submitted.
+	// GpusRequested is the total number of GPUs requested by the submitted trainings.
+	GpusRequested                    int64   `json:"gpus_requested"`
+	NumTrainingsCompleted            int64   `json:"num_trainings_completed"`
+	CumulativeTrainingWaitTimeMillis int64   `json:"cumulative_training_wait_time_millis"`
+	TrainingWaitTimesMillis          []int64 `json:"training_wait_times_millis"`
+	GpuSeconds                       float64 `json:"gpu_seconds"` // GpuSeconds is the GPU-time of the completed trainings.
+	// AverageGpuUtilization is the mean GPU utilization of the completed trainings, as reported by the trace.
+	AverageGpuUtilization float64 `json:"average_gpu_utilization"`
+}
+
+// SessionCost is the cost of one session under the billing model of the workload.
+type SessionCost struct {
+	SessionId               string  `json:"session_id"`
+	Username                string  `json:"username,omitempty"`
+	LifetimeSec             float64 `json:"lifetime_sec"` // LifetimeSec is only set once the session has stopped.
+	TrainingTimeSec         float64 `json:"training_time_sec"`
+	NumTrainings            int64   `json:"num_trainings"`
+	UserCostUSD             float64 `json:"user_cost_usd"` // UserCostUSD is the cost of the session under the serverful cost model.
+	UserCostResourceCredits float64 `json:"user_cost_resource_credits"`
+	FaasUserCostUSD         float64 `json:"faas_user_cost_usd"` // FaasUserCostUSD is the cost of the session's trainings under the serverless cost model.
+	DataTransferCostUSD     float64 `json:"data_transfer_cost_usd"`
+	FaasProviderCostUSD     float64 `json:"faas_provider_cost_usd"`
+}
+
+// RouteStatistics are the statistics of the sessions assigned to one route of a domain.SessionRoutingTable.
+type RouteStatistics struct {
+	JupyterServerAddress           string  `json:"jupyter_server_address"`
+	KernelSpec                     string  `json:"kernel_spec"`
+	NumSessionsCreated             int64   `json:"num_sessions_created"`
+	NumFailedSessionCreations      int64   `json:"num_failed_session_creations"`
+	NumTasksExecuted               int64   `json:"num_tasks_executed"`
+	SessionCreationLatenciesMillis []int64 `json:"session_creation_latencies_millis"`
+	ExecRequestTimesMillis         []int64 `json:"exec_request_times_millis"`
 }
 
 func NewStatistics(sessionsSamplePercentage float64) *Statistics {
@@ -95,9 +229,16 @@ func NewStatistics(sessionsSamplePercentage float64) *Statistics {
 		TickDurationsMillis:                      make([]int64, 0),
 		JupyterSessionCreationLatenciesMillis:    make([]int64, 0),
 		JupyterSessionTerminationLatenciesMillis: make([]int64, 0),
+		PooledSessionCreationLatenciesMillis:     make([]int64, 0),
+		ColdSessionCreationLatenciesMillis:       make([]int64, 0),
 		JupyterExecRequestTimesMillis:            make([]int64, 0),
 		TotalReplyLatenciesMillis:                make([]int64, 0),
+		GangWaitTimesMillis:                      make([]int64, 0),
+		DependencyWaitTimesMillis:                make([]int64, 0),
+		InteractiveCellLatenciesMillis:           make([]int64, 0),
+		InteractiveCellOverheadsMillis:           make([]int64, 0),
 		SessionsSamplePercentage:                 sessionsSamplePercentage,
+		SampledSessionIds:                        make([]string, 0),
 		TimeElapsed:                              time.Duration(0),
 		CurrentTick:                              0,
 		WorkloadState:                            Ready,
@@ -0,0 +1,268 @@
+package workload
+
+import (
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+// tenancy keeps track of the simulated users of a workload, enforces their quotas, and accounts for the
+// resources that they receive.
+type tenancy struct {
+	config   *domain.TenancyConfig
+	assigner *domain.UserAssigner
+
+	users             map[string]*tenant // users is a map from username to tenant.
+	activeTrainings   map[string]*tenantTraining
+	sessionsAdmitted  map[string]struct{} // sessionsAdmitted are the internal IDs of the sessions that count against their user's quota.
+	sessionsPerTenant map[string]int      // sessionsPerTenant is a map from username to the number of admitted sessions of that user.
+	mu                sync.Mutex
+}
+
+// tenant is one simulated user of a workload.
+type tenant struct {
+	quota      *domain.UserQuota
+	activeGpus int
+	stats      *UserStatistics
+}
+
+// tenantTraining is a training of a tenant that is holding GPUs against the tenant's quota.
+type tenantTraining struct {
+	username  string
+	startedAt time.Time
+	gpus      int
+}
+
+// configureTenancy assigns the sessions of the workload to simulated users as specified by the given
+// domain.TenancyConfig. configureTenancy must be called after the workload is assigned to the driver.
+func (d *BasicWorkloadDriver) configureTenancy(config *domain.TenancyConfig) {
+	t := &tenancy{
+		config:            config,
+		assigner:          domain.NewUserAssigner(config, d.workload.GetSeed()),
+		users:             make(map[string]*tenant),
+		activeTrainings:   make(map[string]*tenantTraining),
+		sessionsAdmitted:  make(map[string]struct{}),
+		sessionsPerTenant: make(map[string]int),
+	}
+
+	// The owners of template sessions are known from the template itself.
+	for _, session := range d.workloadSessions {
+		if session != nil && session.User != "" {
+			t.assigner.SetSessionUser(d.getInternalSessionId(session.GetId()), session.User)
+		}
+	}
+
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		stats.Users = make(map[string]*UserStatistics)
+		for _, username := range config.Usernames() {
+			stats.Users[username] = t.tenantOf(username).stats
+		}
+	})
+
+	d.tenancy = t
+
+	d.logger.Debug("Configured simulated users of workload.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.Strings("users", config.Usernames()))
+}
+
+// tenantOf returns the tenant with the given username, creating it if necessary. tenantOf must be
+// called with the mutex held, or before the tenancy is in use.
+func (t *tenancy) tenantOf(username string) *tenant {
+	user, loaded := t.users[username]
+	if !loaded {
+		user = &tenant{
+			quota: t.config.QuotaOf(username),
+			stats: &UserStatistics{Username: username},
+		}
+		t.users[username] = user
+	}
+
+	return user
+}
+
+// usernameOf returns the name of the user that owns the specified session, or an empty string
+// if the workload does not simulate users.
+func (d *BasicWorkloadDriver) usernameOf(internalSessionId string) string {
+	if d.tenancy == nil {
+		return ""
+	}
+
+	return d.tenancy.assigner.AssignUser(internalSessionId)
+}
+
+// updateTenantStatistics applies the given function to the statistics of the specified user under the protection
+// of the workload's statistics lock, and it then recomputes the fairness of the workload.
+func (d *BasicWorkloadDriver) updateTenantStatistics(username string, f func(user *tenant)) {
+	d.workload.UpdateStatistics(func(stats *Statistics) {
+		d.tenancy.mu.Lock()
+		defer d.tenancy.mu.Unlock()
+
+		user := d.tenancy.tenantOf(username)
+		f(user)
+		stats.Users[username] = user.stats
+
+		gpuSeconds := make([]float64, 0, len(d.tenancy.users))
+		for _, tenant := range d.tenancy.users {
+			gpuSeconds = append(gpuSeconds, tenant.stats.GpuSeconds)
+		}
+		stats.JainFairnessIndex = domain.JainFairnessIndex(gpuSeconds)
+	})
+}
+
+// admitSession returns true if the user that owns the specified session may create another session, in which case
+// the session counts against the user's quota until tenantSessionStopped is called. If admitSession returns false,
+// then the session's events are pushed back and the 'session-ready' event is placed back in the event queue.
+func (d *BasicWorkloadDriver) admitSession(sessionReadyEvent *domain.Event) bool {
+	if d.tenancy == nil {
+		return true
+	}
+
+	internalSessionId := d.getInternalSessionId(sessionReadyEvent.SessionID())
+	username := d.usernameOf(internalSessionId)
+
+	admitted := false
+	d.updateTenantStatistics(username, func(user *tenant) {
+		if _, alreadyAdmitted := d.tenancy.sessionsAdmitted[internalSessionId]; alreadyAdmitted {
+			admitted = true
+			return
+		}
+
+		numSessions := d.tenancy.sessionsPerTenant[username]
+		if user.quota != nil && user.quota.MaxActiveSessions > 0 && numSessions >= user.quota.MaxActiveSessions {
+			user.stats.NumQuotaDeferrals += 1
+			return
+		}
+
+		d.tenancy.sessionsAdmitted[internalSessionId] = struct{}{}
+		d.tenancy.sessionsPerTenant[username] = numSessions + 1
+		user.stats.NumActiveSessions = numSessions + 1
+		user.stats.NumSessions += 1
+		admitted = true
+	})
+
+	if admitted {
+		return true
+	}
+
+	d.logger.Debug("User is at their session quota. Deferring session.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.String("username", username))
+
+	d.delaySession(internalSessionId, d.targetTickDuration*2)
+	d.eventQueue.EnqueueEvent(sessionReadyEvent)
+
+	return false
+}
+
+// tenantSessionStopped releases the quota held by the specified session.
+func (d *BasicWorkloadDriver) tenantSessionStopped(internalSessionId string) {
+	if d.tenancy == nil {
+		return
+	}
+
+	username := d.usernameOf(internalSessionId)
+	d.updateTenantStatistics(username, func(user *tenant) {
+		if _, admitted := d.tenancy.sessionsAdmitted[internalSessionId]; !admitted {
+			return
+		}
+
+		delete(d.tenancy.sessionsAdmitted, internalSessionId)
+		d.tenancy.sessionsPerTenant[username] -= 1
+		user.stats.NumActiveSessions = d.tenancy.sessionsPerTenant[username]
+	})
+}
+
+// admitTraining returns true if the user that owns the specified session may use the GPUs of the training of the
+// given 'training-started' event, in which case the GPUs count against the user's quota until tenantTrainingStopped
+// is called. If admitTraining returns false, then the session's events are pushed back and the event is placed
+// back in the event queue.
+func (d *BasicWorkloadDriver) admitTraining(evt *domain.Event, internalSessionId string) bool {
+	if d.tenancy == nil {
+		return true
+	}
+
+	meta := evt.Data.(domain.SessionMetadata)
+	username := d.usernameOf(internalSessionId)
+
+	admitted := false
+	d.updateTenantStatistics(username, func(user *tenant) {
+		// A training that exceeds the quota on its own is admitted once the user has no other active trainings,
+		// as it would otherwise never run.
+		gpus := meta.GetCurrentTrainingMaxGPUs()
+		if user.quota != nil && user.quota.MaxGpus > 0 && user.activeGpus > 0 && user.activeGpus+gpus > user.quota.MaxGpus {
+			user.stats.NumQuotaDeferrals += 1
+			return
+		}
+
+		d.tenancy.activeTrainings[internalSessionId] = &tenantTraining{
+			username:  username,
+			startedAt: d.clockTime.GetClockTime(),
+			gpus:      gpus,
+		}
+		user.activeGpus += gpus
+		admitted = true
+	})
+
+	if admitted {
+		return true
+	}
+
+	d.logger.Debug("User is at their GPU quota. Deferring training.",
+		zap.String("workload_id", d.workload.GetId()),
+		zap.String("workload_name", d.workload.WorkloadName()),
+		zap.String(ZapInternalSessionIDKey, internalSessionId),
+		zap.String("username", username))
+
+	d.delaySession(internalSessionId, d.targetTickDuration*2)
+	d.eventQueue.EnqueueEvent(evt)
+
+	return false
+}
+
+// tenantTrainingStopped releases the GPUs held by the current training of the specified session. If the training
+// completed, then the GPU-time that the training received is credited to the user that owns the session.
+func (d *BasicWorkloadDriver) tenantTrainingStopped(internalSessionId string, completed bool) {
+	if d.tenancy == nil {
+		return
+	}
+
+	username := d.usernameOf(internalSessionId)
+	d.updateTenantStatistics(username, func(user *tenant) {
+		training, loaded := d.tenancy.activeTrainings[internalSessionId]
+		if !loaded {
+			return
+		}
+
+		delete(d.tenancy.activeTrainings, internalSessionId)
+		user.activeGpus -= training.gpus
+
+		if !completed {
+			return
+		}
+
+		duration := d.clockTime.GetClockTime().Sub(training.startedAt)
+		if duration < 0 {
+			duration = 0
+		}
+
+		user.stats.GpuSeconds += float64(training.gpus) * duration.Seconds()
+		user.stats.NumTrainings += 1
+	})
+}
+
+// tenantSessionDelayed charges a delay of the specified session to the user that owns it.
+func (d *BasicWorkloadDriver) tenantSessionDelayed(internalSessionId string, delay time.Duration) {
+	if d.tenancy == nil {
+		return
+	}
+
+	d.updateTenantStatistics(d.usernameOf(internalSessionId), func(user *tenant) {
+		user.stats.DelayMillis += delay.Milliseconds()
+	})
+}
@@ -27,6 +27,12 @@ const (
 	OpUnpauseWorkload         string = "unpause_workload"
 	OpWorkloadToggleDebugLogs string = "toggle_debug_logs"
 	OpWorkloadSubscribe       string = "subscribe"
+	OpStepWorkloadTick        string = "step_workload_tick"
+	OpStepWorkloadEvent       string = "step_workload_event"
+	OpContinueWorkload        string = "continue_workload"
+	OpAddBreakpoint           string = "add_breakpoint"
+	OpRemoveBreakpoint        string = "remove_breakpoint"
+	OpInspectWorkload         string = "inspect_workload"
 
 	OpPushedWorkloadUpdate string = "pushed_workload_update"
 
@@ -116,6 +122,12 @@ func (h *WebsocketHandler) setupRequestHandlers() {
 	h.handlers[OpUnpauseWorkload] = h.handleUnpauseWorkload
 	h.handlers[OpWorkloadToggleDebugLogs] = h.handleToggleDebugLogs
 	h.handlers[OpWorkloadSubscribe] = h.handleSubscriptionRequest
+	h.handlers[OpStepWorkloadTick] = h.handleStepWorkload
+	h.handlers[OpStepWorkloadEvent] = h.handleStepWorkload
+	h.handlers[OpContinueWorkload] = h.handleStepWorkload
+	h.handlers[OpAddBreakpoint] = h.handleBreakpointRequest
+	h.handlers[OpRemoveBreakpoint] = h.handleBreakpointRequest
+	h.handlers[OpInspectWorkload] = h.handleInspectWorkload
 }
 
 // Upgrade the given HTTP connection to a Websocket connection.
@@ -515,6 +527,103 @@ func (h *WebsocketHandler) handleUnpauseWorkload(msgId string, message []byte, w
 	return response.Encode()
 }
 
+// Handle a request to step a paused workload by a single tick or a single event, or to continue a paused workload.
+func (h *WebsocketHandler) handleStepWorkload(msgId string, message []byte, _ domain.ConcurrentWebSocket) ([]byte, error) {
+	req, err := domain.UnmarshalRequestPayload[*domain.DebugWorkloadRequest](message)
+	if err != nil {
+		h.logger.Error("Failed to unmarshal DebugWorkloadRequest.", zap.Error(err))
+		return nil, err
+	}
+
+	h.logger.Debug("Stepping workload.", zap.String("workload_id", req.WorkloadId), zap.String("op", req.Operation))
+
+	var steppedWorkload domain.Workload
+	switch req.Operation {
+	case OpStepWorkloadTick:
+		steppedWorkload, err = h.workloadManager.StepWorkloadTick(req.WorkloadId)
+	case OpStepWorkloadEvent:
+		steppedWorkload, err = h.workloadManager.StepWorkloadEvent(req.WorkloadId)
+	case OpContinueWorkload:
+		steppedWorkload, err = h.workloadManager.ContinueWorkload(req.WorkloadId)
+	default:
+		panic(fmt.Sprintf("Unexpected operation field in DebugWorkloadRequest: \"%s\"", req.Operation))
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to step workload.", zap.String("workload_id", req.WorkloadId),
+			zap.String("op", req.Operation), zap.Error(err))
+		return nil, err
+	}
+
+	steppedWorkload.UpdateTimeElapsed()
+	responseBuilder := newResponseBuilder(msgId, req.Operation)
+	response := responseBuilder.WithModifiedWorkload(steppedWorkload).BuildResponse()
+	return response.Encode()
+}
+
+// Handle a request to add a breakpoint to or remove a breakpoint from a workload.
+func (h *WebsocketHandler) handleBreakpointRequest(msgId string, message []byte, _ domain.ConcurrentWebSocket) ([]byte, error) {
+	req, err := domain.UnmarshalRequestPayload[*domain.DebugWorkloadRequest](message)
+	if err != nil {
+		h.logger.Error("Failed to unmarshal DebugWorkloadRequest.", zap.Error(err))
+		return nil, err
+	}
+
+	var breakpoints []*domain.Breakpoint
+	switch req.Operation {
+	case OpAddBreakpoint:
+		breakpoints, err = h.workloadManager.AddBreakpoint(req.WorkloadId, req.Breakpoint)
+	case OpRemoveBreakpoint:
+		breakpoints, err = h.workloadManager.RemoveBreakpoint(req.WorkloadId, req.BreakpointId)
+	default:
+		panic(fmt.Sprintf("Unexpected operation field in DebugWorkloadRequest: \"%s\"", req.Operation))
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to update breakpoints of workload.", zap.String("workload_id", req.WorkloadId),
+			zap.String("op", req.Operation), zap.Error(err))
+		return nil, err
+	}
+
+	response := &DebugWorkloadResponse{
+		Operation:   req.Operation,
+		Status:      domain.ResponseStatusOK,
+		MessageId:   msgId,
+		WorkloadId:  req.WorkloadId,
+		Breakpoints: breakpoints,
+	}
+	return response.Encode()
+}
+
+// Handle a request to inspect the internal state of a paused workload.
+func (h *WebsocketHandler) handleInspectWorkload(msgId string, message []byte, _ domain.ConcurrentWebSocket) ([]byte, error) {
+	req, err := domain.UnmarshalRequestPayload[*domain.DebugWorkloadRequest](message)
+	if err != nil {
+		h.logger.Error("Failed to unmarshal DebugWorkloadRequest.", zap.Error(err))
+		return nil, err
+	}
+
+	if req.Operation != OpInspectWorkload {
+		panic(fmt.Sprintf("Unexpected operation field in DebugWorkloadRequest: \"%s\"", req.Operation))
+	}
+
+	inspection, err := h.workloadManager.InspectWorkload(req.WorkloadId, req.NumUpcomingEvents)
+	if err != nil {
+		h.logger.Error("Failed to inspect workload.", zap.String("workload_id", req.WorkloadId), zap.Error(err))
+		return nil, err
+	}
+
+	response := &DebugWorkloadResponse{
+		Operation:   req.Operation,
+		Status:      domain.ResponseStatusOK,
+		MessageId:   msgId,
+		WorkloadId:  req.WorkloadId,
+		Breakpoints: inspection.Breakpoints,
+		Inspection:  inspection,
+	}
+	return response.Encode()
+}
+
 // Handle a request to register a new workload.
 // This does not start the workload; that is a separate operation.
 func (h *WebsocketHandler) handleRegisterWorkload(msgId string, message []byte, ws domain.ConcurrentWebSocket) ([]byte, error) {
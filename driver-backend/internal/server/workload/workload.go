@@ -3,11 +3,10 @@ package workload
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
 	"github.com/scusemua/workload-driver-react/m/v2/internal/server/metrics"
-	"math/rand"
 	"sync"
 	"time"
 
@@ -30,6 +29,7 @@ const (
 	PresetWorkload      Kind = "Preset"
 	TemplateWorkload    Kind = "Template"
 	TraceWorkload       Kind = "WorkloadFromTrace"
+	NotebookWorkload    Kind = "Notebook"
 )
 
 // Kind defines a type that a workload can have/be.
@@ -79,6 +79,35 @@ func GetWorkloadStateAsString(state State) string {
 	}
 }
 
+// StateChangeHandler is called whenever a workload transitions from one State to another.
+//
+// StateChangeHandler is called while the workload's internal lock is held, so it must not block, nor may it call
+// any methods of the workload that acquire the workload's lock.
+type StateChangeHandler func(workloadId string, workloadName string, from State, to State)
+
+// stateChangeEventType returns the events.EventType that corresponds to a transition between the given states.
+func stateChangeEventType(from State, to State) events.EventType {
+	switch to {
+	case Running:
+		if from == Paused || from == Pausing {
+			return events.WorkloadResumed
+		}
+		return events.WorkloadStarted
+	case Pausing:
+		return events.WorkloadPausing
+	case Paused:
+		return events.WorkloadPaused
+	case Finished:
+		return events.WorkloadCompleted
+	case Erred:
+		return events.WorkloadErred
+	case Terminated:
+		return events.WorkloadTerminated
+	default:
+		return events.WorkloadStateChanged
+	}
+}
+
 type workloadInternal interface {
 	domain.Workload
 
@@ -98,7 +127,8 @@ type BasicWorkload struct {
 	// SampledSessions is a map (really, just a set; the values of the map are not used) that keeps track of the
 	// sessions that this BasicWorkload is actively sampling and processing from the workload.
 	//
-	// The likelihood that a Session is selected for sampling is based on the SessionsSamplePercentage field.
+	// The likelihood that a Session is selected for sampling is based on the SessionsSamplePercentage field
+	// and the domain.SessionSamplingConfig of the workload.
 	//
 	// SampledSessions is a sort of counterpart to the UnsampledSessions field.
 	SampledSessions map[string]interface{} `json:"-"`
@@ -136,6 +166,7 @@ type BasicWorkload struct {
 	workloadSource            interface{}
 	mu                        sync.RWMutex
 	sessionsMap               map[string]interface{} // Internal mapping of session ID to session.
+	sessionSampler            *domain.SessionSampler // Decides which sessions are sampled.
 	trainingStartedTimes      map[string]time.Time   // Internal mapping of session ID to the time at which it began training.
 	trainingStartedTimesTicks map[string]int64       // Mapping from Session ID to the tick at which it began training.
 	seedSet                   bool                   // Flag keeping track of whether we've already set the seed for this workload.
@@ -149,6 +180,9 @@ type BasicWorkload struct {
 	// If a non-critical error occurs during the execution of the workload, then this handler is called.
 	onNonCriticalError domain.WorkloadErrorHandler
 
+	// onStateChange is called whenever the workload transitions from one State to another.
+	onStateChange StateChangeHandler
+
 	RemoteStorageDefinition *proto.RemoteStorageDefinition `json:"remote_storage_definition"`
 }
 
@@ -183,6 +217,30 @@ func (w *BasicWorkload) RegisterOnNonCriticalErrorHandler(handler domain.Workloa
 	w.onNonCriticalError = handler
 }
 
+// RegisterOnStateChangeHandler registers a handler that is called whenever the target workload transitions
+// from one State to another.
+//
+// If there is already a state change handler registered for the target workload, then the existing
+// state change handler is overwritten.
+func (w *BasicWorkload) RegisterOnStateChangeHandler(handler StateChangeHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onStateChange = handler
+}
+
+// unsafeSetState sets the State of the workload and calls the registered StateChangeHandler, if there is one.
+//
+// unsafeSetState must be called while the workload's lock is held.
+func (w *BasicWorkload) unsafeSetState(state State) {
+	previousState := w.Statistics.WorkloadState
+	w.Statistics.WorkloadState = state
+
+	if w.onStateChange != nil && previousState != state {
+		w.onStateChange(w.Id, w.Name, previousState, state)
+	}
+}
+
 // GetTickDurationsMillis returns a slice containing the clock time that elapsed for each tick
 // of the workload in order, in milliseconds.
 func (w *BasicWorkload) GetTickDurationsMillis() []int64 {
@@ -203,7 +261,7 @@ func (w *BasicWorkload) SetPausing() error {
 		return domain.ErrWorkloadNotPaused
 	}
 
-	w.Statistics.WorkloadState = Pausing
+	w.unsafeSetState(Pausing)
 	return nil
 }
 
@@ -220,7 +278,7 @@ func (w *BasicWorkload) SetPaused() error {
 		return domain.ErrWorkloadNotPaused
 	}
 
-	w.Statistics.WorkloadState = Paused
+	w.unsafeSetState(Paused)
 	return nil
 }
 
@@ -237,7 +295,7 @@ func (w *BasicWorkload) Unpause() error {
 		return domain.ErrWorkloadNotPaused
 	}
 
-	w.Statistics.WorkloadState = Running
+	w.unsafeSetState(Running)
 
 	// pauseWaitBegin is set to zero after being processed.
 	// So, if it is currently zero, then we're not paused, and we should do nothing.
@@ -324,6 +382,13 @@ func (w *BasicWorkload) IsTraceWorkload() bool {
 	return w.WorkloadType == TraceWorkload
 }
 
+// IsNotebookWorkload returns true if this workload replays the code cells of one or more notebooks.
+func (w *BasicWorkload) IsNotebookWorkload() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.WorkloadType == NotebookWorkload
+}
+
 // GetWorkloadSource returns the "source" of the workload.
 // If this is a preset workload, return the name of the preset.
 // If this is a trace workload, return the trace information.
@@ -354,7 +419,7 @@ func (w *BasicWorkload) TerminateWorkloadPrematurely(simulationTimestamp time.Ti
 	now := time.Now()
 
 	w.Statistics.EndTime = now
-	w.Statistics.WorkloadState = Terminated
+	w.unsafeSetState(Terminated)
 	w.Statistics.NumEventsProcessed += 1
 
 	// workloadEvent := NewWorkloadEvent(len(w.Statistics.EventsProcessed), uuid.NewString(), "workload-terminated", "N/A", simulationTimestamp.String(), now.String(), true, nil)
@@ -396,7 +461,7 @@ func (w *BasicWorkload) StartWorkload() error {
 		return fmt.Errorf("%w: cannot start workload that is in state '%s'", domain.ErrInvalidState, GetWorkloadStateAsString(w.Statistics.WorkloadState))
 	}
 
-	w.Statistics.WorkloadState = Running
+	w.unsafeSetState(Running)
 	w.Statistics.StartTime = time.Now()
 
 	return nil
@@ -414,7 +479,7 @@ func (w *BasicWorkload) SetWorkloadCompleted() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.Statistics.WorkloadState = Finished
+	w.unsafeSetState(Finished)
 	w.Statistics.EndTime = time.Now()
 	w.Statistics.WorkloadDuration = time.Since(w.Statistics.StartTime)
 }
@@ -485,7 +550,7 @@ func (w *BasicWorkload) SetState(state State) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.Statistics.WorkloadState = state
+	w.unsafeSetState(state)
 }
 
 // GetStartTime returns the time that the workload was started.
@@ -593,13 +658,24 @@ func (w *BasicWorkload) ProcessedEvent(evt *domain.WorkloadEvent) {
 		return
 	}
 
-	w.Statistics.NumEventsProcessed += 1
 	evt.Index = len(w.Statistics.EventsProcessed)
 	w.Statistics.EventsProcessed = append(w.Statistics.EventsProcessed, evt)
 
+	// Audit records are kept alongside the processed events, but they aren't events that were processed.
+	if evt.Status == domain.Audited {
+		w.logger.Debug("Recorded workload audit event.",
+			zap.String("workload_id", w.Id),
+			zap.String("workload_name", w.Name),
+			zap.String("event_name", evt.Name),
+			zap.String("description", evt.Description))
+		return
+	}
+
+	w.Statistics.NumEventsProcessed += 1
+
 	if metrics.PrometheusMetricsWrapperInstance != nil && metrics.PrometheusMetricsWrapperInstance.WorkloadEventsProcessed != nil {
 		metrics.PrometheusMetricsWrapperInstance.WorkloadEventsProcessed.
-			With(prometheus.Labels{"workload_id": w.Id}).
+			With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 			Add(1)
 	}
 
@@ -620,11 +696,11 @@ func (w *BasicWorkload) SessionCreated(sessionId string, metadata domain.Session
 	w.mu.Unlock()
 
 	metrics.PrometheusMetricsWrapperInstance.WorkloadTotalNumSessions.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Add(1)
 
 	metrics.PrometheusMetricsWrapperInstance.WorkloadActiveNumSessions.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Add(1)
 
 	w.workloadInstance.SessionCreated(sessionId, metadata)
@@ -634,7 +710,7 @@ func (w *BasicWorkload) SessionCreated(sessionId string, metadata domain.Session
 // Just updates some internal metrics.
 func (w *BasicWorkload) SessionStopped(sessionId string, _ *domain.Event) {
 	metrics.PrometheusMetricsWrapperInstance.WorkloadActiveNumSessions.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Sub(1)
 
 	w.mu.Lock()
@@ -710,7 +786,7 @@ func (w *BasicWorkload) TrainingStarted(sessionId string, tickNumber int64) {
 	w.trainingStartedTimesTicks[sessionId] = tickNumber
 
 	metrics.PrometheusMetricsWrapperInstance.WorkloadActiveTrainingSessions.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Add(1)
 }
 
@@ -721,11 +797,11 @@ func (w *BasicWorkload) TrainingStopped(sessionId string, evt *domain.Event, tic
 	defer w.mu.Unlock()
 
 	metrics.PrometheusMetricsWrapperInstance.WorkloadTrainingEventsCompleted.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Add(1)
 
 	metrics.PrometheusMetricsWrapperInstance.WorkloadActiveTrainingSessions.
-		With(prometheus.Labels{"workload_id": w.Id}).
+		With(metrics.PrometheusMetricsWrapperInstance.WorkloadLabels(w.Id)).
 		Sub(1)
 
 	trainingStartedAt, loaded := w.trainingStartedTimes[sessionId]
@@ -738,7 +814,7 @@ func (w *BasicWorkload) TrainingStopped(sessionId string, evt *domain.Event, tic
 		trainingDuration := time.Since(trainingStartedAt)
 
 		metrics.PrometheusMetricsWrapperInstance.WorkloadTrainingEventDurationMilliseconds.
-			With(prometheus.Labels{"workload_id": w.Id, "session_id": sessionId}).
+			With(metrics.PrometheusMetricsWrapperInstance.WorkloadSessionLabels(w.Id, sessionId)).
 			Observe(float64(trainingDuration.Milliseconds()))
 	}
 
@@ -916,10 +992,11 @@ func (w *BasicWorkload) unsafeSetSessionSampled(sessionId string) {
 		zap.Int("num_sampled_sessions", len(w.SampledSessions)),
 		zap.Int("num_discarded_sessions", len(w.UnsampledSessions)))
 	w.Statistics.NumSampledSessions += 1
+	w.Statistics.SampledSessionIds = append(w.Statistics.SampledSessionIds, sessionId)
 }
 
 func (w *BasicWorkload) unsafeSetSessionDiscarded(sessionId string) {
-	err := w.SessionDiscarded(sessionId)
+	err := w.unsafeSessionDiscarded(sessionId)
 	if err != nil {
 		w.logger.Error("Failed to disable session.",
 			zap.String("workload_id", w.Id),
@@ -966,9 +1043,8 @@ func (w *BasicWorkload) unsafeIsSessionBeingSampled(sessionId string) bool {
 		return true
 	}
 
-	// Randomly decide if we're going to sample/process [events for] this session or not.
-	randomValue := rand.Float64()
-	if randomValue <= w.Statistics.SessionsSamplePercentage {
+	// Decide if we're going to sample/process [events for] this session or not.
+	if w.sessionSampler.Sample(sessionId) {
 		w.unsafeSetSessionSampled(sessionId)
 		return true
 	}
@@ -1024,6 +1100,88 @@ func (w *BasicWorkload) RecordSessionExecutionTime(sessionId string, execTimeMil
 	session.ExecutionTimes = append(session.ExecutionTimes, execTimeMillis)
 }
 
+// unsafeGetSession returns the specified session, or false if there is no such session.
+//
+// unsafeGetSession must be called with the BasicWorkload's mutex held.
+func (w *BasicWorkload) unsafeGetSession(sessionId string) (Session, bool) {
+	val, ok := w.sessionsMap[sessionId]
+	if !ok {
+		return nil, false
+	}
+
+	// Preset-based workloads maintain *domain.BasicWorkloadSession instances rather than
+	// *domain.WorkloadTemplateSession instances, both of which implement Session.
+	session, ok := val.(Session)
+	return session, ok
+}
+
+// GetSessionState returns the current domain.SessionState of the specified session, or false if there is no
+// such session.
+func (w *BasicWorkload) GetSessionState(sessionId string) (domain.SessionState, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	session, ok := w.unsafeGetSession(sessionId)
+	if !ok {
+		return "", false
+	}
+
+	return session.GetState(), true
+}
+
+// GetSessionTrainingsCompleted returns the number of trainings that the specified session has completed, or false
+// if there is no such session.
+func (w *BasicWorkload) GetSessionTrainingsCompleted(sessionId string) (int, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	session, ok := w.unsafeGetSession(sessionId)
+	if !ok {
+		return 0, false
+	}
+
+	return session.GetTrainingsCompleted(), true
+}
+
+// InspectSession returns a SessionInspection of the specified session, or false if there is no such session.
+func (w *BasicWorkload) InspectSession(sessionId string) (*SessionInspection, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	session, ok := w.unsafeGetSession(sessionId)
+	if !ok {
+		return nil, false
+	}
+
+	return &SessionInspection{
+		SessionId:          session.GetId(),
+		State:              session.GetState(),
+		TrainingsCompleted: session.GetTrainingsCompleted(),
+		NumFailedTicks:     session.NumFailedTicks(),
+		CreatedAt:          session.GetCreatedAt(),
+		TrainingStartedAt:  session.GetTrainingStartedAt(),
+	}, true
+}
+
+// RecordSessionGpuUtilization appends the given GpuUtilizationSample to the GPU utilization time series of the
+// specified session.
+func (w *BasicWorkload) RecordSessionGpuUtilization(sessionId string, sample *domain.GpuUtilizationSample) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	session, ok := w.unsafeGetSession(sessionId)
+	if !ok {
+		w.logger.Error("Could not find specified session. Cannot record GPU utilization.",
+			zap.String("workload_id", w.Id),
+			zap.String("workload_name", w.Name),
+			zap.String("session_id", sessionId),
+			zap.Float64("gpu_utilization", sample.Utilization))
+		return
+	}
+
+	session.AddGpuUtilizationSample(sample)
+}
+
 func (w *BasicWorkload) GetSessionTrainingEvent(sessionId string, trainingIndex int) *domain.TrainingEvent {
 	return w.workloadInstance.getSessionTrainingEvent(sessionId, trainingIndex)
 }
@@ -0,0 +1,211 @@
+package workload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultNotebookCellTimeout is how long a single notebook cell may execute if the workload registration
+	// request does not specify a timeout.
+	DefaultNotebookCellTimeout = time.Minute * 30
+)
+
+var (
+	ErrWorkloadRegistrationMissingNotebooks = errors.New("workload registration request for notebook-based workload is missing notebooks")
+	ErrNotebookHasNoCodeCells               = errors.New("notebook does not contain any code cells")
+	ErrDuplicateNotebookName                = errors.New("multiple notebooks have the same name")
+)
+
+// defaultNotebookThinkTime is used if neither a notebook's metadata nor the registration request specify a think time.
+var defaultNotebookThinkTime = &domain.ThinkTimeDistribution{Distribution: domain.ConstantThinkTime}
+
+// Notebook is a workload that replays the code cells of one or more notebooks. Each notebook is replayed by its
+// own session, and each code cell of the notebook is executed as a "training" of that session.
+//
+// The schedule of a Notebook is expressed as a template, so a Notebook is driven just like a Template, except
+// that the real code of each cell is executed rather than the synthetic training code.
+type Notebook struct {
+	*Template
+
+	// Notebooks come from the domain.WorkloadRegistrationRequest used to register the workload.
+	Notebooks []*domain.WorkloadNotebook `json:"notebooks"`
+
+	// contents maps session IDs to the contents of the .ipynb files replayed by those sessions.
+	contents map[string]json.RawMessage
+	// cells maps session IDs to the code cells of the notebooks replayed by those sessions.
+	cells map[string][]*domain.NotebookCell
+	// nextCellIndex maps session IDs to the index of the next code cell to be executed by those sessions.
+	nextCellIndex map[string]int
+	cellsMutex    sync.Mutex
+}
+
+// NewWorkloadFromNotebooks creates a new Notebook workload that replays the specified notebooks.
+//
+// The think time between consecutive cells is taken from each cell's metadata, falling back to the notebook's
+// metadata, and then to the specified distribution. Think times are rounded up to a whole number of ticks.
+func NewWorkloadFromNotebooks(baseWorkload *BasicWorkload, notebooks []*domain.WorkloadNotebook,
+	thinkTime *domain.ThinkTimeDistribution, tickDuration time.Duration) (*Notebook, error) {
+
+	if baseWorkload == nil {
+		panic("Base workload cannot be nil when creating a new workload.")
+	}
+
+	if len(notebooks) == 0 {
+		return nil, ErrWorkloadRegistrationMissingNotebooks
+	}
+
+	if thinkTime == nil {
+		thinkTime = defaultNotebookThinkTime
+	}
+
+	if err := thinkTime.Validate(); err != nil {
+		return nil, err
+	}
+
+	notebookWorkload := &Notebook{
+		Notebooks:     notebooks,
+		contents:      make(map[string]json.RawMessage, len(notebooks)),
+		cells:         make(map[string][]*domain.NotebookCell, len(notebooks)),
+		nextCellIndex: make(map[string]int, len(notebooks)),
+	}
+
+	rng := rand.New(rand.NewSource(baseWorkload.Seed))
+	sessions := make([]*domain.WorkloadTemplateSession, 0, len(notebooks))
+	for _, workloadNotebook := range notebooks {
+		session, err := notebookWorkload.createSession(workloadNotebook, thinkTime, tickDuration, rng, baseWorkload.atom)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	template, err := NewWorkloadFromTemplate(baseWorkload, sessions)
+	if err != nil {
+		return nil, err
+	}
+
+	notebookWorkload.Template = template
+	baseWorkload.WorkloadType = NotebookWorkload
+	baseWorkload.workloadInstance = notebookWorkload
+
+	return notebookWorkload, nil
+}
+
+// createSession loads and parses the specified notebook and creates the session that will replay it.
+func (w *Notebook) createSession(workloadNotebook *domain.WorkloadNotebook, thinkTime *domain.ThinkTimeDistribution,
+	tickDuration time.Duration, rng *rand.Rand, atom *zap.AtomicLevel) (*domain.WorkloadTemplateSession, error) {
+
+	content := workloadNotebook.Content
+	if len(content) == 0 {
+		var err error
+		if content, err = os.ReadFile(workloadNotebook.FilePath); err != nil {
+			return nil, err
+		}
+	}
+
+	notebook, err := domain.ParseNotebook(content)
+	if err != nil {
+		return nil, fmt.Errorf("%w (notebook \"%s\")", err, workloadNotebook.Name)
+	}
+
+	sessionId := workloadNotebook.Name
+	if sessionId == "" {
+		sessionId = strings.TrimSuffix(filepath.Base(workloadNotebook.FilePath), filepath.Ext(workloadNotebook.FilePath))
+		workloadNotebook.Name = sessionId
+	}
+
+	if _, loaded := w.cells[sessionId]; loaded {
+		return nil, fmt.Errorf("%w: \"%s\"", ErrDuplicateNotebookName, sessionId)
+	}
+
+	cells := notebook.CodeCells()
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("%w: \"%s\"", ErrNotebookHasNoCodeCells, sessionId)
+	}
+
+	resourceRequest := workloadNotebook.ResourceRequest
+	if resourceRequest == nil {
+		resourceRequest = domain.NewResourceRequest(100, 256, 0, 0, "ANY_GPU")
+	}
+
+	gpuUtil := make([]domain.GpuUtilization, resourceRequest.Gpus)
+	for i := range gpuUtil {
+		gpuUtil[i] = domain.GpuUtilization{Utilization: 100}
+	}
+
+	// Each cell occupies one tick, and there is at least one tick of think time before each cell.
+	tick := workloadNotebook.StartTick
+	trainings := make([]*domain.TrainingEvent, 0, len(cells))
+	for idx, cell := range cells {
+		cellThinkTime, ok := cell.ThinkTime()
+		if !ok {
+			if cellThinkTime, ok = notebook.ThinkTime(); !ok {
+				cellThinkTime = thinkTime.Sample(rng)
+			}
+		}
+
+		tick += int(math.Max(1, math.Ceil(float64(cellThinkTime)/float64(tickDuration))))
+
+		trainings = append(trainings, &domain.TrainingEvent{
+			TrainingIndex:   idx,
+			Millicpus:       resourceRequest.Cpus,
+			MemUsageMB:      resourceRequest.MemoryMB,
+			VRamUsageGB:     resourceRequest.VRAM,
+			GpuUtil:         gpuUtil,
+			StartTick:       tick,
+			DurationInTicks: 1,
+		})
+
+		tick += 1
+	}
+
+	w.contents[sessionId] = content
+	w.cells[sessionId] = cells
+
+	return &domain.WorkloadTemplateSession{
+		BasicWorkloadSession: domain.NewWorkloadSession(sessionId, nil, resourceRequest, time.Now(), atom),
+		StartTick:            workloadNotebook.StartTick,
+		StopTick:             tick + 1,
+		Trainings:            trainings,
+		NumTrainingEvents:    len(trainings),
+	}, nil
+}
+
+func (w *Notebook) GetWorkloadSource() interface{} {
+	return w.Notebooks
+}
+
+// GetNotebookContent returns the contents of the .ipynb file replayed by the specified session.
+func (w *Notebook) GetNotebookContent(sessionId string) (json.RawMessage, bool) {
+	content, loaded := w.contents[sessionId]
+	return content, loaded
+}
+
+// NextCell returns the next code cell to be executed by the specified session, along with its index.
+// If the session has already executed all of its cells, then NextCell returns nil.
+func (w *Notebook) NextCell(sessionId string) (*domain.NotebookCell, int) {
+	w.cellsMutex.Lock()
+	defer w.cellsMutex.Unlock()
+
+	cells := w.cells[sessionId]
+	idx := w.nextCellIndex[sessionId]
+	if idx >= len(cells) {
+		return nil, idx
+	}
+
+	w.nextCellIndex[sessionId] = idx + 1
+	return cells[idx], idx
+}
@@ -67,8 +67,11 @@ func (w *Preset) unsafeSetSource(source interface{}) error {
 	return nil
 }
 
+// SetMaxUtilizationWrapper sets the maximum utilization of each of the preset's sessions, which also serves as the
+// population of sessions from which the sampled sessions are selected.
 func (w *Preset) SetMaxUtilizationWrapper(wrapper *domain.MaxUtilizationWrapper) {
 	w.MaxUtilizationWrapper = wrapper
+	w.sessionSampler.SetPopulation(domain.SessionProfilesFromMaxUtilization(wrapper))
 }
 
 func (w *Preset) unsafeSetSessions(sessions []*domain.BasicWorkloadSession) error {
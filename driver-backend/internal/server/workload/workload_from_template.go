@@ -121,6 +121,7 @@ func (w *Template) unsafeSetSessions(sessions []*domain.WorkloadTemplateSession)
 	w.Sessions = sessions
 	w.sessionsSet = true
 	w.Statistics.TotalNumSessions = len(sessions)
+	w.sessionSampler.SetPopulation(templateSessionProfiles(sessions))
 
 	// Add each session to our internal mapping and initialize the session.
 	for _, session := range sessions {
@@ -167,6 +168,26 @@ func (w *Template) unsafeSetSessions(sessions []*domain.WorkloadTemplateSession)
 	return nil
 }
 
+// templateSessionProfiles returns the domain.SessionProfile of each of the given sessions.
+func templateSessionProfiles(sessions []*domain.WorkloadTemplateSession) []*domain.SessionProfile {
+	profiles := make([]*domain.SessionProfile, 0, len(sessions))
+	for _, session := range sessions {
+		profile := &domain.SessionProfile{
+			SessionId:    session.GetId(),
+			NumTrainings: max(session.NumTrainingEvents, len(session.Trainings)),
+			Duration:     float64(session.StopTick - session.StartTick),
+		}
+
+		if session.MaxResourceRequest != nil {
+			profile.Gpus = session.MaxResourceRequest.Gpus
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	return profiles
+}
+
 // SessionCreated is called when a Session is created for/in the Workload.
 // Just updates some internal metrics.
 func (w *Template) SessionCreated(sessionId string, metadata domain.SessionMetadata) {
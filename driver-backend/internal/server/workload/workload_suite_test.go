@@ -0,0 +1,57 @@
+package workload
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+
+	"github.com/scusemua/workload-driver-react/m/v2/internal/domain"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/api/proto"
+	"github.com/scusemua/workload-driver-react/m/v2/internal/server/events"
+)
+
+// stubCallbackProvider is a CallbackProvider that does nothing.
+type stubCallbackProvider struct{}
+
+func (p *stubCallbackProvider) RefreshAndClearClusterStatistics(bool, bool) (*ClusterStatistics, error) {
+	return nil, nil
+}
+func (p *stubCallbackProvider) HandleCriticalWorkloadError(string, error) {}
+func (p *stubCallbackProvider) HandleWorkloadError(string, error)         {}
+func (p *stubCallbackProvider) SendNotification(*proto.Notification)      {}
+func (p *stubCallbackProvider) GetSchedulingPolicy() (string, bool)       { return "", false }
+func (p *stubCallbackProvider) IsConnectedToGateway() bool                { return false }
+func (p *stubCallbackProvider) PublishWorkloadEvent(*events.Event)        {}
+
+// templateTestSession creates a WorkloadTemplateSession with the given number of trainings and dependencies.
+func templateTestSession(id string, numTrainings int, dependsOn ...*domain.SessionDependency) *domain.WorkloadTemplateSession {
+	trainings := make([]*domain.TrainingEvent, 0, numTrainings)
+	for i := 0; i < numTrainings; i++ {
+		trainings = append(trainings, &domain.TrainingEvent{TrainingIndex: i})
+	}
+
+	return &domain.WorkloadTemplateSession{
+		BasicWorkloadSession: &domain.BasicWorkloadSession{
+			Id:                 id,
+			MaxResourceRequest: domain.NewResourceRequest(0, 0, 0, 0, "ANY_GPU"),
+		},
+		Trainings: trainings,
+		DependsOn: dependsOn,
+	}
+}
+
+// newTestDriver creates a BasicWorkloadDriver that does not perform clock ticks and whose workload has not yet
+// been assigned.
+func newTestDriver(atom *zap.AtomicLevel) *BasicWorkloadDriver {
+	driver, err := NewBasicWorkloadDriver(&domain.Configuration{TraceStep: 60}, false, 1.0, nil, atom, &stubCallbackProvider{})
+	Expect(err).To(BeNil())
+
+	return driver
+}
+
+func TestWorkload(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Workload Suite")
+}
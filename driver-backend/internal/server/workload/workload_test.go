@@ -0,0 +1,45 @@
+package workload
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+)
+
+// stateChange is a single call to a StateChangeHandler.
+type stateChange struct {
+	workloadId string
+	from       State
+	to         State
+}
+
+var _ = Describe("Workload Tests", func() {
+	var (
+		workload     *BasicWorkload
+		stateChanges []stateChange
+	)
+
+	BeforeEach(func() {
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		workload = NewBuilder(&atom).SetID("workload1").SetWorkloadName("TestWorkload").Build()
+
+		stateChanges = make([]stateChange, 0)
+		workload.RegisterOnStateChangeHandler(func(workloadId string, _ string, from State, to State) {
+			stateChanges = append(stateChanges, stateChange{workloadId: workloadId, from: from, to: to})
+		})
+	})
+
+	It("Will call the state change handler once per state transition", func() {
+		workload.SetState(Running)
+
+		Expect(workload.GetState()).To(Equal(Running))
+		Expect(stateChanges).To(Equal([]stateChange{{workloadId: "workload1", from: Ready, to: Running}}))
+	})
+
+	It("Will not call the state change handler when the state does not change", func() {
+		Expect(workload.StartWorkload()).To(BeNil())
+		workload.SetState(Running)
+
+		Expect(stateChanges).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,354 @@
+package jupyter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// xsrfCookieName is the name of the cookie in which the Jupyter Server stores the XSRF token.
+	xsrfCookieName = "_xsrf"
+	// xsrfHeaderName is the name of the header in which the XSRF token must be echoed for cookie-authenticated requests.
+	xsrfHeaderName = "X-XSRFToken"
+
+	// DefaultWebsocketHandshakeTimeout is the default timeout of the handshake when dialing a kernel websocket.
+	DefaultWebsocketHandshakeTimeout = 90 * time.Second
+)
+
+var (
+	ErrInvalidCACertificate = errors.New("could not load any certificates from the specified CA certificate file")
+	ErrLoginFailed          = errors.New("failed to log in to the Jupyter Server using the configured password")
+)
+
+// ClientConfig configures how a ServerClient connects to and authenticates with a Jupyter Server.
+//
+// The zero value of ClientConfig connects to an unauthenticated Jupyter Server over plain HTTP and WS.
+type ClientConfig struct {
+	// BaseUrl is the base_url of the Jupyter Server, such as "/user/alice/" for a JupyterHub single-user server.
+	BaseUrl string
+
+	// Token is sent in the Authorization header of every request. This can be a Jupyter Server token or
+	// a JupyterHub API token.
+	Token string
+
+	// Password is used to log in to the Jupyter Server if the server uses password authentication.
+	// The resulting session cookie and XSRF token are then sent with every request.
+	Password string
+
+	// UseTLS instructs the client to use HTTPS and WSS rather than HTTP and WS.
+	UseTLS bool
+
+	// CACertFile is the path to a PEM-encoded file containing additional CA certificates to trust.
+	CACertFile string
+
+	// InsecureSkipVerify disables the verification of the Jupyter Server's TLS certificate.
+	InsecureSkipVerify bool
+
+	// Headers are added to every HTTP request and websocket handshake.
+	Headers map[string]string
+
+	// ReconnectionPolicy configures how kernel connections reconnect after losing their websocket.
+	// If nil, then the DefaultReconnectionPolicy is used.
+	ReconnectionPolicy *ReconnectionPolicy
+}
+
+// ServerClient issues HTTP requests to and dials websockets with a Jupyter Server, taking care of the URL scheme,
+// the base_url of the server, authentication, and XSRF protection.
+//
+// A single ServerClient is shared by a KernelSessionManager and all the connections that it creates so that
+// the Jupyter Server is only logged in to once.
+type ServerClient struct {
+	address    string // address is the host (and port) of the Jupyter Server.
+	config     ClientConfig
+	jar        http.CookieJar
+	httpClient *http.Client
+	dialer     *websocket.Dialer
+
+	loggedIn   bool
+	loginMutex sync.Mutex
+}
+
+// NewServerClient creates a new ServerClient for the Jupyter Server at the specified address.
+//
+// The address may optionally be prefixed with a scheme. An "https://" prefix enables TLS regardless of
+// the UseTLS field of the ClientConfig. If config is nil, then the zero value of ClientConfig is used.
+func NewServerClient(address string, config *ClientConfig) (*ServerClient, error) {
+	client := &ServerClient{}
+	if config != nil {
+		client.config = *config
+	}
+
+	if strings.HasPrefix(address, "https://") {
+		client.config.UseTLS = true
+	}
+
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	client.address = strings.TrimSuffix(address, "/")
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	client.jar = jar
+
+	tlsConfig, err := client.config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client.httpClient = &http.Client{Transport: transport, Jar: jar}
+	client.dialer = &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: DefaultWebsocketHandshakeTimeout,
+		TLSClientConfig:  tlsConfig,
+		Jar:              jar,
+	}
+
+	return client, nil
+}
+
+// reconnectionPolicy returns the ReconnectionPolicy used by the kernel connections created with the client.
+// It is safe to call reconnectionPolicy on a nil ServerClient.
+func (c *ServerClient) reconnectionPolicy() *ReconnectionPolicy {
+	if c == nil || c.config.ReconnectionPolicy == nil {
+		return DefaultReconnectionPolicy()
+	}
+
+	policy := *c.config.ReconnectionPolicy
+	return &policy
+}
+
+// tlsConfig returns the tls.Config specified by the ClientConfig, or nil if TLS is not configured.
+func (c *ClientConfig) tlsConfig() (*tls.Config, error) {
+	if c.CACertFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+
+	if c.CACertFile != "" {
+		pem, err := os.ReadFile(c.CACertFile)
+		if err != nil {
+			return nil, err
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: \"%s\"", ErrInvalidCACertificate, c.CACertFile)
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
+}
+
+// Address returns the address of the Jupyter Server, including the base_url.
+func (c *ServerClient) Address() string {
+	baseUrl := strings.Trim(c.config.BaseUrl, "/")
+	if baseUrl == "" {
+		return c.address
+	}
+
+	return c.address + "/" + baseUrl
+}
+
+// HttpUrl returns the HTTP(S) URL of the specified path, which is resolved relative to the base_url of the server.
+func (c *ServerClient) HttpUrl(elem ...string) string {
+	scheme := "http"
+	if c.config.UseTLS {
+		scheme = "https"
+	}
+
+	return c.resolve(scheme, elem...)
+}
+
+// WebsocketUrl returns the WS(S) URL of the specified path, which is resolved relative to the base_url of the server.
+func (c *ServerClient) WebsocketUrl(elem ...string) string {
+	scheme := "ws"
+	if c.config.UseTLS {
+		scheme = "wss"
+	}
+
+	return c.resolve(scheme, elem...)
+}
+
+func (c *ServerClient) resolve(scheme string, elem ...string) string {
+	u := &url.URL{Scheme: scheme, Host: c.address, Path: "/"}
+	return u.JoinPath(append([]string{c.config.BaseUrl}, elem...)...).String()
+}
+
+// NewRequest creates a new HTTP request for the specified URL with the configured authentication headers.
+func (c *ServerClient) NewRequest(method string, rawUrl string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, rawUrl, body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addHeaders(req.Header, req.URL)
+
+	return req, nil
+}
+
+// Do sends the given HTTP request, first logging in to the Jupyter Server if a password is configured.
+//
+// If a password is configured and the server rejects the request as unauthenticated (e.g., because
+// the session cookie expired), then Do logs in again and retries the request once.
+func (c *ServerClient) Do(req *http.Request) (*http.Response, error) {
+	if c.config.Password == "" {
+		return c.httpClient.Do(req)
+	}
+
+	if err := c.ensureLoggedIn(false); err != nil {
+		return nil, err
+	}
+	c.addHeaders(req.Header, req.URL) // The XSRF token may not have been available when the request was created.
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil || (resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden) {
+		return resp, err
+	}
+
+	// We can only retry requests whose body can be re-read.
+	if req.Body != nil && req.GetBody == nil {
+		return resp, err
+	}
+
+	_ = resp.Body.Close()
+
+	if err = c.ensureLoggedIn(true); err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	c.addHeaders(retry.Header, retry.URL)
+
+	return c.httpClient.Do(retry)
+}
+
+// DialWebsocket dials the websocket at the specified URL with the configured authentication headers.
+func (c *ServerClient) DialWebsocket(wsUrl string) (*websocket.Conn, *http.Response, error) {
+	if c.config.Password != "" {
+		if err := c.ensureLoggedIn(false); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	header := http.Header{}
+	if u, err := url.Parse(c.HttpUrl()); err == nil {
+		c.addHeaders(header, u)
+	}
+
+	return c.dialer.Dial(wsUrl, header)
+}
+
+// addHeaders adds the token, XSRF, and custom headers to the given http.Header.
+func (c *ServerClient) addHeaders(header http.Header, u *url.URL) {
+	if c.config.Token != "" {
+		header.Set("Authorization", "token "+c.config.Token)
+	}
+
+	if xsrf := c.xsrfToken(u); xsrf != "" {
+		header.Set(xsrfHeaderName, xsrf)
+	}
+
+	for key, value := range c.config.Headers {
+		header.Set(key, value)
+	}
+}
+
+// xsrfToken returns the XSRF token issued by the Jupyter Server, if there is one.
+func (c *ServerClient) xsrfToken(u *url.URL) string {
+	// Cookies are stored under the http(s) scheme, including those used for websocket handshakes.
+	cookieUrl := *u
+	switch cookieUrl.Scheme {
+	case "ws":
+		cookieUrl.Scheme = "http"
+	case "wss":
+		cookieUrl.Scheme = "https"
+	}
+
+	for _, cookie := range c.jar.Cookies(&cookieUrl) {
+		if cookie.Name == xsrfCookieName {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+// ensureLoggedIn logs in to the Jupyter Server using the configured password if the client has not
+// already done so, or if force is true.
+func (c *ServerClient) ensureLoggedIn(force bool) error {
+	c.loginMutex.Lock()
+	defer c.loginMutex.Unlock()
+
+	if c.loggedIn && !force {
+		return nil
+	}
+
+	loginUrl := c.HttpUrl("login")
+
+	// Retrieve the login page first so that the server issues us an XSRF cookie.
+	req, err := c.NewRequest(http.MethodGet, loginUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	form := url.Values{}
+	form.Set("password", c.config.Password)
+	if xsrf := c.xsrfToken(req.URL); xsrf != "" {
+		form.Set(xsrfCookieName, xsrf)
+	}
+
+	req, err = c.NewRequest(http.MethodPost, loginUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err = c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%w: HTTP %d %s", ErrLoginFailed, resp.StatusCode, resp.Status)
+	}
+
+	c.loggedIn = true
+	return nil
+}
@@ -0,0 +1,174 @@
+package jupyter_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+)
+
+var _ = Describe("ServerClient Tests", func() {
+	Context("URLs", func() {
+		It("Will respect the base URL and the configured scheme", func() {
+			client, err := jupyter.NewServerClient("localhost:8888", &jupyter.ClientConfig{BaseUrl: "/user/alice/"})
+			Expect(err).To(BeNil())
+
+			Expect(client.HttpUrl("api", "sessions")).To(Equal("http://localhost:8888/user/alice/api/sessions"))
+			Expect(client.WebsocketUrl("api", "kernels", "abc")).To(Equal("ws://localhost:8888/user/alice/api/kernels/abc"))
+			Expect(client.Address()).To(Equal("localhost:8888/user/alice"))
+
+			client, err = jupyter.NewServerClient("localhost:8888", &jupyter.ClientConfig{UseTLS: true})
+			Expect(err).To(BeNil())
+
+			Expect(client.HttpUrl("api", "sessions")).To(Equal("https://localhost:8888/api/sessions"))
+			Expect(client.WebsocketUrl("api", "kernels")).To(Equal("wss://localhost:8888/api/kernels"))
+		})
+
+		It("Will enable TLS if the address has an https scheme", func() {
+			client, err := jupyter.NewServerClient("https://hub.example.com/", nil)
+			Expect(err).To(BeNil())
+
+			Expect(client.HttpUrl("api")).To(Equal("https://hub.example.com/api"))
+		})
+
+		It("Will reject an invalid CA certificate file", func() {
+			_, err := jupyter.NewServerClient("localhost:8888", &jupyter.ClientConfig{CACertFile: "client_test.go"})
+			Expect(errors.Is(err, jupyter.ErrInvalidCACertificate)).To(BeTrue())
+		})
+	})
+
+	Context("Authentication", func() {
+		It("Will send the token and custom headers with every request", func() {
+			var authorization, custom string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				custom = r.Header.Get("X-Custom")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client, err := jupyter.NewServerClient(server.URL, &jupyter.ClientConfig{
+				Token:   "secret",
+				Headers: map[string]string{"X-Custom": "value"},
+			})
+			Expect(err).To(BeNil())
+
+			req, err := client.NewRequest(http.MethodGet, client.HttpUrl("api", "status"), nil)
+			Expect(err).To(BeNil())
+
+			resp, err := client.Do(req)
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			Expect(authorization).To(Equal("token secret"))
+			Expect(custom).To(Equal("value"))
+		})
+
+		It("Will log in with the password and echo the XSRF token", func() {
+			var numLogins int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/lab/login" && r.Method == http.MethodGet:
+					http.SetCookie(w, &http.Cookie{Name: "_xsrf", Value: "xsrf-token", Path: "/"})
+				case r.URL.Path == "/lab/login" && r.Method == http.MethodPost:
+					Expect(r.ParseForm()).To(Succeed())
+					if r.PostForm.Get("password") != "hunter2" || r.PostForm.Get("_xsrf") != "xsrf-token" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
+					numLogins += 1
+					http.SetCookie(w, &http.Cookie{Name: "username-jupyter", Value: "authenticated", Path: "/"})
+				case strings.HasPrefix(r.URL.Path, "/lab/api/"):
+					cookie, err := r.Cookie("username-jupyter")
+					if err != nil || cookie.Value != "authenticated" || r.Header.Get("X-XSRFToken") != "xsrf-token" {
+						w.WriteHeader(http.StatusForbidden)
+						return
+					}
+
+					w.WriteHeader(http.StatusCreated)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client, err := jupyter.NewServerClient(server.URL, &jupyter.ClientConfig{BaseUrl: "lab", Password: "hunter2"})
+			Expect(err).To(BeNil())
+
+			req, err := client.NewRequest(http.MethodPost, client.HttpUrl("api", "sessions"), strings.NewReader("{}"))
+			Expect(err).To(BeNil())
+
+			resp, err := client.Do(req)
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			Expect(numLogins).To(Equal(1))
+
+			req, err = client.NewRequest(http.MethodDelete, client.HttpUrl("api", "sessions", "abc"), nil)
+			Expect(err).To(BeNil())
+
+			resp, err = client.Do(req)
+			Expect(err).To(BeNil())
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+			Expect(numLogins).To(Equal(1))
+		})
+
+		It("Will return an error if the password is rejected", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer server.Close()
+
+			client, err := jupyter.NewServerClient(server.URL, &jupyter.ClientConfig{Password: "wrong"})
+			Expect(err).To(BeNil())
+
+			req, err := client.NewRequest(http.MethodGet, client.HttpUrl("api", "sessions"), nil)
+			Expect(err).To(BeNil())
+
+			_, err = client.Do(req)
+			Expect(errors.Is(err, jupyter.ErrLoginFailed)).To(BeTrue())
+		})
+	})
+
+	Context("Inventory", func() {
+		It("Will list and delete the sessions of the Jupyter Server", func() {
+			var deleted []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/sessions":
+					_, _ = w.Write([]byte(`[{"id": "abc", "name": "abc", "path": "abc.ipynb", "type": "notebook",
+						"kernel": {"id": "kernel-abc", "name": "distributed"}, "workload_id": "workload"}]`))
+				case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/sessions/"):
+					deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/sessions/"))
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client, err := jupyter.NewServerClient(server.URL, nil)
+			Expect(err).To(BeNil())
+
+			sessions, err := client.ListSessions()
+			Expect(err).To(BeNil())
+			Expect(sessions).To(HaveLen(1))
+			Expect(sessions[0].Id).To(Equal("abc"))
+			Expect(sessions[0].Kernel.Id).To(Equal("kernel-abc"))
+			Expect(sessions[0].WorkloadId).To(Equal("workload"))
+
+			Expect(client.DeleteSession("abc")).To(Succeed())
+			Expect(deleted).To(Equal([]string{"abc"}))
+
+			err = client.DeleteKernel("kernel-abc")
+			Expect(errors.Is(err, jupyter.ErrUnexpectedFailure)).To(BeTrue())
+		})
+	})
+})
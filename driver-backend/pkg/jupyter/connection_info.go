@@ -0,0 +1,105 @@
+package jupyter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// DefaultSignatureScheme is the signature scheme used by Jupyter kernels unless configured otherwise.
+	DefaultSignatureScheme = "hmac-sha256"
+)
+
+var (
+	ErrInvalidConnectionInfo = errors.New("invalid kernel connection info")
+)
+
+// ConnectionInfo describes how to connect directly to the ZMQ sockets of a Jupyter kernel.
+//
+// The JSON representation of a ConnectionInfo is that of a kernel connection file.
+//
+// - Connection files: https://jupyter-client.readthedocs.io/en/latest/kernels.html#connection-files
+type ConnectionInfo struct {
+	IP              string `json:"ip"`
+	Transport       string `json:"transport"`
+	ShellPort       int    `json:"shell_port"`
+	ControlPort     int    `json:"control_port"`
+	IOPubPort       int    `json:"iopub_port"`
+	StdinPort       int    `json:"stdin_port"`
+	HeartbeatPort   int    `json:"hb_port"`
+	SignatureScheme string `json:"signature_scheme"`
+	Key             string `json:"key"`
+	KernelName      string `json:"kernel_name,omitempty"`
+}
+
+// gatewayConnectionInfo is implemented by the KernelConnectionInfo returned by the Cluster Gateway's StartKernel RPC.
+type gatewayConnectionInfo interface {
+	GetIp() string
+	GetTransport() string
+	GetControlPort() int32
+	GetShellPort() int32
+	GetStdinPort() int32
+	GetHbPort() int32
+	GetIopubPort() int32
+	GetSignatureScheme() string
+	GetKey() string
+}
+
+// LoadConnectionFile reads the kernel connection file at the specified path.
+func LoadConnectionFile(path string) (*ConnectionInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var connectionInfo *ConnectionInfo
+	if err = json.Unmarshal(data, &connectionInfo); err != nil {
+		return nil, fmt.Errorf("%w: \"%s\": %v", ErrInvalidConnectionInfo, path, err)
+	}
+
+	if connectionInfo == nil {
+		return nil, fmt.Errorf("%w: \"%s\" is empty", ErrInvalidConnectionInfo, path)
+	}
+
+	return connectionInfo, nil
+}
+
+// ConnectionInfoFromGateway converts the KernelConnectionInfo returned by the Cluster Gateway's StartKernel RPC
+// into a ConnectionInfo.
+func ConnectionInfoFromGateway(info gatewayConnectionInfo) *ConnectionInfo {
+	return &ConnectionInfo{
+		IP:              info.GetIp(),
+		Transport:       info.GetTransport(),
+		ShellPort:       int(info.GetShellPort()),
+		ControlPort:     int(info.GetControlPort()),
+		IOPubPort:       int(info.GetIopubPort()),
+		StdinPort:       int(info.GetStdinPort()),
+		HeartbeatPort:   int(info.GetHbPort()),
+		SignatureScheme: info.GetSignatureScheme(),
+		Key:             info.GetKey(),
+	}
+}
+
+// Validate returns an ErrInvalidConnectionInfo error if the ConnectionInfo cannot be used to connect to a kernel.
+func (info *ConnectionInfo) Validate() error {
+	if info.Transport != "" && info.Transport != "tcp" {
+		return fmt.Errorf("%w: \"%s\"", ErrZmtpUnsupportedTransport, info.Transport)
+	}
+
+	if info.IP == "" {
+		return fmt.Errorf("%w: missing IP address", ErrInvalidConnectionInfo)
+	}
+
+	if info.ShellPort <= 0 || info.ControlPort <= 0 || info.IOPubPort <= 0 || info.StdinPort <= 0 {
+		return fmt.Errorf("%w: the shell, control, iopub, and stdin ports must all be specified", ErrInvalidConnectionInfo)
+	}
+
+	return nil
+}
+
+// address returns the TCP address of the specified port of the kernel.
+func (info *ConnectionInfo) address(port int) string {
+	return fmt.Sprintf("%s:%d", info.IP, port)
+}
@@ -0,0 +1,108 @@
+package jupyter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ServerKernel is a kernel as listed by the Jupyter Server's REST API.
+type ServerKernel struct {
+	Id             string `json:"id"`
+	Name           string `json:"name"`
+	LastActivity   string `json:"last_activity"`
+	ExecutionState string `json:"execution_state"`
+	Connections    int    `json:"connections"`
+}
+
+// ServerSession is a session as listed by the Jupyter Server's REST API.
+type ServerSession struct {
+	Id     string        `json:"id"`
+	Name   string        `json:"name"`
+	Path   string        `json:"path"`
+	Type   string        `json:"type"`
+	Kernel *ServerKernel `json:"kernel"`
+
+	// WorkloadId is the ID of the workload that created the session, as embedded in the request that created
+	// the session. WorkloadId is empty if the Jupyter Server does not report it.
+	WorkloadId string `json:"workload_id,omitempty"`
+}
+
+// ListSessions returns the sessions of the Jupyter Server.
+func (c *ServerClient) ListSessions() ([]*ServerSession, error) {
+	sessions := make([]*ServerSession, 0)
+	if err := c.getJson(c.HttpUrl("api", "sessions"), &sessions); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// ListKernels returns the kernels of the Jupyter Server.
+func (c *ServerClient) ListKernels() ([]*ServerKernel, error) {
+	kernels := make([]*ServerKernel, 0)
+	if err := c.getJson(c.HttpUrl("api", "kernels"), &kernels); err != nil {
+		return nil, err
+	}
+
+	return kernels, nil
+}
+
+// DeleteSession deletes the specified session of the Jupyter Server, which also shuts down its kernel.
+func (c *ServerClient) DeleteSession(sessionId string) error {
+	return c.delete(c.HttpUrl("api", "sessions", sessionId))
+}
+
+// DeleteKernel shuts down the specified kernel of the Jupyter Server.
+func (c *ServerClient) DeleteKernel(kernelId string) error {
+	return c.delete(c.HttpUrl("api", "kernels", kernelId))
+}
+
+// getJson issues an HTTP GET request to the specified URL and decodes the JSON response body into v.
+func (c *ServerClient) getJson(url string, v interface{}) error {
+	req, err := c.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: HTTP %d %s - %s", ErrUnexpectedFailure, resp.StatusCode, resp.Status, string(body))
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// delete issues an HTTP DELETE request to the specified URL.
+func (c *ServerClient) delete(url string) error {
+	req, err := c.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: HTTP %d %s - %s", ErrUnexpectedFailure, resp.StatusCode, resp.Status, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package jupyter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestJupyter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Jupyter Suite")
+}
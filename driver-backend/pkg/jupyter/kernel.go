@@ -8,10 +8,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
 	"runtime/debug"
 	"strings"
 	"sync"
@@ -35,12 +35,29 @@ const (
 	KernelDisconnected   KernelConnectionStatus = "disconnected" // We're not connected to the kernel, but we're unsure if it is dead or not.
 	KernelDead           KernelConnectionStatus = "dead"         // Kernel is dead. We're not connected.
 
+	// maxStreamHistory is the number of most-recent stdout and stderr messages retained by a BasicKernelConnection.
+	maxStreamHistory = 1000
+
 	ExecuteRequest          MessageType = "execute_request"
 	KernelInfoRequest       MessageType = "kernel_info_request"
 	StopRunningTrainingCode MessageType = "stop_running_training_code_request"
 	DummyMessage            MessageType = "dummy_message_request"
 	AckMessage              MessageType = "ACK"
 	CommCloseMessage        MessageType = "comm_close"
+	CompleteRequest         MessageType = "complete_request"
+	InspectRequest          MessageType = "inspect_request"
+	HistoryRequest          MessageType = "history_request"
+	IsCompleteRequest       MessageType = "is_complete_request"
+	ShutdownRequest         MessageType = "shutdown_request"
+	CommInfoRequest         MessageType = "comm_info_request"
+	CommOpenMessage         MessageType = "comm_open"
+	CommMsgMessage          MessageType = "comm_msg"
+	InputRequest            MessageType = "input_request"
+	InputReply              MessageType = "input_reply"
+	InterruptRequest        MessageType = "interrupt_request"
+
+	// DefaultRequestTimeout is how long we wait for the reply to a request sent via one of the typed request methods.
+	DefaultRequestTimeout = time.Second * 20
 
 	// KernelIdMetadataKey is a reserved metadata key, meaning it cannot be overwritten in the kernel's
 	// metadata dictionary.
@@ -107,14 +124,23 @@ type BasicKernelConnection struct {
 	// Keys for this channel are generated by the 'getResponseChannelKeyX' functions defined in "internal/server/jupyter/utils.go".
 	// See the documentation of those functions for additional details.
 	responseChannels map[string]chan KernelMessage
+	// pendingRequests are the shell and control requests that have not yet received a reply.
+	// Keys are the same as those of the responseChannels.
+	pendingRequests map[string]*pendingRequest
+	// responseChannelsMutex ensures atomic access to the responseChannels and pendingRequests.
+	responseChannelsMutex sync.Mutex
 
 	// IOPub message handlers.
 	iopubMessageHandlers map[string]IOPubMessageHandler
 
+	// messageObservers are notified of the shell and control messages sent to and received from the kernel.
+	messageObservers      map[string]MessageObserver
+	messageObserversMutex sync.RWMutex
+
 	messageCount                  int                     // How many messages we've sent. Used when creating message IDs.
 	connectionStatus              KernelConnectionStatus  // Connection status with the remote kernel.
 	kernelId                      string                  // ID of the associated kernel
-	jupyterServerAddress          string                  // Jupyter server IP address
+	client                        *ServerClient           // Used to issue requests to and dial websockets with the Jupyter Server.
 	clientId                      string                  // Jupyter client ID
 	username                      string                  // Jupyter username
 	webSocket                     *websocket.Conn         // The websocket that is connected to Jupyter
@@ -128,6 +154,19 @@ type BasicKernelConnection struct {
 
 	setupInProgress        atomic.Int32
 	reconnectionInProgress atomic.Int32
+	closed                 atomic.Bool // closed is set once Close is called, after which we no longer try to reconnect.
+
+	// reconnectionPolicy configures how we reconnect to the kernel after losing our connection.
+	reconnectionPolicy *ReconnectionPolicy
+	// consecutiveReconnectionFailures is the number of consecutive failed reconnections, used for circuit breaking.
+	consecutiveReconnectionFailures int
+	// circuitOpenUntil is the time until which no reconnection will be attempted.
+	circuitOpenUntil time.Time
+	// rng is used to add jitter to reconnection backoff intervals.
+	rng *rand.Rand
+
+	// onConnectionStatusChanged is called whenever the connection status changes.
+	onConnectionStatusChanged ConnectionStatusChangedHandler
 
 	// metadata is a map containing basic metadata used for labeling kernelMetricsManager.
 	metadata map[string]interface{}
@@ -148,17 +187,43 @@ type BasicKernelConnection struct {
 	wlock             sync.Mutex // Synchronizes write operations on the websocket.
 	iopubHandlerMutex sync.Mutex // Synchronizes access to state related to the IOPub message handlers.
 
+	// inputRequestHandler is invoked when the kernel sends an "input_request" message on the stdin channel.
+	inputRequestHandler InputRequestHandler
+
+	// transport, if non-nil, is used to send messages to the kernel instead of the Jupyter Server websocket.
+	transport kernelMessageTransport
+
 	onError func(err error)
 
 	// Used to publish metrics to Prometheus.
 	metricsConsumer MetricsConsumer
 }
 
+// kernelMessageTransport sends messages to a kernel via something other than the Jupyter Server websocket.
+type kernelMessageTransport interface {
+	sendKernelMessage(message KernelMessage) error
+}
+
 // NewKernelConnection creates and returns a pointer to a new BasicKernelConnection struct.
 //
 // The BasicKernelConnection will not be connected until InitialConnect is called.
-func NewKernelConnection(kernelId string, clientId string, username string, jupyterServerAddress string,
+func NewKernelConnection(kernelId string, clientId string, username string, client *ServerClient,
 	atom *zap.AtomicLevel, metricsConsumer MetricsConsumer, onError func(err error)) (*BasicKernelConnection, error) {
+	conn := newBasicKernelConnection(kernelId, clientId, username, client, atom, metricsConsumer, onError)
+
+	err := conn.setupWebsocket()
+	if err != nil {
+		conn.logger.Error("Failed to setup websocket for new kernel.", zap.Error(err))
+		conn.tryCallOnError(err)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// newBasicKernelConnection creates a BasicKernelConnection without connecting it to the kernel.
+func newBasicKernelConnection(kernelId string, clientId string, username string, client *ServerClient,
+	atom *zap.AtomicLevel, metricsConsumer MetricsConsumer, onError func(err error)) *BasicKernelConnection {
 	if len(clientId) == 0 {
 		clientId = uuid.NewString()
 	}
@@ -168,15 +233,19 @@ func NewKernelConnection(kernelId string, clientId string, username string, jupy
 		kernelId:             kernelId,
 		username:             username,
 		atom:                 atom,
-		jupyterServerAddress: jupyterServerAddress,
+		client:               client,
 		connectionStatus:     KernelConnectionInit,
 		responseChannels:     make(map[string]chan KernelMessage),
+		pendingRequests:      make(map[string]*pendingRequest),
+		reconnectionPolicy:   client.reconnectionPolicy(),
+		rng:                  rand.New(rand.NewSource(time.Now().UnixNano())),
 		registeredShell:      false,
 		registeredControl:    false,
 		messageCount:         0,
 		kernelStdout:         make([]string, 0),
 		kernelStderr:         make([]string, 0),
 		iopubMessageHandlers: make(map[string]IOPubMessageHandler),
+		messageObservers:     make(map[string]MessageObserver),
 		metadata:             make(map[string]interface{}),
 		serializedMetadata:   make(map[string]string),
 		metricsConsumer:      metricsConsumer,
@@ -194,14 +263,7 @@ func NewKernelConnection(kernelId string, clientId string, username string, jupy
 	conn.logger = logger
 	conn.sugaredLogger = logger.Sugar()
 
-	err := conn.setupWebsocket(conn.jupyterServerAddress)
-	if err != nil {
-		conn.logger.Error("Failed to setup websocket for new kernel.", zap.Error(err))
-		conn.tryCallOnError(err)
-		return nil, err
-	}
-
-	return conn, nil
+	return conn
 }
 
 func (conn *BasicKernelConnection) tryCallOnError(err error) {
@@ -215,6 +277,27 @@ func (conn *BasicKernelConnection) SetOnError(onError func(err error)) {
 	conn.onError = onError
 }
 
+// SetOnConnectionStatusChanged registers a handler that is called whenever the connection status changes.
+func (conn *BasicKernelConnection) SetOnConnectionStatusChanged(handler ConnectionStatusChangedHandler) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	conn.onConnectionStatusChanged = handler
+}
+
+// SetReconnectionPolicy sets the ReconnectionPolicy used after losing the connection to the kernel.
+// If policy is nil, then the DefaultReconnectionPolicy is used.
+func (conn *BasicKernelConnection) SetReconnectionPolicy(policy *ReconnectionPolicy) {
+	if policy == nil {
+		policy = DefaultReconnectionPolicy()
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	conn.reconnectionPolicy = policy
+}
+
 // AddMetadata attaches some metadata to the BasicKernelConnection.
 //
 // This particular implementation of AddMetadata is thread-safe.
@@ -288,6 +371,17 @@ func (conn *BasicKernelConnection) Stderr() []string {
 	return conn.kernelStderr
 }
 
+// appendStreamHistory appends the text to the history, discarding the oldest entries such that no more than
+// maxStreamHistory entries are retained.
+func appendStreamHistory(history []string, text string) []string {
+	history = append(history, text)
+	if len(history) > maxStreamHistory {
+		history = append(history[:0], history[len(history)-maxStreamHistory:]...)
+	}
+
+	return history
+}
+
 func (conn *BasicKernelConnection) waitForResponseWithTimeout(responseChan chan KernelMessage, timeoutInterval time.Duration, messageType MessageType) (KernelMessage, error) {
 	st := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutInterval)
@@ -306,6 +400,7 @@ func (conn *BasicKernelConnection) waitForResponseWithTimeout(responseChan chan
 				zap.String("kernel_id", conn.kernelId),
 				zap.Duration("timeout_interval", timeoutInterval),
 				zap.Error(err))
+			conn.forgetPendingRequest(responseChan)
 			return nil, err
 		}
 	case resp := <-responseChan:
@@ -315,6 +410,11 @@ func (conn *BasicKernelConnection) waitForResponseWithTimeout(responseChan chan
 				zap.String("request_message_type", messageType.String()),
 				zap.String("kernel_id", conn.kernelId),
 				zap.Duration("time_elapsed", time.Since(st)))
+
+			if err := PendingRequestFailure(resp); err != nil {
+				return nil, err
+			}
+
 			return resp, nil
 		}
 	}
@@ -350,6 +450,52 @@ func (conn *BasicKernelConnection) UnregisterIoPubHandler(id string) error {
 	return nil
 }
 
+// RegisterMessageObserver registers an observer of the shell and control messages exchanged with the kernel
+// under a specific ID.
+func (conn *BasicKernelConnection) RegisterMessageObserver(id string, observer MessageObserver) error {
+	conn.messageObserversMutex.Lock()
+	defer conn.messageObserversMutex.Unlock()
+
+	if _, ok := conn.messageObservers[id]; ok {
+		conn.logger.Error("Could not register message observer.", zap.String("id", id), zap.Error(ErrHandlerAlreadyExists))
+		return ErrHandlerAlreadyExists
+	}
+
+	conn.messageObservers[id] = observer
+	conn.logger.Debug("Registered message observer.", zap.String("id", id))
+	return nil
+}
+
+// UnregisterMessageObserver unregisters the MessageObserver that was registered under the specified ID.
+func (conn *BasicKernelConnection) UnregisterMessageObserver(id string) error {
+	conn.messageObserversMutex.Lock()
+	defer conn.messageObserversMutex.Unlock()
+
+	if _, ok := conn.messageObservers[id]; !ok {
+		conn.logger.Error("Could not unregister message observer.", zap.String("id", id), zap.Error(ErrNoHandlerFound))
+		return ErrNoHandlerFound
+	}
+
+	delete(conn.messageObservers, id)
+	conn.logger.Debug("Unregistered message observer.", zap.String("id", id))
+	return nil
+}
+
+// notifyMessageObservers notifies the registered MessageObserver instances of a shell or control message.
+func (conn *BasicKernelConnection) notifyMessageObservers(direction MessageDirection, kernelMessage KernelMessage) {
+	channel := kernelMessage.GetChannel()
+	if channel != ShellChannel && channel != ControlChannel {
+		return
+	}
+
+	conn.messageObserversMutex.RLock()
+	defer conn.messageObserversMutex.RUnlock()
+
+	for _, observer := range conn.messageObservers {
+		observer(conn, direction, kernelMessage)
+	}
+}
+
 func (conn *BasicKernelConnection) SendDummyMessage(channel KernelSocketChannel, content interface{}, waitForResponse bool) (KernelMessage, error) {
 	message, responseChan := conn.createKernelMessage(DummyMessage, channel, content)
 	err := conn.sendMessage(message)
@@ -434,7 +580,7 @@ func (conn *BasicKernelConnection) sendAck(msg KernelMessage, channel KernelSock
 
 // JupyterServerAddress returns the address of the Jupyter Server associated with this kernel.
 func (conn *BasicKernelConnection) JupyterServerAddress() string {
-	return conn.jupyterServerAddress
+	return conn.client.Address()
 }
 
 // Connected returns true if the connection is currently active.
@@ -542,7 +688,8 @@ func (conn *BasicKernelConnection) RequestExecute(args *RequestExecuteArgs) (Ker
 	conn.waitingForExecuteResponses.Add(1)
 
 	if args.AwaitResponse() {
-		return conn.handleExecuteRequestResponse(message, args, responseChan, sentAt), nil // blocking
+		response := conn.handleExecuteRequestResponse(message, args, responseChan, sentAt) // blocking
+		return response, PendingRequestFailure(response)
 	} else {
 		go conn.handleExecuteRequestResponse(message, args, responseChan, sentAt) // non-blocking
 	}
@@ -576,6 +723,7 @@ func (conn *BasicKernelConnection) RequestKernelInfo() (KernelMessage, error) {
 		{
 			conn.logger.Error("Request of type \"kernel_info_request\" has timed out.",
 				zap.String("kernel_id", conn.kernelId), zap.String("message_id", message.GetHeader().MessageId))
+			conn.forgetPendingRequest(responseChan)
 			return nil, fmt.Errorf("ErrRequestTimedOut %w : %s", ErrRequestTimedOut, ctx.Err())
 		}
 	case resp := <-responseChan:
@@ -584,7 +732,7 @@ func (conn *BasicKernelConnection) RequestKernelInfo() (KernelMessage, error) {
 				zap.String("kernel_id", conn.kernelId),
 				zap.String("message_id", resp.GetHeader().MessageId),
 				zap.String("response", resp.String()))
-			return resp, nil
+			return resp, PendingRequestFailure(resp)
 		}
 	}
 }
@@ -617,17 +765,15 @@ func (conn *BasicKernelConnection) InterruptKernel() error {
 		return err
 	}
 
-	endpoint := path.Join(conn.jupyterServerAddress, fmt.Sprintf("/api/kernels/%s/interrupt", conn.kernelId))
-	// endpoint := fmt.Sprintf("%s/api/kernels/%s/interrupt", conn.jupyterServerAddress, conn.kernelId)
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(requestBodyEncoded))
+	endpoint := conn.client.HttpUrl(kernelServiceApi, conn.kernelId, "interrupt")
+	req, err := conn.client.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(requestBodyEncoded))
 
 	if err != nil {
 		conn.logger.Error("Failed to create HTTP request for kernel interruption.", zap.String("url", endpoint), zap.Error(err))
 		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := conn.client.Do(req)
 	if err != nil {
 		conn.logger.Error("Error while issuing HTTP request to interrupt kernel.", zap.String("url", endpoint), zap.Error(err))
 		return err
@@ -645,6 +791,8 @@ func (conn *BasicKernelConnection) InterruptKernel() error {
 
 // Close the connection to the kernel.
 func (conn *BasicKernelConnection) Close() error {
+	conn.closed.Store(true)
+
 	message, _ := conn.createKernelMessage(CommCloseMessage, ShellChannel, nil)
 	err := conn.sendMessage(message)
 
@@ -674,6 +822,22 @@ func (conn *BasicKernelConnection) Username() string {
 	return conn.username
 }
 
+// setUsername changes the user on whose behalf the BasicKernelConnection sends messages to the kernel.
+// setUsername is used when a pooled kernel is handed out to a user.
+func (conn *BasicKernelConnection) setUsername(username string) {
+	conn.username = username
+}
+
+// messageUsername returns the username placed in the header of the messages sent to the kernel, which is the
+// Jupyter client ID if the BasicKernelConnection was not created on behalf of a particular user.
+func (conn *BasicKernelConnection) messageUsername() string {
+	if conn.username == "" {
+		return conn.clientId
+	}
+
+	return conn.username
+}
+
 // decodeKernelMessage decodes a kernel message according to the WebSocket protocol and returns the
 // decoded result, or an error if one occurred.
 //
@@ -747,41 +911,39 @@ func (conn *BasicKernelConnection) decodeKernelMessage(buf []byte) (*BaseKernelM
 }
 
 // Listen for messages from the kernel.
-func (conn *BasicKernelConnection) serveMessages() {
+//
+// serveMessages returns once the websocket can no longer be read from. If the websocket was not closed
+// intentionally, and it has not already been replaced by a reconnection, then serveMessages tries to reconnect.
+func (conn *BasicKernelConnection) serveMessages(webSocket *websocket.Conn) {
 	for {
 		conn.rlock.Lock()
-		messageType, data, err := conn.webSocket.ReadMessage()
+		messageType, data, err := webSocket.ReadMessage()
 		conn.rlock.Unlock()
 
 		if err != nil {
+			if conn.closed.Load() || conn.webSocket != webSocket || conn.connectionStatus == KernelDead {
+				return
+			}
+
 			conn.logger.Error("Error while reading from kernel WebSocket.",
 				zap.String("kernel_id", conn.kernelId),
 				zap.String("client_id", conn.clientId),
 				zap.String("username", conn.username),
 				zap.Int("websocket_message_type", messageType),
-				zap.ByteString("data_byte_string", data),
-				zap.Binary("data_binary", data),
 				zap.Error(err))
-			st := time.Now()
-			connErr := conn.updateConnectionStatus(KernelDead)
 
-			if !conn.Connected() || connErr != nil {
-				conn.logger.Error("Failed to re-establish connection with kernel.",
+			st := time.Now()
+			if reconnected, _ := conn.reconnect(); reconnected {
+				conn.logger.Debug("Successfully re-established WebSocket connection to kernel following connection loss.",
 					zap.String("kernel_id", conn.kernelId),
 					zap.String("client_id", conn.clientId),
 					zap.String("username", conn.username),
-					zap.Error(connErr))
-
-				return
+					zap.Duration("time_elapsed", time.Since(st)))
 			}
 
-			conn.logger.Debug("Successfully re-established WebSocket connection to kernel following connection loss.",
-				zap.String("kernel_id", conn.kernelId),
-				zap.String("client_id", conn.clientId),
-				zap.String("username", conn.username),
-				zap.Duration("time_elapsed", time.Since(st)))
-
-			continue
+			// Either the reconnection created a new websocket, which is served by its own goroutine,
+			// or we could not reconnect (or another goroutine is already reconnecting).
+			return
 		}
 
 		var kernelMessage *BaseKernelMessage
@@ -810,54 +972,69 @@ func (conn *BasicKernelConnection) serveMessages() {
 			continue
 		}
 
-		// We send ACKs for Shell and Control messages.
-		// We will also attempt to pair the message with its original request.
-		if kernelMessage.Channel == ShellChannel || kernelMessage.Channel == ControlChannel {
-			conn.logger.Debug("Received message from kernel.",
+		conn.dispatchMessage(kernelMessage)
+	}
+}
+
+// dispatchMessage delivers a message received from the kernel to whoever is waiting for it.
+func (conn *BasicKernelConnection) dispatchMessage(kernelMessage *BaseKernelMessage) {
+	// We send ACKs for Shell and Control messages.
+	// We will also attempt to pair the message with its original request.
+	if kernelMessage.Channel == ShellChannel || kernelMessage.Channel == ControlChannel {
+		conn.logger.Debug("Received message from kernel.",
+			zap.String("kernel_id", conn.kernelId),
+			zap.String("client_id", conn.clientId),
+			zap.String("username", conn.username),
+			zap.String("channel", kernelMessage.Channel.String()),
+			zap.String("message_type", kernelMessage.Header.MessageType.String()),
+			zap.String("message_id", kernelMessage.Header.MessageId),
+			zap.String("message", kernelMessage.String()))
+
+		// Commented-out; for now, we're not ACK-ing anything.
+		// We do this in another goroutine so as not to block this message-receiver goroutine.
+		// go conn.sendAck(kernelMessage, kernelMessage.Channel)
+
+		conn.notifyMessageObservers(ReceivedMessage, kernelMessage)
+
+		responseChannelKey := getResponseChannelKeyFromReply(kernelMessage)
+
+		conn.responseChannelsMutex.Lock()
+		responseChannel, ok := conn.responseChannels[responseChannelKey]
+		delete(conn.responseChannels, responseChannelKey)
+		delete(conn.pendingRequests, responseChannelKey)
+		conn.responseChannelsMutex.Unlock()
+
+		if ok {
+			conn.logger.Debug("Found response channel for websocket message.",
+				zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
+				zap.String("response_message_id", kernelMessage.GetHeader().MessageId),
+				zap.String("message_type", string(kernelMessage.Header.MessageType)),
+				zap.String("channel", kernelMessage.Channel.String()),
+				zap.String("response_channel_key", responseChannelKey),
 				zap.String("kernel_id", conn.kernelId),
 				zap.String("client_id", conn.clientId),
-				zap.String("username", conn.username),
-				zap.String("channel", kernelMessage.Channel.String()),
-				zap.String("message_type", kernelMessage.Header.MessageType.String()),
-				zap.String("message_id", kernelMessage.Header.MessageId),
-				zap.String("message", kernelMessage.String()))
-
-			// Commented-out; for now, we're not ACK-ing anything.
-			// We do this in another goroutine so as not to block this message-receiver goroutine.
-			// go conn.sendAck(kernelMessage, kernelMessage.Channel)
-
-			responseChannelKey := getResponseChannelKeyFromReply(kernelMessage)
-			if responseChannel, ok := conn.responseChannels[responseChannelKey]; ok {
-				conn.logger.Debug("Found response channel for websocket message.",
-					zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
-					zap.String("response_message_id", kernelMessage.GetHeader().MessageId),
-					zap.String("message_type", string(kernelMessage.Header.MessageType)),
-					zap.String("channel", kernelMessage.Channel.String()),
-					zap.String("response_channel_key", responseChannelKey),
-					zap.String("kernel_id", conn.kernelId),
-					zap.String("client_id", conn.clientId),
-					zap.String("username", conn.username))
-				responseChannel <- kernelMessage
-				conn.logger.Debug("Response delivered (via channel) for websocket message.",
-					zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
-					zap.String("response_message_id", kernelMessage.GetHeader().MessageId))
-			} else {
-				conn.logger.Warn("Could not find response channel associated with message.",
-					zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
-					zap.String("response_message_id", kernelMessage.GetHeader().MessageId),
-					zap.String("message_type", string(kernelMessage.Header.MessageType)),
-					zap.String("channel", kernelMessage.Channel.String()),
-					zap.String("response_channel_key", responseChannelKey),
-					zap.String("kernel_id", conn.kernelId),
-					zap.String("client_id", conn.clientId),
-					zap.String("username", conn.username))
-			}
+				zap.String("username", conn.username))
+			responseChannel <- kernelMessage
+			conn.logger.Debug("Response delivered (via channel) for websocket message.",
+				zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
+				zap.String("response_message_id", kernelMessage.GetHeader().MessageId))
 		} else {
-			if kernelMessage.Channel == IOPubChannel {
-				// TODO: Make it so we can query/view all of the output generated by a Session via the Workload Driver console/frontend.
-				conn.handleIOPubMessage(kernelMessage)
-			}
+			conn.logger.Warn("Could not find response channel associated with message.",
+				zap.String("request_message_id", kernelMessage.GetParentHeader().MessageId),
+				zap.String("response_message_id", kernelMessage.GetHeader().MessageId),
+				zap.String("message_type", string(kernelMessage.Header.MessageType)),
+				zap.String("channel", kernelMessage.Channel.String()),
+				zap.String("response_channel_key", responseChannelKey),
+				zap.String("kernel_id", conn.kernelId),
+				zap.String("client_id", conn.clientId),
+				zap.String("username", conn.username))
 		}
+	} else if kernelMessage.Channel == IOPubChannel {
+		// TODO: Make it so we can query/view all of the output generated by a Session via the Workload Driver console/frontend.
+		conn.handleIOPubMessage(kernelMessage)
+	} else if kernelMessage.Channel == StdinChannel {
+		// We do this in another goroutine, as the handler may block while it waits for input.
+		go conn.handleStdinMessage(kernelMessage)
 	}
 }
 
@@ -917,11 +1094,11 @@ func (conn *BasicKernelConnection) defaultHandleIOPubMessage(kernelMessage Kerne
 	switch stream {
 	case "stdout":
 		{
-			conn.kernelStdout = append(conn.kernelStdout, text)
+			conn.kernelStdout = appendStreamHistory(conn.kernelStdout, text)
 		}
 	case "stderr":
 		{
-			conn.kernelStderr = append(conn.kernelStdout, text)
+			conn.kernelStderr = appendStreamHistory(conn.kernelStderr, text)
 		}
 	default:
 		conn.logger.Error("Unknown or unsupported stream found in IOPub message.",
@@ -973,7 +1150,7 @@ func (conn *BasicKernelConnection) createKernelMessage(messageType MessageType,
 		MessageId:   messageId,
 		MessageType: messageType,
 		Session:     conn.clientId,
-		Username:    conn.clientId,
+		Username:    conn.messageUsername(),
 		Version:     VERSION,
 	}
 
@@ -1033,7 +1210,10 @@ func (conn *BasicKernelConnection) createKernelMessage(messageType MessageType,
 			return message, nil
 		}
 
+		conn.responseChannelsMutex.Lock()
 		conn.responseChannels[responseChannelKey] = responseChannel
+		conn.pendingRequests[responseChannelKey] = &pendingRequest{message: message, responseChannel: responseChannel}
+		conn.responseChannelsMutex.Unlock()
 
 		conn.sugaredLogger.Debugf("Stored response channel for %s \"%s\" message under key \"%s\" for kernel %s.",
 			channel, messageType, responseChannelKey, conn.kernelId)
@@ -1056,7 +1236,7 @@ func (conn *BasicKernelConnection) updateConnectionStatus(status KernelConnectio
 		return nil
 	}
 
-	conn.connectionStatus = status
+	conn.setConnectionStatus(status)
 
 	// Send a kernel info request to make sure we send at least one
 	// message to get kernel status back. Always request kernel info
@@ -1095,7 +1275,7 @@ func (conn *BasicKernelConnection) updateConnectionStatus(status KernelConnectio
 				zap.String("kernel_id", conn.kernelId),
 				zap.Int("num_attempts", maxNumTries))
 
-			conn.connectionStatus = KernelDisconnected
+			conn.setConnectionStatus(KernelDisconnected)
 
 			if err == nil {
 				err = fmt.Errorf("failed to issue \"kernel_info_request\" message")
@@ -1110,13 +1290,32 @@ func (conn *BasicKernelConnection) updateConnectionStatus(status KernelConnectio
 	return nil
 }
 
+// setConnectionStatus sets the connection status and notifies the ConnectionStatusChangedHandler, if there is one.
+func (conn *BasicKernelConnection) setConnectionStatus(status KernelConnectionStatus) {
+	previous := conn.connectionStatus
+	if previous == status {
+		return
+	}
+
+	conn.connectionStatus = status
+
+	conn.mu.Lock()
+	handler := conn.onConnectionStatusChanged
+	conn.mu.Unlock()
+
+	if handler != nil {
+		handler(conn, previous, status)
+	}
+}
+
 // setupWebsocket sets up the WebSocket connection to the Jupyter Server.
 // Side-effect: updates the BasicKernelConnection's `webSocket` field.
-func (conn *BasicKernelConnection) setupWebsocket(jupyterServerAddress string) error {
+func (conn *BasicKernelConnection) setupWebsocket() error {
 	if !conn.setupInProgress.CompareAndSwap(0, 1) {
 		conn.logger.Warn("Cannot setup WebSocket. Another setup procedure is already underway.")
 		return ErrSetupInProgress
 	}
+	defer conn.setupInProgress.Store(0)
 
 	if conn.webSocket != nil {
 		conn.logger.Warn("Existing WebSocket found. Recreating anyway.", zap.String("kernel_id", conn.kernelId))
@@ -1135,16 +1334,7 @@ func (conn *BasicKernelConnection) setupWebsocket(jupyterServerAddress string) e
 		return err
 	}
 
-	wsUrl := "ws://" + jupyterServerAddress
-	idUrl := url.PathEscape(conn.kernelId)
-
-	partialUrl, err := url.JoinPath(wsUrl, kernelServiceApi, idUrl)
-	if err != nil {
-		conn.logger.Error("Error when creating partial URL.", zap.String("wsUrl", wsUrl), zap.String("kernelServiceApi", kernelServiceApi), zap.String("idUrl", idUrl), zap.Error(err))
-		err = fmt.Errorf("ErrWebsocketCreationFailed %w : %s", ErrWebsocketCreationFailed, err.Error())
-		conn.tryCallOnError(err)
-		return err
-	}
+	partialUrl := conn.client.WebsocketUrl(kernelServiceApi, url.PathEscape(conn.kernelId))
 
 	conn.sugaredLogger.Debugf("Created partial kernel websocket URL: '%s'", partialUrl)
 	endpoint := partialUrl + "/" + fmt.Sprintf("channels?session_id=%s", url.PathEscape(conn.clientId))
@@ -1153,11 +1343,7 @@ func (conn *BasicKernelConnection) setupWebsocket(jupyterServerAddress string) e
 
 	st := time.Now()
 
-	dialer := &websocket.Dialer{
-		Proxy:            http.ProxyFromEnvironment,
-		HandshakeTimeout: 90 * time.Second,
-	}
-	ws, _, err := dialer.Dial(endpoint, nil)
+	ws, _, err := conn.client.DialWebsocket(endpoint)
 	if err != nil {
 		conn.logger.Error("Failed to dial kernel websocket.", zap.String("endpoint", endpoint), zap.String("kernel_id", conn.kernelId), zap.Error(err))
 		err = fmt.Errorf("ErrWebsocketCreationFailed %w : %s", ErrWebsocketCreationFailed, err.Error())
@@ -1168,7 +1354,7 @@ func (conn *BasicKernelConnection) setupWebsocket(jupyterServerAddress string) e
 	conn.logger.Debug("Successfully connected to the kernel.", zap.Duration("time-taken-to-connect", time.Since(st)), zap.String("kernel_id", conn.kernelId))
 	conn.webSocket = ws
 
-	go conn.serveMessages()
+	go conn.serveMessages(ws)
 
 	// Set up the close handler, which automatically tries to reconnect.
 	if conn.originalWebsocketCloseHandler == nil {
@@ -1192,10 +1378,6 @@ func (conn *BasicKernelConnection) setupWebsocket(jupyterServerAddress string) e
 	// The registration idea was so we could figure out a way to add support for ACKs between the Cluster Gateway and the Golang Jupyter frontends.
 	// conn.registerAsGolangFrontend()
 
-	if !conn.setupInProgress.CompareAndSwap(1, 0) {
-		panic("CompareAndSwap should have swapped.")
-	}
-
 	return nil
 }
 
@@ -1213,7 +1395,10 @@ func (conn *BasicKernelConnection) websocketClosed(code int, text string) error
 		conn.logger.Error("Exception encountered while trying to retrieve kernel model.",
 			zap.String("kernel_id", conn.kernelId), zap.Error(err))
 
-		if errors.Is(err, ErrNetworkIssue) {
+		// The kernel is definitely gone if the Jupyter Server does not know about it or reports an unexpected
+		// failure. Any other error (e.g., a network issue or the Jupyter Server being briefly unreachable) may be
+		// transient, so we try to reconnect.
+		if !errors.Is(err, ErrKernelNotFound) && !errors.Is(err, ErrUnexpectedFailure) {
 			reconnected, reconnectionAttempted := conn.reconnect()
 			if !reconnectionAttempted {
 				// Error is only non-nil if reconnect could not be attempted due to another concurrent reconnection attempt.
@@ -1222,13 +1407,13 @@ func (conn *BasicKernelConnection) websocketClosed(code int, text string) error
 			}
 
 			if reconnected {
-				// If it was a network error, and we were able to reconnect, then exit the 'websocket closed' handler.
+				// If the error was transient, and we were able to reconnect, then exit the 'websocket closed' handler.
 				return nil
 			}
 		}
 
 		originalStatus := conn.connectionStatus
-		// If it was not a network error, or it was, but we failed to reconnect, then call the original 'websocket closed' handler.
+		// If the kernel is gone, or we failed to reconnect, then call the original 'websocket closed' handler.
 		err = conn.updateConnectionStatus(KernelDead)
 		if err != nil {
 			conn.logger.Error("Failed to set kernel connection status.",
@@ -1272,77 +1457,182 @@ func (conn *BasicKernelConnection) websocketClosed(code int, text string) error
 	}
 }
 
-// reconnect attempts to reconnect to the kernel.
+// reconnect attempts to reconnect to the kernel in accordance with the connection's ReconnectionPolicy.
 // The first boolean returned indicates whether the reconnection was successful.
 // The second boolean returned indicates whether the reconnection was attempted.
 // If there is already another reconnect attempt underway, then this call to reconnect will return immediately.
+//
+// Once reconnect returns, the shell and control requests that were awaiting a reply when the connection was lost
+// have either been re-sent or failed with an ErrPendingRequestFailed error, as specified by the ReconnectionPolicy.
 func (conn *BasicKernelConnection) reconnect() (bool, bool) {
 	if !conn.reconnectionInProgress.CompareAndSwap(0, 1) {
 		conn.logger.Warn("Cannot attempt to reconnect. Another reconnection attempt is already underway.", zap.String("kernel_id", conn.kernelId))
 		return false /* reconnection failed */, false /* we did not try to reconnect */
 	}
+	defer conn.reconnectionInProgress.Store(0)
 
-	numTries := 0
-	maxNumTries := 5
+	conn.mu.Lock()
+	policy := conn.reconnectionPolicy
+	conn.mu.Unlock()
 
-	conn.logger.Warn("Attempting to reconnect to kernel.", zap.String("kernel_id", conn.kernelId))
+	// Requests sent before the connection was lost. Requests sent while reconnecting are not included.
+	pending := conn.takePendingRequests()
 
-	for numTries < maxNumTries {
-		err := conn.setupWebsocket(conn.jupyterServerAddress)
-		if err != nil {
-			if errors.Is(err, ErrNetworkIssue) && (numTries+1) <= maxNumTries {
-				numTries += 1
-				sleepInterval := time.Second * time.Duration(2*numTries)
-				conn.logger.Error("Network error encountered while trying to reconnect to kernel.", zap.String("kernel_id", conn.kernelId), zap.Error(err), zap.Duration("next-sleep-interval", sleepInterval))
-				originalStatus := conn.connectionStatus
-				err = conn.updateConnectionStatus(KernelDisconnected)
-				if err != nil {
-					conn.logger.Error("Failed to set kernel connection status.",
-						zap.String("initial_status", originalStatus.String()),
-						zap.String("target_status", KernelDead.String()),
-						zap.String("kernel_id", conn.kernelId))
-				}
-
-				time.Sleep(sleepInterval)
-				continue
+	if time.Now().Before(conn.circuitOpenUntil) {
+		conn.logger.Warn("Not attempting to reconnect to kernel, as the circuit is open.",
+			zap.String("kernel_id", conn.kernelId), zap.Time("circuit_open_until", conn.circuitOpenUntil))
+		conn.setConnectionStatus(KernelDead)
+		conn.failPendingRequests(pending, fmt.Errorf("%w: \"%s\"", ErrCircuitOpen, conn.kernelId))
+		return false /* reconnection failed */, false /* we did not try to reconnect */
+	}
+
+	conn.logger.Warn("Attempting to reconnect to kernel.", zap.String("kernel_id", conn.kernelId),
+		zap.Int("num_pending_requests", len(pending)))
+
+	st := time.Now()
+	var err error
+	for attempt := 0; policy.MaxAttempts <= 0 || attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := policy.Backoff(attempt-1, conn.rng)
+			if policy.MaxElapsedTime > 0 && time.Since(st)+backoff > policy.MaxElapsedTime {
+				conn.logger.Warn("Giving up on reconnecting to kernel, as the maximum elapsed time would be exceeded.",
+					zap.String("kernel_id", conn.kernelId), zap.Duration("time_elapsed", time.Since(st)),
+					zap.Duration("max_elapsed_time", policy.MaxElapsedTime))
+				break
 			}
 
-			conn.logger.Error("Connection to kernel is dead.", zap.String("kernel_id", conn.kernelId), zap.Error(err))
-			originalStatus := conn.connectionStatus
-			err = conn.updateConnectionStatus(KernelDead)
-			if err != nil {
-				conn.logger.Error("Failed to set kernel connection status.",
-					zap.String("initial_status", originalStatus.String()),
-					zap.String("target_status", KernelDead.String()),
-					zap.String("kernel_id", conn.kernelId))
+			conn.setConnectionStatus(KernelDisconnected)
+			time.Sleep(backoff)
+		}
+
+		if conn.closed.Load() {
+			break
+		}
+
+		if err = conn.setupWebsocket(); err == nil {
+			conn.consecutiveReconnectionFailures = 0
+			conn.recordReconnectionAttempt(true)
+
+			if policy.ReplayPendingRequests {
+				conn.replayPendingRequests(pending)
+			} else {
+				conn.failPendingRequests(pending, fmt.Errorf("%w: \"%s\" reconnected", ErrPendingRequestFailed, conn.kernelId))
 			}
-			return false /* reconnection failed */, true /* we did try to reconnect */
-		} else {
+
 			return true /* reconnection succeeded */, true /* we did try to reconnect */
 		}
+
+		conn.logger.Error("Failed to reconnect to kernel.", zap.String("kernel_id", conn.kernelId),
+			zap.Int("attempt", attempt+1), zap.Int("max_attempts", policy.MaxAttempts), zap.Error(err))
+	}
+
+	conn.consecutiveReconnectionFailures += 1
+	if policy.CircuitBreakerThreshold > 0 && conn.consecutiveReconnectionFailures >= policy.CircuitBreakerThreshold {
+		conn.circuitOpenUntil = time.Now().Add(policy.CircuitBreakerCooldown)
+		conn.logger.Error("Too many consecutive failed reconnections. Opening circuit.",
+			zap.String("kernel_id", conn.kernelId),
+			zap.Int("consecutive_failures", conn.consecutiveReconnectionFailures),
+			zap.Time("circuit_open_until", conn.circuitOpenUntil))
+	}
+
+	conn.logger.Error("Connection to kernel is dead.", zap.String("kernel_id", conn.kernelId), zap.Error(err))
+	conn.setConnectionStatus(KernelDead)
+	conn.recordReconnectionAttempt(false)
+	conn.failPendingRequests(pending, fmt.Errorf("%w: \"%s\": %v", ErrReconnectionFailed, conn.kernelId, err))
+
+	return false /* reconnection failed */, true /* we did try to reconnect */
+}
+
+// takePendingRequests removes and returns all the pending requests.
+func (conn *BasicKernelConnection) takePendingRequests() map[string]*pendingRequest {
+	conn.responseChannelsMutex.Lock()
+	defer conn.responseChannelsMutex.Unlock()
+
+	pending := conn.pendingRequests
+	conn.pendingRequests = make(map[string]*pendingRequest)
+	for key := range pending {
+		delete(conn.responseChannels, key)
+	}
+
+	return pending
+}
+
+// forgetPendingRequest stops tracking the request whose reply would be delivered via the given channel,
+// such as when we stop waiting for the reply.
+func (conn *BasicKernelConnection) forgetPendingRequest(responseChannel chan KernelMessage) {
+	conn.responseChannelsMutex.Lock()
+	defer conn.responseChannelsMutex.Unlock()
+
+	for key, request := range conn.pendingRequests {
+		if request.responseChannel == responseChannel {
+			delete(conn.pendingRequests, key)
+			delete(conn.responseChannels, key)
+			return
+		}
 	}
+}
 
-	if !conn.reconnectionInProgress.CompareAndSwap(1, 0) {
-		panic("CompareAndSwap should've swapped.")
+// replayPendingRequests re-sends the given requests. Requests that cannot be re-sent are failed.
+func (conn *BasicKernelConnection) replayPendingRequests(pending map[string]*pendingRequest) {
+	for key, request := range pending {
+		conn.responseChannelsMutex.Lock()
+		conn.responseChannels[key] = request.responseChannel
+		conn.pendingRequests[key] = request
+		conn.responseChannelsMutex.Unlock()
+
+		conn.logger.Debug("Replaying pending request.", zap.String("kernel_id", conn.kernelId),
+			zap.String("message_id", request.message.GetHeader().MessageId),
+			zap.String("message_type", request.message.GetHeader().MessageType.String()))
+
+		if err := conn.sendMessage(request.message); err != nil {
+			conn.forgetPendingRequest(request.responseChannel)
+			conn.failPendingRequests(map[string]*pendingRequest{key: request}, fmt.Errorf("%w: replay failed: %v", ErrPendingRequestFailed, err))
+		}
 	}
+}
+
+// failPendingRequests delivers a synthetic error reply in place of the reply to each of the given requests.
+func (conn *BasicKernelConnection) failPendingRequests(pending map[string]*pendingRequest, err error) {
+	for _, request := range pending {
+		conn.logger.Warn("Failing pending request.", zap.String("kernel_id", conn.kernelId),
+			zap.String("message_id", request.message.GetHeader().MessageId),
+			zap.String("message_type", request.message.GetHeader().MessageType.String()),
+			zap.Error(err))
 
-	return false /* reconnection succeeded */, true /* we did try to reconnect */
+		// Response channels are buffered, so this will not block unless a reply was somehow already delivered.
+		select {
+		case request.responseChannel <- newPendingRequestFailedReply(request.message, err):
+		default:
+		}
+	}
+}
+
+// recordReconnectionAttempt publishes the outcome of a reconnection attempt via the MetricsConsumer, if one is configured.
+func (conn *BasicKernelConnection) recordReconnectionAttempt(succeeded bool) {
+	if conn.metricsConsumer == nil {
+		return
+	}
+
+	var workloadId string
+	if val, loaded := conn.GetMetadata(WorkloadIdMetadataKey); loaded {
+		workloadId, _ = val.(string)
+	}
+
+	conn.metricsConsumer.RecordKernelReconnectionAttempt(conn.kernelId, workloadId, succeeded)
 }
 
 func (conn *BasicKernelConnection) getKernelModel() (*jupyterKernel, error) {
 	conn.logger.Debug("Retrieving kernel model via HTTP Rest API.", zap.String("kernel_id", conn.kernelId))
 
-	address := path.Join(conn.jupyterServerAddress, fmt.Sprintf("/api/kernels/%s", conn.kernelId))
-	endpoint := fmt.Sprintf("http://%s", address)
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	endpoint := conn.client.HttpUrl(kernelServiceApi, conn.kernelId)
+	req, err := conn.client.NewRequest(http.MethodGet, endpoint, nil)
 	if err != nil {
 		conn.logger.Error("Error encountered while creating HTTP request to get model for kernel.", zap.String("kernel_id", conn.kernelId), zap.String("endpoint", endpoint), zap.Error(err))
 		conn.tryCallOnError(err)
 		return nil, err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := conn.client.Do(req)
 	if err != nil {
 		conn.logger.Error("Received error while requesting model for kernel.", zap.String("kernel_id", conn.kernelId), zap.String("endpoint", endpoint), zap.Error(err))
 		conn.tryCallOnError(err)
@@ -1413,7 +1703,12 @@ func (conn *BasicKernelConnection) sendMessage(message KernelMessage) error {
 
 		message.AddMetadata("sent_at_unix_micro", time.Now().UnixMicro())
 
-		err := conn.webSocket.WriteJSON(message)
+		var err error
+		if conn.transport != nil {
+			err = conn.transport.sendKernelMessage(message)
+		} else {
+			err = conn.webSocket.WriteJSON(message)
+		}
 		conn.wlock.Unlock()
 		if err != nil {
 			conn.sugaredLogger.Errorf("Error while writing %s message (ID=%s) of type '%s' now to kernel %s. Error: %v", message.GetChannel(), message.GetHeader().MessageId, message.GetHeader().MessageType, conn.kernelId, zap.Error(err))
@@ -1425,5 +1720,6 @@ func (conn *BasicKernelConnection) sendMessage(message KernelMessage) error {
 	}
 
 	conn.sugaredLogger.Debugf("Successfully sent %s message %s of type %s to kernel %s.", message.GetChannel(), message.GetHeader().MessageId, message.GetHeader().MessageType, conn.kernelId)
+	conn.notifyMessageObservers(SentMessage, message)
 	return nil
 }
@@ -0,0 +1,205 @@
+package jupyter
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestComplete sends a 'complete_request' message and returns the content of the 'complete_reply'.
+func (conn *BasicKernelConnection) RequestComplete(code string, cursorPos int) (*CompleteReplyContent, error) {
+	reply := &CompleteReplyContent{}
+	err := conn.sendRequestAndAwaitReply(CompleteRequest, ShellChannel,
+		&CompleteRequestContent{Code: code, CursorPos: cursorPos}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// RequestInspect sends an 'inspect_request' message and returns the content of the 'inspect_reply'.
+func (conn *BasicKernelConnection) RequestInspect(code string, cursorPos int, detailLevel int) (*InspectReplyContent, error) {
+	reply := &InspectReplyContent{}
+	err := conn.sendRequestAndAwaitReply(InspectRequest, ShellChannel,
+		&InspectRequestContent{Code: code, CursorPos: cursorPos, DetailLevel: detailLevel}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// RequestHistory sends a 'history_request' message and returns the content of the 'history_reply'.
+func (conn *BasicKernelConnection) RequestHistory(request *HistoryRequestContent) (*HistoryReplyContent, error) {
+	if request == nil {
+		request = &HistoryRequestContent{HistAccessType: "tail", N: 10}
+	}
+
+	reply := &HistoryReplyContent{}
+	err := conn.sendRequestAndAwaitReply(HistoryRequest, ShellChannel, request, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// RequestIsComplete sends an 'is_complete_request' message and returns the content of the 'is_complete_reply'.
+func (conn *BasicKernelConnection) RequestIsComplete(code string) (*IsCompleteReplyContent, error) {
+	reply := &IsCompleteReplyContent{}
+	err := conn.sendRequestAndAwaitReply(IsCompleteRequest, ShellChannel, &IsCompleteRequestContent{Code: code}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// RequestShutdown sends a 'shutdown_request' message via the control channel and returns the content of
+// the 'shutdown_reply'. If restart is true, then the kernel will be restarted after it shuts down.
+func (conn *BasicKernelConnection) RequestShutdown(restart bool) (*ShutdownReplyContent, error) {
+	reply := &ShutdownReplyContent{}
+	err := conn.sendRequestAndAwaitReply(ShutdownRequest, ControlChannel, &ShutdownRequestContent{Restart: restart}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// RequestCommInfo sends a 'comm_info_request' message and returns the content of the 'comm_info_reply'.
+// If targetName is non-empty, then only the comms with that target are returned.
+func (conn *BasicKernelConnection) RequestCommInfo(targetName string) (*CommInfoReplyContent, error) {
+	reply := &CommInfoReplyContent{}
+	err := conn.sendRequestAndAwaitReply(CommInfoRequest, ShellChannel, &CommInfoRequestContent{TargetName: targetName}, reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// OpenComm sends a 'comm_open' message for a new comm with the specified target and returns the comm's ID.
+//
+// Messages sent by the kernel to the comm are delivered via the IOPub channel and can thus be consumed by
+// registering an IOPubMessageHandler.
+func (conn *BasicKernelConnection) OpenComm(targetName string, data map[string]interface{}) (string, error) {
+	commId := uuid.NewString()
+
+	message, _ := conn.createKernelMessage(CommOpenMessage, ShellChannel,
+		&CommOpenContent{CommId: commId, TargetName: targetName, Data: data})
+	if err := conn.sendMessage(message); err != nil {
+		conn.logger.Error("Error while writing 'comm_open' message.", zap.String("kernel_id", conn.kernelId),
+			zap.String("target_name", targetName), zap.Error(err))
+		return "", err
+	}
+
+	return commId, nil
+}
+
+// SendCommMessage sends a 'comm_msg' message to the specified comm.
+func (conn *BasicKernelConnection) SendCommMessage(commId string, data map[string]interface{}) error {
+	message, _ := conn.createKernelMessage(CommMsgMessage, ShellChannel, &CommMessageContent{CommId: commId, Data: data})
+	if err := conn.sendMessage(message); err != nil {
+		conn.logger.Error("Error while writing 'comm_msg' message.", zap.String("kernel_id", conn.kernelId),
+			zap.String("comm_id", commId), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// CloseComm sends a 'comm_close' message for the specified comm.
+func (conn *BasicKernelConnection) CloseComm(commId string, data map[string]interface{}) error {
+	message, _ := conn.createKernelMessage(CommCloseMessage, ShellChannel, &CommMessageContent{CommId: commId, Data: data})
+	if err := conn.sendMessage(message); err != nil {
+		conn.logger.Error("Error while writing 'comm_close' message.", zap.String("kernel_id", conn.kernelId),
+			zap.String("comm_id", commId), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// SetInputRequestHandler registers the handler that is invoked when the kernel sends an 'input_request'.
+// If no handler is registered, then an empty string is sent in reply to 'input_request' messages.
+func (conn *BasicKernelConnection) SetInputRequestHandler(handler InputRequestHandler) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	conn.inputRequestHandler = handler
+}
+
+// sendRequestAndAwaitReply sends a request of the specified type and decodes the content of the reply into reply.
+//
+// If the kernel does not reply within DefaultRequestTimeout, then an ErrRequestTimedOut error is returned.
+// If the kernel replies with an error, then an ErrKernelReplyError error is returned.
+func (conn *BasicKernelConnection) sendRequestAndAwaitReply(messageType MessageType, channel KernelSocketChannel,
+	content interface{}, reply replyContent) error {
+
+	message, responseChan := conn.createKernelMessage(messageType, channel, content)
+
+	err := conn.sendMessage(message)
+	if err != nil {
+		conn.logger.Error("Error while writing request.", zap.String("kernel_id", conn.kernelId),
+			zap.String("message_type", messageType.String()), zap.Error(err))
+		return err
+	}
+
+	resp, err := conn.waitForResponseWithTimeout(responseChan, DefaultRequestTimeout, messageType)
+	if err != nil {
+		return fmt.Errorf("%w: \"%s\" request %s to kernel \"%s\": %v",
+			ErrRequestTimedOut, messageType, message.GetHeader().MessageId, conn.kernelId, err)
+	}
+
+	if err = decodeMessageContent(resp, reply); err != nil {
+		conn.logger.Error("Failed to decode content of reply.", zap.String("kernel_id", conn.kernelId),
+			zap.String("message_type", resp.GetHeader().MessageType.String()), zap.Error(err))
+		return err
+	}
+
+	return reply.Err()
+}
+
+// handleStdinMessage handles a message received from the kernel via the stdin channel.
+//
+// Important: this will be called in its own goroutine.
+func (conn *BasicKernelConnection) handleStdinMessage(msg KernelMessage) {
+	if msg.GetHeader().MessageType != InputRequest {
+		conn.logger.Warn("Received unexpected message on stdin channel.", zap.String("kernel_id", conn.kernelId),
+			zap.String("message_type", msg.GetHeader().MessageType.String()))
+		return
+	}
+
+	request := &InputRequestContent{}
+	if err := decodeMessageContent(msg, request); err != nil {
+		conn.logger.Error("Failed to decode content of 'input_request' message.",
+			zap.String("kernel_id", conn.kernelId), zap.Error(err))
+	}
+
+	conn.mu.Lock()
+	handler := conn.inputRequestHandler
+	conn.mu.Unlock()
+
+	var value string
+	if handler == nil {
+		conn.logger.Warn("Received 'input_request' with no input request handler registered. Replying with empty input.",
+			zap.String("kernel_id", conn.kernelId), zap.String("prompt", request.Prompt))
+	} else {
+		var err error
+		if value, err = handler(conn, request); err != nil {
+			conn.logger.Error("Input request handler failed. Replying with empty input.",
+				zap.String("kernel_id", conn.kernelId), zap.String("prompt", request.Prompt), zap.Error(err))
+			value = ""
+		}
+	}
+
+	reply, _ := conn.createKernelMessage(InputReply, StdinChannel, &InputReplyContent{Value: value})
+	reply.(*BaseKernelMessage).ParentHeader = msg.GetHeader()
+
+	if err := conn.sendMessage(reply); err != nil {
+		conn.logger.Error("Error while writing 'input_reply' message.", zap.String("kernel_id", conn.kernelId), zap.Error(err))
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path"
 	"strings"
 	"sync"
 	"time"
@@ -27,6 +26,11 @@ const (
 	// of "execute_request" and "yield_request" messages to instruct the kernel how to simulate
 	// remote storage reads and writes.
 	RemoteStorageDefinitionMetadataKey = "remote_storage_definition"
+
+	// ResourceUsageHintMetadataKey is used to attach the most recent resource usage of a kernel to the metadata
+	// of the messages sent to that kernel, so that the Cluster Gateway, which forwards those messages, can take
+	// the kernel's actual resource usage into account.
+	ResourceUsageHintMetadataKey = "resource_usage_hint"
 )
 
 var (
@@ -91,7 +95,7 @@ type BasicKernelSessionManager struct {
 	sugaredLogger *zap.SugaredLogger
 	atom          *zap.AtomicLevel
 
-	jupyterServerAddress             string                        // Address of the Jupyter Server.
+	client                           *ServerClient                 // Used to issue requests to the Jupyter Server.
 	kernelMetricsManager             *KernelMetricsManager         // Maintains some metrics for the kernel.
 	localSessionIdToKernelId         map[string]string             // Map from "local" (provided by us) Session IDs to Kernel IDs. We provide the Session IDs, while Jupyter provides the Kernel IDs.
 	kernelIdToLocalSessionId         map[string]string             // Map from Kernel IDs to Jupyter Session IDs. We provide the Session IDs, while Jupyter provides the Kernel IDs.
@@ -101,6 +105,10 @@ type BasicKernelSessionManager struct {
 	metadata                         map[string]interface{}        // Metadata is miscellaneous metadata attached to the BasicKernelSessionManager that is mostly used for kernelMetricsManager
 	metadataMutex                    sync.Mutex                    // Synchronizes access to the metadata map.
 	adjustSessionNames               bool                          // If true, ensure all session names are 36 characters in length. For now, this should be true. Setting it to false causes problems for some reason...
+	pooledSessionIds                 map[string]string             // Map from "local" Session IDs to the IDs with which the sessions were created, for sessions handed out by the session pool.
+
+	// pool is the optional session pool. If nil, then every session is created on demand.
+	pool *sessionPool
 
 	// Invoked in a new goroutine when an error occurs.
 	onError ErrorHandler
@@ -108,9 +116,32 @@ type BasicKernelSessionManager struct {
 	mu sync.Mutex
 }
 
+// NewKernelSessionManager creates a new BasicKernelSessionManager that connects to an unauthenticated Jupyter Server
+// at the specified address over plain HTTP and WS.
 func NewKernelSessionManager(jupyterServerAddress string, adjustSessionNames bool, atom *zap.AtomicLevel, metricsConsumer MetricsConsumer) *BasicKernelSessionManager {
+	manager, err := NewKernelSessionManagerWithConfig(jupyterServerAddress, nil, adjustSessionNames, atom, metricsConsumer)
+	if err != nil {
+		// The default client configuration does not load any files, so this should never happen.
+		panic(err)
+	}
+
+	return manager
+}
+
+// NewKernelSessionManagerWithConfig creates a new BasicKernelSessionManager that connects to the Jupyter Server
+// at the specified address as specified by the given ClientConfig, which may be nil.
+//
+// NewKernelSessionManagerWithConfig returns an error if the ClientConfig specifies an invalid CA certificate file.
+func NewKernelSessionManagerWithConfig(jupyterServerAddress string, config *ClientConfig, adjustSessionNames bool,
+	atom *zap.AtomicLevel, metricsConsumer MetricsConsumer) (*BasicKernelSessionManager, error) {
+
+	client, err := NewServerClient(jupyterServerAddress, config)
+	if err != nil {
+		return nil, err
+	}
+
 	manager := &BasicKernelSessionManager{
-		jupyterServerAddress:             jupyterServerAddress,
+		client:                           client,
 		kernelMetricsManager:             &KernelMetricsManager{metricsConsumer: metricsConsumer},
 		localSessionIdToKernelId:         make(map[string]string),
 		localSessionIdToJupyterSessionId: make(map[string]string),
@@ -118,6 +149,7 @@ func NewKernelSessionManager(jupyterServerAddress string, adjustSessionNames boo
 		kernelIdToLocalSessionId:         make(map[string]string),
 		sessionMap:                       make(map[string]*SessionConnection),
 		metadata:                         make(map[string]interface{}),
+		pooledSessionIds:                 make(map[string]string),
 		adjustSessionNames:               adjustSessionNames,
 		atom:                             atom,
 	}
@@ -127,7 +159,7 @@ func NewKernelSessionManager(jupyterServerAddress string, adjustSessionNames boo
 
 	manager.sugaredLogger = manager.logger.Sugar()
 
-	return manager
+	return manager, nil
 }
 
 // RegisterOnErrorHandler registers an error handler to be called if the kernel manager encounters an error.
@@ -185,29 +217,198 @@ func (m *BasicKernelSessionManager) GetMetadata(key string) (interface{}, bool)
 	return value, ok
 }
 
-// CreateSession creates a new session.
+// EnableSessionPool enables the session pool as specified by the given SessionPoolConfig. If the
+// SessionPoolConfig does not specify any pooling, then EnableSessionPool does nothing.
+//
+// As the pooled kernels are created with the metadata of the BasicKernelSessionManager, EnableSessionPool should
+// be called after all metadata has been added. Generic kernels begin warming up immediately.
+func (m *BasicKernelSessionManager) EnableSessionPool(config *SessionPoolConfig) {
+	if !config.Enabled() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pool != nil {
+		m.logger.Warn("Session pool is already enabled.")
+		return
+	}
+
+	m.logger.Debug("Enabling session pool.",
+		zap.Int("warm_kernels_per_spec", config.WarmKernelsPerSpec),
+		zap.Strings("kernel_specs", config.KernelSpecs),
+		zap.Int("max_prewarmed_sessions", config.MaxPrewarmedSessions))
+
+	m.pool = newSessionPool(m, *config)
+}
+
+// PrewarmSession creates a kernel in the background for the specified upcoming session, such that the call to
+// CreateSession for that session can hand out the pre-warmed kernel rather than creating one.
+//
+// PrewarmSession returns ErrSessionPoolDisabled if the session pool is not enabled (or does not permit
+// pre-warming), and it returns ErrSessionPoolFull if too many pre-warmed kernels have yet to be claimed.
+func (m *BasicKernelSessionManager) PrewarmSession(sessionId string, kernelSpecName string, resourceSpec *ResourceSpec) error {
+	m.mu.Lock()
+	pool := m.pool
+	m.mu.Unlock()
+
+	if pool == nil {
+		return ErrSessionPoolDisabled
+	}
+
+	return pool.prewarm(sessionId, kernelSpecName, resourceSpec)
+}
+
+// CloseSessionPool stops all idle pooled kernels and disables the session pool.
+// Sessions that have already been handed out by the session pool are not affected.
+func (m *BasicKernelSessionManager) CloseSessionPool() {
+	m.mu.Lock()
+	pool := m.pool
+	m.pool = nil
+	m.mu.Unlock()
+
+	if pool != nil {
+		pool.close()
+	}
+}
+
+// adjustSessionName ensures that the given session ID is 36 characters in length if the
+// BasicKernelSessionManager has been configured to adjust session names.
+func (m *BasicKernelSessionManager) adjustSessionName(sessionId string) (string, error) {
+	if !m.adjustSessionNames {
+		return sessionId, nil
+	}
+
+	if len(sessionId) < 36 {
+		generatedUuid := uuid.NewString()
+		sessionId = strings.ToLower(sessionId) + "-" + generatedUuid[0:36-(len(sessionId)+1)]
+	} else if len(sessionId) > 36 {
+		return "", fmt.Errorf("%w: specified session ID \"%s\" is too long (max length is 36 characters when the KernelSessionManager has been configured to adjust names)", ErrInvalidSessionName, sessionId)
+	}
+
+	return sessionId, nil
+}
+
+// CreateSession creates a new session on behalf of the specified user. The username may be empty.
+//
+// If the session pool is enabled and holds a suitable idle kernel, then that kernel is handed out rather than
+// creating a new one, in which case the Pooled method of the returned SessionConnection returns true.
 //
 // This is thread-safe.
 func (m *BasicKernelSessionManager) CreateSession(sessionId string, sessionPath string, sessionType string,
-	kernelSpecName string, resourceSpec *ResourceSpec) (*SessionConnection, error) {
+	kernelSpecName string, resourceSpec *ResourceSpec, username string) (*SessionConnection, error) {
 
-	workloadId, loadedWorkloadIdFromMetadata := m.GetMetadata(WorkloadIdMetadataKey)
+	m.mu.Lock()
+	pool := m.pool
+	m.mu.Unlock()
 
-	if m.adjustSessionNames {
-		if len(sessionId) < 36 {
-			generatedUuid := uuid.NewString()
-			sessionId = strings.ToLower(sessionId) + "-" + generatedUuid[0:36-(len(sessionId)+1)]
-		} else if len(sessionId) > 36 {
-			return nil, fmt.Errorf("%w: specified session ID \"%s\" is too long (max length is 36 characters when the KernelSessionManager has been configured to adjust names)", ErrInvalidSessionName, sessionId)
+	if pool != nil {
+		if session := pool.acquire(sessionId, kernelSpecName, resourceSpec); session != nil {
+			return m.adoptPooledSession(pool, sessionId, sessionPath, sessionType, session, username)
 		}
 	}
 
+	return m.createSession(sessionId, sessionPath, sessionType, kernelSpecName, resourceSpec, username)
+}
+
+// adoptPooledSession hands out an idle pooled session as the specified session of the specified user.
+//
+// The Jupyter session is renamed and moved to the given path; however, it retains the ID with which it was created.
+func (m *BasicKernelSessionManager) adoptPooledSession(pool *sessionPool, sessionId string, sessionPath string,
+	sessionType string, session *pooledSession, username string) (*SessionConnection, error) {
+
+	sessionId, err := m.adjustSessionName(sessionId)
+	if err != nil {
+		go pool.stop(session)
+		return nil, err
+	}
+
+	connection := session.connection
+	kernelId := connection.model.JupyterKernel.Id
+	jupyterSessionId := connection.model.JupyterSessionId
+
+	if err = m.updateSession(jupyterSessionId, sessionId, sessionPath, sessionType); err != nil {
+		// The kernel is usable regardless, so we carry on.
+		m.logger.Warn("Failed to rename pooled Jupyter session.", zap.String(ZapSessionIDKey, sessionId),
+			zap.String("pooled_session_id", session.sessionId), zap.Error(err))
+	}
+
+	m.mu.Lock()
+	delete(m.localSessionIdToKernelId, session.sessionId)
+	delete(m.localSessionIdToJupyterSessionId, session.sessionId)
+	delete(m.sessionMap, session.sessionId)
+
+	m.localSessionIdToKernelId[sessionId] = kernelId
+	m.localSessionIdToJupyterSessionId[sessionId] = jupyterSessionId
+	m.kernelIdToLocalSessionId[kernelId] = sessionId
+	m.sessionMap[sessionId] = connection
+	m.pooledSessionIds[sessionId] = session.sessionId
+	m.mu.Unlock()
+
+	connection.model.Name = sessionId
+	connection.model.Path = sessionPath
+	connection.model.SessionType = sessionType
+	connection.pooled = true
+
+	if kernel, ok := connection.kernel.(*BasicKernelConnection); ok {
+		kernel.setUsername(username)
+	}
+
+	m.logger.Debug("Handed out pooled session.", zap.String(ZapSessionIDKey, sessionId),
+		zap.String("pooled_session_id", session.sessionId), zap.String("kernel_id", kernelId),
+		zap.Duration("time_pooled", time.Since(session.createdAt)))
+
+	return connection, nil
+}
+
+// updateSession renames and moves the specified Jupyter session.
+func (m *BasicKernelSessionManager) updateSession(jupyterSessionId string, name string, sessionPath string, sessionType string) error {
+	payload, err := json.Marshal(map[string]string{"name": name, "path": sessionPath, "type": sessionType})
+	if err != nil {
+		return err
+	}
+
+	url := m.client.HttpUrl("api", "sessions", jupyterSessionId)
+	req, err := m.client.NewRequest(http.MethodPatch, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: HTTP %d %s - %s", ErrUnexpectedFailure, resp.StatusCode, resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// createSession creates a new session and kernel on behalf of the specified user, bypassing the session pool.
+func (m *BasicKernelSessionManager) createSession(sessionId string, sessionPath string, sessionType string,
+	kernelSpecName string, resourceSpec *ResourceSpec, username string) (*SessionConnection, error) {
+
+	workloadId, loadedWorkloadIdFromMetadata := m.GetMetadata(WorkloadIdMetadataKey)
+
+	sessionId, err := m.adjustSessionName(sessionId)
+	if err != nil {
+		return nil, err
+	}
+
 	var requestBody *jupyterSessionReq
 	if loadedWorkloadIdFromMetadata {
 		requestBody = newJupyterSessionForRequest(sessionId, sessionPath, sessionType, kernelSpecName, resourceSpec, workloadId.(string))
 	} else {
 		requestBody = newJupyterSessionForRequest(sessionId, sessionPath, sessionType, kernelSpecName, resourceSpec, "N/A")
 	}
+	requestBody.Username = username
 
 	requestBodyJson, err := json.Marshal(&requestBody)
 	if err != nil {
@@ -216,9 +417,8 @@ func (m *BasicKernelSessionManager) CreateSession(sessionId string, sessionPath
 		return nil, err
 	}
 
-	address := path.Join(m.jupyterServerAddress, "/api/sessions")
-	url := fmt.Sprintf("http://%s", address)
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBodyJson))
+	url := m.client.HttpUrl("api", "sessions")
+	req, err := m.client.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBodyJson))
 	if err != nil {
 		m.logger.Error("Error encountered while creating request for CreateFile operation.", zap.String("request-args", requestBody.String()), zap.String("sessionPath", sessionPath), zap.String("url", url), zap.Error(err))
 		m.tryCallErrorHandler("", sessionId, err)
@@ -228,8 +428,7 @@ func (m *BasicKernelSessionManager) CreateSession(sessionId string, sessionPath
 	m.logger.Debug("Issuing 'CREATE-SESSION' request now.", zap.String("request-args", requestBody.String()), zap.String("request-url", url))
 
 	sentAt := time.Now()
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := m.client.Do(req)
 	if err != nil {
 		m.logger.Error("Received error when creating new session.", zap.String("request-args", requestBody.String()), zap.String("local-session-id", sessionId), zap.String("url", url), zap.Error(err))
 		m.tryCallErrorHandler("", sessionId, err)
@@ -265,7 +464,7 @@ func (m *BasicKernelSessionManager) CreateSession(sessionId string, sessionPath
 
 			st := time.Now()
 			// Connect to the Session and to the associated kernel.
-			sessionConnection, err = NewSessionConnection(jupyterSession, "", m.jupyterServerAddress, m.atom, m.kernelMetricsManager.metricsConsumer, func(err error) {
+			sessionConnection, err = NewSessionConnection(jupyterSession, username, m.client, m.atom, m.kernelMetricsManager.metricsConsumer, func(err error) {
 				m.tryCallErrorHandler(kernelId, sessionId, err)
 			})
 			if err != nil {
@@ -401,8 +600,8 @@ func (m *BasicKernelSessionManager) InterruptKernel(sessionId string) error {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/api/kernels/%s/interrupt", conn.JupyterServerAddress(), conn.KernelId())
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBodyEncoded))
+	url := m.client.HttpUrl(kernelServiceApi, conn.KernelId(), "interrupt")
+	req, err := m.client.NewRequest(http.MethodPost, url, bytes.NewBuffer(requestBodyEncoded))
 
 	if err != nil {
 		m.logger.Error("Failed to create HTTP request for kernel interruption.", zap.String("url", url), zap.Error(err))
@@ -410,8 +609,7 @@ func (m *BasicKernelSessionManager) InterruptKernel(sessionId string) error {
 		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := m.client.Do(req)
 	if err != nil {
 		m.logger.Error("Error while issuing HTTP request to interrupt kernel.", zap.String("url", url), zap.Error(err))
 		m.tryCallErrorHandler(kernelId, sessionId, err)
@@ -430,9 +628,7 @@ func (m *BasicKernelSessionManager) InterruptKernel(sessionId string) error {
 }
 
 func (m *BasicKernelSessionManager) CreateFile(target string) error {
-	pathSuffix := fmt.Sprintf("/api/contents/%s", target)
-	address := path.Join(m.jupyterServerAddress, pathSuffix)
-	url := fmt.Sprintf("http://%s", address)
+	url := m.client.HttpUrl("api", "contents", target)
 
 	createFileRequest := newCreateFileRequest(target)
 	payload, err := json.Marshal(&createFileRequest)
@@ -442,15 +638,14 @@ func (m *BasicKernelSessionManager) CreateFile(target string) error {
 		return err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(payload))
+	req, err := m.client.NewRequest(http.MethodPost, url, bytes.NewBuffer(payload))
 	if err != nil {
 		m.logger.Error("Error encountered while creating request for CreateFile operation.", zap.String("target", target), zap.String("url", url), zap.Error(err))
 		m.tryCallErrorHandler("", "", err)
 		return err
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := m.client.Do(req)
 	if err != nil {
 		m.logger.Error("Received error when creating new file.", zap.String("target", target), zap.String("url", url), zap.Error(err))
 		m.tryCallErrorHandler("", "", err)
@@ -493,21 +688,79 @@ func (m *BasicKernelSessionManager) CreateFile(target string) error {
 	return nil
 }
 
+// UploadNotebook saves the given .ipynb contents at the specified path via the contents API,
+// overwriting any existing file at that path.
+func (m *BasicKernelSessionManager) UploadNotebook(target string, content json.RawMessage) error {
+	url := m.client.HttpUrl("api", "contents", target)
+
+	payload, err := json.Marshal(newSaveNotebookRequest(content))
+	if err != nil {
+		m.logger.Error("Error encountered while marshalling payload for UploadNotebook operation.", zap.Error(err))
+		m.tryCallErrorHandler("", "", err)
+		return err
+	}
+
+	req, err := m.client.NewRequest(http.MethodPut, url, bytes.NewBuffer(payload))
+	if err != nil {
+		m.logger.Error("Error encountered while creating request for UploadNotebook operation.", zap.String("target", target), zap.String("url", url), zap.Error(err))
+		m.tryCallErrorHandler("", "", err)
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		m.logger.Error("Received error when uploading notebook.", zap.String("target", target), zap.String("url", url), zap.Error(err))
+		m.tryCallErrorHandler("", "", err)
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		{
+			m.logger.Debug("Uploaded notebook.", zap.String("target", target), zap.String("status", resp.Status))
+		}
+	case http.StatusBadRequest, http.StatusNotFound:
+		{
+			m.logger.Error("Request to upload notebook was rejected.", zap.String("status", resp.Status), zap.Any("headers", resp.Header), zap.Any("body", string(body)))
+			err = fmt.Errorf("ErrCreateFileBadRequest %w : %s", ErrCreateFileBadRequest, string(body))
+			m.tryCallErrorHandler("", "", err)
+			return err
+		}
+	default:
+		{
+			m.logger.Error("Unexpected response status code when uploading notebook.", zap.Int("status-code", resp.StatusCode), zap.String("status", resp.Status), zap.Any("body", string(body)))
+			err = fmt.Errorf("ErrCreateFileUnknownFailure %w: %s", ErrCreateFileUnknownFailure, string(body))
+			m.tryCallErrorHandler("", "", err)
+			return err
+		}
+	}
+
+	m.kernelMetricsManager.FileCreated()
+	return nil
+}
+
 func (m *BasicKernelSessionManager) StopKernel(id string) error {
-	pathSuffix := fmt.Sprintf("/api/sessions/%s", id)
-	address := path.Join(m.jupyterServerAddress, pathSuffix)
-	url := fmt.Sprintf("http://%s", address)
+	// Sessions handed out by the session pool retain the ID with which they were created.
+	m.mu.Lock()
+	if pooledSessionId, loaded := m.pooledSessionIds[id]; loaded {
+		id = pooledSessionId
+	}
+	m.mu.Unlock()
+
+	url := m.client.HttpUrl("api", "sessions", id)
 
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	req, err := m.client.NewRequest(http.MethodDelete, url, nil)
 	if err != nil {
 		m.logger.Error("Failed to create DeleteSession request while stopping kernel.", zap.String(ZapSessionIDKey, id), zap.Error(err))
 		return err
 	}
 
-	client := &http.Client{}
-
 	sentAt := time.Now()
-	resp, err := client.Do(req)
+	resp, err := m.client.Do(req)
 	if err != nil {
 		m.logger.Error("Received error when stopping session.", zap.String(ZapSessionIDKey, id), zap.String("url", url), zap.Error(err))
 		return err
@@ -562,7 +815,7 @@ func (m *BasicKernelSessionManager) GetMetrics() KernelManagerMetrics {
 // @returns a WebSocket-backed connection to the kernel.
 func (m *BasicKernelSessionManager) ConnectTo(kernelId string, sessionId string, username string) (KernelConnection, error) {
 	m.logger.Debug("Connecting to kernel now.", zap.String("kernel_id", kernelId), zap.String("session_id", sessionId))
-	conn, err := NewKernelConnection(kernelId, sessionId, username, m.jupyterServerAddress, m.atom, m.kernelMetricsManager.metricsConsumer, func(err error) { m.tryCallErrorHandler(kernelId, sessionId, err) })
+	conn, err := NewKernelConnection(kernelId, sessionId, username, m.client, m.atom, m.kernelMetricsManager.metricsConsumer, func(err error) { m.tryCallErrorHandler(kernelId, sessionId, err) })
 	if err != nil {
 		m.logger.Error("Failed to connect to kernel.",
 			zap.String("kernel_id", kernelId), zap.String("session_id", sessionId))
@@ -40,6 +40,9 @@ type ResourceSpec struct {
 	Mem  float64 `json:"memory"` // In MB
 	Gpu  int     `json:"gpu"`
 	Vram float64 `json:"vram"` // In GB
+
+	// GpuTypes are the acceptable GPU models, in order of preference. Empty GpuTypes accept any GPU.
+	GpuTypes []string `json:"gpu_types,omitempty"`
 }
 
 type BaseKernelMessage struct {
@@ -0,0 +1,187 @@
+package jupyter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// The following types define the content of the request and reply messages of the Jupyter messaging protocol.
+//
+// See the [Official Jupyter Kernel Messaging Documentation] for additional information concerning these messages.
+//
+// [Official Jupyter Kernel Messaging Documentation]: https://jupyter-client.readthedocs.io/en/latest/messaging.html
+
+const (
+	ReplyStatusOk    = "ok"
+	ReplyStatusError = "error"
+	ReplyStatusAbort = "aborted"
+
+	IsCompleteStatusComplete   = "complete"
+	IsCompleteStatusIncomplete = "incomplete"
+	IsCompleteStatusInvalid    = "invalid"
+	IsCompleteStatusUnknown    = "unknown"
+)
+
+var (
+	ErrKernelReplyError = errors.New("kernel replied with an error")
+)
+
+// ReplyStatus is embedded in the content of every reply message.
+type ReplyStatus struct {
+	Status    string   `json:"status"`
+	EName     string   `json:"ename,omitempty"`
+	EValue    string   `json:"evalue,omitempty"`
+	Traceback []string `json:"traceback,omitempty"`
+}
+
+// Err returns an ErrKernelReplyError error if the reply indicates that the request failed, and nil otherwise.
+func (s *ReplyStatus) Err() error {
+	if s.Status == ReplyStatusError || s.Status == ReplyStatusAbort {
+		return fmt.Errorf("%w: status=%s, %s: %s", ErrKernelReplyError, s.Status, s.EName, s.EValue)
+	}
+
+	return nil
+}
+
+// replyContent is implemented by the content of every reply message.
+type replyContent interface {
+	Err() error
+}
+
+// CompleteRequestContent is the content of a "complete_request" message.
+type CompleteRequestContent struct {
+	Code      string `json:"code"`       // Code is the code context in which completion is requested.
+	CursorPos int    `json:"cursor_pos"` // CursorPos is the cursor position within Code, in unicode characters.
+}
+
+// CompleteReplyContent is the content of a "complete_reply" message.
+type CompleteReplyContent struct {
+	ReplyStatus
+	Matches     []string               `json:"matches"`
+	CursorStart int                    `json:"cursor_start"`
+	CursorEnd   int                    `json:"cursor_end"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// InspectRequestContent is the content of an "inspect_request" message.
+type InspectRequestContent struct {
+	Code        string `json:"code"`
+	CursorPos   int    `json:"cursor_pos"`
+	DetailLevel int    `json:"detail_level"` // DetailLevel is either 0 or 1.
+}
+
+// InspectReplyContent is the content of an "inspect_reply" message.
+type InspectReplyContent struct {
+	ReplyStatus
+	Found    bool                   `json:"found"`
+	Data     map[string]interface{} `json:"data"` // Data is a mime-bundle, as in "display_data" messages.
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// HistoryRequestContent is the content of a "history_request" message.
+type HistoryRequestContent struct {
+	Output         bool   `json:"output"`           // Output indicates whether to return the output history as well.
+	Raw            bool   `json:"raw"`              // Raw indicates whether to return the raw input history.
+	HistAccessType string `json:"hist_access_type"` // HistAccessType is one of "range", "tail", or "search".
+	Session        int    `json:"session,omitempty"`
+	Start          int    `json:"start,omitempty"`
+	Stop           int    `json:"stop,omitempty"`
+	N              int    `json:"n,omitempty"`
+	Pattern        string `json:"pattern,omitempty"`
+	Unique         bool   `json:"unique,omitempty"`
+}
+
+// HistoryReplyContent is the content of a "history_reply" message.
+//
+// Each entry of History is a (session, line_number, input) tuple, or a (session, line_number, (input, output))
+// tuple if output was requested.
+type HistoryReplyContent struct {
+	ReplyStatus
+	History [][]interface{} `json:"history"`
+}
+
+// IsCompleteRequestContent is the content of an "is_complete_request" message.
+type IsCompleteRequestContent struct {
+	Code string `json:"code"`
+}
+
+// IsCompleteReplyContent is the content of an "is_complete_reply" message.
+//
+// Unlike other replies, the Status of an IsCompleteReplyContent is one of the IsCompleteStatus constants.
+type IsCompleteReplyContent struct {
+	ReplyStatus
+	Indent string `json:"indent,omitempty"` // Indent is only present if the Status is IsCompleteStatusIncomplete.
+}
+
+// ShutdownRequestContent is the content of a "shutdown_request" message.
+type ShutdownRequestContent struct {
+	Restart bool `json:"restart"`
+}
+
+// ShutdownReplyContent is the content of a "shutdown_reply" message.
+type ShutdownReplyContent struct {
+	ReplyStatus
+	Restart bool `json:"restart"`
+}
+
+// CommOpenContent is the content of a "comm_open" message.
+type CommOpenContent struct {
+	CommId     string                 `json:"comm_id"`
+	TargetName string                 `json:"target_name"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// CommMessageContent is the content of "comm_msg" and "comm_close" messages.
+type CommMessageContent struct {
+	CommId string                 `json:"comm_id"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// CommInfoRequestContent is the content of a "comm_info_request" message.
+type CommInfoRequestContent struct {
+	TargetName string `json:"target_name,omitempty"` // TargetName optionally restricts the returned comms to those with the given target.
+}
+
+// CommInfo describes an open comm.
+type CommInfo struct {
+	TargetName string `json:"target_name"`
+}
+
+// CommInfoReplyContent is the content of a "comm_info_reply" message.
+type CommInfoReplyContent struct {
+	ReplyStatus
+	Comms map[string]*CommInfo `json:"comms"` // Comms is a map from comm ID to CommInfo.
+}
+
+// InputRequestContent is the content of an "input_request" message, which the kernel sends on the stdin channel.
+type InputRequestContent struct {
+	Prompt   string `json:"prompt"`
+	Password bool   `json:"password"` // Password indicates that the input should not be echoed.
+}
+
+// InputReplyContent is the content of an "input_reply" message.
+type InputReplyContent struct {
+	Value string `json:"value"`
+}
+
+// decodeMessageContent decodes the content of the given KernelMessage into target.
+//
+// The content of received messages is generally a map[string]interface{}, so decodeMessageContent
+// round-trips the content through JSON.
+func decodeMessageContent(msg KernelMessage, target interface{}) error {
+	var encoded []byte
+	switch content := msg.GetContent().(type) {
+	case []byte:
+		encoded = content
+	case json.RawMessage:
+		encoded = content
+	default:
+		var err error
+		if encoded, err = json.Marshal(content); err != nil {
+			return err
+		}
+	}
+
+	return json.Unmarshal(encoded, target)
+}
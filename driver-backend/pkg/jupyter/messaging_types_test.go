@@ -0,0 +1,53 @@
+package jupyter_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+)
+
+var _ = Describe("Messaging Types Tests", func() {
+	It("Will report an error for error and aborted replies", func() {
+		var reply jupyter.CompleteReplyContent
+		err := json.Unmarshal([]byte(`{"status": "error", "ename": "NameError", "evalue": "name 'x' is not defined"}`), &reply)
+		Expect(err).To(BeNil())
+
+		Expect(errors.Is(reply.Err(), jupyter.ErrKernelReplyError)).To(BeTrue())
+
+		reply.Status = jupyter.ReplyStatusAbort
+		Expect(errors.Is(reply.Err(), jupyter.ErrKernelReplyError)).To(BeTrue())
+	})
+
+	It("Will not report an error for successful replies", func() {
+		var reply jupyter.CompleteReplyContent
+		err := json.Unmarshal([]byte(`{"status": "ok", "matches": ["print", "property"], "cursor_start": 0, "cursor_end": 2, "metadata": {}}`), &reply)
+		Expect(err).To(BeNil())
+
+		Expect(reply.Err()).To(BeNil())
+		Expect(reply.Matches).To(Equal([]string{"print", "property"}))
+		Expect(reply.CursorEnd).To(Equal(2))
+	})
+
+	It("Will not treat the status of an is_complete_reply as an error", func() {
+		var reply jupyter.IsCompleteReplyContent
+		err := json.Unmarshal([]byte(`{"status": "incomplete", "indent": "    "}`), &reply)
+		Expect(err).To(BeNil())
+
+		Expect(reply.Err()).To(BeNil())
+		Expect(reply.Status).To(Equal(jupyter.IsCompleteStatusIncomplete))
+		Expect(reply.Indent).To(Equal("    "))
+	})
+
+	It("Will decode the comms of a comm_info_reply", func() {
+		var reply jupyter.CommInfoReplyContent
+		err := json.Unmarshal([]byte(`{"status": "ok", "comms": {"abc": {"target_name": "jupyter.widget"}}}`), &reply)
+		Expect(err).To(BeNil())
+
+		Expect(reply.Comms).To(HaveKey("abc"))
+		Expect(reply.Comms["abc"].TargetName).To(Equal("jupyter.widget"))
+	})
+})
@@ -17,4 +17,8 @@ type MetricsConsumer interface {
 	// AddJupyterRequestExecuteTime records the time taken to process an "execute_request" for the total, aggregate,
 	// cumulative time spent processing "execute_request" messages.
 	AddJupyterRequestExecuteTime(latencyMilliseconds int64, kernelId string, workloadId string)
+
+	// RecordKernelReconnectionAttempt records that a Jupyter client attempted to reconnect to the specified kernel,
+	// along with whether the reconnection attempt succeeded.
+	RecordKernelReconnectionAttempt(kernelId string, workloadId string, succeeded bool)
 }
@@ -0,0 +1,130 @@
+package jupyter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	// PendingRequestFailedErrorName is the "ename" of the synthetic error replies that are delivered in place of
+	// the replies to requests that were abandoned because the connection to the kernel was lost.
+	PendingRequestFailedErrorName = "PendingRequestFailed"
+)
+
+var (
+	ErrPendingRequestFailed = errors.New("request was abandoned because the connection to the kernel was lost")
+	ErrReconnectionFailed   = errors.New("failed to reconnect to kernel")
+	ErrCircuitOpen          = errors.New("not reconnecting to kernel as too many previous reconnection attempts have failed")
+)
+
+// ReconnectionPolicy configures how a BasicKernelConnection reconnects to its kernel after losing its connection.
+//
+// Reconnection attempts are separated by exponentially-increasing backoff intervals with jitter. Reconnection is
+// abandoned after MaxAttempts attempts or once MaxElapsedTime has elapsed, whichever occurs first.
+//
+// If CircuitBreakerThreshold consecutive reconnections fail, then the circuit "opens", and no reconnection will be
+// attempted until CircuitBreakerCooldown has elapsed. After the cooldown, one reconnection is attempted; if it
+// fails, then the circuit opens again.
+type ReconnectionPolicy struct {
+	InitialBackoff time.Duration // InitialBackoff is the backoff interval after the first failed attempt.
+	MaxBackoff     time.Duration // MaxBackoff bounds the backoff interval from above.
+	Multiplier     float64       // Multiplier is the factor by which the backoff interval grows after each failed attempt.
+	Jitter         float64       // Jitter is the fraction, in [0, 1], by which each backoff interval is randomly perturbed.
+	MaxAttempts    int           // MaxAttempts is the maximum number of attempts per reconnection. Zero means unlimited.
+	MaxElapsedTime time.Duration // MaxElapsedTime bounds the duration of each reconnection. Zero means unlimited.
+
+	CircuitBreakerThreshold int           // CircuitBreakerThreshold is the number of consecutive failed reconnections that opens the circuit. Zero disables circuit breaking.
+	CircuitBreakerCooldown  time.Duration // CircuitBreakerCooldown is how long the circuit stays open.
+
+	// ReplayPendingRequests indicates whether shell and control requests that have not received a reply are re-sent
+	// after reconnecting. If false, then such requests are failed with an ErrPendingRequestFailed error instead.
+	ReplayPendingRequests bool
+}
+
+// DefaultReconnectionPolicy returns the ReconnectionPolicy used when none is configured.
+func DefaultReconnectionPolicy() *ReconnectionPolicy {
+	return &ReconnectionPolicy{
+		InitialBackoff:          time.Second,
+		MaxBackoff:              time.Second * 30,
+		Multiplier:              2,
+		Jitter:                  0.2,
+		MaxAttempts:             8,
+		MaxElapsedTime:          time.Minute * 2,
+		CircuitBreakerThreshold: 3,
+		CircuitBreakerCooldown:  time.Minute * 5,
+		ReplayPendingRequests:   true,
+	}
+}
+
+// Backoff returns the interval to wait after the specified (zero-indexed) failed attempt.
+func (p *ReconnectionPolicy) Backoff(attempt int, rng *rand.Rand) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt))
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+
+	if p.Jitter > 0 && rng != nil {
+		jitter := math.Min(p.Jitter, 1)
+		backoff *= 1 + jitter*(2*rng.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// pendingRequest is a shell or control request that has not yet received a reply.
+type pendingRequest struct {
+	message         KernelMessage
+	responseChannel chan KernelMessage
+}
+
+// newPendingRequestFailedReply creates the synthetic error reply that is delivered in place of the reply to a
+// request that was abandoned because the connection to the kernel was lost.
+func newPendingRequestFailedReply(request KernelMessage, err error) KernelMessage {
+	return &BaseKernelMessage{
+		Channel: request.GetChannel(),
+		Header: &KernelMessageHeader{
+			Date:        time.Now().UTC().Format(JavascriptISOString),
+			MessageId:   fmt.Sprintf("%s-failed", request.GetHeader().MessageId),
+			MessageType: MessageType(request.GetHeader().MessageType.getBaseMessageType() + "reply"),
+			Session:     request.GetHeader().Session,
+			Username:    request.GetHeader().Username,
+			Version:     VERSION,
+		},
+		ParentHeader: request.GetHeader(),
+		Metadata:     make(map[string]interface{}),
+		Content: map[string]interface{}{
+			"status": ReplyStatusError,
+			"ename":  PendingRequestFailedErrorName,
+			"evalue": err.Error(),
+		},
+		Buffers: make([][]byte, 0),
+	}
+}
+
+// PendingRequestFailure returns an ErrPendingRequestFailed error if the given reply is the synthetic error reply
+// to a request that was abandoned because the connection to the kernel was lost. Otherwise, it returns nil.
+func PendingRequestFailure(reply KernelMessage) error {
+	if reply == nil {
+		return nil
+	}
+
+	content, ok := reply.GetContent().(map[string]interface{})
+	if !ok || content["ename"] != PendingRequestFailedErrorName {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %v", ErrPendingRequestFailed, content["evalue"])
+}
+
+// ConnectionStatusChangedHandler is called whenever the status of a KernelConnection changes.
+//
+// Important: a ConnectionStatusChangedHandler is called synchronously and must therefore not block.
+type ConnectionStatusChangedHandler func(conn KernelConnection, previous KernelConnectionStatus, current KernelConnectionStatus)
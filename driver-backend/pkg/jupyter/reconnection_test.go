@@ -0,0 +1,103 @@
+package jupyter_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+var _ = Describe("Kernel Reconnection Tests", func() {
+	Context("ReconnectionPolicy", func() {
+		It("Will grow the backoff exponentially up to the maximum", func() {
+			policy := &jupyter.ReconnectionPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second * 5, Multiplier: 2}
+
+			Expect(policy.Backoff(0, nil)).To(Equal(time.Second))
+			Expect(policy.Backoff(1, nil)).To(Equal(time.Second * 2))
+			Expect(policy.Backoff(2, nil)).To(Equal(time.Second * 4))
+			Expect(policy.Backoff(3, nil)).To(Equal(time.Second * 5))
+			Expect(policy.Backoff(10, nil)).To(Equal(time.Second * 5))
+		})
+
+		It("Will keep jittered backoffs within bounds", func() {
+			policy := &jupyter.ReconnectionPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second * 30, Multiplier: 2, Jitter: 0.25}
+			rng := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 100; i++ {
+				backoff := policy.Backoff(1, rng)
+				Expect(backoff).To(BeNumerically(">=", time.Millisecond*1500))
+				Expect(backoff).To(BeNumerically("<=", time.Millisecond*2500))
+			}
+		})
+	})
+
+	It("Will fail pending requests once the connection to the kernel is lost", func() {
+		listeners := make(map[string]net.Listener)
+		for _, channel := range []string{"shell", "control", "stdin", "iopub"} {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer listener.Close()
+			listeners[channel] = listener
+		}
+
+		port := func(channel string) int {
+			return listeners[channel].Addr().(*net.TCPAddr).Port
+		}
+
+		// The fake kernel answers the initial "kernel_info_request" and then drops the connection
+		// upon receiving an "execute_request", without replying.
+		go func() {
+			defer GinkgoRecover()
+
+			shell := acceptFakeKernelSocket(listeners["shell"], "ROUTER")
+			acceptFakeKernelSocket(listeners["control"], "ROUTER")
+			acceptFakeKernelSocket(listeners["stdin"], "ROUTER")
+			acceptFakeKernelSocket(listeners["iopub"], "PUB")
+
+			for {
+				request := shell.recv()
+				if request == nil {
+					return
+				}
+
+				if bytes.Contains(request[2], []byte("kernel_info_request")) {
+					shell.send(fakeKernelReply(nil, request[2], "kernel_info_reply", map[string]interface{}{"status": "ok"}))
+					continue
+				}
+
+				_ = shell.conn.Close()
+				return
+			}
+		}()
+
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		conn, err := jupyter.NewZmqKernelConnection("fake-kernel", &jupyter.ConnectionInfo{
+			IP:              "127.0.0.1",
+			Transport:       "tcp",
+			ShellPort:       port("shell"),
+			ControlPort:     port("control"),
+			IOPubPort:       port("iopub"),
+			StdinPort:       port("stdin"),
+			SignatureScheme: jupyter.DefaultSignatureScheme,
+			Key:             fakeKernelKey,
+		}, &atom, nil, nil)
+		Expect(err).To(BeNil())
+		defer conn.Close()
+
+		statuses := make(chan jupyter.KernelConnectionStatus, 4)
+		conn.SetOnConnectionStatusChanged(func(_ jupyter.KernelConnection, _ jupyter.KernelConnectionStatus, current jupyter.KernelConnectionStatus) {
+			statuses <- current
+		})
+
+		_, err = conn.RequestExecute(jupyter.NewRequestExecuteArgsBuilder().Code("1 + 1").AwaitResponse(true).Build())
+		Expect(errors.Is(err, jupyter.ErrPendingRequestFailed)).To(BeTrue())
+		Eventually(statuses).Should(Receive(Equal(jupyter.KernelDead)))
+	})
+})
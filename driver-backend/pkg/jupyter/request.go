@@ -1,5 +1,7 @@
 package jupyter
 
+import "encoding/json"
+
 type createFileRequest struct {
 	Path string `json:"path"`
 }
@@ -9,3 +11,18 @@ func newCreateFileRequest(path string) *createFileRequest {
 		Path: path,
 	}
 }
+
+// saveNotebookRequest is the body of a request to save a notebook via the Jupyter Server contents API.
+type saveNotebookRequest struct {
+	Type    string          `json:"type"`
+	Format  string          `json:"format"`
+	Content json.RawMessage `json:"content"`
+}
+
+func newSaveNotebookRequest(content json.RawMessage) *saveNotebookRequest {
+	return &saveNotebookRequest{
+		Type:    "notebook",
+		Format:  "json",
+		Content: content,
+	}
+}
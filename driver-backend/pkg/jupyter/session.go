@@ -27,6 +27,7 @@ type jupyterSessionReq struct {
 	JupyterNotebook  map[string]interface{} `json:"notebook"`
 	ResourceSpec     *ResourceSpec          `json:"resource_spec"`
 	WorkloadId       string                 `json:"workload_id"`
+	Username         string                 `json:"username,omitempty"`
 
 	SessionConnection *SessionConnection `json:"-"`
 }
@@ -132,11 +133,14 @@ type SessionConnection struct {
 	model  *jupyterSession
 	kernel KernelConnection
 
-	jupyterServerAddress string
+	client *ServerClient // client is used to issue requests to the Jupyter Server.
 
 	// createdAt is the time at which the SessionConnection was created.
 	createdAt time.Time
 
+	// pooled indicates whether the session was handed out by a session pool rather than created on demand.
+	pooled bool
+
 	// metadata is a map containing basic metadata used for labeling kernelMetricsManager.
 	metadata      map[string]interface{}
 	metadataMutex sync.Mutex
@@ -154,17 +158,17 @@ type SessionConnection struct {
 // NewSessionConnection creates a new SessionConnection.
 //
 // We do not return until we've successfully connected to the kernel.
-func NewSessionConnection(model *jupyterSession, username string, jupyterServerAddress string, atom *zap.AtomicLevel,
+func NewSessionConnection(model *jupyterSession, username string, client *ServerClient, atom *zap.AtomicLevel,
 	metricsConsumer MetricsConsumer, onError func(err error)) (*SessionConnection, error) {
 
 	conn := &SessionConnection{
-		model:                model,
-		jupyterServerAddress: jupyterServerAddress,
-		atom:                 atom,
-		createdAt:            time.Now(),
-		metadata:             make(map[string]interface{}),
-		metricsConsumer:      metricsConsumer,
-		onError:              onError,
+		model:           model,
+		client:          client,
+		atom:            atom,
+		createdAt:       time.Now(),
+		metadata:        make(map[string]interface{}),
+		metricsConsumer: metricsConsumer,
+		onError:         onError,
 	}
 
 	core := zapcore.NewCore(zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()), os.Stdout, atom)
@@ -228,7 +232,7 @@ func (conn *SessionConnection) connectToKernel(username string) error {
 	}
 
 	kernel, err := NewKernelConnection(conn.model.JupyterKernel.Id, conn.model.JupyterSessionId, username,
-		conn.jupyterServerAddress, conn.atom, conn.metricsConsumer, conn.onError)
+		conn.client, conn.atom, conn.metricsConsumer, conn.onError)
 
 	if err != nil {
 		return err
@@ -270,3 +274,8 @@ func (conn *SessionConnection) UnregisterIoPubHandler(id string) error {
 func (conn *SessionConnection) Kernel() KernelConnection {
 	return conn.kernel
 }
+
+// Pooled returns true if the session was handed out by a session pool rather than created on demand.
+func (conn *SessionConnection) Pooled() bool {
+	return conn.pooled
+}
@@ -0,0 +1,311 @@
+package jupyter
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// pooledSessionIdPrefix is the prefix of the IDs of the sessions created by a sessionPool.
+	pooledSessionIdPrefix = "pool-"
+)
+
+var (
+	ErrSessionPoolDisabled = errors.New("the session pool is not enabled")
+	ErrSessionPoolFull     = errors.New("the session pool cannot hold any more pre-warmed sessions")
+)
+
+// SessionPoolConfig configures the optional session pool of a BasicKernelSessionManager.
+//
+// The session pool creates kernels ahead of time so that sessions can be handed out without waiting for a kernel
+// to be created. Kernels are pooled in two (complementary) ways:
+//
+// - WarmKernelsPerSpec generic kernels are kept warm for each of the KernelSpecs. A generic kernel is handed out
+// to a session if the WarmResourceSpec covers the ResourceSpec of the session.
+//
+// - Kernels can be created ahead of time for specific upcoming sessions via PrewarmSession, such as when a
+// workload driver sees a "session-ready" event approaching in its event queue.
+type SessionPoolConfig struct {
+	// WarmKernelsPerSpec is the number of idle generic kernels kept for each of the KernelSpecs.
+	WarmKernelsPerSpec int
+
+	// KernelSpecs are the names of the kernel specs for which generic kernels are kept warm.
+	KernelSpecs []string
+
+	// WarmResourceSpec is the ResourceSpec with which generic kernels are created.
+	WarmResourceSpec *ResourceSpec
+
+	// MaxPrewarmedSessions bounds the number of kernels created via PrewarmSession that have not yet been claimed.
+	// If MaxPrewarmedSessions is zero, then PrewarmSession is disabled.
+	MaxPrewarmedSessions int
+}
+
+// Enabled returns true if the SessionPoolConfig specifies any pooling at all.
+func (c *SessionPoolConfig) Enabled() bool {
+	return c != nil && ((c.WarmKernelsPerSpec > 0 && len(c.KernelSpecs) > 0) || c.MaxPrewarmedSessions > 0)
+}
+
+// pooledSession is an idle session that was created by a sessionPool.
+type pooledSession struct {
+	sessionId      string // sessionId is the ID with which the session was created.
+	kernelSpecName string
+	resourceSpec   *ResourceSpec
+	connection     *SessionConnection
+	createdAt      time.Time
+}
+
+// covers returns true if every resource of the ResourceSpec is at least that of the given ResourceSpec, and if the
+// preferred GPU type of the ResourceSpec is acceptable to the given ResourceSpec.
+func covers(spec *ResourceSpec, required *ResourceSpec) bool {
+	if required == nil {
+		return true
+	}
+
+	if spec == nil {
+		return false
+	}
+
+	if len(required.GpuTypes) > 0 && (len(spec.GpuTypes) == 0 || !slices.Contains(required.GpuTypes, spec.GpuTypes[0])) {
+		return false
+	}
+
+	return spec.Cpu >= required.Cpu && spec.Mem >= required.Mem && spec.Gpu >= required.Gpu && spec.Vram >= required.Vram
+}
+
+// sessionPool maintains the idle kernels of a BasicKernelSessionManager.
+//
+// Kernels are created using the BasicKernelSessionManager's createSession method and are then adopted by the
+// sessions that they are handed out to. Idle kernels are stopped when the sessionPool is closed.
+type sessionPool struct {
+	manager *BasicKernelSessionManager
+	config  SessionPoolConfig
+	logger  *zap.Logger
+
+	warm           map[string][]*pooledSession // warm is a map from kernel spec name to the idle generic sessions.
+	numWarmPending map[string]int              // numWarmPending is a map from kernel spec name to the number of generic sessions being created.
+	prewarmed      map[string]*pooledSession   // prewarmed is a map from upcoming session ID to the session created for it.
+	prewarming     map[string]chan interface{} // prewarming is a map from upcoming session ID to a channel that is closed once the session is created.
+	closed         bool
+
+	mu sync.Mutex
+}
+
+func newSessionPool(manager *BasicKernelSessionManager, config SessionPoolConfig) *sessionPool {
+	pool := &sessionPool{
+		manager:        manager,
+		config:         config,
+		logger:         manager.logger,
+		warm:           make(map[string][]*pooledSession),
+		numWarmPending: make(map[string]int),
+		prewarmed:      make(map[string]*pooledSession),
+		prewarming:     make(map[string]chan interface{}),
+	}
+
+	for _, kernelSpecName := range config.KernelSpecs {
+		pool.refill(kernelSpecName)
+	}
+
+	return pool
+}
+
+// newPooledSessionId returns a new session ID of the same length as the session IDs used by the
+// BasicKernelSessionManager when it adjusts session names.
+func newPooledSessionId() string {
+	return (pooledSessionIdPrefix + uuid.NewString())[0:36]
+}
+
+// create creates a new idle session with the given kernel spec and ResourceSpec.
+func (p *sessionPool) create(kernelSpecName string, resourceSpec *ResourceSpec) (*pooledSession, error) {
+	sessionId := newPooledSessionId()
+
+	st := time.Now()
+	connection, err := p.manager.createSession(sessionId, fmt.Sprintf("%s.ipynb", sessionId), "notebook", kernelSpecName, resourceSpec, "")
+	if err != nil {
+		p.logger.Warn("Failed to create pooled session.", zap.String("kernel_spec", kernelSpecName), zap.Error(err))
+		return nil, err
+	}
+
+	p.logger.Debug("Created pooled session.", zap.String(ZapSessionIDKey, sessionId),
+		zap.String("kernel_spec", kernelSpecName), zap.Duration("time_elapsed", time.Since(st)))
+
+	return &pooledSession{
+		sessionId:      sessionId,
+		kernelSpecName: kernelSpecName,
+		resourceSpec:   resourceSpec,
+		connection:     connection,
+		createdAt:      time.Now(),
+	}, nil
+}
+
+// refill creates as many generic sessions of the specified kernel spec as are needed to bring the number of idle
+// generic sessions of that kernel spec back up to WarmKernelsPerSpec. The sessions are created in the background.
+func (p *sessionPool) refill(kernelSpecName string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	deficit := p.config.WarmKernelsPerSpec - len(p.warm[kernelSpecName]) - p.numWarmPending[kernelSpecName]
+	for i := 0; i < deficit; i++ {
+		p.numWarmPending[kernelSpecName] += 1
+
+		go func() {
+			session, err := p.create(kernelSpecName, p.config.WarmResourceSpec)
+
+			p.mu.Lock()
+			p.numWarmPending[kernelSpecName] -= 1
+			if err == nil && !p.closed {
+				p.warm[kernelSpecName] = append(p.warm[kernelSpecName], session)
+				session = nil
+			}
+			p.mu.Unlock()
+
+			// If the pool was closed while the session was being created, then the session is not needed.
+			if session != nil {
+				p.stop(session)
+			}
+		}()
+	}
+}
+
+// prewarm creates a session in the background for the specified upcoming session.
+//
+// prewarm returns nil without doing anything if a session has already been (or is already being) created for the
+// specified upcoming session.
+func (p *sessionPool) prewarm(sessionId string, kernelSpecName string, resourceSpec *ResourceSpec) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || p.config.MaxPrewarmedSessions <= 0 {
+		return ErrSessionPoolDisabled
+	}
+
+	if _, loaded := p.prewarmed[sessionId]; loaded {
+		return nil
+	}
+
+	if _, loaded := p.prewarming[sessionId]; loaded {
+		return nil
+	}
+
+	if len(p.prewarmed)+len(p.prewarming) >= p.config.MaxPrewarmedSessions {
+		return fmt.Errorf("%w: %d session(s) already pre-warmed", ErrSessionPoolFull, p.config.MaxPrewarmedSessions)
+	}
+
+	doneChan := make(chan interface{})
+	p.prewarming[sessionId] = doneChan
+
+	go func() {
+		defer close(doneChan)
+
+		session, err := p.create(kernelSpecName, resourceSpec)
+
+		p.mu.Lock()
+		delete(p.prewarming, sessionId)
+		if err == nil && !p.closed {
+			p.prewarmed[sessionId] = session
+			session = nil
+		}
+		p.mu.Unlock()
+
+		if session != nil {
+			p.stop(session)
+		}
+	}()
+
+	return nil
+}
+
+// acquire removes and returns an idle session suitable for the specified session, or nil if there is none.
+//
+// If a session is presently being pre-warmed for the specified session, then acquire waits for it to be created,
+// as that will generally be faster than creating another session from scratch.
+func (p *sessionPool) acquire(sessionId string, kernelSpecName string, resourceSpec *ResourceSpec) *pooledSession {
+	p.mu.Lock()
+	doneChan, prewarming := p.prewarming[sessionId]
+	p.mu.Unlock()
+
+	if prewarming {
+		<-doneChan
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	if session, loaded := p.prewarmed[sessionId]; loaded {
+		delete(p.prewarmed, sessionId)
+
+		if session.kernelSpecName == kernelSpecName && covers(session.resourceSpec, resourceSpec) {
+			return session
+		}
+
+		// The session no longer fits the upcoming session, so it will never be used.
+		p.logger.Warn("Discarding pre-warmed session that does not fit the session it was created for.",
+			zap.String(ZapSessionIDKey, sessionId), zap.String("pooled_session_id", session.sessionId))
+		go p.stop(session)
+	}
+
+	sessions := p.warm[kernelSpecName]
+	for i, session := range sessions {
+		if !covers(session.resourceSpec, resourceSpec) {
+			continue
+		}
+
+		p.warm[kernelSpecName] = append(sessions[:i:i], sessions[i+1:]...)
+		go p.refill(kernelSpecName)
+		return session
+	}
+
+	return nil
+}
+
+// stop stops the kernel of an idle session.
+func (p *sessionPool) stop(session *pooledSession) {
+	if err := p.manager.StopKernel(session.sessionId); err != nil {
+		p.logger.Warn("Failed to stop pooled session.", zap.String(ZapSessionIDKey, session.sessionId), zap.Error(err))
+	}
+
+	if kernel := session.connection.Kernel(); kernel != nil {
+		_ = kernel.Close()
+	}
+}
+
+// close stops all idle sessions. Sessions that are still being created are stopped once they have been created.
+func (p *sessionPool) close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+
+	idle := make([]*pooledSession, 0, len(p.prewarmed))
+	for _, session := range p.prewarmed {
+		idle = append(idle, session)
+	}
+	for _, sessions := range p.warm {
+		idle = append(idle, sessions...)
+	}
+
+	p.prewarmed = make(map[string]*pooledSession)
+	p.warm = make(map[string][]*pooledSession)
+	p.mu.Unlock()
+
+	p.logger.Debug("Closing session pool.", zap.Int("num_idle_sessions", len(idle)))
+
+	for _, session := range idle {
+		p.stop(session)
+	}
+}
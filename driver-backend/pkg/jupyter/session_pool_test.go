@@ -0,0 +1,212 @@
+package jupyter_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+// fakeJupyterServer implements just enough of the Jupyter Server REST and websocket APIs to create sessions
+// whose kernels answer every request with an "ok" reply.
+type fakeJupyterServer struct {
+	*httptest.Server
+
+	created []map[string]interface{} // created are the bodies of the requests to create sessions.
+	patched []string                 // patched are the IDs of the sessions that were renamed.
+	deleted []string                 // deleted are the IDs of the sessions that were deleted.
+	mu      sync.Mutex
+}
+
+func newFakeJupyterServer() *fakeJupyterServer {
+	server := &fakeJupyterServer{}
+	upgrader := websocket.Upgrader{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/sessions":
+			var body map[string]interface{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			server.created = append(server.created, body)
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":     body["id"],
+				"name":   body["name"],
+				"path":   body["path"],
+				"type":   body["type"],
+				"kernel": map[string]interface{}{"id": uuid.NewString(), "name": "distributed"},
+			})
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/api/sessions/"):
+			server.patched = append(server.patched, strings.TrimPrefix(r.URL.Path, "/api/sessions/"))
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/sessions/"):
+			server.deleted = append(server.deleted, strings.TrimPrefix(r.URL.Path, "/api/sessions/"))
+			w.WriteHeader(http.StatusNoContent)
+		case strings.HasSuffix(r.URL.Path, "/channels"):
+			conn, err := upgrader.Upgrade(w, r, nil)
+			Expect(err).To(BeNil())
+			go serveFakeKernelWebsocket(conn)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+// serveFakeKernelWebsocket replies to each request received via the websocket with an "ok" reply.
+func serveFakeKernelWebsocket(conn *websocket.Conn) {
+	defer conn.Close()
+
+	for {
+		var request map[string]interface{}
+		if err := conn.ReadJSON(&request); err != nil {
+			return
+		}
+
+		header := request["header"].(map[string]interface{})
+		messageType := header["msg_type"].(string)
+		if !strings.HasSuffix(messageType, "_request") {
+			continue
+		}
+
+		reply := map[string]interface{}{
+			"channel":       request["channel"],
+			"header":        map[string]interface{}{"msg_id": uuid.NewString(), "msg_type": strings.TrimSuffix(messageType, "request") + "reply", "session": header["session"]},
+			"parent_header": header,
+			"metadata":      map[string]interface{}{},
+			"content":       map[string]interface{}{"status": "ok"},
+			"buffers":       []interface{}{},
+		}
+
+		if err := conn.WriteJSON(reply); err != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeJupyterServer) numCreated() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.created)
+}
+
+func (s *fakeJupyterServer) deletedSessions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.deleted...)
+}
+
+var _ = Describe("Session Pool Tests", func() {
+	var (
+		server  *fakeJupyterServer
+		manager *jupyter.BasicKernelSessionManager
+	)
+
+	BeforeEach(func() {
+		server = newFakeJupyterServer()
+
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		var err error
+		manager, err = jupyter.NewKernelSessionManagerWithConfig(server.URL, nil, true, &atom, nil)
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		manager.CloseSessionPool()
+		server.Close()
+	})
+
+	It("Will not pool anything unless configured to", func() {
+		Expect((&jupyter.SessionPoolConfig{KernelSpecs: []string{"distributed"}}).Enabled()).To(BeFalse())
+		Expect(manager.PrewarmSession("session", "distributed", nil)).To(MatchError(jupyter.ErrSessionPoolDisabled))
+
+		session, err := manager.CreateSession("session", "session.ipynb", "notebook", "distributed", nil, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeFalse())
+	})
+
+	It("Will hand out warm kernels that cover the requested resources and refill the pool", func() {
+		manager.EnableSessionPool(&jupyter.SessionPoolConfig{
+			WarmKernelsPerSpec: 1,
+			KernelSpecs:        []string{"distributed"},
+			WarmResourceSpec:   &jupyter.ResourceSpec{Cpu: 4000, Mem: 16384, Gpu: 4, Vram: 16},
+		})
+		Eventually(server.numCreated).Should(Equal(1))
+
+		// Too many GPUs for the warm kernel, so this is a cold start.
+		session, err := manager.CreateSession("large-session", "large.ipynb", "notebook", "distributed", &jupyter.ResourceSpec{Gpu: 8}, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeFalse())
+		Expect(server.numCreated()).To(Equal(2))
+
+		// Session IDs are 36 characters long so that the BasicKernelSessionManager does not adjust them.
+		smallSessionId := uuid.NewString()
+		session, err = manager.CreateSession(smallSessionId, "small.ipynb", "notebook", "distributed", &jupyter.ResourceSpec{Gpu: 2}, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeTrue())
+
+		// The pool is refilled in the background.
+		Eventually(server.numCreated).Should(Equal(3))
+
+		server.mu.Lock()
+		Expect(server.patched).To(HaveLen(1))
+		pooledSessionId := server.patched[0]
+		server.mu.Unlock()
+		Expect(pooledSessionId).To(HavePrefix("pool-"))
+
+		// Stopping the adopted session stops the Jupyter session that the pool created.
+		Expect(manager.StopKernel(smallSessionId)).To(Succeed())
+		Expect(server.deletedSessions()).To(ContainElement(pooledSessionId))
+	})
+
+	It("Will only hand out warm kernels with an acceptable GPU type", func() {
+		manager.EnableSessionPool(&jupyter.SessionPoolConfig{
+			WarmKernelsPerSpec: 1,
+			KernelSpecs:        []string{"distributed"},
+			WarmResourceSpec:   &jupyter.ResourceSpec{Gpu: 4, GpuTypes: []string{"T4"}},
+		})
+		Eventually(server.numCreated).Should(Equal(1))
+
+		session, err := manager.CreateSession(uuid.NewString(), "a100.ipynb", "notebook", "distributed",
+			&jupyter.ResourceSpec{Gpu: 1, GpuTypes: []string{"A100-80GB"}}, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeFalse())
+
+		session, err = manager.CreateSession(uuid.NewString(), "fallback.ipynb", "notebook", "distributed",
+			&jupyter.ResourceSpec{Gpu: 1, GpuTypes: []string{"A100-80GB", "T4"}}, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeTrue())
+	})
+
+	It("Will hand out kernels pre-warmed for specific sessions and stop unclaimed kernels when closed", func() {
+		manager.EnableSessionPool(&jupyter.SessionPoolConfig{MaxPrewarmedSessions: 1})
+
+		Expect(manager.PrewarmSession("upcoming-session", "distributed", &jupyter.ResourceSpec{Gpu: 2})).To(Succeed())
+		Expect(manager.PrewarmSession("other-session", "distributed", nil)).To(MatchError(jupyter.ErrSessionPoolFull))
+
+		session, err := manager.CreateSession("upcoming-session", "upcoming.ipynb", "notebook", "distributed", &jupyter.ResourceSpec{Gpu: 2}, "")
+		Expect(err).To(BeNil())
+		Expect(session.Pooled()).To(BeTrue())
+		Expect(server.numCreated()).To(Equal(1))
+
+		Expect(manager.PrewarmSession("other-session", "distributed", nil)).To(Succeed())
+		Eventually(server.numCreated).Should(Equal(2))
+
+		manager.CloseSessionPool()
+		Eventually(server.deletedSessions).Should(HaveLen(1))
+	})
+})
@@ -1,6 +1,7 @@
 package jupyter
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 )
@@ -16,6 +17,26 @@ var (
 // It can return an arbitrary value.
 type IOPubMessageHandler func(conn KernelConnection, kernelMessage KernelMessage) interface{}
 
+// MessageObserver is notified of each shell and control message sent to or received from a kernel.
+//
+// Important: a MessageObserver is called synchronously from the goroutine that sends or receives the message,
+// so it must not block.
+type MessageObserver func(conn KernelConnection, direction MessageDirection, kernelMessage KernelMessage)
+
+// MessageDirection indicates whether a message observed by a MessageObserver was sent to or received from a kernel.
+type MessageDirection string
+
+const (
+	SentMessage     MessageDirection = "sent"
+	ReceivedMessage MessageDirection = "received"
+)
+
+// InputRequestHandler is invoked when a kernel requests input from the user by sending an "input_request" message
+// on the stdin channel. The returned value is sent back to the kernel in an "input_reply" message.
+//
+// Important: an InputRequestHandler will be called from its own goroutine.
+type InputRequestHandler func(conn KernelConnection, request *InputRequestContent) (string, error)
+
 type KernelConnection interface {
 	// ConnectionStatus returns the connection status of the kernel.
 	ConnectionStatus() KernelConnectionStatus
@@ -80,17 +101,64 @@ type KernelConnection interface {
 	// StopRunningTrainingCode sends a 'stop_running_training_code_request' message.
 	StopRunningTrainingCode(waitForResponse bool) error
 
+	// RequestComplete sends a 'complete_request' message and returns the content of the 'complete_reply'.
+	RequestComplete(code string, cursorPos int) (*CompleteReplyContent, error)
+
+	// RequestInspect sends an 'inspect_request' message and returns the content of the 'inspect_reply'.
+	RequestInspect(code string, cursorPos int, detailLevel int) (*InspectReplyContent, error)
+
+	// RequestHistory sends a 'history_request' message and returns the content of the 'history_reply'.
+	RequestHistory(request *HistoryRequestContent) (*HistoryReplyContent, error)
+
+	// RequestIsComplete sends an 'is_complete_request' message and returns the content of the 'is_complete_reply'.
+	RequestIsComplete(code string) (*IsCompleteReplyContent, error)
+
+	// RequestShutdown sends a 'shutdown_request' message via the control channel and returns the content of
+	// the 'shutdown_reply'. If restart is true, then the kernel will be restarted after it shuts down.
+	RequestShutdown(restart bool) (*ShutdownReplyContent, error)
+
+	// RequestCommInfo sends a 'comm_info_request' message and returns the content of the 'comm_info_reply'.
+	// If targetName is non-empty, then only the comms with that target are returned.
+	RequestCommInfo(targetName string) (*CommInfoReplyContent, error)
+
+	// OpenComm sends a 'comm_open' message for a new comm with the specified target and returns the comm's ID.
+	OpenComm(targetName string, data map[string]interface{}) (string, error)
+
+	// SendCommMessage sends a 'comm_msg' message to the specified comm.
+	SendCommMessage(commId string, data map[string]interface{}) error
+
+	// CloseComm sends a 'comm_close' message for the specified comm.
+	CloseComm(commId string, data map[string]interface{}) error
+
+	// SetInputRequestHandler registers the handler that is invoked when the kernel sends an 'input_request'.
+	// If no handler is registered, then an empty string is sent in reply to 'input_request' messages.
+	SetInputRequestHandler(handler InputRequestHandler)
+
 	// Close the connection to the kernel.
 	Close() error
 
 	SetOnError(func(err error))
 
+	// SetOnConnectionStatusChanged registers a handler that is called whenever the connection status changes.
+	SetOnConnectionStatusChanged(handler ConnectionStatusChangedHandler)
+
+	// SetReconnectionPolicy sets the ReconnectionPolicy used after losing the connection to the kernel.
+	// If policy is nil, then the DefaultReconnectionPolicy is used.
+	SetReconnectionPolicy(policy *ReconnectionPolicy)
+
 	// RegisterIoPubHandler registers a handler/consumer of IOPub messages under a specific ID.
 	RegisterIoPubHandler(id string, handler IOPubMessageHandler) error
 
 	// UnregisterIoPubHandler unregisters a handler/consumer of IOPub messages that was registered under the specified ID.
 	UnregisterIoPubHandler(id string) error
 
+	// RegisterMessageObserver registers an observer of the shell and control messages exchanged with the kernel
+	// under a specific ID.
+	RegisterMessageObserver(id string, observer MessageObserver) error
+
+	// UnregisterMessageObserver unregisters the MessageObserver that was registered under the specified ID.
+	UnregisterMessageObserver(id string) error
+
 	// AddMetadata attaches some metadata to the KernelConnection.
 	// This metadata is primarily used for attaching labels to Prometheus kernelMetricsManager.
 	AddMetadata(key string, value interface{}) error
@@ -103,10 +171,21 @@ type KernelConnection interface {
 type ErrorHandler func(sessionId string, kernelId string, err error)
 
 type KernelSessionManager interface {
-	// CreateSession creates a new session.
+	// CreateSession creates a new session on behalf of the specified user. The username may be empty.
 	//
 	// This is thread-safe.
-	CreateSession(sessionId string, path string, sessionType string, kernelSpecName string, resourceSpec *ResourceSpec) (*SessionConnection, error)
+	CreateSession(sessionId string, path string, sessionType string, kernelSpecName string, resourceSpec *ResourceSpec, username string) (*SessionConnection, error)
+
+	// EnableSessionPool enables the session pool as specified by the given SessionPoolConfig. If the
+	// SessionPoolConfig does not specify any pooling, then EnableSessionPool does nothing.
+	EnableSessionPool(config *SessionPoolConfig)
+
+	// PrewarmSession creates a kernel in the background for the specified upcoming session, such that the call to
+	// CreateSession for that session can hand out the pre-warmed kernel rather than creating one.
+	PrewarmSession(sessionId string, kernelSpecName string, resourceSpec *ResourceSpec) error
+
+	// CloseSessionPool stops all idle pooled kernels and disables the session pool.
+	CloseSessionPool()
 
 	// InterruptKernel interrupts a kernel.
 	//
@@ -123,6 +202,10 @@ type KernelSessionManager interface {
 
 	CreateFile(path string) error
 
+	// UploadNotebook saves the given .ipynb contents at the specified path via the contents API,
+	// overwriting any existing file at that path.
+	UploadNotebook(path string, content json.RawMessage) error
+
 	StopKernel(id string) error
 
 	GetMetrics() KernelManagerMetrics
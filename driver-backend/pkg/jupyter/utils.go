@@ -48,17 +48,15 @@ func getResponseChannelKeyImpl(messageId string, messageType string, channel Ker
 // This function accepts the original message that is/was sent TO the Jupyter components.
 //
 // For the message type, we convert "{action}_request" message types to "{action}_reply"for use in the key.
+// The empty string is returned for messages that are not requests, as no reply will be sent for them.
 func getResponseChannelKeyFromRequest(originalMessage KernelMessage) string {
 	var messageType = originalMessage.GetHeader().MessageType
 	var channel = originalMessage.GetChannel()
 	var messageId = originalMessage.GetHeader().MessageId
 
-	if originalMessage.GetHeader().MessageType == CommCloseMessage {
-		return ""
-	}
-
+	// Messages such as "comm_open", "comm_msg", and "comm_close" do not receive a reply.
 	if !strings.HasSuffix(messageType.String(), "request") {
-		panic(fmt.Sprintf("%s request %s has invalid message type: \"%s\"", channel, messageId, messageType))
+		return ""
 	}
 
 	// Since we're using the request to generate the key, we convert the message type to its reply variant.
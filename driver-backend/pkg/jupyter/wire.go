@@ -0,0 +1,145 @@
+package jupyter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+const (
+	// wireDelimiter separates the ZMQ routing identities of a message from the message itself.
+	wireDelimiter = "<IDS|MSG>"
+)
+
+var (
+	ErrUnsupportedSignatureScheme = errors.New("unsupported message signature scheme")
+	ErrInvalidMessageSignature    = errors.New("kernel message has an invalid signature")
+	ErrMalformedWireMessage       = errors.New("malformed kernel message")
+)
+
+// messageSigner computes and verifies the HMAC signatures of messages sent using the Jupyter wire protocol.
+//
+// - Wire protocol: https://jupyter-client.readthedocs.io/en/latest/messaging.html#the-wire-protocol
+type messageSigner struct {
+	key     []byte
+	newHash func() hash.Hash
+}
+
+func newMessageSigner(scheme string, key string) (*messageSigner, error) {
+	switch scheme {
+	case "", DefaultSignatureScheme:
+		return &messageSigner{key: []byte(key), newHash: sha256.New}, nil
+	default:
+		return nil, fmt.Errorf("%w: \"%s\"", ErrUnsupportedSignatureScheme, scheme)
+	}
+}
+
+// sign returns the hex-encoded signature of the given message parts.
+// If signing is disabled (i.e., the key is empty), then sign returns the empty string.
+func (s *messageSigner) sign(parts ...[]byte) []byte {
+	if len(s.key) == 0 {
+		return []byte{}
+	}
+
+	mac := hmac.New(s.newHash, s.key)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (s *messageSigner) verify(signature []byte, parts ...[]byte) bool {
+	if len(s.key) == 0 {
+		return true
+	}
+
+	return hmac.Equal(signature, s.sign(parts...))
+}
+
+// encodeWireMessage encodes a KernelMessage as the frames of a Jupyter wire protocol message.
+func encodeWireMessage(message KernelMessage, signer *messageSigner) ([][]byte, error) {
+	header, err := json.Marshal(message.GetHeader())
+	if err != nil {
+		return nil, err
+	}
+
+	parentHeader := []byte("{}")
+	if message.GetParentHeader() != nil && message.GetParentHeader().MessageId != "" {
+		if parentHeader, err = json.Marshal(message.GetParentHeader()); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata := []byte("{}")
+	if message.GetMetadata() != nil {
+		if metadata, err = json.Marshal(message.GetMetadata()); err != nil {
+			return nil, err
+		}
+	}
+
+	content := []byte("{}")
+	if message.GetContent() != nil {
+		if content, err = json.Marshal(message.GetContent()); err != nil {
+			return nil, err
+		}
+	}
+
+	frames := [][]byte{[]byte(wireDelimiter), signer.sign(header, parentHeader, metadata, content), header, parentHeader, metadata, content}
+	return append(frames, message.GetBuffers()...), nil
+}
+
+// decodeWireMessage decodes the frames of a Jupyter wire protocol message received on the specified channel.
+//
+// Any routing identities or IOPub topics preceding the delimiter are discarded.
+func decodeWireMessage(frames [][]byte, channel KernelSocketChannel, signer *messageSigner) (*BaseKernelMessage, error) {
+	delimiterIndex := -1
+	for i, frame := range frames {
+		if string(frame) == wireDelimiter {
+			delimiterIndex = i
+			break
+		}
+	}
+
+	if delimiterIndex < 0 || len(frames) < delimiterIndex+6 {
+		return nil, fmt.Errorf("%w: expected delimiter followed by at least 5 frames", ErrMalformedWireMessage)
+	}
+
+	parts := frames[delimiterIndex+1:]
+	if !signer.verify(parts[0], parts[1], parts[2], parts[3], parts[4]) {
+		return nil, ErrInvalidMessageSignature
+	}
+
+	message := &BaseKernelMessage{
+		Channel:      channel,
+		Header:       &KernelMessageHeader{},
+		ParentHeader: &KernelMessageHeader{},
+		Metadata:     make(map[string]interface{}),
+		Buffers:      parts[5:],
+	}
+
+	if err := json.Unmarshal(parts[1], message.Header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %v", ErrMalformedWireMessage, err)
+	}
+
+	if err := json.Unmarshal(parts[2], message.ParentHeader); err != nil {
+		return nil, fmt.Errorf("%w: invalid parent header: %v", ErrMalformedWireMessage, err)
+	}
+
+	if err := json.Unmarshal(parts[3], &message.Metadata); err != nil {
+		return nil, fmt.Errorf("%w: invalid metadata: %v", ErrMalformedWireMessage, err)
+	}
+
+	// Content is decoded as a map, just like the content of messages received via the Jupyter Server's websocket.
+	var content map[string]interface{}
+	if err := json.Unmarshal(parts[4], &content); err != nil {
+		return nil, fmt.Errorf("%w: invalid content: %v", ErrMalformedWireMessage, err)
+	}
+	message.Content = content
+
+	return message, nil
+}
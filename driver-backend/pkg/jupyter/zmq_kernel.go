@@ -0,0 +1,320 @@
+package jupyter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ZmqDialTimeout is how long we wait to connect to (and complete the ZMTP handshake with) each kernel socket.
+	ZmqDialTimeout = time.Second * 10
+
+	// ZmqHeartbeatInterval is how often we ping the kernel's heartbeat socket.
+	ZmqHeartbeatInterval = time.Second * 3
+
+	// ZmqMaxMissedHeartbeats is the number of consecutive missed heartbeats after which the kernel is
+	// considered to be disconnected.
+	ZmqMaxMissedHeartbeats = 3
+)
+
+var (
+	ErrZmqConnectionFailed = errors.New("failed to connect to kernel socket")
+)
+
+// ZmqKernelConnection is a KernelConnection that speaks the Jupyter wire protocol directly to the ZMQ sockets of
+// a kernel, bypassing the Jupyter Server. This allows for measuring kernel latency without the additional hop
+// through the Jupyter Server and for testing kernels in isolation.
+//
+// ZmqKernelConnection reuses the messaging machinery of BasicKernelConnection; only the transport differs.
+// Because there is no Jupyter Server, kernels are interrupted via an "interrupt_request" message, which
+// requires the kernel's interrupt_mode to be "message".
+type ZmqKernelConnection struct {
+	*BasicKernelConnection
+
+	connectionInfo *ConnectionInfo
+	signer         *messageSigner
+
+	shell     *zmtpConn
+	control   *zmtpConn
+	stdin     *zmtpConn
+	iopub     *zmtpConn
+	heartbeat *zmtpConn
+
+	lostOnce sync.Once
+	stopChan chan interface{}
+}
+
+// NewZmqKernelConnection connects directly to the ZMQ sockets of the kernel described by the given ConnectionInfo.
+//
+// The ConnectionInfo can be read from a kernel connection file using LoadConnectionFile, or it can be created
+// from the KernelConnectionInfo returned by the Cluster Gateway using ConnectionInfoFromGateway.
+func NewZmqKernelConnection(kernelId string, connectionInfo *ConnectionInfo, atom *zap.AtomicLevel,
+	metricsConsumer MetricsConsumer, onError func(err error)) (*ZmqKernelConnection, error) {
+
+	if connectionInfo == nil {
+		return nil, fmt.Errorf("%w: connection info is nil", ErrInvalidConnectionInfo)
+	}
+
+	if err := connectionInfo.Validate(); err != nil {
+		return nil, err
+	}
+
+	signer, err := newMessageSigner(connectionInfo.SignatureScheme, connectionInfo.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &ZmqKernelConnection{
+		BasicKernelConnection: newBasicKernelConnection(kernelId, "", "", nil, atom, metricsConsumer, onError),
+		connectionInfo:        connectionInfo,
+		signer:                signer,
+		stopChan:              make(chan interface{}),
+	}
+	conn.transport = conn
+
+	if err = conn.updateConnectionStatus(KernelConnecting); err != nil {
+		return nil, err
+	}
+
+	if err = conn.dialSockets(); err != nil {
+		conn.logger.Error("Failed to connect to kernel sockets.", zap.String("kernel_id", kernelId),
+			zap.String("ip", connectionInfo.IP), zap.Error(err))
+		_ = conn.Close()
+		conn.tryCallOnError(err)
+		return nil, err
+	}
+
+	go conn.serveSocket(conn.shell, ShellChannel)
+	go conn.serveSocket(conn.control, ControlChannel)
+	go conn.serveSocket(conn.stdin, StdinChannel)
+	go conn.serveSocket(conn.iopub, IOPubChannel)
+
+	if conn.heartbeat != nil {
+		go conn.serveHeartbeat()
+	}
+
+	// This sends a "kernel_info_request" to ensure that the kernel is responsive.
+	if err = conn.updateConnectionStatus(KernelConnected); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	conn.logger.Debug("Connected directly to kernel sockets.", zap.String("kernel_id", kernelId),
+		zap.String("ip", connectionInfo.IP))
+
+	return conn, nil
+}
+
+// dialSockets connects to the kernel's shell, control, stdin, iopub, and (optionally) heartbeat sockets.
+func (conn *ZmqKernelConnection) dialSockets() error {
+	sockets := []struct {
+		target     **zmtpConn
+		port       int
+		socketType string
+		channel    string
+	}{
+		{&conn.shell, conn.connectionInfo.ShellPort, zmtpDealer, ShellChannel.String()},
+		{&conn.control, conn.connectionInfo.ControlPort, zmtpDealer, ControlChannel.String()},
+		{&conn.stdin, conn.connectionInfo.StdinPort, zmtpDealer, StdinChannel.String()},
+		{&conn.iopub, conn.connectionInfo.IOPubPort, zmtpSub, IOPubChannel.String()},
+		{&conn.heartbeat, conn.connectionInfo.HeartbeatPort, zmtpReq, "heartbeat"},
+	}
+
+	for _, socket := range sockets {
+		if socket.port <= 0 {
+			continue
+		}
+
+		address := conn.connectionInfo.address(socket.port)
+		zconn, err := dialZmtp(address, socket.socketType, ZmqDialTimeout)
+		if err != nil {
+			return fmt.Errorf("%w: %s socket at %s: %v", ErrZmqConnectionFailed, socket.channel, address, err)
+		}
+
+		*socket.target = zconn
+	}
+
+	// Subscribe to all IOPub messages.
+	return conn.iopub.subscribe(nil)
+}
+
+// sendKernelMessage sends a message to the kernel via the socket corresponding to the message's channel.
+func (conn *ZmqKernelConnection) sendKernelMessage(message KernelMessage) error {
+	var socket *zmtpConn
+	switch message.GetChannel() {
+	case ShellChannel:
+		socket = conn.shell
+	case ControlChannel:
+		socket = conn.control
+	case StdinChannel:
+		socket = conn.stdin
+	default:
+		return fmt.Errorf("cannot send messages on the \"%s\" channel", message.GetChannel())
+	}
+
+	frames, err := encodeWireMessage(message, conn.signer)
+	if err != nil {
+		return err
+	}
+
+	return socket.send(frames)
+}
+
+// serveSocket receives messages from one of the kernel's sockets until the connection is closed.
+func (conn *ZmqKernelConnection) serveSocket(socket *zmtpConn, channel KernelSocketChannel) {
+	for {
+		frames, err := socket.recv()
+		if err != nil {
+			conn.connectionLost(channel.String(), err)
+			return
+		}
+
+		kernelMessage, err := decodeWireMessage(frames, channel, conn.signer)
+		if err != nil {
+			conn.logger.Error("Failed to decode message from kernel.",
+				zap.String("kernel_id", conn.kernelId),
+				zap.String("channel", channel.String()),
+				zap.Int("num_frames", len(frames)),
+				zap.Error(err))
+			continue
+		}
+
+		conn.dispatchMessage(kernelMessage)
+	}
+}
+
+// serveHeartbeat periodically pings the kernel's heartbeat socket. If the kernel misses ZmqMaxMissedHeartbeats
+// consecutive heartbeats, then the connection status is set to KernelDisconnected until the kernel responds again.
+func (conn *ZmqKernelConnection) serveHeartbeat() {
+	replies := make(chan string, 1)
+	go func() {
+		for {
+			frames, err := conn.heartbeat.recv()
+			if err != nil {
+				conn.connectionLost("heartbeat", err)
+				return
+			}
+
+			// Heartbeat replies are preceded by the empty delimiter frame of the REQ socket.
+			select {
+			case replies <- string(frames[len(frames)-1]):
+			case <-conn.stopChan:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(ZmqHeartbeatInterval)
+	defer ticker.Stop()
+
+	missed := 0
+	for beat := 0; ; beat++ {
+		select {
+		case <-conn.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		payload := strconv.Itoa(beat)
+		if err := conn.heartbeat.send([][]byte{{}, []byte(payload)}); err != nil {
+			conn.connectionLost("heartbeat", err)
+			return
+		}
+
+		if conn.awaitHeartbeat(replies, payload) {
+			if missed >= ZmqMaxMissedHeartbeats {
+				conn.logger.Debug("Kernel is responding to heartbeats again.", zap.String("kernel_id", conn.kernelId))
+				conn.setConnectionStatus(KernelConnected)
+			}
+			missed = 0
+			continue
+		}
+
+		missed += 1
+		if missed == ZmqMaxMissedHeartbeats {
+			conn.logger.Warn("Kernel has stopped responding to heartbeats.", zap.String("kernel_id", conn.kernelId),
+				zap.Int("missed_heartbeats", missed))
+			conn.setConnectionStatus(KernelDisconnected)
+		}
+	}
+}
+
+// awaitHeartbeat waits for the reply to the heartbeat with the given payload, discarding any late replies
+// to earlier heartbeats. It returns false if no reply is received within ZmqHeartbeatInterval.
+func (conn *ZmqKernelConnection) awaitHeartbeat(replies chan string, payload string) bool {
+	timeout := time.After(ZmqHeartbeatInterval)
+	for {
+		select {
+		case reply := <-replies:
+			if reply == payload {
+				return true
+			}
+		case <-timeout:
+			return false
+		case <-conn.stopChan:
+			return false
+		}
+	}
+}
+
+// connectionLost is called when one of the kernel's sockets can no longer be read from.
+func (conn *ZmqKernelConnection) connectionLost(channel string, err error) {
+	if conn.closed.Load() {
+		return
+	}
+
+	conn.lostOnce.Do(func() {
+		conn.logger.Error("Lost connection to kernel socket.", zap.String("kernel_id", conn.kernelId),
+			zap.String("channel", channel), zap.Error(err))
+
+		conn.setConnectionStatus(KernelDead)
+		conn.tryCallOnError(fmt.Errorf("%w: %s socket: %v", ErrKernelIsDead, channel, err))
+
+		// There is no reconnecting to the kernel's sockets, so any requests awaiting a reply will never receive one.
+		conn.failPendingRequests(conn.takePendingRequests(), fmt.Errorf("%w: %s socket: %v", ErrPendingRequestFailed, channel, err))
+	})
+}
+
+// InterruptKernel interrupts the kernel by sending an "interrupt_request" via the control channel.
+//
+// This requires the kernel's interrupt_mode to be "message", as there is no Jupyter Server to signal the kernel.
+func (conn *ZmqKernelConnection) InterruptKernel() error {
+	if conn.connectionStatus == KernelDead {
+		return fmt.Errorf("%w: no connection to kernel \"%s\"", ErrKernelIsDead, conn.kernelId)
+	}
+
+	return conn.sendRequestAndAwaitReply(InterruptRequest, ControlChannel, nil, &ReplyStatus{})
+}
+
+// JupyterServerAddress returns the address of the kernel itself, as there is no Jupyter Server.
+func (conn *ZmqKernelConnection) JupyterServerAddress() string {
+	return fmt.Sprintf("tcp://%s", conn.connectionInfo.IP)
+}
+
+// Close closes all the sockets connected to the kernel.
+func (conn *ZmqKernelConnection) Close() error {
+	if !conn.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	close(conn.stopChan)
+	conn.setConnectionStatus(KernelDead)
+
+	var firstErr error
+	for _, socket := range []*zmtpConn{conn.shell, conn.control, conn.stdin, conn.iopub, conn.heartbeat} {
+		if socket == nil {
+			continue
+		}
+
+		if err := socket.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,275 @@
+package jupyter_test
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/scusemua/workload-driver-react/m/v2/pkg/jupyter"
+	"go.uber.org/zap"
+)
+
+const fakeKernelKey = "a0436f6c-1916-498b-8eb9-e81ab9368e84"
+
+// fakeKernelSocket is the kernel side of a single ZMTP 3.0 connection.
+type fakeKernelSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func acceptFakeKernelSocket(listener net.Listener, socketType string) *fakeKernelSocket {
+	conn, err := listener.Accept()
+	Expect(err).To(BeNil())
+
+	socket := &fakeKernelSocket{conn: conn, reader: bufio.NewReader(conn)}
+
+	greeting := make([]byte, 64)
+	greeting[0], greeting[9], greeting[10] = 0xFF, 0x7F, 3
+	copy(greeting[12:], "NULL")
+	_, err = conn.Write(greeting)
+	Expect(err).To(BeNil())
+
+	_, err = io.ReadFull(socket.reader, make([]byte, 64))
+	Expect(err).To(BeNil())
+
+	ready := []byte{5}
+	ready = append(ready, "READY"...)
+	ready = append(ready, 11)
+	ready = append(ready, "Socket-Type"...)
+	ready = binary.BigEndian.AppendUint32(ready, uint32(len(socketType)))
+	ready = append(ready, socketType...)
+	_, err = conn.Write(append([]byte{0x04, byte(len(ready))}, ready...))
+	Expect(err).To(BeNil())
+
+	// The client's READY command.
+	Expect(socket.recv()).ToNot(BeNil())
+
+	return socket
+}
+
+// recv returns the next message received by the socket, or nil once the client has disconnected.
+func (s *fakeKernelSocket) recv() [][]byte {
+	frames := make([][]byte, 0)
+	for {
+		flags, err := s.reader.ReadByte()
+		if err != nil {
+			return nil
+		}
+
+		var size uint64
+		if flags&0x02 != 0 {
+			buf := make([]byte, 8)
+			if _, err = io.ReadFull(s.reader, buf); err != nil {
+				return nil
+			}
+			size = binary.BigEndian.Uint64(buf)
+		} else {
+			sizeByte, err := s.reader.ReadByte()
+			if err != nil {
+				return nil
+			}
+			size = uint64(sizeByte)
+		}
+
+		body := make([]byte, size)
+		if _, err = io.ReadFull(s.reader, body); err != nil {
+			return nil
+		}
+
+		frames = append(frames, body)
+		if flags&0x01 == 0 {
+			return frames
+		}
+	}
+}
+
+func (s *fakeKernelSocket) send(frames [][]byte) {
+	for i, frame := range frames {
+		var flags byte
+		if i < len(frames)-1 {
+			flags = 0x01
+		}
+
+		header := []byte{flags | 0x02}
+		header = binary.BigEndian.AppendUint64(header, uint64(len(frame)))
+		if _, err := s.conn.Write(append(header, frame...)); err != nil {
+			return
+		}
+	}
+}
+
+func signFakeKernelMessage(parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, []byte(fakeKernelKey))
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return []byte(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// fakeKernelReply creates the frames of a message sent by the kernel in reply to the request with the given header.
+func fakeKernelReply(prefix [][]byte, parentHeader []byte, messageType string, content map[string]interface{}) [][]byte {
+	header, _ := json.Marshal(map[string]string{"msg_id": "reply-" + messageType, "msg_type": messageType, "session": "kernel", "version": "5.3"})
+	encodedContent, _ := json.Marshal(content)
+	metadata := []byte("{}")
+
+	frames := append(prefix, []byte("<IDS|MSG>"), signFakeKernelMessage(header, parentHeader, metadata, encodedContent))
+	return append(frames, header, parentHeader, metadata, encodedContent)
+}
+
+var _ = Describe("ZMQ Kernel Connection Tests", func() {
+	Context("Connection info", func() {
+		It("Will load a kernel connection file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "kernel.json")
+			err := os.WriteFile(path, []byte(`{"shell_port": 1, "iopub_port": 2, "stdin_port": 3, "control_port": 4, "hb_port": 5,
+				"ip": "127.0.0.1", "key": "abc", "transport": "tcp", "signature_scheme": "hmac-sha256", "kernel_name": "python3"}`), 0644)
+			Expect(err).To(BeNil())
+
+			info, err := jupyter.LoadConnectionFile(path)
+			Expect(err).To(BeNil())
+			Expect(info.Validate()).To(BeNil())
+			Expect(info.ShellPort).To(Equal(1))
+			Expect(info.HeartbeatPort).To(Equal(5))
+			Expect(info.Key).To(Equal("abc"))
+		})
+
+		It("Will reject unusable connection info", func() {
+			err := (&jupyter.ConnectionInfo{IP: "127.0.0.1", Transport: "ipc", ShellPort: 1, ControlPort: 2, IOPubPort: 3, StdinPort: 4}).Validate()
+			Expect(errors.Is(err, jupyter.ErrZmtpUnsupportedTransport)).To(BeTrue())
+
+			err = (&jupyter.ConnectionInfo{IP: "127.0.0.1", ShellPort: 1}).Validate()
+			Expect(errors.Is(err, jupyter.ErrInvalidConnectionInfo)).To(BeTrue())
+
+			_, err = jupyter.NewZmqKernelConnection("kernel", &jupyter.ConnectionInfo{IP: "127.0.0.1", ShellPort: 1, ControlPort: 2,
+				IOPubPort: 3, StdinPort: 4, SignatureScheme: "hmac-md5"}, nil, nil, nil)
+			Expect(errors.Is(err, jupyter.ErrUnsupportedSignatureScheme)).To(BeTrue())
+		})
+	})
+
+	It("Will exchange signed messages with a kernel over ZMQ", func() {
+		listeners := make(map[string]net.Listener)
+		for _, channel := range []string{"shell", "control", "stdin", "iopub"} {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).To(BeNil())
+			defer listener.Close()
+			listeners[channel] = listener
+		}
+
+		port := func(channel string) int {
+			return listeners[channel].Addr().(*net.TCPAddr).Port
+		}
+
+		// Serve the kernel's sockets in the background.
+		subscribed := make(chan interface{})
+		executeRequests := make(chan [][]byte, 1)
+		go func() {
+			defer GinkgoRecover()
+
+			shell := acceptFakeKernelSocket(listeners["shell"], "ROUTER")
+			acceptFakeKernelSocket(listeners["control"], "ROUTER")
+			acceptFakeKernelSocket(listeners["stdin"], "ROUTER")
+			iopub := acceptFakeKernelSocket(listeners["iopub"], "PUB")
+
+			go func() {
+				defer GinkgoRecover()
+
+				// The subscription message of the SUB socket.
+				subscription := iopub.recv()
+				Expect(subscription).ToNot(BeNil())
+				Expect(subscription[0]).To(Equal([]byte{0x01}))
+				close(subscribed)
+			}()
+
+			for {
+				request := shell.recv()
+				if request == nil {
+					return
+				}
+
+				Expect(string(request[0])).To(Equal("<IDS|MSG>"))
+				Expect(request[1]).To(Equal(signFakeKernelMessage(request[2], request[3], request[4], request[5])))
+
+				var header map[string]interface{}
+				Expect(json.Unmarshal(request[2], &header)).To(Succeed())
+
+				// The replies must reference the request as their parent.
+				parent := request[2]
+				switch header["msg_type"] {
+				case "kernel_info_request":
+					shell.send(fakeKernelReply(nil, parent, "kernel_info_reply", map[string]interface{}{"status": "ok", "implementation": "fake"}))
+				case "execute_request":
+					executeRequests <- request
+					<-subscribed
+					iopub.send(fakeKernelReply([][]byte{[]byte("kernel.stream")}, parent, "stream", map[string]interface{}{"name": "stdout", "text": "hello\n"}))
+					shell.send(fakeKernelReply(nil, parent, "execute_reply", map[string]interface{}{"status": "ok", "execution_count": 1}))
+				}
+			}
+		}()
+
+		atom := zap.NewAtomicLevelAt(zap.InfoLevel)
+		conn, err := jupyter.NewZmqKernelConnection("fake-kernel", &jupyter.ConnectionInfo{
+			IP:              "127.0.0.1",
+			Transport:       "tcp",
+			ShellPort:       port("shell"),
+			ControlPort:     port("control"),
+			IOPubPort:       port("iopub"),
+			StdinPort:       port("stdin"),
+			SignatureScheme: jupyter.DefaultSignatureScheme,
+			Key:             fakeKernelKey,
+		}, &atom, nil, nil)
+		Expect(err).To(BeNil())
+		Expect(conn.Connected()).To(BeTrue())
+		defer conn.Close()
+
+		var kernelConnection jupyter.KernelConnection = conn
+
+		outputs := make(chan string, 1)
+		err = kernelConnection.RegisterIoPubHandler("test", func(conn jupyter.KernelConnection, kernelMessage jupyter.KernelMessage) interface{} {
+			if kernelMessage.GetHeader().MessageType == "stream" {
+				outputs <- kernelMessage.GetContent().(map[string]interface{})["text"].(string)
+			}
+			return nil
+		})
+		Expect(err).To(BeNil())
+
+		observed := make(chan string, 8)
+		err = kernelConnection.RegisterMessageObserver("test", func(conn jupyter.KernelConnection, direction jupyter.MessageDirection, kernelMessage jupyter.KernelMessage) {
+			observed <- string(direction) + ":" + kernelMessage.GetHeader().MessageType.String()
+		})
+		Expect(err).To(BeNil())
+		Expect(errors.Is(kernelConnection.RegisterMessageObserver("test", nil), jupyter.ErrHandlerAlreadyExists)).To(BeTrue())
+
+		reply, err := kernelConnection.RequestExecute(jupyter.NewRequestExecuteArgsBuilder().Code("print('hello')").AwaitResponse(true).Build())
+		Expect(err).To(BeNil())
+		Expect(reply.GetHeader().MessageType.String()).To(Equal("execute_reply"))
+		Expect(reply.GetContent().(map[string]interface{})["status"]).To(Equal("ok"))
+
+		var request [][]byte
+		Eventually(executeRequests).Should(Receive(&request))
+		var content map[string]interface{}
+		Expect(json.Unmarshal(request[5], &content)).To(Succeed())
+		Expect(content["code"]).To(Equal("print('hello')"))
+
+		Eventually(outputs, time.Second*5).Should(Receive(Equal("hello\n")))
+
+		// Shell messages are observed in both directions, whereas IOPub messages are not.
+		Eventually(observed).Should(Receive(Equal("sent:execute_request")))
+		Eventually(observed).Should(Receive(Equal("received:execute_reply")))
+		Expect(kernelConnection.UnregisterMessageObserver("test")).To(BeNil())
+		Expect(errors.Is(kernelConnection.UnregisterMessageObserver("test"), jupyter.ErrNoHandlerFound)).To(BeTrue())
+
+		Expect(kernelConnection.Close()).To(BeNil())
+		Expect(kernelConnection.Connected()).To(BeFalse())
+	})
+})
@@ -0,0 +1,321 @@
+package jupyter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// This file contains a minimal, pure-Go implementation of the ZeroMQ Message Transport Protocol (ZMTP 3.0) using the
+// NULL security mechanism. It supports exactly what is needed to talk to a Jupyter kernel: a single connection per
+// socket, multipart messages, and SUB-side subscriptions. Messages are authenticated by the Jupyter wire protocol
+// itself (see wire.go), which is why the NULL mechanism suffices.
+//
+// - ZMTP 3.0: https://rfc.zeromq.org/spec/23/
+
+const (
+	zmtpDealer = "DEALER"
+	zmtpRouter = "ROUTER"
+	zmtpSub    = "SUB"
+	zmtpPub    = "PUB"
+	zmtpReq    = "REQ"
+	zmtpRep    = "REP"
+
+	zmtpGreetingSize  = 64
+	zmtpMechanismNull = "NULL"
+
+	zmtpFlagMore    byte = 0x01
+	zmtpFlagLong    byte = 0x02
+	zmtpFlagCommand byte = 0x04
+
+	// zmtpMaxFrameSize bounds the size of the frames that we are willing to receive.
+	zmtpMaxFrameSize = 1 << 31
+)
+
+var (
+	ErrZmtpHandshakeFailed      = errors.New("ZMTP handshake failed")
+	ErrZmtpIncompatibleSockets  = errors.New("peer ZMQ socket type is incompatible")
+	ErrZmtpFrameTooLarge        = errors.New("ZMTP frame exceeds maximum supported size")
+	ErrZmtpUnsupportedTransport = errors.New("unsupported ZMQ transport")
+
+	// zmtpCompatibleSocketTypes maps each socket type to the socket types of the peers that it may connect to.
+	zmtpCompatibleSocketTypes = map[string][]string{
+		zmtpDealer: {zmtpRouter, zmtpDealer, zmtpRep},
+		zmtpRouter: {zmtpDealer, zmtpRouter, zmtpReq},
+		zmtpSub:    {zmtpPub, "XPUB"},
+		zmtpPub:    {zmtpSub, "XSUB"},
+		zmtpReq:    {zmtpRep, zmtpRouter},
+		zmtpRep:    {zmtpReq, zmtpDealer},
+	}
+)
+
+// zmtpConn is a single ZMTP 3.0 connection.
+//
+// A zmtpConn supports one concurrent reader and any number of concurrent writers.
+type zmtpConn struct {
+	conn           net.Conn
+	reader         *bufio.Reader
+	socketType     string
+	peerSocketType string
+	wlock          sync.Mutex
+}
+
+// dialZmtp connects to the specified TCP address and performs the ZMTP handshake.
+func dialZmtp(address string, socketType string, timeout time.Duration) (*zmtpConn, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	zconn, err := newZmtpConn(conn, socketType, timeout)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return zconn, nil
+}
+
+// newZmtpConn performs the ZMTP handshake over an existing connection.
+func newZmtpConn(conn net.Conn, socketType string, timeout time.Duration) (*zmtpConn, error) {
+	zconn := &zmtpConn{
+		conn:       conn,
+		reader:     bufio.NewReader(conn),
+		socketType: socketType,
+	}
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := zconn.handshake(); err != nil {
+		return nil, err
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return zconn, nil
+}
+
+// handshake exchanges greetings and READY commands with the peer.
+func (c *zmtpConn) handshake() error {
+	greeting := make([]byte, zmtpGreetingSize)
+	greeting[0] = 0xFF
+	greeting[9] = 0x7F
+	greeting[10] = 3 // Major version.
+	greeting[11] = 0 // Minor version.
+	copy(greeting[12:32], zmtpMechanismNull)
+
+	if _, err := c.conn.Write(greeting); err != nil {
+		return err
+	}
+
+	peerGreeting := make([]byte, zmtpGreetingSize)
+	if _, err := io.ReadFull(c.reader, peerGreeting); err != nil {
+		return fmt.Errorf("%w: failed to read greeting: %v", ErrZmtpHandshakeFailed, err)
+	}
+
+	if peerGreeting[0] != 0xFF || peerGreeting[9] != 0x7F {
+		return fmt.Errorf("%w: invalid greeting signature", ErrZmtpHandshakeFailed)
+	}
+
+	if peerGreeting[10] < 3 {
+		return fmt.Errorf("%w: unsupported ZMTP version %d.%d", ErrZmtpHandshakeFailed, peerGreeting[10], peerGreeting[11])
+	}
+
+	if mechanism := string(bytes.TrimRight(peerGreeting[12:32], "\x00")); mechanism != zmtpMechanismNull {
+		return fmt.Errorf("%w: unsupported security mechanism \"%s\"", ErrZmtpHandshakeFailed, mechanism)
+	}
+
+	if err := c.writeFrame(zmtpFlagCommand, encodeZmtpCommand("READY", map[string]string{"Socket-Type": c.socketType})); err != nil {
+		return err
+	}
+
+	flags, body, err := c.readFrame()
+	if err != nil {
+		return fmt.Errorf("%w: failed to read READY command: %v", ErrZmtpHandshakeFailed, err)
+	}
+
+	if flags&zmtpFlagCommand == 0 {
+		return fmt.Errorf("%w: expected READY command", ErrZmtpHandshakeFailed)
+	}
+
+	name, properties, err := decodeZmtpCommand(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrZmtpHandshakeFailed, err)
+	}
+
+	if name == "ERROR" {
+		return fmt.Errorf("%w: peer reported error", ErrZmtpHandshakeFailed)
+	} else if name != "READY" {
+		return fmt.Errorf("%w: expected READY command, received \"%s\"", ErrZmtpHandshakeFailed, name)
+	}
+
+	c.peerSocketType = properties["Socket-Type"]
+	for _, compatible := range zmtpCompatibleSocketTypes[c.socketType] {
+		if c.peerSocketType == compatible {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: cannot connect %s socket to %s socket", ErrZmtpIncompatibleSockets, c.socketType, c.peerSocketType)
+}
+
+// send sends a multipart message.
+func (c *zmtpConn) send(frames [][]byte) error {
+	c.wlock.Lock()
+	defer c.wlock.Unlock()
+
+	for i, frame := range frames {
+		var flags byte
+		if i < len(frames)-1 {
+			flags |= zmtpFlagMore
+		}
+
+		if err := c.writeFrame(flags, frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subscribe subscribes a SUB socket to all messages whose first frame begins with the specified prefix.
+// An empty prefix subscribes to all messages.
+func (c *zmtpConn) subscribe(prefix []byte) error {
+	return c.send([][]byte{append([]byte{0x01}, prefix...)})
+}
+
+// recv receives the next multipart message. Any commands sent by the peer are ignored.
+func (c *zmtpConn) recv() ([][]byte, error) {
+	frames := make([][]byte, 0, 8)
+	for {
+		flags, body, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		if flags&zmtpFlagCommand != 0 {
+			continue
+		}
+
+		frames = append(frames, body)
+		if flags&zmtpFlagMore == 0 {
+			return frames, nil
+		}
+	}
+}
+
+func (c *zmtpConn) close() error {
+	return c.conn.Close()
+}
+
+func (c *zmtpConn) writeFrame(flags byte, body []byte) error {
+	var header []byte
+	if len(body) > 255 {
+		header = make([]byte, 9)
+		header[0] = flags | zmtpFlagLong
+		binary.BigEndian.PutUint64(header[1:], uint64(len(body)))
+	} else {
+		header = []byte{flags, byte(len(body))}
+	}
+
+	if _, err := c.conn.Write(append(header, body...)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *zmtpConn) readFrame() (byte, []byte, error) {
+	flags, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var size uint64
+	if flags&zmtpFlagLong != 0 {
+		buf := make([]byte, 8)
+		if _, err = io.ReadFull(c.reader, buf); err != nil {
+			return 0, nil, err
+		}
+		size = binary.BigEndian.Uint64(buf)
+	} else {
+		sizeByte, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size = uint64(sizeByte)
+	}
+
+	if size > zmtpMaxFrameSize {
+		return 0, nil, fmt.Errorf("%w: %d bytes", ErrZmtpFrameTooLarge, size)
+	}
+
+	body := make([]byte, size)
+	if _, err = io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+
+	return flags, body, nil
+}
+
+// encodeZmtpCommand encodes the body of a ZMTP command with the given name and metadata properties.
+func encodeZmtpCommand(name string, properties map[string]string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(len(name)))
+	buf.WriteString(name)
+
+	for key, value := range properties {
+		buf.WriteByte(byte(len(key)))
+		buf.WriteString(key)
+		_ = binary.Write(&buf, binary.BigEndian, uint32(len(value)))
+		buf.WriteString(value)
+	}
+
+	return buf.Bytes()
+}
+
+// decodeZmtpCommand decodes the body of a ZMTP command into its name and metadata properties.
+func decodeZmtpCommand(body []byte) (string, map[string]string, error) {
+	if len(body) < 1 || len(body) < 1+int(body[0]) {
+		return "", nil, errors.New("malformed command")
+	}
+
+	name := string(body[1 : 1+body[0]])
+	rest := body[1+body[0]:]
+	properties := make(map[string]string)
+
+	// Only READY commands carry metadata properties.
+	if name != "READY" {
+		return name, properties, nil
+	}
+
+	for len(rest) > 0 {
+		keyLen := int(rest[0])
+		if len(rest) < 1+keyLen+4 {
+			return "", nil, errors.New("malformed command property")
+		}
+
+		key := string(rest[1 : 1+keyLen])
+		valueLen := int(binary.BigEndian.Uint32(rest[1+keyLen : 5+keyLen]))
+		rest = rest[5+keyLen:]
+
+		if len(rest) < valueLen {
+			return "", nil, errors.New("malformed command property")
+		}
+
+		properties[key] = string(rest[:valueLen])
+		rest = rest[valueLen:]
+	}
+
+	return name, properties, nil
+}